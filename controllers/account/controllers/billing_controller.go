@@ -149,6 +149,25 @@ type BillingReconciler struct {
 	concurrentLimit        int64
 	DebtUserMap            *maps.ConcurrentMap
 	debtOwnerMap           *maps.ConcurrentNullValueMap
+	// WorkspaceBudget charges the settled billing to the workspace budgets, nil disables budgets.
+	WorkspaceBudget *WorkspaceBudgetController
+	workspaceSpent  *workspaceSpent
+}
+
+// workspaceSpent sums the settled billing amount of the workspaces in a billing hour.
+type workspaceSpent struct {
+	mu     sync.Mutex
+	amount map[string]int64
+}
+
+func (s *workspaceSpent) add(billings []*resources.Billing) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, billing := range billings {
+		if billing.Status == resources.Settled && billing.Amount > 0 {
+			s.amount[billing.Namespace] += billing.Amount
+		}
+	}
 }
 
 func (r *BillingReconciler) ExecuteBillingTask() error {
@@ -256,6 +275,7 @@ func (r *BillingReconciler) ExecuteBillingTaskAt(endHourTime time.Time) error {
 	DebtUserMap = maps.NewConcurrentNullValueMap()
 	SubscriptionWorkspaceMap = maps.NewConcurrentNullValueMap()
 	r.debtOwnerMap = maps.NewConcurrentNullValueMap()
+	r.workspaceSpent = &workspaceSpent{amount: map[string]int64{}}
 	var ownerListMap map[string][]string
 	var ownerUserUIDs map[string]uuid.UUID
 	inputStartedAt := time.Now()
@@ -294,6 +314,7 @@ func (r *BillingReconciler) ExecuteBillingTaskAt(endHourTime time.Time) error {
 	); err != nil {
 		return fmt.Errorf("failed to reconcile owner list batch: %w", err)
 	}
+	r.reconcileWorkspaceBudgets(endHourTime)
 	r.Info(
 		"finish billing reconcile",
 		"billingTime", endHourTime.Format(time.RFC3339),
@@ -627,6 +648,9 @@ func (r *BillingReconciler) reconcileOwnerList(
 				<-workers
 			}()
 			reconcileErr := r.reconcileBillingFunc(owner, billings, endHourTime)
+			if reconcileErr == nil && r.workspaceSpent != nil {
+				r.workspaceSpent.add(billings)
+			}
 			if reconcileErr != nil {
 				r.Error(
 					reconcileErr,
@@ -660,6 +684,29 @@ func billingBusinessKey(billing *resources.Billing) string {
 	return fmt.Sprintf("%s\x00%d\x00%s", billing.Namespace, billing.AppType, billing.AppName)
 }
 
+// reconcileWorkspaceBudgets charges the billing hour to the workspace budgets. A failure doesn't fail
+// the billing hour, the budgets of the next hour are reconciled anyway.
+func (r *BillingReconciler) reconcileWorkspaceBudgets(endHourTime time.Time) {
+	if r.WorkspaceBudget == nil || r.workspaceSpent == nil {
+		return
+	}
+	startedAt := time.Now()
+	inDebt := func(userUID string) bool {
+		if DebtUserMap == nil {
+			return false
+		}
+		_, ok := DebtUserMap.Get(userUID)
+		return ok
+	}
+	if err := r.WorkspaceBudget.ReconcileBillingHour(
+		context.Background(), r.workspaceSpent.amount, endHourTime, inDebt,
+	); err != nil {
+		r.Error(err, "failed to reconcile workspace budgets", "billingTime", endHourTime)
+		return
+	}
+	r.Info("reconcile workspace budgets", "duration", time.Since(startedAt))
+}
+
 func classifyGeneratedBillings(ownerBillings map[string][]*resources.Billing) {
 	for _, billings := range ownerBillings {
 		for _, billing := range billings {
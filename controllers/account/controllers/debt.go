@@ -26,6 +26,7 @@ import (
 	utils2 "github.com/labring/sealos/controllers/account/controllers/utils"
 	"github.com/labring/sealos/controllers/pkg/database"
 	"github.com/labring/sealos/controllers/pkg/database/cockroach"
+	"github.com/labring/sealos/controllers/pkg/resources"
 	"github.com/labring/sealos/controllers/pkg/types"
 	"github.com/labring/sealos/controllers/pkg/utils"
 	"github.com/labring/sealos/controllers/pkg/utils/env"
@@ -34,6 +35,7 @@ import (
 	userv1 "github.com/labring/sealos/controllers/user/api/v1"
 	"github.com/volcengine/volc-sdk-golang/service/vms"
 	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -1057,6 +1059,11 @@ func (r *DebtReconciler) SendUserDebtMsg(
 		}
 		emailRender.SetLanguage(r.debtEmailLanguage)
 		emailRender.SetBalance(oweamount)
+		if !types.ContainDebtStatus(types.DebtStates, currentStatus) {
+			if days, ok := r.runsOutInDays(userUID, oweamount); ok {
+				emailRender.SetRunsOutInDays(days)
+			}
+		}
 
 		if types.ContainDebtStatus(types.DebtStates, currentStatus) {
 			if r.debtEmailLanguage == "zh" {
@@ -1095,6 +1102,39 @@ func (r *DebtReconciler) SendUserDebtMsg(
 	return nil
 }
 
+// runsOutInDays estimates in how many days the balance is used up at the current usage of
+// the user's workspaces, the estimate is best effort and skipped on any error.
+func (r *DebtReconciler) runsOutInDays(userUID uuid.UUID, usableBalance int64) (int64, bool) {
+	if r.AccountReconciler == nil || r.DBClient == nil {
+		return 0, false
+	}
+	userCr, err := r.AccountV2.GetUserCr(&types.UserQueryOpts{UID: userUID})
+	if err != nil {
+		r.Logger.Error(err, "failed to get user cr for cost forecast", "user", userUID)
+		return 0, false
+	}
+	nsList := &corev1.NamespaceList{}
+	if err := r.List(
+		context.Background(),
+		nsList,
+		client.MatchingLabels{userv1.UserLabelOwnerKey: userCr.CrName},
+	); err != nil {
+		r.Logger.Error(err, "failed to list namespaces for cost forecast", "user", userUID)
+		return 0, false
+	}
+	namespaces := make([]string, 0, len(nsList.Items))
+	for i := range nsList.Items {
+		namespaces = append(namespaces, nsList.Items[i].Name)
+	}
+	monitors, err := r.DBClient.GetLatestMonitors(namespaces, time.Now())
+	if err != nil {
+		r.Logger.Error(err, "failed to get latest monitors for cost forecast", "user", userUID)
+		return 0, false
+	}
+	return resources.NewCostForecast(monitors, resources.DefaultPropertyTypeLS).
+		RunsOutInDays(usableBalance)
+}
+
 type AdminFlushResourceStatusReq struct {
 	UserUID             uuid.UUID            `json:"userUID"                       bson:"userUID"`
 	LastDebtStatus      types.DebtStatusType `json:"lastDebtStatus"                bson:"lastDebtStatus"`
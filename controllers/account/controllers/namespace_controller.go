@@ -55,6 +55,8 @@ type NamespaceReconciler struct {
 	// devboxVersion stores the detected devbox API version (v1alpha1 or v1alpha2)
 	// empty string if devbox CRD is not installed
 	devboxVersion string
+	// SuspendHandlers suspends and resumes the resources per kind, the built-in kinds are used if nil
+	SuspendHandlers *SuspendHandlerRegistry
 }
 
 const (
@@ -420,27 +422,23 @@ func (r *NamespaceReconciler) SuspendUserResource(ctx context.Context, namespace
 	//    which requires pod creation permissions.
 	// 2. limitResourceQuotaCreate runs immediately after to quickly block all new resource creation,
 	//    preventing any new workloads from being created during the suspension process.
-	pipelines := []func(context.Context, string) error{
-		r.suspendOrphanPod,          // Recreate orphan pods with debt scheduler (requires pod creation)
-		r.limitResourceQuotaCreate,  // Create resource quota to block all new resources (must be after suspendOrphanPod)
-		r.suspendKBCluster,          // Stop KubeBlocks clusters and disable backup
-		r.suspendDevboxes,           // Stop devboxes and save original state
-		r.suspendCertificates,       // Disable cert-manager certificate renewal
-		r.suspendIngresses,          // Pause ingresses by changing ingress class to "pause"
-		r.suspendOrphanDeployments,  // Scale orphan deployments to 0 replicas
-		r.suspendOrphanStatefulSets, // Scale orphan statefulsets to 0 replicas
-		r.suspendOrphanReplicaSets,  // Scale orphan replicasets to 0 replicas
-		r.suspendOrphanCronJob,      // Suspend orphan cronjobs
-		r.suspendOrphanJob,          // Suspend orphan jobs
-		r.deleteControlledPod,       // Delete controlled pods
-		r.suspendObjectStorage,      // Disable object storage access
-	}
+	// 3. The registered suspend handlers stop every kind, see defaultSuspendHandlers.
+	// 4. deleteControlledPod runs last, after the owners of the pods are scaled down.
 	var errs []error
-	for _, fn := range pipelines {
+	for _, fn := range []func(context.Context, string) error{
+		r.suspendOrphanPod,         // Recreate orphan pods with debt scheduler (requires pod creation)
+		r.limitResourceQuotaCreate, // Create resource quota to block all new resources (must be after suspendOrphanPod)
+	} {
 		if err := fn(ctx, namespace); err != nil {
 			errs = append(errs, err)
 		}
 	}
+	progress, handlerErrs := r.runSuspendHandlers(ctx, namespace, true)
+	errs = append(errs, handlerErrs...)
+	if err := r.deleteControlledPod(ctx, namespace); err != nil {
+		errs = append(errs, err)
+	}
+	r.updateSuspendProgress(ctx, namespace, true, progress)
 	return errors2.Join(errs...)
 }
 
@@ -555,26 +553,19 @@ func (r *NamespaceReconciler) DeleteUserResource(ctx context.Context, namespace
 }
 
 func (r *NamespaceReconciler) ResumeUserResource(ctx context.Context, namespace string) error {
-	pipelines := []func(context.Context, string) error{
+	// The resource quota is removed first so that the resumed workloads can create pods again.
+	var errs []error
+	for _, fn := range []func(context.Context, string) error{
 		r.limitResourceQuotaDelete, // Remove resource quota
 		r.resumeOrphanPod,          // Resume orphan pods
-		r.resumeKBCluster,          // Start KubeBlocks clusters and restore backup
-		r.resumeDevboxes,           // Restore devboxes to original state
-		r.resumeOrphanReplicaSets,  // Restore orphan replicaset replicas
-		r.resumeOrphanDeployments,  // Restore orphan deployment replicas
-		r.resumeOrphanStatefulSets, // Restore orphan statefulset replicas
-		r.resumeOrphanCronJob,      // Restore orphan cronjob suspend state
-		r.resumeOrphanJob,          // Restore orphan job suspend state
-		r.resumeCertificates,       // Restore certificate renewal
-		r.resumeIngresses,          // Restore ingresses by changing ingress class back
-		r.resumeObjectStorage,      // Enable object storage access
-	}
-	var errs []error
-	for _, fn := range pipelines {
+	} {
 		if err := fn(ctx, namespace); err != nil {
 			errs = append(errs, err)
 		}
 	}
+	progress, handlerErrs := r.runSuspendHandlers(ctx, namespace, false)
+	errs = append(errs, handlerErrs...)
+	r.updateSuspendProgress(ctx, namespace, false, progress)
 	return errors2.Join(errs...)
 }
 
@@ -1418,6 +1409,15 @@ func (r *NamespaceReconciler) SetupWithManager(
 		r.Log.Info("Devbox CRD not detected, devbox suspend/resume will be skipped")
 	}
 
+	if r.SuspendHandlers == nil {
+		r.SuspendHandlers = r.defaultSuspendHandlers()
+	}
+	if path := os.Getenv(EnvSuspendHandlersConfig); path != "" {
+		if err := r.registerConfiguredSuspendHandlers(path); err != nil {
+			return err
+		}
+	}
+
 	if r.OSAdminSecret == "" || r.InternalEndpoint == "" || r.OSNamespace == "" {
 		r.Log.V(1).
 			Info("failed to get the endpoint or namespace or admin secret env of object storage")
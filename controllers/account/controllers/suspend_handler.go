@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NamespaceSuspendProgressCondition reports the suspend or resume progress of every kind in the namespace.
+const NamespaceSuspendProgressCondition corev1.NamespaceConditionType = "SuspendProgress"
+
+// SuspendHandler suspends and resumes the objects of one kind in a namespace.
+type SuspendHandler interface {
+	GroupVersionKind() schema.GroupVersionKind
+	Suspend(ctx context.Context, namespace string) error
+	Resume(ctx context.Context, namespace string) error
+	// Suspended reports whether every object of the kind in the namespace is suspended.
+	Suspended(ctx context.Context, namespace string) (bool, error)
+}
+
+// SuspendHandlerRegistry keeps the suspend handlers keyed by GVK,
+// handlers are run in the order they are registered.
+type SuspendHandlerRegistry struct {
+	handlers map[schema.GroupVersionKind]SuspendHandler
+	order    []schema.GroupVersionKind
+}
+
+func NewSuspendHandlerRegistry() *SuspendHandlerRegistry {
+	return &SuspendHandlerRegistry{handlers: map[schema.GroupVersionKind]SuspendHandler{}}
+}
+
+// Register adds a handler, there can be only one handler for a GVK.
+func (r *SuspendHandlerRegistry) Register(h SuspendHandler) error {
+	gvk := h.GroupVersionKind()
+	if gvk.Kind == "" {
+		return fmt.Errorf("suspend handler without kind")
+	}
+	if _, ok := r.handlers[gvk]; ok {
+		return fmt.Errorf("suspend handler for %s is already registered", gvk)
+	}
+	r.handlers[gvk] = h
+	r.order = append(r.order, gvk)
+	return nil
+}
+
+func (r *SuspendHandlerRegistry) Get(gvk schema.GroupVersionKind) (SuspendHandler, bool) {
+	h, ok := r.handlers[gvk]
+	return h, ok
+}
+
+func (r *SuspendHandlerRegistry) Handlers() []SuspendHandler {
+	handlers := make([]SuspendHandler, 0, len(r.order))
+	for _, gvk := range r.order {
+		handlers = append(handlers, r.handlers[gvk])
+	}
+	return handlers
+}
+
+// funcSuspendHandler adapts the suspend and resume functions of NamespaceReconciler to SuspendHandler.
+type funcSuspendHandler struct {
+	gvk     schema.GroupVersionKind
+	suspend func(context.Context, string) error
+	resume  func(context.Context, string) error
+	// suspended is nil if the suspension can't be read back from the objects
+	suspended func(context.Context, string) (bool, error)
+}
+
+func (h *funcSuspendHandler) GroupVersionKind() schema.GroupVersionKind { return h.gvk }
+
+func (h *funcSuspendHandler) Suspend(ctx context.Context, namespace string) error {
+	return h.suspend(ctx, namespace)
+}
+
+func (h *funcSuspendHandler) Resume(ctx context.Context, namespace string) error {
+	return h.resume(ctx, namespace)
+}
+
+func (h *funcSuspendHandler) Suspended(ctx context.Context, namespace string) (bool, error) {
+	if h.suspended == nil {
+		return true, nil
+	}
+	return h.suspended(ctx, namespace)
+}
+
+// annotatedSuspended returns a detector which treats the objects carrying OriginalSuspendStateAnnotation
+// as suspended, objects with a controller are ignored if orphanOnly is set.
+func (r *NamespaceReconciler) annotatedSuspended(
+	gvr schema.GroupVersionResource,
+	orphanOnly bool,
+) func(context.Context, string) (bool, error) {
+	return func(ctx context.Context, namespace string) (bool, error) {
+		if r.dynamicClient == nil {
+			return true, nil
+		}
+		list, err := r.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, v12.ListOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		for _, obj := range list.Items {
+			if orphanOnly && hasController(obj.GetOwnerReferences()) {
+				continue
+			}
+			if _, ok := obj.GetAnnotations()[OriginalSuspendStateAnnotation]; !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// defaultSuspendHandlers registers the handlers of the kinds built into the controller, orphan pods and
+// the resource quota are not among them because they fence the handlers, see SuspendUserResource.
+func (r *NamespaceReconciler) defaultSuspendHandlers() *SuspendHandlerRegistry {
+	registry := NewSuspendHandlerRegistry()
+	register := func(gvr schema.GroupVersionResource, kind string, orphanOnly bool,
+		suspend, resume func(context.Context, string) error,
+	) {
+		_ = registry.Register(&funcSuspendHandler{
+			gvk:       gvr.GroupVersion().WithKind(kind),
+			suspend:   suspend,
+			resume:    resume,
+			suspended: r.annotatedSuspended(gvr, orphanOnly),
+		})
+	}
+
+	register(
+		schema.GroupVersionResource{
+			Group:    "apps.kubeblocks.io",
+			Version:  "v1alpha1",
+			Resource: "clusters",
+		},
+		"Cluster",
+		false,
+		r.suspendKBCluster,
+		r.resumeKBCluster,
+	)
+	if r.devboxVersion != "" {
+		register(
+			schema.GroupVersionResource{
+				Group:    "devbox.sealos.io",
+				Version:  r.devboxVersion,
+				Resource: "devboxes",
+			},
+			"Devbox",
+			false,
+			r.suspendDevboxes,
+			r.resumeDevboxes,
+		)
+	}
+	register(
+		schema.GroupVersionResource{
+			Group:    "cert-manager.io",
+			Version:  "v1",
+			Resource: "certificates",
+		},
+		"Certificate",
+		false,
+		r.suspendCertificates,
+		r.resumeCertificates,
+	)
+	register(
+		schema.GroupVersionResource{
+			Group:    "networking.k8s.io",
+			Version:  "v1",
+			Resource: "ingresses",
+		},
+		"Ingress",
+		false,
+		r.suspendIngresses,
+		r.resumeIngresses,
+	)
+	register(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		"Deployment", true, r.suspendOrphanDeployments, r.resumeOrphanDeployments)
+	register(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		"StatefulSet", true, r.suspendOrphanStatefulSets, r.resumeOrphanStatefulSets)
+	register(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"},
+		"ReplicaSet", true, r.suspendOrphanReplicaSets, r.resumeOrphanReplicaSets)
+	register(schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"},
+		"CronJob", true, r.suspendOrphanCronJob, r.resumeOrphanCronJob)
+	register(schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"},
+		"Job", true, r.suspendOrphanJob, r.resumeOrphanJob)
+	// the state of object storage users lives in minio, there is nothing to read back
+	_ = registry.Register(&funcSuspendHandler{
+		gvk: schema.GroupVersionKind{
+			Group:   "objectstorage.sealos.io",
+			Version: "v1",
+			Kind:    "ObjectStorageUser",
+		},
+		suspend: r.suspendObjectStorage,
+		resume:  r.resumeObjectStorage,
+	})
+	return registry
+}
+
+func (r *NamespaceReconciler) suspendHandlers() *SuspendHandlerRegistry {
+	if r.SuspendHandlers == nil {
+		r.SuspendHandlers = r.defaultSuspendHandlers()
+	}
+	return r.SuspendHandlers
+}
+
+type kindProgress struct {
+	kind  string
+	state string
+	err   error
+}
+
+func (p kindProgress) String() string {
+	if p.err != nil {
+		return fmt.Sprintf("%s: %s: %v", p.kind, p.state, p.err)
+	}
+	return p.kind + ": " + p.state
+}
+
+func gvkString(gvk schema.GroupVersionKind) string {
+	if gvk.Group == "" {
+		return gvk.Kind
+	}
+	return gvk.Kind + "." + gvk.Group
+}
+
+// runSuspendHandlers suspends or resumes every registered kind, a failing kind doesn't stop the others.
+func (r *NamespaceReconciler) runSuspendHandlers(
+	ctx context.Context,
+	namespace string,
+	suspend bool,
+) ([]kindProgress, []error) {
+	var (
+		progress []kindProgress
+		errs     []error
+	)
+	for _, h := range r.suspendHandlers().Handlers() {
+		p := kindProgress{kind: gvkString(h.GroupVersionKind())}
+		if suspend {
+			p.err = h.Suspend(ctx, namespace)
+		} else {
+			p.err = h.Resume(ctx, namespace)
+		}
+		switch {
+		case p.err != nil:
+			p.state = "failed"
+			errs = append(errs, fmt.Errorf("%s: %w", p.kind, p.err))
+		case !suspend:
+			p.state = "resumed"
+		default:
+			p.state = "suspended"
+			if ok, err := h.Suspended(ctx, namespace); err != nil {
+				r.Log.Error(
+					err,
+					"failed to check suspension",
+					"Namespace",
+					namespace,
+					"Kind",
+					p.kind,
+				)
+				p.state = "unknown"
+			} else if !ok {
+				p.state = "pending"
+			}
+		}
+		progress = append(progress, p)
+	}
+	return progress, errs
+}
+
+// updateSuspendProgress records the progress of every kind in the status condition of the namespace,
+// it is best effort and never fails the reconciliation.
+func (r *NamespaceReconciler) updateSuspendProgress(
+	ctx context.Context,
+	namespace string,
+	suspend bool,
+	progress []kindProgress,
+) {
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if !errors.IsNotFound(err) {
+			r.Log.Error(err, "failed to get namespace for suspend progress", "Namespace", namespace)
+		}
+		return
+	}
+
+	done, reason := "Suspended", "Suspending"
+	if !suspend {
+		done, reason = "Resumed", "Resuming"
+	}
+	status := corev1.ConditionTrue
+	messages := make([]string, len(progress))
+	for i, p := range progress {
+		messages[i] = p.String()
+		if p.state != strings.ToLower(done) {
+			status = corev1.ConditionFalse
+		}
+	}
+	if status == corev1.ConditionTrue {
+		reason = done
+	}
+	condition := corev1.NamespaceCondition{
+		Type:               NamespaceSuspendProgressCondition,
+		Status:             status,
+		LastTransitionTime: v12.Now(),
+		Reason:             reason,
+		Message:            strings.Join(messages, "; "),
+	}
+
+	conditions := ns.Status.Conditions[:0:0]
+	for _, c := range ns.Status.Conditions {
+		if c.Type != NamespaceSuspendProgressCondition {
+			conditions = append(conditions, c)
+		} else if c.Status == condition.Status {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+	}
+	ns.Status.Conditions = append(conditions, condition)
+	if err := r.Client.Status().Update(ctx, ns); err != nil {
+		r.Log.Error(err, "failed to update suspend progress", "Namespace", namespace)
+	}
+}
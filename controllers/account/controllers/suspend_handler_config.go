@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/labring/sealos/controllers/pkg/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// EnvSuspendHandlersConfig is the path of the file declaring suspend handlers of extra kinds.
+const EnvSuspendHandlersConfig = "SUSPEND_HANDLERS_CONFIG"
+
+// SuspendHandlersConfig declares suspend handlers of extra kinds, e.g. to suspend Argo Workflows:
+//
+//	handlers:
+//	- group: argoproj.io
+//	  version: v1alpha1
+//	  kind: Workflow
+//	  resource: workflows
+//	  suspendPatch:
+//	    spec:
+//	      suspend: true
+type SuspendHandlersConfig struct {
+	Handlers []PatchSuspendHandlerConfig `yaml:"handlers"`
+}
+
+// PatchSuspendHandlerConfig suspends the objects of a kind with a JSON merge patch. The values replaced by
+// the patch are saved in OriginalSuspendStateAnnotation and put back on resume.
+type PatchSuspendHandlerConfig struct {
+	Group    string `yaml:"group"`
+	Version  string `yaml:"version"`
+	Kind     string `yaml:"kind"`
+	Resource string `yaml:"resource"`
+	// OrphanOnly skips the objects which have a controller, the controller is suspended instead
+	OrphanOnly   bool           `yaml:"orphanOnly"`
+	SuspendPatch map[string]any `yaml:"suspendPatch"`
+}
+
+func (c PatchSuspendHandlerConfig) validate() error {
+	if c.Version == "" || c.Kind == "" || c.Resource == "" {
+		return fmt.Errorf("version, kind and resource are required")
+	}
+	if len(c.SuspendPatch) == 0 {
+		return fmt.Errorf("suspendPatch of %s is empty", c.Kind)
+	}
+	return nil
+}
+
+// LoadSuspendHandlersConfig reads the suspend handlers from path.
+func LoadSuspendHandlersConfig(path string) (*SuspendHandlersConfig, error) {
+	cfg := &SuspendHandlersConfig{}
+	if err := config.LoadConfig(path, cfg); err != nil {
+		return nil, fmt.Errorf("failed to load suspend handlers config %s: %w", path, err)
+	}
+	for i := range cfg.Handlers {
+		if err := cfg.Handlers[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid suspend handler %d: %w", i, err)
+		}
+	}
+	return cfg, nil
+}
+
+type patchSuspendHandler struct {
+	cfg           PatchSuspendHandlerConfig
+	gvr           schema.GroupVersionResource
+	dynamicClient dynamic.Interface
+	log           logr.Logger
+}
+
+func NewPatchSuspendHandler(
+	cfg PatchSuspendHandlerConfig,
+	dynamicClient dynamic.Interface,
+	log logr.Logger,
+) SuspendHandler {
+	return &patchSuspendHandler{
+		cfg: cfg,
+		gvr: schema.GroupVersionResource{
+			Group:    cfg.Group,
+			Version:  cfg.Version,
+			Resource: cfg.Resource,
+		},
+		dynamicClient: dynamicClient,
+		log:           log.WithValues("Kind", cfg.Kind),
+	}
+}
+
+func (h *patchSuspendHandler) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: h.cfg.Group, Version: h.cfg.Version, Kind: h.cfg.Kind}
+}
+
+func (h *patchSuspendHandler) list(
+	ctx context.Context,
+	namespace string,
+) ([]unstructured.Unstructured, error) {
+	list, err := h.dynamicClient.Resource(h.gvr).Namespace(namespace).List(ctx, v12.ListOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %s: %w", h.gvr.Resource, err)
+	}
+	items := list.Items[:0]
+	for _, obj := range list.Items {
+		if h.cfg.OrphanOnly && hasController(obj.GetOwnerReferences()) {
+			continue
+		}
+		items = append(items, obj)
+	}
+	return items, nil
+}
+
+func (h *patchSuspendHandler) patch(
+	ctx context.Context,
+	obj *unstructured.Unstructured,
+	patch map[string]any,
+) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = h.dynamicClient.Resource(h.gvr).Namespace(obj.GetNamespace()).
+		Patch(ctx, obj.GetName(), k8stypes.MergePatchType, data, v12.PatchOptions{})
+	return err
+}
+
+func (h *patchSuspendHandler) Suspend(ctx context.Context, namespace string) error {
+	items, err := h.list(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range items {
+		obj := &items[i]
+		if _, ok := obj.GetAnnotations()[OriginalSuspendStateAnnotation]; ok {
+			continue
+		}
+		state, err := json.Marshal(restorePatch(obj.Object, h.cfg.SuspendPatch))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to encode state of %s: %w", obj.GetName(), err))
+			continue
+		}
+		patch := mergePatches(h.cfg.SuspendPatch, annotationPatch(string(state)))
+		if err := h.patch(ctx, obj, patch); err != nil {
+			errs = append(errs, fmt.Errorf("failed to suspend %s: %w", obj.GetName(), err))
+			continue
+		}
+		h.log.V(1).Info("Suspended object", "Namespace", namespace, "Name", obj.GetName())
+	}
+	return errors.Join(errs...)
+}
+
+func (h *patchSuspendHandler) Resume(ctx context.Context, namespace string) error {
+	items, err := h.list(ctx, namespace)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i := range items {
+		obj := &items[i]
+		state, ok := obj.GetAnnotations()[OriginalSuspendStateAnnotation]
+		if !ok {
+			continue
+		}
+		restore := map[string]any{}
+		if err := json.Unmarshal([]byte(state), &restore); err != nil {
+			errs = append(errs, fmt.Errorf("failed to decode state of %s: %w", obj.GetName(), err))
+			continue
+		}
+		if err := h.patch(ctx, obj, mergePatches(restore, annotationPatch(nil))); err != nil {
+			errs = append(errs, fmt.Errorf("failed to resume %s: %w", obj.GetName(), err))
+			continue
+		}
+		h.log.V(1).Info("Resumed object", "Namespace", namespace, "Name", obj.GetName())
+	}
+	return errors.Join(errs...)
+}
+
+func (h *patchSuspendHandler) Suspended(ctx context.Context, namespace string) (bool, error) {
+	items, err := h.list(ctx, namespace)
+	if err != nil {
+		return false, err
+	}
+	for _, obj := range items {
+		if _, ok := obj.GetAnnotations()[OriginalSuspendStateAnnotation]; !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// restorePatch returns the merge patch which puts back the values of obj replaced by patch,
+// fields missing from obj are removed by a null.
+func restorePatch(obj, patch map[string]any) map[string]any {
+	restore := make(map[string]any, len(patch))
+	for key, value := range patch {
+		current, exists := obj[key]
+		sub, isMap := value.(map[string]any)
+		currentMap, currentIsMap := current.(map[string]any)
+		switch {
+		case isMap && currentIsMap:
+			restore[key] = restorePatch(currentMap, sub)
+		case exists:
+			restore[key] = current
+		default:
+			restore[key] = nil
+		}
+	}
+	return restore
+}
+
+func annotationPatch(value any) map[string]any {
+	return map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{OriginalSuspendStateAnnotation: value},
+		},
+	}
+}
+
+// mergePatches deep merges b into a copy of a.
+func mergePatches(a, b map[string]any) map[string]any {
+	merged := make(map[string]any, len(a)+len(b))
+	for key, value := range a {
+		merged[key] = value
+	}
+	for key, value := range b {
+		am, aIsMap := merged[key].(map[string]any)
+		bm, bIsMap := value.(map[string]any)
+		if aIsMap && bIsMap {
+			merged[key] = mergePatches(am, bm)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// registerConfiguredSuspendHandlers adds the handlers declared in the file of EnvSuspendHandlersConfig,
+// a kind which already has a handler is skipped.
+func (r *NamespaceReconciler) registerConfiguredSuspendHandlers(path string) error {
+	cfg, err := LoadSuspendHandlersConfig(path)
+	if err != nil {
+		return err
+	}
+	registry := r.suspendHandlers()
+	for _, hc := range cfg.Handlers {
+		if err := registry.Register(NewPatchSuspendHandler(hc, r.dynamicClient, r.Log)); err != nil {
+			r.Log.Error(err, "skip configured suspend handler")
+			continue
+		}
+		r.Log.Info(
+			"Registered suspend handler",
+			"Group",
+			hc.Group,
+			"Version",
+			hc.Version,
+			"Kind",
+			hc.Kind,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSuspendHandlerRegistry(t *testing.T) {
+	r := &NamespaceReconciler{Log: logr.Discard()}
+	registry := r.defaultSuspendHandlers()
+
+	deployGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	if _, ok := registry.Get(deployGVK); !ok {
+		t.Fatal("expected a built-in handler for deployments")
+	}
+	devboxGVK := schema.GroupVersionKind{Group: "devbox.sealos.io", Version: "v1alpha2", Kind: "Devbox"}
+	if _, ok := registry.Get(devboxGVK); ok {
+		t.Error("devbox handler should not be registered without the devbox CRD")
+	}
+	if err := registry.Register(&funcSuspendHandler{gvk: deployGVK}); err == nil {
+		t.Error("expected an error for a duplicate handler")
+	}
+
+	workflowGVK := schema.GroupVersionKind{
+		Group:   "argoproj.io",
+		Version: "v1alpha1",
+		Kind:    "Workflow",
+	}
+	if err := registry.Register(&funcSuspendHandler{gvk: workflowGVK}); err != nil {
+		t.Fatal(err)
+	}
+	handlers := registry.Handlers()
+	if got := handlers[len(handlers)-1].GroupVersionKind(); got != workflowGVK {
+		t.Errorf("handlers should keep the registration order, got %s last", got)
+	}
+}
+
+func TestPatchSuspendHandler(t *testing.T) {
+	gvr := schema.GroupVersionResource{
+		Group:    "argoproj.io",
+		Version:  "v1alpha1",
+		Resource: "workflows",
+	}
+	newWorkflow := func(name string, spec map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+		obj.SetAPIVersion("argoproj.io/v1alpha1")
+		obj.SetKind("Workflow")
+		obj.SetNamespace("ns-test")
+		obj.SetName(name)
+		return obj
+	}
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "WorkflowList"},
+		newWorkflow("plain", map[string]any{"entrypoint": "main"}),
+		newWorkflow("paused", map[string]any{"entrypoint": "main", "suspend": false}),
+	)
+	h := NewPatchSuspendHandler(PatchSuspendHandlerConfig{
+		Group:        "argoproj.io",
+		Version:      "v1alpha1",
+		Kind:         "Workflow",
+		Resource:     "workflows",
+		SuspendPatch: map[string]any{"spec": map[string]any{"suspend": true}},
+	}, dynamicClient, logr.Discard())
+
+	ctx := context.Background()
+	get := func(name string) *unstructured.Unstructured {
+		obj, err := dynamicClient.Resource(gvr).Namespace("ns-test").Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return obj
+	}
+
+	if ok, err := h.Suspended(ctx, "ns-test"); err != nil || ok {
+		t.Fatalf("expected workflows not suspended, got %v %v", ok, err)
+	}
+	if err := h.Suspend(ctx, "ns-test"); err != nil {
+		t.Fatal(err)
+	}
+	// suspending twice must not overwrite the saved state
+	if err := h.Suspend(ctx, "ns-test"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"plain", "paused"} {
+		if suspend, _, _ := unstructured.NestedBool(get(name).Object, "spec", "suspend"); !suspend {
+			t.Errorf("workflow %s is not suspended", name)
+		}
+	}
+	if ok, err := h.Suspended(ctx, "ns-test"); err != nil || !ok {
+		t.Fatalf("expected workflows suspended, got %v %v", ok, err)
+	}
+
+	if err := h.Resume(ctx, "ns-test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(get("plain").Object, "spec", "suspend"); found {
+		t.Error("spec.suspend should be removed from a workflow which didn't have it")
+	}
+	paused := get("paused")
+	if suspend, found, _ := unstructured.NestedBool(paused.Object, "spec", "suspend"); !found ||
+		suspend {
+		t.Errorf("spec.suspend should be restored to false, got %v %v", suspend, found)
+	}
+	if _, ok := paused.GetAnnotations()[OriginalSuspendStateAnnotation]; ok {
+		t.Error("the saved state should be removed on resume")
+	}
+}
+
+func TestLoadSuspendHandlersConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "handlers.yaml")
+	data := `handlers:
+- group: argoproj.io
+  version: v1alpha1
+  kind: CronWorkflow
+  resource: cronworkflows
+  suspendPatch:
+    spec:
+      suspend: true
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadSuspendHandlersConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Handlers) != 1 || cfg.Handlers[0].Kind != "CronWorkflow" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	spec, _ := cfg.Handlers[0].SuspendPatch["spec"].(map[string]any)
+	if spec["suspend"] != true {
+		t.Errorf("unexpected suspend patch %v", cfg.Handlers[0].SuspendPatch)
+	}
+
+	if err := os.WriteFile(path, []byte("handlers:\n- kind: Workflow\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSuspendHandlersConfig(path); err == nil {
+		t.Error("expected an error for a handler without resource and patch")
+	}
+}
+
+func TestUpdateSuspendProgress(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{Name: "ns-test"}}
+	fakeClient := clientfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(ns).
+		WithStatusSubresource(ns).
+		Build()
+	r := &NamespaceReconciler{Client: fakeClient, Log: logr.Discard()}
+
+	ctx := context.Background()
+	r.updateSuspendProgress(ctx, "ns-test", true, []kindProgress{
+		{kind: "Deployment.apps", state: "suspended"},
+		{kind: "Workflow.argoproj.io", state: "pending"},
+	})
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(ns), ns); err != nil {
+		t.Fatal(err)
+	}
+	if len(ns.Status.Conditions) != 1 {
+		t.Fatalf("expected 1 condition, got %d", len(ns.Status.Conditions))
+	}
+	c := ns.Status.Conditions[0]
+	if c.Type != NamespaceSuspendProgressCondition || c.Status != corev1.ConditionFalse ||
+		c.Reason != "Suspending" ||
+		c.Message != "Deployment.apps: suspended; Workflow.argoproj.io: pending" {
+		t.Errorf("unexpected condition %+v", c)
+	}
+
+	r.updateSuspendProgress(
+		ctx,
+		"ns-test",
+		false,
+		[]kindProgress{{kind: "Deployment.apps", state: "resumed"}},
+	)
+	if err := fakeClient.Get(ctx, client.ObjectKeyFromObject(ns), ns); err != nil {
+		t.Fatal(err)
+	}
+	if c := ns.Status.Conditions[0]; len(ns.Status.Conditions) != 1 ||
+		c.Status != corev1.ConditionTrue ||
+		c.Reason != "Resumed" {
+		t.Errorf("unexpected conditions %+v", ns.Status.Conditions)
+	}
+}
@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	usernotify "github.com/labring/sealos/controllers/pkg/user_notify"
+	corev1 "k8s.io/api/core/v1"
+	types2 "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WorkspaceBudgetController charges the settled billing of workspaces to their monthly budgets. It
+// alerts the owner of a workspace when its budget reaches a threshold and soft-stops the workspace
+// once the budget is used up if the budget asks for it, the other workspaces of the owner keep
+// running.
+type WorkspaceBudgetController struct {
+	*AccountReconciler
+}
+
+// NewWorkspaceBudgetController creates a new workspace budget controller
+func NewWorkspaceBudgetController(ar *AccountReconciler) *WorkspaceBudgetController {
+	return &WorkspaceBudgetController{AccountReconciler: ar}
+}
+
+// ReconcileBillingHour charges the settled amount of every workspace billed in the hour ending at
+// hour, then resumes the soft-stopped workspaces whose budget is no longer used up. inDebt reports
+// whether the owner of a workspace is in debt, such a workspace is left to the debt controller.
+func (c *WorkspaceBudgetController) ReconcileBillingHour(
+	ctx context.Context,
+	spent map[string]int64,
+	hour time.Time,
+	inDebt func(userUID string) bool,
+) error {
+	budgets, err := c.AccountV2.ListWorkspaceBudgets(c.localDomain, "")
+	if err != nil {
+		return err
+	}
+	// the billing of the hour belongs to the period the hour starts in
+	billingTime := hour.Add(-time.Hour)
+	var errs []error
+	for i := range budgets {
+		amount, ok := spent[budgets[i].Workspace]
+		if !ok || amount <= 0 {
+			continue
+		}
+		budget, err := c.AccountV2.ChargeWorkspaceBudget(
+			budgets[i].Workspace, c.localDomain, amount, hour,
+		)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", budgets[i].Workspace, err))
+			continue
+		}
+		if budget == nil {
+			continue
+		}
+		if err := c.enforceBudget(ctx, budget, billingTime); err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", budget.Workspace, err))
+		}
+	}
+	for i := range budgets {
+		budget := &budgets[i]
+		if budget.Status != types.WorkspaceBudgetStatusSoftStopped {
+			continue
+		}
+		if _, charged := spent[budget.Workspace]; charged {
+			// the budget was reloaded and enforced above
+			continue
+		}
+		if budget.SoftStop && budget.Exhausted(hour) {
+			// the debt and subscription controllers may have resumed the workspace since it was stopped
+			if err := c.softStopWorkspace(ctx, budget.Workspace); err != nil {
+				errs = append(errs, fmt.Errorf("workspace %s: %w", budget.Workspace, err))
+			}
+			continue
+		}
+		if err := c.resumeWorkspace(
+			ctx, budget.Workspace, inDebt != nil && inDebt(budget.UserUID.String()),
+		); err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", budget.Workspace, err))
+			continue
+		}
+		if err := c.AccountV2.UpdateWorkspaceBudgetState(
+			budget.ID, budget.AlertedPercent, types.WorkspaceBudgetStatusNormal,
+		); err != nil {
+			errs = append(errs, fmt.Errorf("workspace %s: %w", budget.Workspace, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// enforceBudget alerts the threshold the budget reaches and soft-stops the workspace when the budget
+// is used up, now is in the period the budget is charged for.
+func (c *WorkspaceBudgetController) enforceBudget(
+	ctx context.Context,
+	budget *types.WorkspaceBudget,
+	now time.Time,
+) error {
+	threshold := budget.PendingAlertThreshold(now)
+	status := budget.Status
+	switch {
+	case budget.SoftStop && budget.Exhausted(now):
+		// a soft-stopped workspace is stopped again if the debt or subscription controllers resumed it
+		if err := c.softStopWorkspace(ctx, budget.Workspace); err != nil {
+			return fmt.Errorf("failed to soft-stop workspace: %w", err)
+		}
+		status = types.WorkspaceBudgetStatusSoftStopped
+	case status == types.WorkspaceBudgetStatusSoftStopped:
+		// the limit is raised or soft stop is disabled since the workspace was stopped
+		if err := c.resumeWorkspace(ctx, budget.Workspace, false); err != nil {
+			return fmt.Errorf("failed to resume workspace: %w", err)
+		}
+		status = types.WorkspaceBudgetStatusNormal
+	}
+	if threshold == 0 && status == budget.Status {
+		return nil
+	}
+	alerted := max(threshold, budget.AlertedPercent)
+	if err := c.AccountV2.UpdateWorkspaceBudgetState(budget.ID, alerted, status); err != nil {
+		return fmt.Errorf("failed to update workspace budget state: %w", err)
+	}
+	if threshold != 0 {
+		c.sendBudgetAlert(
+			ctx, budget, now, threshold, status == types.WorkspaceBudgetStatusSoftStopped,
+		)
+	}
+	return nil
+}
+
+// softStopWorkspace suspends the resources of the workspace through the debt status of its
+// namespace, a workspace which is still suspended is left alone.
+func (c *WorkspaceBudgetController) softStopWorkspace(ctx context.Context, workspace string) error {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types2.NamespacedName{Name: workspace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	switch ns.Annotations[types.DebtNamespaceAnnoStatusKey] {
+	case types.SuspendDebtNamespaceAnnoStatus,
+		types.SuspendCompletedDebtNamespaceAnnoStatus,
+		types.FinalDeletionDebtNamespaceAnnoStatus,
+		types.FinalDeletionCompletedDebtNamespaceAnnoStatus:
+		return nil
+	}
+	original := ns.DeepCopy()
+	if ns.Annotations == nil {
+		ns.Annotations = make(map[string]string)
+	}
+	ns.Annotations[types.DebtNamespaceAnnoStatusKey] = types.SuspendDebtNamespaceAnnoStatus
+	ns.Annotations[types.WorkspaceBudgetStatusAnnoKey] = types.SuspendDebtNamespaceAnnoStatus
+	if err := c.Patch(ctx, ns, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("patch namespace annotation failed: %w", err)
+	}
+	return nil
+}
+
+// resumeWorkspace resumes the workspace soft-stopped by its budget. The workspace stays suspended if
+// its owner is in debt, the debt controller resumes it once the debt is paid.
+func (c *WorkspaceBudgetController) resumeWorkspace(
+	ctx context.Context,
+	workspace string,
+	ownerInDebt bool,
+) error {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, types2.NamespacedName{Name: workspace}, ns); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if ns.Annotations[types.WorkspaceBudgetStatusAnnoKey] == "" {
+		return nil
+	}
+	original := ns.DeepCopy()
+	delete(ns.Annotations, types.WorkspaceBudgetStatusAnnoKey)
+	switch ns.Annotations[types.DebtNamespaceAnnoStatusKey] {
+	case types.SuspendDebtNamespaceAnnoStatus, types.SuspendCompletedDebtNamespaceAnnoStatus:
+		if !ownerInDebt {
+			ns.Annotations[types.DebtNamespaceAnnoStatusKey] = types.ResumeDebtNamespaceAnnoStatus
+		}
+	}
+	if err := c.Patch(ctx, ns, client.MergeFrom(original)); err != nil {
+		return fmt.Errorf("patch namespace annotation failed: %w", err)
+	}
+	return nil
+}
+
+func (c *WorkspaceBudgetController) sendBudgetAlert(
+	ctx context.Context,
+	budget *types.WorkspaceBudget,
+	now time.Time,
+	threshold int64,
+	softStopped bool,
+) {
+	if c.UserContactProvider == nil || c.UserNotificationService == nil {
+		return
+	}
+	userUID := budget.UserUID
+	nr, err := c.AccountV2.GetNotificationRecipient(userUID)
+	if err != nil {
+		c.Logger.Error(err, "failed to get notification recipient", "user", userUID)
+		return
+	}
+	c.UserContactProvider.SetUserContact(userUID, nr)
+	defer c.UserContactProvider.RemoveUserContact(userUID)
+
+	eventData := &usernotify.WorkspaceBudgetEventData{
+		Type:         usernotify.EventTypeWorkspaceBudgetAlert,
+		RegionDomain: budget.RegionDomain,
+		Workspace:    budget.Workspace,
+		UsagePercent: budget.UsedPercent(now),
+		Threshold:    threshold,
+		MonthlyLimit: budget.MonthlyLimit,
+		Spent:        budget.CurrentSpent(now),
+		SoftStopped:  softStopped,
+	}
+	priority := usernotify.NotificationPriorityNormal
+	if softStopped {
+		priority = usernotify.NotificationPriorityHigh
+	}
+	if _, err = c.UserNotificationService.SendEventNotification(ctx, &usernotify.NotificationEvent{
+		UserUID:   userUID,
+		EventType: eventData.GetType(),
+		EventData: eventData.ToMap(),
+		Methods:   []usernotify.NotificationMethod{usernotify.NotificationMethodEmail},
+		Priority:  priority,
+	}); err != nil {
+		c.Logger.Error(
+			err, "failed to send workspace budget alert",
+			"user", userUID, "workspace", budget.Workspace,
+		)
+	}
+}
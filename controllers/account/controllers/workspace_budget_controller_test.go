@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/database"
+	"github.com/labring/sealos/controllers/pkg/types"
+	usernotify "github.com/labring/sealos/controllers/pkg/user_notify"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	types2 "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type budgetTestAccountV2 struct {
+	database.AccountV2
+	budgets map[string]*types.WorkspaceBudget
+}
+
+func (f *budgetTestAccountV2) ListWorkspaceBudgets(
+	_ string,
+	status types.WorkspaceBudgetStatus,
+) ([]types.WorkspaceBudget, error) {
+	var budgets []types.WorkspaceBudget
+	for _, b := range f.budgets {
+		if status == "" || b.Status == status {
+			budgets = append(budgets, *b)
+		}
+	}
+	return budgets, nil
+}
+
+func (f *budgetTestAccountV2) ChargeWorkspaceBudget(
+	workspace, _ string,
+	amount int64,
+	hour time.Time,
+) (*types.WorkspaceBudget, error) {
+	b, ok := f.budgets[workspace]
+	if !ok {
+		return nil, nil
+	}
+	if period := types.WorkspaceBudgetPeriodStart(hour.Add(-time.Hour)); period.After(
+		b.PeriodStartAt,
+	) {
+		b.PeriodStartAt, b.Spent, b.AlertedPercent = period, 0, 0
+	}
+	b.Spent += amount
+	b.LastChargedAt = hour
+	budget := *b
+	return &budget, nil
+}
+
+func (f *budgetTestAccountV2) UpdateWorkspaceBudgetState(
+	id uuid.UUID,
+	alertedPercent int64,
+	status types.WorkspaceBudgetStatus,
+) error {
+	for _, b := range f.budgets {
+		if b.ID == id {
+			b.AlertedPercent, b.Status = alertedPercent, status
+		}
+	}
+	return nil
+}
+
+func (f *budgetTestAccountV2) GetNotificationRecipient(
+	userUID uuid.UUID,
+) (*types.NotificationRecipient, error) {
+	return &types.NotificationRecipient{UserUID: userUID, Email: "user@example.com"}, nil
+}
+
+type budgetTestNotificationService struct {
+	usernotify.EventNotificationService
+	events []*usernotify.NotificationEvent
+}
+
+func (f *budgetTestNotificationService) SendEventNotification(
+	_ context.Context,
+	event *usernotify.NotificationEvent,
+) ([]*usernotify.NotificationResult, error) {
+	f.events = append(f.events, event)
+	return nil, nil
+}
+
+func newBudgetTestController(
+	budgets ...*types.WorkspaceBudget,
+) (*WorkspaceBudgetController, *budgetTestNotificationService, client.Client) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	accountV2 := &budgetTestAccountV2{budgets: map[string]*types.WorkspaceBudget{}}
+	for _, b := range budgets {
+		accountV2.budgets[b.Workspace] = b
+		builder = builder.WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: b.Workspace}},
+		)
+	}
+	k8sClient := builder.Build()
+	notifications := &budgetTestNotificationService{}
+	controller := NewWorkspaceBudgetController(&AccountReconciler{
+		Client:                  k8sClient,
+		AccountV2:               accountV2,
+		Logger:                  logr.Discard(),
+		localDomain:             "region.example.com",
+		UserContactProvider:     usernotify.NewMemoryContactProvider(),
+		UserNotificationService: notifications,
+	})
+	return controller, notifications, k8sClient
+}
+
+func newTestWorkspaceBudget(workspace string, softStop bool) *types.WorkspaceBudget {
+	return &types.WorkspaceBudget{
+		ID:              uuid.New(),
+		Workspace:       workspace,
+		RegionDomain:    "region.example.com",
+		UserUID:         uuid.New(),
+		MonthlyLimit:    1000,
+		AlertThresholds: types.DefaultWorkspaceBudgetAlertThresholds,
+		SoftStop:        softStop,
+		Status:          types.WorkspaceBudgetStatusNormal,
+		PeriodStartAt:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func budgetNamespaceAnnotations(
+	t *testing.T,
+	c client.Client,
+	workspace string,
+) map[string]string {
+	t.Helper()
+	ns := &corev1.Namespace{}
+	if err := c.Get(context.Background(), types2.NamespacedName{Name: workspace}, ns); err != nil {
+		t.Fatalf("get namespace: %v", err)
+	}
+	return ns.Annotations
+}
+
+func TestWorkspaceBudgetAlertsAndSoftStop(t *testing.T) {
+	budget := newTestWorkspaceBudget("ns-a", true)
+	other := newTestWorkspaceBudget("ns-b", false)
+	controller, notifications, k8sClient := newBudgetTestController(budget, other)
+	ctx := context.Background()
+	hour := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+
+	// 85% reaches the 50% and 80% thresholds, only the highest one is alerted
+	if err := controller.ReconcileBillingHour(
+		ctx, map[string]int64{"ns-a": 850, "ns-b": 100}, hour, nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications.events) != 1 ||
+		notifications.events[0].EventData["threshold"] != int64(80) {
+		t.Fatalf("expected a single 80%% alert, got %+v", notifications.events)
+	}
+	if budget.AlertedPercent != 80 || budget.Status != types.WorkspaceBudgetStatusNormal {
+		t.Fatalf("unexpected budget state %d %s", budget.AlertedPercent, budget.Status)
+	}
+
+	// an hour below the next threshold doesn't alert again
+	if err := controller.ReconcileBillingHour(
+		ctx, map[string]int64{"ns-a": 50}, hour.Add(time.Hour), nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications.events) != 1 {
+		t.Fatalf("expected no new alert, got %d", len(notifications.events))
+	}
+
+	// the budget is used up, ns-a is soft-stopped while ns-b keeps running
+	if err := controller.ReconcileBillingHour(
+		ctx, map[string]int64{"ns-a": 200, "ns-b": 100}, hour.Add(2*time.Hour), nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if budget.Status != types.WorkspaceBudgetStatusSoftStopped || budget.AlertedPercent != 100 {
+		t.Fatalf("expected soft-stopped budget, got %d %s", budget.AlertedPercent, budget.Status)
+	}
+	if len(notifications.events) != 2 ||
+		notifications.events[1].EventData["soft_stopped"] != true {
+		t.Fatalf("expected a soft stop alert, got %+v", notifications.events)
+	}
+	annotations := budgetNamespaceAnnotations(t, k8sClient, "ns-a")
+	if annotations[types.DebtNamespaceAnnoStatusKey] != types.SuspendDebtNamespaceAnnoStatus ||
+		annotations[types.WorkspaceBudgetStatusAnnoKey] == "" {
+		t.Fatalf("expected ns-a to be suspended, got %v", annotations)
+	}
+	if annotations := budgetNamespaceAnnotations(t, k8sClient, "ns-b"); len(annotations) != 0 {
+		t.Fatalf("expected ns-b untouched, got %v", annotations)
+	}
+}
+
+func TestWorkspaceBudgetResume(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*types.WorkspaceBudget)
+		hour        time.Time
+		ownerInDebt bool
+		debtStatus  string
+		wantStatus  string
+		wantStopped bool
+	}{
+		{
+			name:        "still used up",
+			modify:      func(*types.WorkspaceBudget) {},
+			hour:        time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			wantStatus:  types.SuspendCompletedDebtNamespaceAnnoStatus,
+			wantStopped: true,
+		},
+		{
+			name:        "resumed by a top-up",
+			modify:      func(*types.WorkspaceBudget) {},
+			hour:        time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			debtStatus:  types.NormalDebtNamespaceAnnoStatus,
+			wantStatus:  types.SuspendDebtNamespaceAnnoStatus,
+			wantStopped: true,
+		},
+		{
+			name:       "new month",
+			modify:     func(*types.WorkspaceBudget) {},
+			hour:       time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			wantStatus: types.ResumeDebtNamespaceAnnoStatus,
+		},
+		{
+			name:       "limit raised",
+			modify:     func(b *types.WorkspaceBudget) { b.MonthlyLimit = 2000 },
+			hour:       time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			wantStatus: types.ResumeDebtNamespaceAnnoStatus,
+		},
+		{
+			name:        "owner in debt",
+			modify:      func(b *types.WorkspaceBudget) { b.SoftStop = false },
+			hour:        time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			ownerInDebt: true,
+			wantStatus:  types.SuspendCompletedDebtNamespaceAnnoStatus,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := newTestWorkspaceBudget("ns-a", true)
+			budget.Spent, budget.AlertedPercent = 1000, 100
+			budget.Status = types.WorkspaceBudgetStatusSoftStopped
+			controller, _, k8sClient := newBudgetTestController(budget)
+			ctx := context.Background()
+			ns := &corev1.Namespace{}
+			if err := k8sClient.Get(ctx, types2.NamespacedName{Name: "ns-a"}, ns); err != nil {
+				t.Fatal(err)
+			}
+			debtStatus := tt.debtStatus
+			if debtStatus == "" {
+				debtStatus = types.SuspendCompletedDebtNamespaceAnnoStatus
+			}
+			ns.Annotations = map[string]string{
+				types.DebtNamespaceAnnoStatusKey:   debtStatus,
+				types.WorkspaceBudgetStatusAnnoKey: types.SuspendDebtNamespaceAnnoStatus,
+			}
+			if err := k8sClient.Update(ctx, ns); err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(budget)
+
+			inDebt := func(string) bool { return tt.ownerInDebt }
+			if err := controller.ReconcileBillingHour(ctx, nil, tt.hour, inDebt); err != nil {
+				t.Fatal(err)
+			}
+			annotations := budgetNamespaceAnnotations(t, k8sClient, "ns-a")
+			if got := annotations[types.DebtNamespaceAnnoStatusKey]; got != tt.wantStatus {
+				t.Errorf("debt status = %s, want %s", got, tt.wantStatus)
+			}
+			if stopped := budget.Status == types.WorkspaceBudgetStatusSoftStopped; stopped != tt.wantStopped {
+				t.Errorf("budget status = %s, want soft-stopped %v", budget.Status, tt.wantStopped)
+			}
+		})
+	}
+}
+
+func TestWorkspaceBudgetStopsResumedWorkspace(t *testing.T) {
+	budget := newTestWorkspaceBudget("ns-a", true)
+	budget.Spent, budget.AlertedPercent = 1000, 100
+	budget.Status = types.WorkspaceBudgetStatusSoftStopped
+	controller, _, k8sClient := newBudgetTestController(budget)
+	ctx := context.Background()
+	ns := &corev1.Namespace{}
+	if err := k8sClient.Get(ctx, types2.NamespacedName{Name: "ns-a"}, ns); err != nil {
+		t.Fatal(err)
+	}
+	// a top-up resumed the workspace while its budget is still used up
+	ns.Annotations = map[string]string{
+		types.DebtNamespaceAnnoStatusKey:   types.ResumeDebtNamespaceAnnoStatus,
+		types.WorkspaceBudgetStatusAnnoKey: types.SuspendDebtNamespaceAnnoStatus,
+	}
+	if err := k8sClient.Update(ctx, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	hour := time.Date(2024, 5, 20, 10, 0, 0, 0, time.UTC)
+	if err := controller.ReconcileBillingHour(
+		ctx, map[string]int64{"ns-a": 10}, hour, nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	annotations := budgetNamespaceAnnotations(t, k8sClient, "ns-a")
+	if got := annotations[types.DebtNamespaceAnnoStatusKey]; got != types.SuspendDebtNamespaceAnnoStatus {
+		t.Errorf("debt status = %s, want %s", got, types.SuspendDebtNamespaceAnnoStatus)
+	}
+	if budget.Status != types.WorkspaceBudgetStatusSoftStopped {
+		t.Errorf("budget status = %s, want soft-stopped", budget.Status)
+	}
+}
+
+func TestWorkspaceBudgetThresholds(t *testing.T) {
+	thresholds, err := types.NormalizeWorkspaceBudgetThresholds([]int64{100, 50, 80, 50})
+	if err != nil || len(thresholds) != 3 || thresholds[0] != 50 || thresholds[2] != 100 {
+		t.Fatalf("unexpected thresholds %v: %v", thresholds, err)
+	}
+	if _, err := types.NormalizeWorkspaceBudgetThresholds([]int64{0}); err == nil {
+		t.Fatal("expected an error for a zero threshold")
+	}
+
+	budget := newTestWorkspaceBudget("ns-a", false)
+	budget.Spent = 1200
+	may := time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)
+	if got := budget.PendingAlertThreshold(may); got != 100 {
+		t.Errorf("PendingAlertThreshold() = %d, want 100", got)
+	}
+	if !budget.Exhausted(may) || budget.Exhausted(may.Add(time.Hour)) {
+		t.Error("the budget should be used up only in May")
+	}
+}
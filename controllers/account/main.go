@@ -32,6 +32,7 @@ import (
 	"github.com/labring/sealos/controllers/pkg/database/cockroach"
 	"github.com/labring/sealos/controllers/pkg/database/mongo"
 	notificationv1 "github.com/labring/sealos/controllers/pkg/notification/api/v1"
+	"github.com/labring/sealos/controllers/pkg/pay"
 	"github.com/labring/sealos/controllers/pkg/resources"
 	"github.com/labring/sealos/controllers/pkg/types"
 	"github.com/labring/sealos/controllers/pkg/utils/env"
@@ -349,6 +350,8 @@ func main() {
 		Scheme:      mgr.GetScheme(),
 		AccountV2:   v2Account,
 		DebtUserMap: debtUserMap,
+		// the account reconciler is set up above, so its region and notification service are ready
+		WorkspaceBudget: controllers.NewWorkspaceBudgetController(accountReconciler),
 	}
 	if err = billingReconciler.Init(); err != nil {
 		setupLog.Error(err, "unable to init billing reconciler")
@@ -381,6 +384,15 @@ func main() {
 		setupManagerError(err, "Namespace")
 	}
 
+	sandboxPayment, err := pay.EnableSandboxFromEnv()
+	if err != nil {
+		setupLog.Error(err, "unable to enable sandbox payment")
+		os.Exit(1)
+	}
+	if sandboxPayment != nil {
+		setupLog.Info("sandbox payment method is enabled, do not use it in production",
+			"payerEndpoint", sandboxPayment.BaseURL, "callback", sandboxPayment.CallbackURL)
+	}
 	if err = (&controllers.PaymentReconciler{
 		Account:        accountReconciler,
 		DebtReconciler: debtController,
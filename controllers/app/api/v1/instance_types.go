@@ -24,13 +24,52 @@ import (
 // +kubebuilder:validation:XValidation:rule="'app_name' in self.defaults",message="defaults must have app_name key"
 type InstanceSpec struct {
 	TemplateData `json:",inline"`
+
+	// Manifests are the resources of the template separated by "---". The expressions
+	// ${{ defaults.<key> }}, ${{ inputs.<key> }} and ${{ SEALOS_<NAME> }} are replaced
+	// before the resources are applied to the namespace of the instance.
+	// +optional
+	Manifests string `json:"manifests,omitempty"`
+	// Args are the values of the inputs, an input without a value falls back to its default.
+	// +optional
+	Args map[string]string `json:"args,omitempty"`
+}
+
+type InstancePhase string
+
+const (
+	InstancePhasePending   InstancePhase = "Pending"
+	InstancePhaseDeploying InstancePhase = "Deploying"
+	InstancePhaseReady     InstancePhase = "Ready"
+	InstancePhaseFailed    InstancePhase = "Failed"
+	InstancePhaseDeleting  InstancePhase = "Deleting"
+)
+
+// InstanceResource is a resource created from the manifests of an instance.
+type InstanceResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Ready      bool   `json:"ready"`
 }
 
 // InstanceStatus defines the observed state of Instance
-type InstanceStatus struct{}
+type InstanceStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Phase              InstancePhase      `json:"phase,omitempty"`
+	Resources          []InstanceResource `json:"resources,omitempty"`
+	ReadyResources     int32              `json:"readyResources,omitempty"`
+	TotalResources     int32              `json:"totalResources,omitempty"`
+	// Message describes why the instance is not ready, e.g. the errors of rendering or applying.
+	Message string `json:"message,omitempty"`
+}
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyResources`
+//+kubebuilder:printcolumn:name="Total",type=integer,JSONPath=`.status.totalResources`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Instance is the Schema for the instances API
 type Instance struct {
@@ -21,6 +21,7 @@ import (
 	"os"
 
 	appv1 "github.com/labring/sealos/controllers/app/api/v1"
+	"github.com/labring/sealos/controllers/app/controllers"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -46,12 +47,12 @@ func init() {
 // Note: Add role here for controllers without real controller go file, with just CRDs.
 // +kubebuilder:rbac:groups=app.sealos.io,resources=apps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=app.sealos.io,resources=templates,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=app.sealos.io,resources=instances,verbs=get;list;watch;create;update;patch;delete
 
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var impersonateOwner bool
 	flag.StringVar(
 		&metricsAddr,
 		"metrics-bind-address",
@@ -67,6 +68,9 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&impersonateOwner, "impersonate-owner", true,
+		"Apply the manifests of instances as the service account of the namespace owner. "+
+			"Disabling this requires granting the controller the permissions to manage the resources.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -100,6 +104,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err = (&controllers.InstanceReconciler{
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		ImpersonateOwner: impersonateOwner,
+		Envs:             controllers.TemplateEnvsFromEnviron(os.Environ()),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Instance")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
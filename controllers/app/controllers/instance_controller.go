@@ -0,0 +1,316 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	appv1 "github.com/labring/sealos/controllers/app/api/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	InstanceFinalizerName = "app.sealos.io/instance-finalizer"
+	// InstanceLabelKey is set on the resources of an instance to the name of the instance,
+	// the same label the template frontend sets on the resources it deploys.
+	InstanceLabelKey   = "cloud.sealos.io/deploy-on-sealos"
+	instanceFieldOwner = "sealos-app-controller"
+
+	userNamespacePrefix     = "ns-"
+	userSystemNamespace     = "user-system"
+	deployingRequeuePeriod  = 10 * time.Second
+	failedRequeuePeriod     = 30 * time.Second
+	readyResyncPeriod       = 5 * time.Minute
+	instanceMessageMaxBytes = 1024
+)
+
+// InstanceReconciler deploys the manifests of an Instance and keeps track of the created resources.
+type InstanceReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Config *rest.Config
+	// ImpersonateOwner applies the manifests as the service account of the user owning the namespace,
+	// so an instance can't create anything its owner is not allowed to.
+	ImpersonateOwner bool
+	// Envs are the platform variables the manifests can reference, e.g. SEALOS_CLOUD_DOMAIN.
+	Envs map[string]string
+}
+
+//+kubebuilder:rbac:groups=app.sealos.io,resources=instances,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=app.sealos.io,resources=instances/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=app.sealos.io,resources=instances/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate
+
+func (r *InstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx, "instance", req.NamespacedName)
+	instance := &appv1.Instance{}
+	if err := r.Get(ctx, req.NamespacedName, instance); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, instance)
+	}
+	if controllerutil.AddFinalizer(instance, InstanceFinalizerName) {
+		if err := r.Update(ctx, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	status := appv1.InstanceStatus{ObservedGeneration: instance.Generation}
+	objs, err := renderManifests(instance, r.Envs)
+	if err != nil {
+		// nothing changes until the spec is fixed, the resources applied before are kept
+		status.Phase = appv1.InstancePhaseFailed
+		status.Resources = instance.Status.Resources
+		status.Message = err.Error()
+		return ctrl.Result{}, r.updateStatus(ctx, instance, status)
+	}
+
+	c, err := r.clientFor(instance.Namespace)
+	if err != nil {
+		status.Phase = appv1.InstancePhaseFailed
+		status.Resources = instance.Status.Resources
+		status.Message = err.Error()
+		return ctrl.Result{}, r.updateStatus(ctx, instance, status)
+	}
+
+	var errs []error
+	applied := map[instanceResourceKey]bool{}
+	for _, obj := range objs {
+		res := appv1.InstanceResource{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Name:       obj.GetName(),
+		}
+		applied[resourceKey(obj.GroupVersionKind(), obj.GetName())] = true
+		if err := r.apply(ctx, c, instance, obj); err != nil {
+			errs = append(errs, fmt.Errorf("failed to apply %s %s: %w", res.Kind, res.Name, err))
+		} else {
+			res.Ready = resourceReady(obj)
+		}
+		status.Resources = append(status.Resources, res)
+	}
+	// resources which are no longer in the manifests are removed, only after the others are applied
+	if len(errs) == 0 {
+		for _, res := range instance.Status.Resources {
+			gvk := schema.FromAPIVersionAndKind(res.APIVersion, res.Kind)
+			if applied[resourceKey(gvk, res.Name)] {
+				continue
+			}
+			if err := r.deleteResource(ctx, c, instance, res); err != nil {
+				errs = append(
+					errs,
+					fmt.Errorf("failed to prune %s %s: %w", res.Kind, res.Name, err),
+				)
+				status.Resources = append(status.Resources, res)
+				continue
+			}
+			logger.Info("pruned resource", "kind", res.Kind, "name", res.Name)
+		}
+	}
+
+	for _, res := range status.Resources {
+		status.TotalResources++
+		if res.Ready {
+			status.ReadyResources++
+		}
+	}
+	result := ctrl.Result{}
+	switch {
+	case len(errs) != 0:
+		status.Phase = appv1.InstancePhaseFailed
+		status.Message = errors.Join(errs...).Error()
+		result.RequeueAfter = failedRequeuePeriod
+	case status.ReadyResources < status.TotalResources:
+		status.Phase = appv1.InstancePhaseDeploying
+		status.Message = notReadyMessage(status.Resources)
+		result.RequeueAfter = deployingRequeuePeriod
+	default:
+		status.Phase = appv1.InstancePhaseReady
+		result.RequeueAfter = readyResyncPeriod
+	}
+	if err := r.updateStatus(ctx, instance, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	return result, nil
+}
+
+// apply server-side applies obj into the namespace of instance and reads the result back into obj.
+func (r *InstanceReconciler) apply(
+	ctx context.Context,
+	c client.Client,
+	instance *appv1.Instance,
+	obj *unstructured.Unstructured,
+) error {
+	obj.SetNamespace(instance.Namespace)
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[InstanceLabelKey] = instance.Name
+	obj.SetLabels(labels)
+
+	// blockOwnerDeletion requires the owner to be allowed to update the finalizers of the instance,
+	// the finalizer of the controller removes the resources anyway
+	ref := metav1.NewControllerRef(instance, appv1.GroupVersion.WithKind("Instance"))
+	ref.BlockOwnerDeletion = nil
+	obj.SetOwnerReferences([]metav1.OwnerReference{*ref})
+
+	return c.Patch(
+		ctx,
+		obj,
+		client.Apply,
+		client.FieldOwner(instanceFieldOwner),
+		client.ForceOwnership,
+	)
+}
+
+// finalize deletes the resources of instance before the instance is gone.
+func (r *InstanceReconciler) finalize(ctx context.Context, instance *appv1.Instance) error {
+	if !controllerutil.ContainsFinalizer(instance, InstanceFinalizerName) {
+		return nil
+	}
+	if instance.Status.Phase != appv1.InstancePhaseDeleting {
+		status := *instance.Status.DeepCopy()
+		status.Phase = appv1.InstancePhaseDeleting
+		status.Message = ""
+		if err := r.updateStatus(ctx, instance, status); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	if len(instance.Status.Resources) != 0 {
+		c, err := r.clientFor(instance.Namespace)
+		if err != nil {
+			return err
+		}
+		for _, res := range instance.Status.Resources {
+			if err := r.deleteResource(ctx, c, instance, res); err != nil {
+				errs = append(
+					errs,
+					fmt.Errorf("failed to delete %s %s: %w", res.Kind, res.Name, err),
+				)
+			}
+		}
+	}
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	controllerutil.RemoveFinalizer(instance, InstanceFinalizerName)
+	return r.Update(ctx, instance)
+}
+
+// deleteResource deletes a resource of instance with the client the manifests are applied with,
+// a resource which is gone or no longer controlled by the instance is left alone.
+func (r *InstanceReconciler) deleteResource(
+	ctx context.Context,
+	c client.Client,
+	instance *appv1.Instance,
+	res appv1.InstanceResource,
+) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(res.APIVersion, res.Kind))
+	err := c.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: res.Name}, obj)
+	// the kind may be no longer served, e.g. its CRD is removed
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ref := metav1.GetControllerOf(obj); ref == nil || ref.UID != instance.UID {
+		return nil
+	}
+	return client.IgnoreNotFound(
+		c.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)),
+	)
+}
+
+// clientFor returns the client to apply and delete the resources in namespace with.
+func (r *InstanceReconciler) clientFor(namespace string) (client.Client, error) {
+	if !r.ImpersonateOwner {
+		return r.Client, nil
+	}
+	user, ok := strings.CutPrefix(namespace, userNamespacePrefix)
+	if !ok || user == "" {
+		return nil, fmt.Errorf("namespace %s is not a user namespace", namespace)
+	}
+	config := rest.CopyConfig(r.Config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:" + userSystemNamespace + ":" + user,
+	}
+	return client.New(config, client.Options{Scheme: r.Scheme, Mapper: r.RESTMapper()})
+}
+
+func (r *InstanceReconciler) updateStatus(
+	ctx context.Context,
+	instance *appv1.Instance,
+	status appv1.InstanceStatus,
+) error {
+	status.Message = truncateMessage(status.Message, instanceMessageMaxBytes)
+	instance.Status = status
+	return r.Status().Update(ctx, instance)
+}
+
+// truncateMessage cuts msg to at most maxBytes bytes on a rune boundary.
+func truncateMessage(msg string, maxBytes int) string {
+	if len(msg) <= maxBytes {
+		return msg
+	}
+	n := maxBytes
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return msg[:n] + "..."
+}
+
+func notReadyMessage(resources []appv1.InstanceResource) string {
+	var names []string
+	for _, res := range resources {
+		if !res.Ready {
+			names = append(names, res.Kind+"/"+res.Name)
+		}
+	}
+	return "waiting for " + strings.Join(names, ", ")
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *InstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.Config = mgr.GetConfig()
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&appv1.Instance{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Complete(r)
+}
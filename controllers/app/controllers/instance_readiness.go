@@ -0,0 +1,83 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// resourceReady reports whether a resource applied by an instance is ready. Workloads are ready when
+// their replicas are, the other kinds are ready once their Ready condition is true, or right after
+// they are created if they have no such condition.
+func resourceReady(obj *unstructured.Unstructured) bool {
+	status, ok := obj.Object["status"].(map[string]any)
+	if !ok {
+		return !hasWorkloadStatus(obj)
+	}
+	if observed, found, _ := unstructured.NestedInt64(status, "observedGeneration"); found &&
+		observed < obj.GetGeneration() {
+		return false
+	}
+
+	gvk := obj.GroupVersionKind()
+	switch gvk.GroupKind().String() {
+	case "Deployment.apps", "StatefulSet.apps", "ReplicaSet.apps":
+		replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(status, "readyReplicas")
+		return ready >= replicas
+	case "DaemonSet.apps":
+		desired, _, _ := unstructured.NestedInt64(status, "desiredNumberScheduled")
+		ready, _, _ := unstructured.NestedInt64(status, "numberReady")
+		return ready >= desired
+	case "Job.batch":
+		return conditionTrue(status, "Complete")
+	case "PersistentVolumeClaim":
+		phase, _, _ := unstructured.NestedString(status, "phase")
+		return phase == "Bound"
+	case "Cluster.apps.kubeblocks.io":
+		phase, _, _ := unstructured.NestedString(status, "phase")
+		return phase == "Running"
+	}
+	if _, found, _ := unstructured.NestedSlice(status, "conditions"); found {
+		return conditionTrue(status, "Ready")
+	}
+	return true
+}
+
+// hasWorkloadStatus reports whether the kind is only ready after its controller reports a status.
+func hasWorkloadStatus(obj *unstructured.Unstructured) bool {
+	switch obj.GroupVersionKind().GroupKind().String() {
+	case "Deployment.apps", "StatefulSet.apps", "ReplicaSet.apps", "DaemonSet.apps", "Job.batch",
+		"PersistentVolumeClaim", "Cluster.apps.kubeblocks.io":
+		return true
+	}
+	return false
+}
+
+func conditionTrue(status map[string]any, conditionType string) bool {
+	conditions, _, _ := unstructured.NestedSlice(status, "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if ok && condition["type"] == conditionType {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
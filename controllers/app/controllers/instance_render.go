@@ -0,0 +1,204 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	appv1 "github.com/labring/sealos/controllers/app/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// templateEnvPrefix is the prefix of the platform variables which can be referenced by templates,
+// e.g. ${{ SEALOS_CLOUD_DOMAIN }}.
+const templateEnvPrefix = "SEALOS_"
+
+var templateExpression = regexp.MustCompile(`\$\{\{\s*(.*?)\s*\}\}`)
+
+// TemplateEnvsFromEnviron picks the platform variables out of environ, see os.Environ.
+func TemplateEnvsFromEnviron(environ []string) map[string]string {
+	envs := map[string]string{}
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(key, templateEnvPrefix) {
+			envs[key] = value
+		}
+	}
+	return envs
+}
+
+// templateContext holds the values the expressions of the manifests are replaced with.
+type templateContext struct {
+	defaults map[string]string
+	inputs   map[string]string
+	envs     map[string]string
+}
+
+// newTemplateContext resolves the defaults and inputs of instance, the value of an input comes
+// from the args of the instance or falls back to its default.
+func newTemplateContext(
+	instance *appv1.Instance,
+	envs map[string]string,
+) (*templateContext, error) {
+	c := &templateContext{
+		defaults: make(map[string]string, len(instance.Spec.Defaults)),
+		inputs:   make(map[string]string, len(instance.Spec.Inputs)),
+		envs:     make(map[string]string, len(envs)+2),
+	}
+	for key, d := range instance.Spec.Defaults {
+		c.defaults[key] = d.Value
+	}
+
+	var missing, unknown []string
+	for key, input := range instance.Spec.Inputs {
+		value := instance.Spec.Args[key]
+		if value == "" {
+			value = input.Default
+		}
+		if value == "" && input.Required {
+			missing = append(missing, key)
+		}
+		c.inputs[key] = value
+	}
+	for key := range instance.Spec.Args {
+		if _, ok := instance.Spec.Inputs[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unknown)
+	if len(missing) != 0 {
+		return nil, fmt.Errorf("required inputs %s have no value", strings.Join(missing, ", "))
+	}
+	if len(unknown) != 0 {
+		return nil, fmt.Errorf(
+			"args %s are not inputs of the template",
+			strings.Join(unknown, ", "),
+		)
+	}
+
+	for key, value := range envs {
+		c.envs[key] = value
+	}
+	c.envs["SEALOS_NAMESPACE"] = instance.Namespace
+	c.envs["SEALOS_SERVICE_ACCOUNT"] = strings.TrimPrefix(instance.Namespace, userNamespacePrefix)
+	return c, nil
+}
+
+// lookup returns the value of a reference like defaults.app_name, inputs.password or SEALOS_NAMESPACE.
+// Unlike the template frontend, which evaluates the expressions as javascript, only references are
+// supported.
+func (c *templateContext) lookup(expr string) (string, error) {
+	var (
+		values map[string]string
+		key    string
+	)
+	switch {
+	case strings.HasPrefix(expr, "defaults."):
+		values, key = c.defaults, strings.TrimPrefix(expr, "defaults.")
+	case strings.HasPrefix(expr, "inputs."):
+		values, key = c.inputs, strings.TrimPrefix(expr, "inputs.")
+	case strings.HasPrefix(expr, templateEnvPrefix):
+		values, key = c.envs, expr
+	default:
+		return "", fmt.Errorf("unsupported expression %q", expr)
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("undefined variable %q", expr)
+	}
+	return value, nil
+}
+
+func (c *templateContext) render(manifests string) (string, error) {
+	var errs []error
+	rendered := templateExpression.ReplaceAllStringFunc(manifests, func(match string) string {
+		value, err := c.lookup(templateExpression.FindStringSubmatch(match)[1])
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return value
+	})
+	return rendered, errors.Join(errs...)
+}
+
+// renderManifests renders the manifests of instance into the objects to apply, the Instance itself is
+// skipped if the manifests contain it.
+func renderManifests(
+	instance *appv1.Instance,
+	envs map[string]string,
+) ([]*unstructured.Unstructured, error) {
+	c, err := newTemplateContext(instance, envs)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := c.render(instance.Spec.Manifests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render manifests: %w", err)
+	}
+
+	var objs []*unstructured.Unstructured
+	seen := map[instanceResourceKey]bool{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(rendered), 4096)
+	for i := 0; ; i++ {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode manifest %d: %w", i, err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind == "" || gvk.Version == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("manifest %d must have apiVersion, kind and metadata.name", i)
+		}
+		if gvk.GroupKind() == appv1.GroupVersion.WithKind("Instance").GroupKind() {
+			continue
+		}
+		if ns := obj.GetNamespace(); ns != "" && ns != instance.Namespace {
+			return nil, fmt.Errorf("%s %s must be in namespace %s, not %s",
+				gvk.Kind, obj.GetName(), instance.Namespace, ns)
+		}
+		key := resourceKey(gvk, obj.GetName())
+		if seen[key] {
+			return nil, fmt.Errorf("%s %s is declared more than once", gvk.Kind, obj.GetName())
+		}
+		seen[key] = true
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// instanceResourceKey identifies a resource regardless of the version it is served with.
+type instanceResourceKey struct {
+	schema.GroupKind
+	Name string
+}
+
+func resourceKey(gvk schema.GroupVersionKind, name string) instanceResourceKey {
+	return instanceResourceKey{GroupKind: gvk.GroupKind(), Name: name}
+}
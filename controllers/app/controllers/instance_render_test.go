@@ -0,0 +1,250 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	appv1 "github.com/labring/sealos/controllers/app/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const testManifests = `apiVersion: app.sealos.io/v1
+kind: Instance
+metadata:
+  name: ${{ defaults.app_name }}
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: ${{ defaults.app_name }}
+stringData:
+  password: ${{ inputs.password }}
+  url: https://${{ defaults.app_host }}.${{ SEALOS_CLOUD_DOMAIN }}
+  namespace: ${{SEALOS_NAMESPACE}}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ${{ defaults.app_name }}
+spec:
+  replicas: 2
+`
+
+func newTestInstance() *appv1.Instance {
+	return &appv1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Name: "fastgpt", Namespace: "ns-test"},
+		Spec: appv1.InstanceSpec{
+			TemplateData: appv1.TemplateData{
+				Defaults: appv1.Defaults{
+					"app_name": {Type: appv1.DefaultDataTypeString, Value: "fastgpt-abc"},
+					"app_host": {Type: appv1.DefaultDataTypeString, Value: "host123"},
+				},
+				Inputs: appv1.Inputs{
+					"password": {Type: appv1.InputDataTypeString, Required: true},
+					"base_url": {
+						Type:    appv1.InputDataTypeString,
+						Default: "https://api.openai.com",
+					},
+				},
+			},
+			Manifests: testManifests,
+			Args:      map[string]string{"password": "secret"},
+		},
+	}
+}
+
+func TestRenderManifests(t *testing.T) {
+	envs := TemplateEnvsFromEnviron([]string{"SEALOS_CLOUD_DOMAIN=cloud.example.com", "HOME=/root"})
+	if len(envs) != 1 {
+		t.Fatalf("only SEALOS_ variables should be picked, got %v", envs)
+	}
+
+	objs, err := renderManifests(newTestInstance(), envs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 2 {
+		t.Fatalf("expected the instance itself to be skipped, got %d objects", len(objs))
+	}
+	secret := objs[0]
+	if secret.GetName() != "fastgpt-abc" {
+		t.Errorf("unexpected name %s", secret.GetName())
+	}
+	data, _, _ := unstructured.NestedStringMap(secret.Object, "stringData")
+	if data["password"] != "secret" || data["url"] != "https://host123.cloud.example.com" ||
+		data["namespace"] != "ns-test" {
+		t.Errorf("unexpected rendered data %v", data)
+	}
+}
+
+func TestRenderManifestsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*appv1.Instance)
+		want   string
+	}{
+		{
+			name:   "missing required input",
+			modify: func(i *appv1.Instance) { i.Spec.Args = nil },
+			want:   "required inputs password",
+		},
+		{
+			name:   "unknown arg",
+			modify: func(i *appv1.Instance) { i.Spec.Args["passwd"] = "x" },
+			want:   "args passwd are not inputs",
+		},
+		{
+			name:   "undefined variable",
+			modify: func(i *appv1.Instance) { i.Spec.Manifests += "# ${{ inputs.api_key }}\n" },
+			want:   `undefined variable "inputs.api_key"`,
+		},
+		{
+			name:   "javascript expression",
+			modify: func(i *appv1.Instance) { i.Spec.Manifests += "# ${{ random(8) }}\n" },
+			want:   "unsupported expression",
+		},
+		{
+			name: "other namespace",
+			modify: func(i *appv1.Instance) {
+				i.Spec.Manifests += "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n" +
+					"  name: c\n  namespace: kube-system\n"
+			},
+			want: "must be in namespace ns-test",
+		},
+		{
+			name: "duplicated resource",
+			modify: func(i *appv1.Instance) {
+				i.Spec.Manifests += "---\napiVersion: apps/v1beta1\nkind: Deployment\n" +
+					"metadata:\n  name: fastgpt-abc\n"
+			},
+			want: "declared more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := newTestInstance()
+			tt.modify(instance)
+			_, err := renderManifests(instance, map[string]string{"SEALOS_CLOUD_DOMAIN": "c"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestResourceReady(t *testing.T) {
+	newObj := func(apiVersion, kind string, fields map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: fields}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName("test")
+		return obj
+	}
+	tests := []struct {
+		name string
+		obj  *unstructured.Unstructured
+		want bool
+	}{
+		{
+			name: "config map",
+			obj:  newObj("v1", "ConfigMap", map[string]any{}),
+			want: true,
+		},
+		{
+			name: "new deployment",
+			obj:  newObj("apps/v1", "Deployment", map[string]any{}),
+			want: false,
+		},
+		{
+			name: "deployment partially ready",
+			obj: newObj("apps/v1", "Deployment", map[string]any{
+				"spec":   map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"readyReplicas": int64(1)},
+			}),
+			want: false,
+		},
+		{
+			name: "deployment ready",
+			obj: newObj("apps/v1", "Deployment", map[string]any{
+				"spec":   map[string]any{"replicas": int64(2)},
+				"status": map[string]any{"readyReplicas": int64(2)},
+			}),
+			want: true,
+		},
+		{
+			name: "outdated status",
+			obj: func() *unstructured.Unstructured {
+				obj := newObj("apps/v1", "StatefulSet", map[string]any{
+					"status": map[string]any{
+						"observedGeneration": int64(1),
+						"readyReplicas":      int64(1),
+					},
+				})
+				obj.SetGeneration(2)
+				return obj
+			}(),
+			want: false,
+		},
+		{
+			name: "kubeblocks cluster",
+			obj: newObj("apps.kubeblocks.io/v1alpha1", "Cluster", map[string]any{
+				"status": map[string]any{"phase": "Running"},
+			}),
+			want: true,
+		},
+		{
+			name: "custom resource not ready",
+			obj: newObj("example.com/v1", "Database", map[string]any{
+				"status": map[string]any{"conditions": []any{
+					map[string]any{"type": "Ready", "status": "False"},
+				}},
+			}),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resourceReady(tt.obj); got != tt.want {
+				t.Errorf("resourceReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		max  int
+		want string
+	}{
+		{name: "short", msg: "abc", max: 3, want: "abc"},
+		{name: "ascii", msg: "abcdef", max: 3, want: "abc..."},
+		{name: "rune boundary", msg: "a你好", max: 4, want: "a你..."},
+		{name: "inside a rune", msg: "a你好", max: 3, want: "a..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateMessage(tt.msg, tt.max); got != tt.want {
+				t.Errorf("truncateMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1079,8 +1079,10 @@ func (c *Cockroach) AddDeductionBalanceWithCreditsAt(
 	if at.IsZero() {
 		at = time.Now().UTC()
 	}
+	var creditTransactions []types.CreditsTransaction
 	err := RetryTransaction(3, 2*time.Second, c.DB, func(tx *gorm.DB) error {
 		remainingAmount := deductionAmount
+		creditTransactions = nil
 		userUID, dErr := c.GetUserUID(ops)
 		if dErr != nil {
 			return fmt.Errorf("failed to get user uid: %w", dErr)
@@ -1097,7 +1099,6 @@ func (c *Cockroach) AddDeductionBalanceWithCreditsAt(
 		}
 		now := time.Now().UTC()
 		var updateCredits []types.Credits
-		// var creditTransactions []types.CreditsTransaction
 		for i := range credits {
 			creditAmt := credits[i].Amount - credits[i].UsedAmount
 			if creditAmt > 0 && remainingAmount > 0 {
@@ -1111,16 +1112,16 @@ func (c *Cockroach) AddDeductionBalanceWithCreditsAt(
 					usedAmount = creditAmt
 				}
 				remainingAmount -= usedAmount
-				// creditTransactions = append(creditTransactions, types.CreditsTransaction{
-				//	ID:                   uuid.New(),
-				//	UserUID:              userUID,
-				//	RegionUID:            c.LocalRegion.UID,
-				//	AccountTransactionID: &accountTransactionID,
-				//	CreditsID:            credits[i].ID,
-				//	UsedAmount:           usedAmount,
-				//	CreatedAt:            now,
-				//	Reason:               types.CreditsRecordReasonResourceAccountTransaction,
-				// })
+				// recorded at the billing time so the statements of the period include it
+				creditTransactions = append(creditTransactions, types.CreditsTransaction{
+					ID:         uuid.New(),
+					UserUID:    userUID,
+					RegionUID:  c.LocalRegion.UID,
+					CreditsID:  credits[i].ID,
+					UsedAmount: usedAmount,
+					CreatedAt:  at,
+					Reason:     types.CreditsRecordReasonResourceAccountTransaction,
+				})
 				credits[i].UpdatedAt = now
 				updateCredits = append(updateCredits, credits[i])
 			}
@@ -1137,14 +1138,23 @@ func (c *Cockroach) AddDeductionBalanceWithCreditsAt(
 				return fmt.Errorf("failed to update balance: %w", dErr)
 			}
 		}
-		// if len(creditTransactions) > 0 {
-		//	if dErr = tx.Create(&creditTransactions).Error; dErr != nil {
-		//		return fmt.Errorf("failed to create credit transactions: %v", dErr)
-		//	}
-		//}
 		return nil
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	// the credits usage is only read by the billing statements, it is written after the deduction
+	// is committed so that a failure of it neither retries nor rolls back the deduction
+	if len(creditTransactions) > 0 {
+		if err = c.DB.Create(&creditTransactions).Error; err != nil {
+			logrus.Errorf(
+				"failed to create credit transactions of user %s: %v",
+				creditTransactions[0].UserUID,
+				err,
+			)
+		}
+	}
+	return nil
 }
 
 func RetryTransaction(
@@ -2179,6 +2189,9 @@ func (c *Cockroach) createTables() error {
 		types.WorkspaceSubscriptionPlan{},
 		types.ProductPrice{},
 		types.UserAlertNotificationAccount{},
+		types.WorkspaceBudget{},
+		types.UserAlertRule{},
+		types.BillingStatementSubscription{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create table: %w", err)
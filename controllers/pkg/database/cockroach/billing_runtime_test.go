@@ -109,6 +109,7 @@ func TestHistoricalBillingDeductionUsesCreditsActiveAtBillingTime(t *testing.T)
 	}
 	if err := db.AutoMigrate(
 		&types.Account{}, &types.AccountTransaction{}, &types.Credits{},
+		&types.CreditsTransaction{},
 	); err != nil {
 		t.Fatal(err)
 	}
@@ -153,6 +154,15 @@ func TestHistoricalBillingDeductionUsesCreditsActiveAtBillingTime(t *testing.T)
 	if storedAccount.DeductionBalance != 25 {
 		t.Fatalf("deduction balance = %d", storedAccount.DeductionBalance)
 	}
+	usedCredits, err := account.GetUsedCredits(
+		userUID, regionUID, billingTime.Add(-time.Second), billingTime.Add(time.Second),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usedCredits != 100 {
+		t.Fatalf("used credits of the billing time = %d", usedCredits)
+	}
 	var transactionCount int64
 	if err := db.Model(&types.AccountTransaction{}).Count(&transactionCount).Error; err != nil {
 		t.Fatal(err)
@@ -0,0 +1,100 @@
+package cockroach
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"gorm.io/gorm/clause"
+)
+
+// GetUsedCredits returns the credits consumed by the billing of the user in the region within
+// [startTime, endTime), summed from the credits transactions recorded by the billing deduction.
+func (c *Cockroach) GetUsedCredits(
+	userUID, regionUID uuid.UUID,
+	startTime, endTime time.Time,
+) (int64, error) {
+	var used int64
+	err := c.DB.Model(&types.CreditsTransaction{}).
+		Select("COALESCE(SUM(used_amount), 0)").
+		Where(
+			"user_uid = ? AND region_uid = ? AND reason = ? AND created_at >= ? AND created_at < ?",
+			userUID,
+			regionUID,
+			types.CreditsRecordReasonResourceAccountTransaction,
+			startTime,
+			endTime,
+		).
+		Scan(&used).
+		Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum used credits: %w", err)
+	}
+	return used, nil
+}
+
+func (c *Cockroach) GetBillingStatementSubscription(
+	userUID uuid.UUID,
+	regionDomain string,
+) (*types.BillingStatementSubscription, error) {
+	var sub types.BillingStatementSubscription
+	err := c.DB.Where("user_uid = ? AND region_domain = ?", userUID, regionDomain).
+		First(&sub).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// SetBillingStatementSubscription creates the subscription of the user or updates its settings.
+// A new subscription starts with the statement of the current month, the last period of an
+// existing one is kept so a statement is not sent twice.
+func (c *Cockroach) SetBillingStatementSubscription(sub *types.BillingStatementSubscription) error {
+	if !sub.Format.IsValid() {
+		return fmt.Errorf("unsupported billing statement format: %s", sub.Format)
+	}
+	if sub.Email == "" {
+		return errors.New("email is required")
+	}
+	if sub.LastPeriod == "" {
+		sub.LastPeriod = types.PreviousBillingStatementPeriod(time.Now())
+	}
+	err := c.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_uid"}, {Name: "region_domain"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"email", "format", "is_enabled", "updated_at",
+		}),
+	}).Create(sub).Error
+	if err != nil {
+		return fmt.Errorf("failed to set billing statement subscription: %w", err)
+	}
+	return nil
+}
+
+// ListDueBillingStatementSubscriptions returns the enabled subscriptions of the region whose last
+// statement is older than period.
+func (c *Cockroach) ListDueBillingStatementSubscriptions(
+	regionDomain, period string,
+) ([]types.BillingStatementSubscription, error) {
+	var subs []types.BillingStatementSubscription
+	err := c.DB.Where(
+		"region_domain = ? AND is_enabled = ? AND (last_period IS NULL OR last_period < ?)",
+		regionDomain,
+		true,
+		period,
+	).Find(&subs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list billing statement subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+func (c *Cockroach) SetBillingStatementSubscriptionPeriod(id uuid.UUID, period string) error {
+	return c.DB.Model(&types.BillingStatementSubscription{}).
+		Where("id = ?", id).
+		Update("last_period", period).
+		Error
+}
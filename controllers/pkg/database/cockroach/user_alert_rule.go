@@ -0,0 +1,105 @@
+package cockroach
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/lib/pq"
+)
+
+func (c *Cockroach) CreateUserAlertRule(rule *types.UserAlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	rule.State = types.UserAlertRuleStateInactive
+	rule.FiringSeries = nil
+	if err := c.DB.Create(rule).Error; err != nil {
+		return fmt.Errorf("failed to create user alert rule: %w", err)
+	}
+	return nil
+}
+
+// ListUserAlertRules returns the rules of the user in the region, only the ones of namespace if it
+// is not empty.
+func (c *Cockroach) ListUserAlertRules(
+	userUID uuid.UUID,
+	regionDomain, namespace string,
+) ([]types.UserAlertRule, error) {
+	query := c.DB.Where("user_uid = ? AND region_domain = ?", userUID, regionDomain)
+	if namespace != "" {
+		query = query.Where("namespace = ?", namespace)
+	}
+	var rules []types.UserAlertRule
+	if err := query.Order("created_at").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list user alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ListEnabledUserAlertRules returns the enabled rules of all users in the region.
+func (c *Cockroach) ListEnabledUserAlertRules(regionDomain string) ([]types.UserAlertRule, error) {
+	var rules []types.UserAlertRule
+	if err := c.DB.Where("region_domain = ? AND is_enabled = ?", regionDomain, true).
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list enabled user alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (c *Cockroach) DeleteUserAlertRules(ids []uuid.UUID, userUID uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := c.DB.Where("id IN ? AND user_uid = ?", ids, userUID).Delete(&types.UserAlertRule{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete user alert rules: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ToggleUserAlertRules enables or disables the rules of the user. The state is reset either way,
+// a disabled rule does not send the resolved events of its firing series.
+func (c *Cockroach) ToggleUserAlertRules(
+	ids []uuid.UUID,
+	userUID uuid.UUID,
+	isEnabled bool,
+) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := c.DB.Model(&types.UserAlertRule{}).
+		Where("id IN ? AND user_uid = ?", ids, userUID).
+		Updates(map[string]any{
+			"is_enabled":    isEnabled,
+			"state":         types.UserAlertRuleStateInactive,
+			"firing_series": pq.StringArray{},
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to toggle user alert rules: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UpdateUserAlertRuleState records the result of an evaluation of the rule, firedAt is kept if it
+// is zero.
+func (c *Cockroach) UpdateUserAlertRuleState(
+	id uuid.UUID,
+	state types.UserAlertRuleState,
+	firingSeries []string,
+	evaluatedAt, firedAt time.Time,
+) error {
+	updates := map[string]any{
+		"state":             state,
+		"firing_series":     pq.StringArray(firingSeries),
+		"last_evaluated_at": evaluatedAt,
+	}
+	if !firedAt.IsZero() {
+		updates["last_fired_at"] = firedAt
+	}
+	return c.DB.Model(&types.UserAlertRule{}).
+		Where("id = ? AND is_enabled = ?", id, true).
+		Updates(updates).
+		Error
+}
@@ -0,0 +1,132 @@
+package cockroach
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (c *Cockroach) GetWorkspaceBudget(
+	workspace, regionDomain string,
+) (*types.WorkspaceBudget, error) {
+	var budget types.WorkspaceBudget
+	err := c.DB.Where("workspace = ? AND region_domain = ?", workspace, regionDomain).
+		First(&budget).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// SetWorkspaceBudget creates the budget of the workspace or updates its settings, the spent amount
+// of the current period is kept and the alerts are evaluated again against the new settings.
+func (c *Cockroach) SetWorkspaceBudget(budget *types.WorkspaceBudget) error {
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+	if budget.PeriodStartAt.IsZero() {
+		budget.PeriodStartAt = types.WorkspaceBudgetPeriodStart(time.Now())
+	}
+	if budget.Status == "" {
+		budget.Status = types.WorkspaceBudgetStatusNormal
+	}
+	budget.AlertedPercent = 0
+	err := c.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "workspace"}, {Name: "region_domain"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"user_uid", "monthly_limit", "alert_thresholds", "soft_stop", "alerted_percent",
+			"updated_at",
+		}),
+	}).Create(budget).Error
+	if err != nil {
+		return fmt.Errorf("failed to set workspace budget: %w", err)
+	}
+	return nil
+}
+
+func (c *Cockroach) DeleteWorkspaceBudget(workspace, regionDomain string) error {
+	return c.DB.Where("workspace = ? AND region_domain = ?", workspace, regionDomain).
+		Delete(&types.WorkspaceBudget{}).
+		Error
+}
+
+// ListWorkspaceBudgets returns the budgets of the region, all of them if status is empty.
+func (c *Cockroach) ListWorkspaceBudgets(
+	regionDomain string,
+	status types.WorkspaceBudgetStatus,
+) ([]types.WorkspaceBudget, error) {
+	query := c.DB.Where("region_domain = ?", regionDomain)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var budgets []types.WorkspaceBudget
+	if err := query.Find(&budgets).Error; err != nil {
+		return nil, fmt.Errorf("failed to list workspace budgets: %w", err)
+	}
+	return budgets, nil
+}
+
+// ChargeWorkspaceBudget adds amount, the settled billing of the workspace in the hour ending at
+// hour, to the budget. The spent amount and the alerts are reset when a new month starts. The
+// budget is returned as it is if the hour is charged already, or nil if the workspace has no budget.
+func (c *Cockroach) ChargeWorkspaceBudget(
+	workspace, regionDomain string,
+	amount int64,
+	hour time.Time,
+) (*types.WorkspaceBudget, error) {
+	var budget types.WorkspaceBudget
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("workspace = ? AND region_domain = ?", workspace, regionDomain).
+			First(&budget).Error; err != nil {
+			return err
+		}
+		if !hour.After(budget.LastChargedAt) {
+			return nil
+		}
+		// the billing of an hour belongs to the month the hour starts in
+		period := types.WorkspaceBudgetPeriodStart(hour.Add(-time.Hour))
+		if period.After(budget.PeriodStartAt) {
+			budget.PeriodStartAt = period
+			budget.Spent = 0
+			budget.AlertedPercent = 0
+		}
+		budget.Spent += amount
+		budget.LastChargedAt = hour
+		return tx.Model(&budget).Updates(map[string]any{
+			"period_start_at": budget.PeriodStartAt,
+			"spent":           budget.Spent,
+			"alerted_percent": budget.AlertedPercent,
+			"last_charged_at": budget.LastChargedAt,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to charge workspace budget: %w", err)
+	}
+	return &budget, nil
+}
+
+// UpdateWorkspaceBudgetState records the highest threshold alerted and whether the workspace is
+// soft-stopped by the budget.
+func (c *Cockroach) UpdateWorkspaceBudgetState(
+	id uuid.UUID,
+	alertedPercent int64,
+	status types.WorkspaceBudgetStatus,
+) error {
+	return c.DB.Model(&types.WorkspaceBudget{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"alerted_percent": alertedPercent,
+			"status":          status,
+		}).
+		Error
+}
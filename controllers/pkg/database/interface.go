@@ -79,6 +79,8 @@ type Account interface {
 	) (map[string][]*resources.Billing, error)
 	InsertMonitor(ctx context.Context, monitors ...*resources.Monitor) error
 	GetDistinctMonitorCombinations(startTime, endTime time.Time) ([]resources.Monitor, error)
+	GetLatestMonitors(namespaces []string, at time.Time) ([]resources.Monitor, error)
+	InsertNodeUsage(ctx context.Context, usages ...*resources.NodeUsage) error
 	DropMonitorCollectionsOlderThan(days int) error
 	Disconnect(ctx context.Context) error
 	Creator
@@ -162,6 +164,23 @@ type AccountV2 interface {
 	GetWorkspaceSubscriptionPlan(planName string) (*types.WorkspaceSubscriptionPlan, error)
 	GetWorkspaceSubscription(workspace, regionDomain string) (*types.WorkspaceSubscription, error)
 	GetWorkspaceSubscriptionTraffic(workspace, regionDomain string) (total, used int64, err error)
+	GetWorkspaceBudget(workspace, regionDomain string) (*types.WorkspaceBudget, error)
+	SetWorkspaceBudget(budget *types.WorkspaceBudget) error
+	DeleteWorkspaceBudget(workspace, regionDomain string) error
+	ListWorkspaceBudgets(
+		regionDomain string,
+		status types.WorkspaceBudgetStatus,
+	) ([]types.WorkspaceBudget, error)
+	ChargeWorkspaceBudget(
+		workspace, regionDomain string,
+		amount int64,
+		hour time.Time,
+	) (*types.WorkspaceBudget, error)
+	UpdateWorkspaceBudgetState(
+		id uuid.UUID,
+		alertedPercent int64,
+		status types.WorkspaceBudgetStatus,
+	) error
 	Payment(payment *types.Payment) error
 	PaymentWithFunc(payment *types.Payment, preDo, postDo func(tx *gorm.DB) error) error
 	GlobalTransactionHandler(funcs ...func(tx *gorm.DB) error) error
@@ -197,6 +216,7 @@ type Creator interface {
 	// suffix by day, eg： monitor_20200101
 	CreateMonitorTimeSeriesIfNotExist(collTime time.Time) error
 	CreateTTLTrafficTimeSeries() error
+	CreateNodeUsageTimeSeriesIfNotExist() error
 }
 
 const (
@@ -603,6 +603,75 @@ func (m *mongoDB) GetDistinctMonitorCombinations(
 	return monitors, nil
 }
 
+// GetLatestMonitors returns the latest sample of every resource of the namespaces
+// monitored within resources.ForecastSampleWindow before at.
+func (m *mongoDB) GetLatestMonitors(
+	namespaces []string,
+	at time.Time,
+) ([]resources.Monitor, error) {
+	return FindLatestMonitors(
+		context.Background(),
+		m.Client.Database(m.AccountDB),
+		m.MonitorConnPrefix,
+		namespaces,
+		at,
+	)
+}
+
+// FindLatestMonitors returns the latest sample of every resource of the namespaces monitored
+// within resources.ForecastSampleWindow before at, from the daily monitor collections of db
+// named with prefix.
+func FindLatestMonitors(
+	ctx context.Context,
+	db *mongo.Database,
+	prefix string,
+	namespaces []string,
+	at time.Time,
+) ([]resources.Monitor, error) {
+	if len(namespaces) == 0 {
+		return nil, nil
+	}
+	endTime := at.UTC()
+	startTime := endTime.Add(-resources.ForecastSampleWindow)
+	filter := bson.M{
+		"time":     bson.M{"$gt": startTime, "$lte": endTime},
+		"category": bson.M{"$in": namespaces},
+	}
+	collNames := []string{monitorCollectionName(prefix, endTime)}
+	// the window crosses midnight, the earlier samples are in the collection of the previous day
+	if name := monitorCollectionName(prefix, startTime); name != collNames[0] {
+		collNames = append(collNames, name)
+	}
+	var records []resources.Monitor
+	for _, collName := range collNames {
+		cursor, err := db.Collection(collName).Find(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find monitor records: %w", err)
+		}
+		var monitors []resources.Monitor
+		err = cursor.All(ctx, &monitors)
+		_ = cursor.Close(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode monitor records: %w", err)
+		}
+		records = append(records, monitors...)
+	}
+	return resources.LatestMonitors(records), nil
+}
+
+// InsertNodeUsage insert the node usage reports to the node-usage collection
+func (m *mongoDB) InsertNodeUsage(ctx context.Context, usages ...*resources.NodeUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	manyUsage := make([]any, 0, len(usages))
+	for i := range usages {
+		manyUsage = append(manyUsage, usages[i])
+	}
+	_, err := m.getNodeUsageCollection().InsertMany(ctx, manyUsage)
+	return err
+}
+
 func (m *mongoDB) GetAllPayment() ([]resources.Billing, error) {
 	filter := bson.M{
 		"type":           1,
@@ -1119,8 +1188,16 @@ func (m *mongoDB) getMonitorCollection(collTime time.Time) *mongo.Collection {
 }
 
 func (m *mongoDB) getMonitorCollectionName(collTime time.Time) string {
+	return monitorCollectionName(m.MonitorConnPrefix, collTime)
+}
+
+func monitorCollectionName(prefix string, collTime time.Time) string {
 	// Calculate the suffix by day, for example, the suffix on the first day of 202012 is 20201201
-	return fmt.Sprintf("%s_%s", m.MonitorConnPrefix, collTime.Format("20060102"))
+	return fmt.Sprintf("%s_%s", prefix, collTime.Format("20060102"))
+}
+
+func (m *mongoDB) getNodeUsageCollection() *mongo.Collection {
+	return m.Client.Database(m.AccountDB).Collection(resources.NodeUsageConn)
 }
 
 func (m *mongoDB) getBillingCollection() *mongo.Collection {
@@ -1219,6 +1296,23 @@ func (m *mongoDB) CreateTTLTrafficTimeSeries() error {
 	return m.Client.Database(m.AccountDB).RunCommand(context.TODO(), cmd).Err()
 }
 
+// CreateNodeUsageTimeSeriesIfNotExist creates the time series table for node usage, the reports
+// expire with the monitor collections.
+func (m *mongoDB) CreateNodeUsageTimeSeriesIfNotExist() error {
+	if exist, err := m.collectionExist(m.AccountDB, resources.NodeUsageConn); exist || err != nil {
+		return err
+	}
+	cmd := bson.D{
+		primitive.E{Key: "create", Value: resources.NodeUsageConn},
+		primitive.E{Key: "timeseries", Value: bson.D{
+			{Key: "timeField", Value: "time"},
+			{Key: "metaField", Value: "node"},
+		}},
+		primitive.E{Key: "expireAfterSeconds", Value: 30 * 24 * 60 * 60},
+	}
+	return m.Client.Database(m.AccountDB).RunCommand(context.TODO(), cmd).Err()
+}
+
 func (m *mongoDB) DropMonitorCollectionsOlderThan(days int) error {
 	db := m.Client.Database(m.AccountDB)
 	// Get the current time minus the number of days
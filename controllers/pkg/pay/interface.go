@@ -14,6 +14,12 @@
 
 package pay
 
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
 type RefundOption struct {
 	OrderID  string `json:"order_id"`
 	RefundID string `json:"refund_id"`
@@ -29,17 +35,63 @@ type Interface interface {
 	ExpireSession(payment string) error
 }
 
+// Factory creates the handler of a payment method
+type Factory func() (Interface, error)
+
+const (
+	MethodStripe  = "stripe"
+	MethodWechat  = "wechat"
+	MethodAlipay  = "alipay"
+	MethodSandbox = "sandbox"
+)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Factory)
+)
+
+func init() {
+	Register(MethodStripe, func() (Interface, error) { return &StripePayment{}, nil })
+	Register(MethodWechat, func() (Interface, error) { return &WechatPayment{}, nil })
+	Register(MethodAlipay, func() (Interface, error) { return NewAlipayPayment() })
+}
+
+// Register makes a payment method available by the name, it panics if the name is
+// registered twice or the factory is nil.
+func Register(paymentMethod string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if factory == nil {
+		panic("pay: register factory is nil for " + paymentMethod)
+	}
+	if _, dup := providers[paymentMethod]; dup {
+		panic("pay: register called twice for " + paymentMethod)
+	}
+	providers[paymentMethod] = factory
+}
+
+// Methods returns the sorted names of the registered payment methods.
+func Methods() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	methods := make([]string, 0, len(providers))
+	for method := range providers {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 func NewPayHandler(paymentMethod string) (Interface, error) {
-	switch paymentMethod {
-	case "stripe":
-		return &StripePayment{}, nil
-	case "wechat":
-		return &WechatPayment{}, nil
-	case "alipay":
-		return NewAlipayPayment()
-	default:
-		// return nil, fmt.Errorf("unsupported payment method: %s", paymentMethod)
-		// TODO Now set it as the default wechat, and modify it a few days later
-		return &WechatPayment{}, nil
+	// payments created before the method was set have always been paid with wechat
+	if paymentMethod == "" {
+		paymentMethod = MethodWechat
+	}
+	providersMu.RLock()
+	factory, ok := providers[paymentMethod]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported payment method: %s", paymentMethod)
 	}
+	return factory()
 }
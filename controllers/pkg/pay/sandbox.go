@@ -0,0 +1,433 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/controllers/pkg/utils/env"
+)
+
+// ENV keys of the sandbox payment
+const (
+	// EnvSandboxEnabled registers the sandbox payment method, never enable it in production:
+	// every sandbox payment can be paid without any money involved.
+	EnvSandboxEnabled = "PAY_SANDBOX_ENABLED"
+	// EnvSandboxAddr is the listen address of the sandbox payer endpoints, e.g. ":8089"
+	EnvSandboxAddr = "PAY_SANDBOX_ADDR"
+	// EnvSandboxBaseURL is the external address of the payer endpoints used in the code url
+	EnvSandboxBaseURL = "PAY_SANDBOX_BASE_URL"
+	// EnvSandboxCallbackURL receives the notifications of the sandbox payments in the shape of
+	// the card payment gateway, e.g. the payment notify url of the account service
+	EnvSandboxCallbackURL = "PAY_SANDBOX_CALLBACK_URL"
+	// EnvSandboxAutoPayAfter pays every payment after the duration, e.g. "5s"
+	EnvSandboxAutoPayAfter = "PAY_SANDBOX_AUTO_PAY_AFTER"
+)
+
+// sandbox notification events
+const (
+	SandboxEventPaid     = "paid"
+	SandboxEventFailed   = "failed"
+	SandboxEventExpired  = "expired"
+	SandboxEventRefunded = "refunded"
+)
+
+const (
+	sandboxTradeNoPrefix   = "sandbox_"
+	sandboxNotifyAttempts  = 3
+	sandboxNotifyBackoff   = time.Second
+	sandboxNotifyTimeout   = 10 * time.Second
+	sandboxDefaultDescribe = "sealos cloud sandbox recharge"
+)
+
+// SandboxClientID is sent in the client-id header of the callbacks, the notify handler
+// verifies a sandbox callback against the registered sandbox instead of the gateway signature.
+const SandboxClientID = MethodSandbox
+
+// SandboxNotification is passed to OnNotify when the state of a payment changes.
+type SandboxNotification struct {
+	Event    string    `json:"event"`
+	TradeNo  string    `json:"tradeNo"`
+	User     string    `json:"user"`
+	Amount   int64     `json:"amount"`
+	Status   string    `json:"status"`
+	RefundNo string    `json:"refundNo,omitempty"`
+	RefundID string    `json:"refundId,omitempty"`
+	Refunded int64     `json:"refunded,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// SandboxSession is a payment created in the sandbox
+type SandboxSession struct {
+	TradeNo   string    `json:"tradeNo"`
+	User      string    `json:"user"`
+	Describe  string    `json:"describe"`
+	Amount    int64     `json:"amount"`
+	Refunded  int64     `json:"refunded"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SandboxPayment is an in-memory payment provider for local development and end-to-end
+// tests, the payer is simulated with Pay/Fail or the HTTP handler instead of a gateway.
+type SandboxPayment struct {
+	// BaseURL is the external address of the HTTP handler, used to build the code url
+	BaseURL string
+	// CallbackURL receives a capture notification when a payment is paid and an order closed
+	// notification when it fails or expires, empty disables it
+	CallbackURL string
+	// AutoPayAfter pays every payment after the delay, zero waits for the payer
+	AutoPayAfter time.Duration
+	// OnNotify is called with every notification after it is delivered to CallbackURL
+	OnNotify func(SandboxNotification)
+
+	mu       sync.Mutex
+	sessions map[string]*SandboxSession
+	client   *http.Client
+}
+
+var _ Interface = &SandboxPayment{}
+
+func NewSandboxPayment() *SandboxPayment {
+	return &SandboxPayment{
+		sessions: make(map[string]*SandboxSession),
+		client:   &http.Client{Timeout: sandboxNotifyTimeout},
+	}
+}
+
+// NewSandboxPaymentFromEnv creates the sandbox payment configured by the PAY_SANDBOX_* env.
+func NewSandboxPaymentFromEnv() (*SandboxPayment, error) {
+	s := NewSandboxPayment()
+	s.BaseURL = os.Getenv(EnvSandboxBaseURL)
+	if s.BaseURL == "" && os.Getenv(EnvSandboxAddr) != "" {
+		addr := os.Getenv(EnvSandboxAddr)
+		if strings.HasPrefix(addr, ":") {
+			addr = "localhost" + addr
+		}
+		s.BaseURL = "http://" + addr
+	}
+	s.CallbackURL = os.Getenv(EnvSandboxCallbackURL)
+	if autoPay := os.Getenv(EnvSandboxAutoPayAfter); autoPay != "" {
+		d, err := time.ParseDuration(autoPay)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s failed: %w", EnvSandboxAutoPayAfter, err)
+		}
+		s.AutoPayAfter = d
+	}
+	return s, nil
+}
+
+// RegisterSandbox registers s as the handler of the sandbox payment method.
+func RegisterSandbox(s *SandboxPayment) {
+	Register(MethodSandbox, func() (Interface, error) { return s, nil })
+}
+
+// EnableSandboxFromEnv registers the sandbox payment method if PAY_SANDBOX_ENABLED is true
+// and serves its payer endpoints on PAY_SANDBOX_ADDR, it returns nil if it is disabled.
+func EnableSandboxFromEnv() (*SandboxPayment, error) {
+	if !env.GetBoolWithDefault(EnvSandboxEnabled, false) {
+		return nil, nil
+	}
+	s, err := NewSandboxPaymentFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	RegisterSandbox(s)
+	if addr := os.Getenv(EnvSandboxAddr); addr != "" {
+		server := &http.Server{Addr: addr, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil &&
+				!errors.Is(err, http.ErrServerClosed) {
+				log.Printf("sandbox payment server stopped: %v", err)
+			}
+		}()
+	}
+	return s, nil
+}
+
+// CreatePayment creates an unpaid payment, the code url points to the payer endpoint
+func (s *SandboxPayment) CreatePayment(
+	amount int64,
+	user, describe string,
+) (string, string, error) {
+	if amount <= 0 {
+		return "", "", fmt.Errorf("invalid amount: %d", amount)
+	}
+	tradeNo := sandboxTradeNoPrefix + GetRandomString(32)
+	if tradeNo == sandboxTradeNoPrefix {
+		return "", "", errors.New("generate tradeNO failed")
+	}
+	if describe == "" {
+		describe = sandboxDefaultDescribe
+	}
+	s.mu.Lock()
+	s.sessions[tradeNo] = &SandboxSession{
+		TradeNo:   tradeNo,
+		User:      user,
+		Describe:  describe,
+		Amount:    amount,
+		Status:    PaymentNotPaid,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.mu.Unlock()
+	if s.AutoPayAfter > 0 {
+		time.AfterFunc(s.AutoPayAfter, func() {
+			if err := s.Pay(tradeNo); err != nil {
+				log.Printf("sandbox auto pay %s failed: %v", tradeNo, err)
+			}
+		})
+	}
+	return tradeNo, strings.TrimSuffix(s.BaseURL, "/") + "/pay/" + tradeNo, nil
+}
+
+func (s *SandboxPayment) GetPaymentDetails(sessionID string) (string, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return PaymentUnknown, 0, fmt.Errorf("sandbox payment %s not found", sessionID)
+	}
+	return session.Status, session.Amount, nil
+}
+
+// ExpireSession expires an unpaid payment, a paid one can only be refunded
+func (s *SandboxPayment) ExpireSession(payment string) error {
+	return s.transit(payment, PaymentExpired, SandboxEventExpired)
+}
+
+func (s *SandboxPayment) RefundPayment(option RefundOption) (string, string, error) {
+	s.mu.Lock()
+	session, ok := s.sessions[option.TradeNo]
+	if !ok {
+		s.mu.Unlock()
+		return "", "", fmt.Errorf("sandbox payment %s not found", option.TradeNo)
+	}
+	if session.Status != PaymentSuccess {
+		s.mu.Unlock()
+		return "", "", fmt.Errorf("sandbox payment %s is %s, only a paid payment can be refunded",
+			option.TradeNo, session.Status)
+	}
+	if option.Amount <= 0 || option.Amount > session.Amount-session.Refunded {
+		s.mu.Unlock()
+		return "", "", fmt.Errorf("invalid refund amount %d, refundable amount is %d",
+			option.Amount, session.Amount-session.Refunded)
+	}
+	session.Refunded += option.Amount
+	refundNo := option.RefundID
+	if refundNo == "" {
+		refundNo = GetRandomString(32)
+	}
+	refundID := "sandbox_refund_" + GetRandomString(32)
+	notification := s.notificationLocked(session, SandboxEventRefunded)
+	notification.RefundNo, notification.RefundID = refundNo, refundID
+	s.mu.Unlock()
+	s.notify(notification)
+	return refundNo, refundID, nil
+}
+
+// Pay simulates the payer paying the payment
+func (s *SandboxPayment) Pay(tradeNo string) error {
+	return s.transit(tradeNo, PaymentSuccess, SandboxEventPaid)
+}
+
+// Fail simulates the payment being declined
+func (s *SandboxPayment) Fail(tradeNo string) error {
+	return s.transit(tradeNo, PaymentFailed, SandboxEventFailed)
+}
+
+// Session returns a copy of the payment
+func (s *SandboxPayment) Session(tradeNo string) (SandboxSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[tradeNo]
+	if !ok {
+		return SandboxSession{}, false
+	}
+	return *session, true
+}
+
+// ServeHTTP serves the payer endpoints:
+//
+//	GET  /pay/{tradeNo}              returns the payment
+//	POST /pay/{tradeNo}              pays the payment
+//	POST /pay/{tradeNo}?result=fail  declines the payment
+func (s *SandboxPayment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tradeNo, ok := strings.CutPrefix(r.URL.Path, "/pay/")
+	if !ok || tradeNo == "" || strings.Contains(tradeNo, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if r.URL.Query().Get("result") == "fail" {
+			err = s.Fail(tradeNo)
+		} else {
+			err = s.Pay(tradeNo)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	session, found := s.Session(tradeNo)
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(session)
+}
+
+// transit moves an unpaid payment to the final status and notifies the callback
+func (s *SandboxPayment) transit(tradeNo, status, event string) error {
+	s.mu.Lock()
+	session, ok := s.sessions[tradeNo]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("sandbox payment %s not found", tradeNo)
+	}
+	if session.Status != PaymentNotPaid {
+		s.mu.Unlock()
+		return fmt.Errorf("sandbox payment %s is already %s", tradeNo, session.Status)
+	}
+	session.Status = status
+	notification := s.notificationLocked(session, event)
+	s.mu.Unlock()
+	s.notify(notification)
+	return nil
+}
+
+func (s *SandboxPayment) notificationLocked(
+	session *SandboxSession,
+	event string,
+) SandboxNotification {
+	return SandboxNotification{
+		Event:    event,
+		TradeNo:  session.TradeNo,
+		User:     session.User,
+		Amount:   session.Amount,
+		Status:   session.Status,
+		Refunded: session.Refunded,
+		Time:     time.Now().UTC(),
+	}
+}
+
+// notify delivers the notification asynchronously like a real gateway, with retries
+func (s *SandboxPayment) notify(notification SandboxNotification) {
+	if s.CallbackURL == "" && s.OnNotify == nil {
+		return
+	}
+	go func() {
+		if body, ok := gatewayNotification(notification); ok && s.CallbackURL != "" {
+			var err error
+			for attempt := range sandboxNotifyAttempts {
+				if attempt > 0 {
+					time.Sleep(sandboxNotifyBackoff * time.Duration(attempt))
+				}
+				if err = s.postNotification(body); err == nil {
+					break
+				}
+			}
+			if err != nil {
+				log.Printf("sandbox notify %s %s failed: %v",
+					notification.Event, notification.TradeNo, err)
+			}
+		}
+		if s.OnNotify != nil {
+			s.OnNotify(notification)
+		}
+	}()
+}
+
+// gatewayNotification converts the notification to the body the card payment gateway posts,
+// refunds have no notification there.
+func gatewayNotification(notification SandboxNotification) (any, bool) {
+	switch notification.Event {
+	case SandboxEventPaid:
+		return types.CaptureNotification{
+			Result: types.Result{
+				ResultCode:    "SUCCESS",
+				ResultMessage: "success",
+				ResultStatus:  "S",
+			},
+			NotifyType:       types.NotifyTypeCaptureResult,
+			CaptureRequestID: notification.TradeNo,
+			PaymentID:        notification.TradeNo,
+			CaptureID:        notification.TradeNo,
+			CaptureTime:      &notification.Time,
+		}, true
+	case SandboxEventFailed, SandboxEventExpired:
+		return types.PaymentNotification{
+			NotifyType: types.NotifyTypePaymentResult,
+			Result: types.Result{
+				ResultCode:    types.OrderClosedResultCode,
+				ResultMessage: "sandbox payment " + notification.Status,
+				ResultStatus:  "F",
+			},
+			PaymentRequestID:  notification.TradeNo,
+			PaymentID:         notification.TradeNo,
+			PaymentCreateTime: notification.Time,
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+func (s *SandboxPayment) postNotification(notification any) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("marshal notification failed: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sandboxNotifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		s.CallbackURL,
+		bytes.NewReader(body),
+	)
+	if err != nil {
+		return fmt.Errorf("new notify request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("client-id", SandboxClientID)
+	req.Header.Set("request-time", time.Now().UTC().Format(time.RFC3339))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post notification failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("callback responded %s", resp.Status)
+	}
+	return nil
+}
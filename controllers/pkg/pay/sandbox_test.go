@@ -0,0 +1,184 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+func TestNewPayHandlerUnknownMethod(t *testing.T) {
+	if _, err := NewPayHandler("unknown"); err == nil {
+		t.Fatal("expected an error for an unknown payment method")
+	}
+	if _, err := NewPayHandler(MethodWechat); err != nil {
+		t.Fatalf("wechat should be registered: %v", err)
+	}
+	if handler, err := NewPayHandler(""); err != nil {
+		t.Fatalf("an empty payment method should default to wechat: %v", err)
+	} else if _, ok := handler.(*WechatPayment); !ok {
+		t.Fatalf("an empty payment method should default to wechat, got %T", handler)
+	}
+	if slices.Contains(Methods(), MethodSandbox) {
+		t.Fatal("the sandbox must not be registered by default")
+	}
+}
+
+// gatewayCallback is what the notify handler reads from a capture or payment notification
+type gatewayCallback struct {
+	ClientID         string
+	NotifyType       string       `json:"notifyType"`
+	Result           types.Result `json:"result"`
+	CaptureRequestID string       `json:"captureRequestId"`
+	PaymentRequestID string       `json:"paymentRequestId"`
+	PaymentID        string       `json:"paymentId"`
+}
+
+func TestSandboxPayment(t *testing.T) {
+	callbacks := make(chan gatewayCallback, 10)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n gatewayCallback
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n.ClientID = r.Header.Get("client-id")
+		callbacks <- n
+	}))
+	defer callback.Close()
+
+	notifications := make(chan SandboxNotification, 10)
+	sandbox := NewSandboxPayment()
+	sandbox.OnNotify = func(n SandboxNotification) { notifications <- n }
+	payer := httptest.NewServer(sandbox)
+	defer payer.Close()
+	sandbox.BaseURL = payer.URL
+	sandbox.CallbackURL = callback.URL
+	RegisterSandbox(sandbox)
+
+	handler, err := NewPayHandler(MethodSandbox)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tradeNo, codeURL, err := handler.CreatePayment(10_000_000, "user-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, amount, _ := handler.GetPaymentDetails(tradeNo); status != PaymentNotPaid ||
+		amount != 10_000_000 {
+		t.Fatalf("unexpected new payment %s %d", status, amount)
+	}
+	if _, _, err := handler.RefundPayment(RefundOption{TradeNo: tradeNo, Amount: 1}); err == nil {
+		t.Fatal("an unpaid payment must not be refunded")
+	}
+
+	// the payer pays through the code url
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, codeURL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pay responded %s", resp.Status)
+	}
+	if n := waitSandboxNotification(t, callbacks); n.ClientID != SandboxClientID ||
+		n.NotifyType != types.NotifyTypeCaptureResult || n.Result.ResultCode != "SUCCESS" ||
+		n.Result.ResultStatus != "S" || n.CaptureRequestID != tradeNo || n.PaymentID != tradeNo {
+		t.Fatalf("unexpected callback %+v", n)
+	}
+	if n := waitSandboxNotification(t, notifications); n.Event != SandboxEventPaid ||
+		n.TradeNo != tradeNo || n.User != "user-a" {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+	if status, _, _ := handler.GetPaymentDetails(tradeNo); status != PaymentSuccess {
+		t.Fatalf("expected a paid payment, got %s", status)
+	}
+	if err := handler.ExpireSession(tradeNo); err == nil {
+		t.Fatal("a paid payment must not expire")
+	}
+
+	if _, _, err := handler.RefundPayment(RefundOption{TradeNo: tradeNo, Amount: 20_000_000}); err == nil {
+		t.Fatal("expected an error for refunding more than paid")
+	}
+	refundNo, refundID, err := handler.RefundPayment(
+		RefundOption{TradeNo: tradeNo, Amount: 4_000_000, RefundID: "refund-1"},
+	)
+	if err != nil || refundNo != "refund-1" || refundID == "" {
+		t.Fatalf("unexpected refund %s %s: %v", refundNo, refundID, err)
+	}
+	if n := waitSandboxNotification(t, notifications); n.Event != SandboxEventRefunded ||
+		n.Refunded != 4_000_000 {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+
+	// an unpaid payment expires
+	expired, _, err := handler.CreatePayment(1_000_000, "user-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handler.ExpireSession(expired); err != nil {
+		t.Fatal(err)
+	}
+	if n := waitSandboxNotification(t, callbacks); n.NotifyType != types.NotifyTypePaymentResult ||
+		n.Result.ResultCode != types.OrderClosedResultCode || n.PaymentRequestID != expired {
+		t.Fatalf("unexpected callback %+v", n)
+	}
+	if n := waitSandboxNotification(t, notifications); n.Event != SandboxEventExpired {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+	// the refund has no callback of the gateway
+	select {
+	case n := <-callbacks:
+		t.Fatalf("unexpected callback %+v", n)
+	default:
+	}
+	if err := sandbox.Pay(expired); err == nil {
+		t.Fatal("an expired payment must not be paid")
+	}
+}
+
+func TestSandboxPaymentAutoPay(t *testing.T) {
+	sandbox := NewSandboxPayment()
+	sandbox.AutoPayAfter = 10 * time.Millisecond
+	paid := make(chan SandboxNotification, 1)
+	sandbox.OnNotify = func(n SandboxNotification) { paid <- n }
+	tradeNo, _, err := sandbox.CreatePayment(1_000_000, "user-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := waitSandboxNotification(t, paid); n.Event != SandboxEventPaid || n.TradeNo != tradeNo {
+		t.Fatalf("unexpected notification %+v", n)
+	}
+}
+
+func waitSandboxNotification[T any](t *testing.T, notifications <-chan T) T {
+	t.Helper()
+	select {
+	case n := <-notifications:
+		return n
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the sandbox notification")
+		var zero T
+		return zero
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// ForecastSampleWindow is how far back the latest monitor samples are looked up,
+	// resources without a sample in the window are treated as no longer running.
+	ForecastSampleWindow = 5 * time.Minute
+	// ForecastDaysPerMonth is the month length used to project the monthly cost.
+	ForecastDaysPerMonth = 30
+)
+
+// CostForecast is the projected spend of the resources running now, amounts use the
+// same unit as the billing amount.
+type CostForecast struct {
+	HourlyCost  int64 `json:"hourlyCost"`
+	DailyCost   int64 `json:"dailyCost"`
+	MonthlyCost int64 `json:"monthlyCost"`
+	// daily cost of each namespace
+	Namespaces map[string]int64 `json:"namespaces"`
+}
+
+// LatestMonitors keeps the most recent sample of every monitored resource.
+func LatestMonitors(records []Monitor) []Monitor {
+	latest := make(map[string]int, len(records))
+	result := make([]Monitor, 0, len(records))
+	for _, rec := range records {
+		key := fmt.Sprintf("%s/%d/%s", rec.Category, rec.Type, rec.Name)
+		i, ok := latest[key]
+		if !ok {
+			latest[key] = len(result)
+			result = append(result, rec)
+			continue
+		}
+		if rec.Time.After(result[i].Time) {
+			result[i] = rec
+		}
+	}
+	return result
+}
+
+// NewCostForecast projects the cost of the latest monitor samples as if the resources
+// kept running with the same usage.
+func NewCostForecast(latest []Monitor, prols *PropertyTypeLS) *CostForecast {
+	forecast := &CostForecast{Namespaces: make(map[string]int64)}
+	if prols == nil {
+		return forecast
+	}
+	nsHourly := make(map[string]int64)
+	for _, rec := range latest {
+		cost := monitorHourlyCost(rec, prols)
+		if cost <= 0 {
+			continue
+		}
+		nsHourly[rec.Category] += cost
+		forecast.HourlyCost += cost
+	}
+	for ns, hourly := range nsHourly {
+		forecast.Namespaces[ns] = hourly * 24
+	}
+	forecast.DailyCost = forecast.HourlyCost * 24
+	forecast.MonthlyCost = forecast.DailyCost * ForecastDaysPerMonth
+	return forecast
+}
+
+// RunsOutIn returns how long the usable balance lasts at the forecast cost,
+// false if nothing is running.
+func (f *CostForecast) RunsOutIn(usableBalance int64) (time.Duration, bool) {
+	if f == nil || f.HourlyCost <= 0 {
+		return 0, false
+	}
+	if usableBalance <= 0 {
+		return 0, true
+	}
+	hours := float64(usableBalance) / float64(f.HourlyCost)
+	if hours > math.MaxInt64/float64(time.Hour) {
+		return time.Duration(math.MaxInt64), true
+	}
+	return time.Duration(hours * float64(time.Hour)), true
+}
+
+// RunsOutInDays is RunsOutIn rounded down to whole days.
+func (f *CostForecast) RunsOutInDays(usableBalance int64) (int64, bool) {
+	d, ok := f.RunsOutIn(usableBalance)
+	if !ok {
+		return 0, false
+	}
+	return int64(d / (24 * time.Hour)), true
+}
+
+// monitorHourlyCost is the cost of one hour at the usage of a single sample, priced the
+// same way as the hourly billing.
+func monitorHourlyCost(rec Monitor, prols *PropertyTypeLS) int64 {
+	var cost int64
+	for propKey, used := range rec.Used {
+		prop, ok := prols.EnumMap[propKey]
+		if !ok || prop.UnitPrice <= 0 || used <= 0 {
+			continue
+		}
+		var hourlyUsed float64
+		switch prop.PriceType {
+		case DIF:
+			// cumulative counters grow with requests, a single sample says nothing about the rate
+			continue
+		case SUM:
+			// samples are taken every minute
+			hourlyUsed = float64(used) * 60
+		default:
+			hourlyUsed = float64(used)
+		}
+		cost += int64(math.Ceil(hourlyUsed * prop.UnitPrice))
+	}
+	return cost
+}
@@ -0,0 +1,99 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCostForecast(t *testing.T) {
+	prols := NewPropertyTypeLS([]PropertyType{
+		{Name: "cpu", Enum: 0, PriceType: AVG, UnitPrice: 2, UnitString: "1m"},
+		{Name: "memory", Enum: 1, PriceType: AVG, UnitPrice: 1, UnitString: "1Mi"},
+		{Name: "network", Enum: 3, PriceType: SUM, UnitPrice: 1, UnitString: "1Mi"},
+		{Name: "gpu", Enum: 5, PriceType: DIF, UnitPrice: 100, UnitString: "1"},
+	})
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	records := []Monitor{
+		// the older sample of the same pod is superseded by the latest one
+		{
+			Time:     now.Add(-2 * time.Minute),
+			Category: "ns-a",
+			Type:     1,
+			Name:     "pod",
+			Used:     EnumUsedMap{0: 9999},
+		},
+		{
+			Time:     now.Add(-time.Minute),
+			Category: "ns-a",
+			Type:     1,
+			Name:     "pod",
+			Used:     EnumUsedMap{0: 500, 1: 1000},
+		},
+		{
+			Time:     now.Add(-time.Minute),
+			Category: "ns-a",
+			Type:     3,
+			Name:     "net",
+			Used:     EnumUsedMap{3: 10},
+		},
+		{
+			Time:     now.Add(-time.Minute),
+			Category: "ns-b",
+			Type:     1,
+			Name:     "pod",
+			Used:     EnumUsedMap{0: 100, 5: 7},
+		},
+	}
+	latest := LatestMonitors(records)
+	if len(latest) != 3 {
+		t.Fatalf("expected 3 latest samples, got %d", len(latest))
+	}
+
+	forecast := NewCostForecast(latest, prols)
+	// ns-a: 500*2 + 1000*1 + 10*60*1 = 2600, ns-b: 100*2, the DIF counter is not projected
+	if forecast.HourlyCost != 2800 {
+		t.Fatalf("HourlyCost = %d, want 2800", forecast.HourlyCost)
+	}
+	if forecast.DailyCost != 2800*24 || forecast.MonthlyCost != 2800*24*ForecastDaysPerMonth {
+		t.Fatalf("unexpected daily %d or monthly %d cost", forecast.DailyCost, forecast.MonthlyCost)
+	}
+	if forecast.Namespaces["ns-a"] != 2600*24 || forecast.Namespaces["ns-b"] != 200*24 {
+		t.Fatalf("unexpected namespace costs %v", forecast.Namespaces)
+	}
+
+	tests := []struct {
+		name    string
+		balance int64
+		want    int64
+	}{
+		{name: "several days", balance: 2800*24*3 + 2800, want: 3},
+		{name: "less than a day", balance: 2800, want: 0},
+		{name: "already used up", balance: -100, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days, ok := forecast.RunsOutInDays(tt.balance)
+			if !ok || days != tt.want {
+				t.Errorf("RunsOutInDays(%d) = %d, %v, want %d", tt.balance, days, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := NewCostForecast(nil, prols).RunsOutIn(1000); ok {
+		t.Error("expected no estimate when nothing is running")
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NodeUsageConn is the collection of the node usage reports, it is kept in the same database
+// as the monitor collections.
+const NodeUsageConn = "node-usage"
+
+// NodeResourceUsage compares what a node can run with what is scheduled on it and what is
+// charged for it. CPU is in millicores, memory in bytes and GPU in cards.
+type NodeResourceUsage struct {
+	// Capacity is the allocatable resource of the node
+	Capacity int64 `json:"capacity"  bson:"capacity"`
+	// Allocated is requested by all the pods scheduled to the node, system pods included
+	Allocated int64 `json:"allocated" bson:"allocated"`
+	// Billed is charged to the users by the monitor
+	Billed int64 `json:"billed"    bson:"billed"`
+	// Idle is the capacity that is not allocated to any pod
+	Idle int64 `json:"idle"      bson:"idle"`
+	// Unbilled is the capacity that is not charged to any user
+	Unbilled int64 `json:"unbilled"  bson:"unbilled"`
+}
+
+// NodeUsage is the usage report of a node at a point in time
+type NodeUsage struct {
+	Time time.Time `json:"time"               bson:"time"`
+	Node string    `json:"node"               bson:"node"`
+	// GPUModel is the gpu product of the node, empty if it has no gpu
+	GPUModel string             `json:"gpuModel,omitempty" bson:"gpu_model,omitempty"`
+	CPU      NodeResourceUsage  `json:"cpu"                bson:"cpu"`
+	Memory   NodeResourceUsage  `json:"memory"             bson:"memory"`
+	GPU      *NodeResourceUsage `json:"gpu,omitempty"      bson:"gpu,omitempty"`
+}
+
+// NodeUsageSummary sums the node usage of the cluster, gpu is grouped by model
+type NodeUsageSummary struct {
+	Nodes  int                          `json:"nodes"`
+	CPU    NodeResourceUsage            `json:"cpu"`
+	Memory NodeResourceUsage            `json:"memory"`
+	GPU    map[string]NodeResourceUsage `json:"gpu,omitempty"`
+}
+
+// NodeUsageBuilder accumulates the pods of the cluster into the usage of their nodes
+type NodeUsageBuilder struct {
+	time  time.Time
+	nodes map[string]*NodeUsage
+	cards map[string]corev1.ResourceName
+}
+
+func NewNodeUsageBuilder(at time.Time) *NodeUsageBuilder {
+	return &NodeUsageBuilder{
+		time:  at.UTC(),
+		nodes: make(map[string]*NodeUsage),
+		cards: make(map[string]corev1.ResourceName),
+	}
+}
+
+// AddNode adds a node to the report, gpuCard is the extended resource of its gpu model.
+func (b *NodeUsageBuilder) AddNode(
+	node *corev1.Node,
+	gpuModel string,
+	gpuCard corev1.ResourceName,
+) {
+	usage := &NodeUsage{
+		Time:   b.time,
+		Node:   node.Name,
+		CPU:    NodeResourceUsage{Capacity: node.Status.Allocatable.Cpu().MilliValue()},
+		Memory: NodeResourceUsage{Capacity: node.Status.Allocatable.Memory().Value()},
+	}
+	if gpuModel != "" && gpuCard != "" {
+		usage.GPUModel = gpuModel
+		capacity := node.Status.Allocatable[gpuCard]
+		usage.GPU = &NodeResourceUsage{Capacity: capacity.Value()}
+		b.cards[node.Name] = gpuCard
+	}
+	b.nodes[node.Name] = usage
+}
+
+// AddPod adds the resources of a pod to its node, billed is true if the namespace of the pod
+// is charged by the monitor. The billed resources follow the monitor: limits are charged
+// before requests, and a pod that does not start for more than a minute is not charged
+// except its gpu.
+func (b *NodeUsageBuilder) AddPod(pod *corev1.Pod, billed bool) {
+	usage, ok := b.nodes[pod.Spec.NodeName]
+	if !ok || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return
+	}
+	card := b.cards[pod.Spec.NodeName]
+	skip := pod.Status.Phase != corev1.PodRunning &&
+		(pod.Status.StartTime == nil || b.time.Sub(pod.Status.StartTime.Time) > time.Minute)
+	for _, container := range pod.Spec.Containers {
+		res := container.Resources
+		usage.CPU.Allocated += res.Requests.Cpu().MilliValue()
+		usage.Memory.Allocated += res.Requests.Memory().Value()
+		gpuLimit := res.Limits[card]
+		if usage.GPU != nil {
+			// gpu can not be overcommitted, the request equals the limit
+			usage.GPU.Allocated += gpuLimit.Value()
+		}
+		if !billed {
+			continue
+		}
+		if usage.GPU != nil {
+			usage.GPU.Billed += gpuLimit.Value()
+		}
+		if skip {
+			continue
+		}
+		usage.CPU.Billed += limitOrRequest(res, corev1.ResourceCPU).MilliValue()
+		usage.Memory.Billed += limitOrRequest(res, corev1.ResourceMemory).Value()
+	}
+}
+
+// Usages returns the node usage sorted by node name
+func (b *NodeUsageBuilder) Usages() []*NodeUsage {
+	usages := make([]*NodeUsage, 0, len(b.nodes))
+	for _, usage := range b.nodes {
+		usage.CPU.complete()
+		usage.Memory.complete()
+		if usage.GPU != nil {
+			usage.GPU.complete()
+		}
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Node < usages[j].Node })
+	return usages
+}
+
+// SummarizeNodeUsage sums the usage of the nodes
+func SummarizeNodeUsage(usages []NodeUsage) NodeUsageSummary {
+	summary := NodeUsageSummary{Nodes: len(usages), GPU: make(map[string]NodeResourceUsage)}
+	for i := range usages {
+		summary.CPU.add(usages[i].CPU)
+		summary.Memory.add(usages[i].Memory)
+		if usages[i].GPU != nil {
+			gpu := summary.GPU[usages[i].GPUModel]
+			gpu.add(*usages[i].GPU)
+			summary.GPU[usages[i].GPUModel] = gpu
+		}
+	}
+	return summary
+}
+
+func (u *NodeResourceUsage) complete() {
+	u.Idle = max(u.Capacity-u.Allocated, 0)
+	u.Unbilled = max(u.Capacity-u.Billed, 0)
+}
+
+func (u *NodeResourceUsage) add(o NodeResourceUsage) {
+	u.Capacity += o.Capacity
+	u.Allocated += o.Allocated
+	u.Billed += o.Billed
+	u.Idle += o.Idle
+	u.Unbilled += o.Unbilled
+}
+
+func limitOrRequest(res corev1.ResourceRequirements, name corev1.ResourceName) *resource.Quantity {
+	if limit, ok := res.Limits[name]; ok {
+		return &limit
+	}
+	request := res.Requests[name]
+	return &request
+}
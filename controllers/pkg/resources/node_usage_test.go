@@ -0,0 +1,114 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resources
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeUsageBuilder(t *testing.T) {
+	const card corev1.ResourceName = "nvidia.com/gpu"
+	now := time.Date(2024, 5, 10, 10, 0, 0, 0, time.UTC)
+	node := func(name string, gpu int64) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		n.Status.Allocatable = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("8"),
+			corev1.ResourceMemory: resource.MustParse("16Gi"),
+		}
+		if gpu > 0 {
+			n.Status.Allocatable[card] = *resource.NewQuantity(gpu, resource.DecimalSI)
+		}
+		return n
+	}
+	pod := func(nodeName string, phase corev1.PodPhase, res corev1.ResourceRequirements) *corev1.Pod {
+		p := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				NodeName:   nodeName,
+				Containers: []corev1.Container{{Resources: res}},
+			},
+		}
+		p.Status.Phase = phase
+		p.Status.StartTime = &metav1.Time{Time: now.Add(-time.Hour)}
+		return p
+	}
+
+	builder := NewNodeUsageBuilder(now)
+	builder.AddNode(node("cpu-node", 0), "", "")
+	builder.AddNode(node("gpu-node", 4), "A100", card)
+
+	// a user pod billed by its limits
+	builder.AddPod(pod("cpu-node", corev1.PodRunning, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("500m"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}), true)
+	// a system pod is allocated but never billed
+	builder.AddPod(pod("cpu-node", corev1.PodRunning, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+	}), false)
+	// a finished pod releases its resources
+	builder.AddPod(pod("cpu-node", corev1.PodSucceeded, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4")},
+	}), true)
+	// a pending gpu pod is billed for the gpu only
+	builder.AddPod(pod("gpu-node", corev1.PodPending, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+		Limits:   corev1.ResourceList{card: resource.MustParse("1")},
+	}), true)
+
+	usages := builder.Usages()
+	if len(usages) != 2 || usages[0].Node != "cpu-node" || usages[1].Node != "gpu-node" {
+		t.Fatalf("unexpected nodes %+v", usages)
+	}
+	cpuNode := usages[0]
+	wantCPU := NodeResourceUsage{
+		Capacity: 8000, Allocated: 1500, Billed: 2000, Idle: 6500, Unbilled: 6000,
+	}
+	if cpuNode.CPU != wantCPU {
+		t.Errorf("cpu-node cpu = %+v, want %+v", cpuNode.CPU, wantCPU)
+	}
+	if cpuNode.Memory.Allocated != 1<<30 || cpuNode.Memory.Billed != 2<<30 {
+		t.Errorf("unexpected cpu-node memory %+v", cpuNode.Memory)
+	}
+	if cpuNode.GPU != nil || cpuNode.GPUModel != "" {
+		t.Errorf("cpu-node should not report gpu")
+	}
+	gpuNode := usages[1]
+	wantGPU := NodeResourceUsage{Capacity: 4, Allocated: 1, Billed: 1, Idle: 3, Unbilled: 3}
+	if gpuNode.GPU == nil || *gpuNode.GPU != wantGPU || gpuNode.GPUModel != "A100" {
+		t.Fatalf("gpu-node gpu = %+v, want %+v", gpuNode.GPU, wantGPU)
+	}
+	if gpuNode.CPU.Allocated != 1000 || gpuNode.CPU.Billed != 0 {
+		t.Errorf("unexpected gpu-node cpu %+v", gpuNode.CPU)
+	}
+
+	summary := SummarizeNodeUsage([]NodeUsage{*cpuNode, *gpuNode})
+	if summary.Nodes != 2 || summary.CPU.Capacity != 16000 || summary.CPU.Billed != 2000 {
+		t.Errorf("unexpected summary cpu %+v", summary.CPU)
+	}
+	if summary.GPU["A100"] != wantGPU {
+		t.Errorf("summary gpu = %+v, want %+v", summary.GPU["A100"], wantGPU)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const billingStatementPeriodLayout = "2006-01"
+
+// BillingStatementFormat is the file format of a billing statement.
+type BillingStatementFormat string
+
+const (
+	BillingStatementFormatCSV  BillingStatementFormat = "csv"
+	BillingStatementFormatXLSX BillingStatementFormat = "xlsx"
+)
+
+// IsValid reports whether the format is one a statement can be rendered in.
+func (f BillingStatementFormat) IsValid() bool {
+	return f == BillingStatementFormatCSV || f == BillingStatementFormatXLSX
+}
+
+// BillingStatementSubscription opts a user in to the monthly billing statement of a region, the
+// statement of the previous month is sent to Email once LastPeriod falls behind.
+type BillingStatementSubscription struct {
+	ID           uuid.UUID              `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id"`
+	UserUID      uuid.UUID              `gorm:"type:uuid;not null;column:user_uid;uniqueIndex:idx_billing_statement_user_region"`
+	RegionDomain string                 `gorm:"type:varchar(50);not null;column:region_domain;uniqueIndex:idx_billing_statement_user_region"`
+	Email        string                 `gorm:"type:varchar(255);not null;column:email"`
+	Format       BillingStatementFormat `gorm:"type:varchar(10);not null;default:'csv';column:format"`
+	IsEnabled    bool                   `gorm:"type:boolean;not null;default:true;column:is_enabled"`
+	// LastPeriod is the month of the last statement sent, formatted as 2006-01.
+	LastPeriod string    `gorm:"type:varchar(7);column:last_period"`
+	CreatedAt  time.Time `gorm:"type:timestamp(3) with time zone;default:current_timestamp;column:created_at"`
+	UpdatedAt  time.Time `gorm:"type:timestamp(3) with time zone;autoUpdateTime;default:current_timestamp;column:updated_at"`
+}
+
+func (BillingStatementSubscription) TableName() string {
+	return "BillingStatementSubscription"
+}
+
+// BillingStatementPeriod returns the month t falls in, formatted as 2006-01 in UTC.
+func BillingStatementPeriod(t time.Time) string {
+	return t.UTC().Format(billingStatementPeriodLayout)
+}
+
+// PreviousBillingStatementPeriod returns the month before the one t falls in, which is the last
+// month a statement can be sent for at t.
+func PreviousBillingStatementPeriod(t time.Time) string {
+	t = t.UTC()
+	monthStart := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return BillingStatementPeriod(monthStart.AddDate(0, -1, 0))
+}
+
+// ParseBillingStatementPeriod returns the start of the month of period and the start of the next
+// month.
+func ParseBillingStatementPeriod(period string) (start, end time.Time, err error) {
+	start, err = time.Parse(billingStatementPeriodLayout, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,163 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// maxUserAlertRuleDuration bounds how long a condition must hold before a rule fires.
+const maxUserAlertRuleDuration = 24 * 60 * 60
+
+// UserAlertRuleSource is the service whose metric templates a rule queries.
+type UserAlertRuleSource string
+
+const (
+	UserAlertRuleSourceDatabase  UserAlertRuleSource = "database"
+	UserAlertRuleSourceLaunchpad UserAlertRuleSource = "launchpad"
+)
+
+type UserAlertRuleComparator string
+
+const (
+	UserAlertRuleComparatorGT  UserAlertRuleComparator = "gt"
+	UserAlertRuleComparatorGTE UserAlertRuleComparator = "gte"
+	UserAlertRuleComparatorLT  UserAlertRuleComparator = "lt"
+	UserAlertRuleComparatorLTE UserAlertRuleComparator = "lte"
+	UserAlertRuleComparatorEQ  UserAlertRuleComparator = "eq"
+	UserAlertRuleComparatorNE  UserAlertRuleComparator = "ne"
+)
+
+type UserAlertRuleSeverity string
+
+const (
+	UserAlertRuleSeverityInfo     UserAlertRuleSeverity = "info"
+	UserAlertRuleSeverityWarning  UserAlertRuleSeverity = "warning"
+	UserAlertRuleSeverityCritical UserAlertRuleSeverity = "critical"
+)
+
+type UserAlertRuleState string
+
+const (
+	UserAlertRuleStateInactive UserAlertRuleState = "inactive"
+	UserAlertRuleStatePending  UserAlertRuleState = "pending"
+	UserAlertRuleStateFiring   UserAlertRuleState = "firing"
+)
+
+// UserAlertRule alerts the user when a metric of a database or launchpad app in the namespace
+// matches the condition for Duration seconds. The metric is one of the query templates the
+// database and launchpad monitor services already serve, the firing and resolved events go to
+// the notification accounts of the user.
+type UserAlertRule struct {
+	ID           uuid.UUID           `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id"`
+	UserUID      uuid.UUID           `gorm:"type:uuid;not null;column:user_uid;index:idx_user_alert_rule_user_uid"`
+	RegionDomain string              `gorm:"type:varchar(50);not null;column:region_domain;index:idx_user_alert_rule_region"`
+	Namespace    string              `gorm:"type:varchar(100);not null;column:namespace"`
+	Name         string              `gorm:"type:varchar(100);not null;column:name"`
+	Source       UserAlertRuleSource `gorm:"type:varchar(20);not null;column:source"`
+	// DatabaseType is the type of the database the template belongs to, like apecloud-mysql,
+	// only used by database rules.
+	DatabaseType string `gorm:"type:varchar(50);column:database_type"`
+	// Resource is the name of the database cluster or the launchpad app.
+	Resource string `gorm:"type:varchar(100);not null;column:resource"`
+	// Template is the key of the query template, like cpu or connections.
+	Template   string                  `gorm:"type:varchar(50);not null;column:template"`
+	Comparator UserAlertRuleComparator `gorm:"type:varchar(10);not null;column:comparator"`
+	Threshold  float64                 `gorm:"type:double precision;not null;column:threshold"`
+	// Duration is how many seconds the condition must hold before the rule fires, 0 fires at once.
+	Duration  int64                 `gorm:"type:bigint;default:0;column:duration"`
+	Severity  UserAlertRuleSeverity `gorm:"type:varchar(20);not null;column:severity"`
+	IsEnabled bool                  `gorm:"type:boolean;not null;default:true;column:is_enabled"`
+	State     UserAlertRuleState    `gorm:"type:varchar(20);default:'inactive';column:state"`
+	// FiringSeries are the label sets of the series notified as firing, so they are not notified
+	// again after a restart.
+	FiringSeries    pq.StringArray `gorm:"type:text[];column:firing_series"`
+	LastEvaluatedAt time.Time      `gorm:"type:timestamp(3) with time zone;column:last_evaluated_at"`
+	LastFiredAt     time.Time      `gorm:"type:timestamp(3) with time zone;column:last_fired_at"`
+	CreatedAt       time.Time      `gorm:"type:timestamp(3) with time zone;default:current_timestamp;column:created_at"`
+	UpdatedAt       time.Time      `gorm:"type:timestamp(3) with time zone;autoUpdateTime;default:current_timestamp;column:updated_at"`
+}
+
+func (UserAlertRule) TableName() string {
+	return "UserAlertRule"
+}
+
+// Validate checks the settings of the rule a user can change, the template itself is checked by
+// the service that knows the templates.
+func (r *UserAlertRule) Validate() error {
+	if r.Namespace == "" || r.RegionDomain == "" {
+		return errors.New("namespace and region domain are required")
+	}
+	if r.Name == "" || r.Resource == "" || r.Template == "" {
+		return errors.New("name, resource and template are required")
+	}
+	switch r.Source {
+	case UserAlertRuleSourceDatabase:
+		if r.DatabaseType == "" {
+			return errors.New("database type is required for database rules")
+		}
+	case UserAlertRuleSourceLaunchpad:
+		r.DatabaseType = ""
+	default:
+		return fmt.Errorf("unsupported source: %s", r.Source)
+	}
+	switch r.Comparator {
+	case UserAlertRuleComparatorGT, UserAlertRuleComparatorGTE, UserAlertRuleComparatorLT,
+		UserAlertRuleComparatorLTE, UserAlertRuleComparatorEQ, UserAlertRuleComparatorNE:
+	default:
+		return fmt.Errorf("unsupported comparator: %s", r.Comparator)
+	}
+	switch r.Severity {
+	case UserAlertRuleSeverityInfo, UserAlertRuleSeverityWarning, UserAlertRuleSeverityCritical:
+	default:
+		return fmt.Errorf("unsupported severity: %s", r.Severity)
+	}
+	if math.IsNaN(r.Threshold) || math.IsInf(r.Threshold, 0) {
+		return errors.New("threshold must be a finite number")
+	}
+	if r.Duration < 0 || r.Duration > maxUserAlertRuleDuration {
+		return fmt.Errorf(
+			"duration %d must be in [0, %d] seconds",
+			r.Duration,
+			maxUserAlertRuleDuration,
+		)
+	}
+	return nil
+}
+
+// Matches reports whether value meets the condition of the rule.
+func (r *UserAlertRule) Matches(value float64) bool {
+	switch r.Comparator {
+	case UserAlertRuleComparatorGT:
+		return value > r.Threshold
+	case UserAlertRuleComparatorGTE:
+		return value >= r.Threshold
+	case UserAlertRuleComparatorLT:
+		return value < r.Threshold
+	case UserAlertRuleComparatorLTE:
+		return value <= r.Threshold
+	case UserAlertRuleComparatorEQ:
+		return value == r.Threshold
+	case UserAlertRuleComparatorNE:
+		return value != r.Threshold
+	}
+	return false
+}
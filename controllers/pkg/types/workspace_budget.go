@@ -0,0 +1,145 @@
+// Copyright © 2024 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// WorkspaceBudgetStatusAnnoKey marks a workspace namespace suspended because its budget is used up,
+// so only the namespaces soft-stopped by the budget are resumed by it.
+const WorkspaceBudgetStatusAnnoKey = "budget.sealos.io/status"
+
+// maxWorkspaceBudgetThreshold bounds the alert thresholds, a budget may alert after it is exceeded.
+const maxWorkspaceBudgetThreshold = 1000
+
+// DefaultWorkspaceBudgetAlertThresholds are the used percentages a budget alerts at by default.
+var DefaultWorkspaceBudgetAlertThresholds = []int64{50, 80, 100}
+
+type WorkspaceBudgetStatus string
+
+const (
+	WorkspaceBudgetStatusNormal      WorkspaceBudgetStatus = "normal"
+	WorkspaceBudgetStatusSoftStopped WorkspaceBudgetStatus = "soft_stopped"
+)
+
+// WorkspaceBudget is the monthly spending limit of a workspace. The settled billing of the workspace is
+// charged to it every billing hour, the spent amount is reset at the start of every month (UTC).
+type WorkspaceBudget struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id"`
+	Workspace    string    `gorm:"type:varchar(50);column:workspace;uniqueIndex:idx_workspace_budget_workspace_region"`
+	RegionDomain string    `gorm:"type:varchar(50);column:region_domain;uniqueIndex:idx_workspace_budget_workspace_region"`
+	UserUID      uuid.UUID `gorm:"type:uuid;column:user_uid;index:idx_workspace_budget_user_uid"`
+	// MonthlyLimit has the same unit as the billing amount.
+	MonthlyLimit int64 `gorm:"type:bigint;not null;column:monthly_limit"`
+	// AlertThresholds are the used percentages of MonthlyLimit to alert at, in ascending order.
+	AlertThresholds pq.Int64Array `gorm:"type:bigint[];column:alert_thresholds"`
+	// SoftStop suspends the workspace once MonthlyLimit is used up.
+	SoftStop bool                  `gorm:"type:boolean;default:false;column:soft_stop"`
+	Status   WorkspaceBudgetStatus `gorm:"type:varchar(20);default:'normal';column:status"`
+	// PeriodStartAt is the start of the month Spent is counted in.
+	PeriodStartAt time.Time `gorm:"type:timestamp(3) with time zone;column:period_start_at"`
+	Spent         int64     `gorm:"type:bigint;default:0;column:spent"`
+	// AlertedPercent is the highest threshold alerted in the current period.
+	AlertedPercent int64 `gorm:"type:bigint;default:0;column:alerted_percent"`
+	// LastChargedAt is the last billing hour charged, an hour is never charged twice.
+	LastChargedAt time.Time `gorm:"type:timestamp(3) with time zone;column:last_charged_at"`
+	CreatedAt     time.Time `gorm:"type:timestamp(3) with time zone;default:current_timestamp;column:created_at"`
+	UpdatedAt     time.Time `gorm:"type:timestamp(3) with time zone;autoUpdateTime;default:current_timestamp;column:updated_at"`
+}
+
+func (WorkspaceBudget) TableName() string {
+	return "WorkspaceBudget"
+}
+
+// WorkspaceBudgetPeriodStart returns the start of the budget period t is in.
+func WorkspaceBudgetPeriodStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// NormalizeWorkspaceBudgetThresholds sorts and deduplicates the alert thresholds, the default ones
+// are returned if thresholds is empty.
+func NormalizeWorkspaceBudgetThresholds(thresholds []int64) ([]int64, error) {
+	if len(thresholds) == 0 {
+		return slices.Clone(DefaultWorkspaceBudgetAlertThresholds), nil
+	}
+	normalized := slices.Clone(thresholds)
+	for _, t := range normalized {
+		if t <= 0 || t > maxWorkspaceBudgetThreshold {
+			return nil, fmt.Errorf(
+				"alert threshold %d must be in (0, %d]", t, maxWorkspaceBudgetThreshold,
+			)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+// Validate checks the settings of the budget a user can change.
+func (b *WorkspaceBudget) Validate() error {
+	if b.Workspace == "" || b.RegionDomain == "" {
+		return errors.New("workspace and region domain are required")
+	}
+	if b.MonthlyLimit <= 0 {
+		return fmt.Errorf("monthly limit must be greater than zero: %d", b.MonthlyLimit)
+	}
+	thresholds, err := NormalizeWorkspaceBudgetThresholds(b.AlertThresholds)
+	if err != nil {
+		return err
+	}
+	b.AlertThresholds = thresholds
+	return nil
+}
+
+// CurrentSpent returns the amount spent in the period now is in.
+func (b *WorkspaceBudget) CurrentSpent(now time.Time) int64 {
+	if WorkspaceBudgetPeriodStart(now).After(b.PeriodStartAt) {
+		return 0
+	}
+	return b.Spent
+}
+
+// UsedPercent returns the used percentage of the monthly limit, rounded down.
+func (b *WorkspaceBudget) UsedPercent(now time.Time) int64 {
+	if b.MonthlyLimit <= 0 {
+		return 0
+	}
+	return b.CurrentSpent(now) * 100 / b.MonthlyLimit
+}
+
+// Exhausted reports whether the monthly limit is used up in the period now is in.
+func (b *WorkspaceBudget) Exhausted(now time.Time) bool {
+	return b.MonthlyLimit > 0 && b.CurrentSpent(now) >= b.MonthlyLimit
+}
+
+// PendingAlertThreshold returns the highest threshold reached but not alerted yet, or 0 if there is
+// none.
+func (b *WorkspaceBudget) PendingAlertThreshold(now time.Time) int64 {
+	used := b.UsedPercent(now)
+	var pending int64
+	for _, t := range b.AlertThresholds {
+		if t <= used && t > b.AlertedPercent {
+			pending = t
+		}
+	}
+	return pending
+}
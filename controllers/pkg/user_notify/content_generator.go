@@ -56,6 +56,12 @@ func (g *DefaultContentGenerator) GenerateContent(
 		EventTypeWorkspaceSubscriptionDebtPreDeletion,
 		EventTypeTrafficUsageAlert:
 		return g.generateWorkspaceSubscriptionContent(method, event)
+	case EventTypeWorkspaceBudgetAlert:
+		return g.generateWorkspaceBudgetContent(method, event)
+	case EventTypeUserAlertRule:
+		return g.generateUserAlertRuleContent(method, event)
+	case EventTypeBillingStatement:
+		return g.generateBillingStatementContent(method, event)
 	case EventTypeTrafficStatusChange /*EventTypeTrafficUsageAlert*/ :
 		return g.generateTrafficContent(method, event.EventData, event.Recipient)
 	case EventTypeCustom:
@@ -220,6 +226,125 @@ func (g *DefaultContentGenerator) generateWorkspaceSubscriptionContent(
 	return title, content, templateID, nil
 }
 
+// generateWorkspaceBudgetContent 生成工作空间预算告警内容
+func (g *DefaultContentGenerator) generateWorkspaceBudgetContent(
+	method NotificationMethod,
+	event *NotificationEvent,
+) (title, content, templateID string, err error) {
+	var budgetData WorkspaceBudgetEventData
+	dataBytes, err := json.Marshal(event.EventData)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal budget event data: %w", err)
+	}
+	if err := json.Unmarshal(dataBytes, &budgetData); err != nil {
+		return "", "", "", fmt.Errorf("failed to parse budget event data: %w", err)
+	}
+
+	if config, exists := g.config[method]; exists {
+		templateID = config.GetVMSTemplateID(EventTypeWorkspaceBudgetAlert)
+		switch method {
+		case NotificationMethodSMS:
+			templateID = config.GetSMSTemplateCode(EventTypeWorkspaceBudgetAlert)
+		case NotificationMethodEmail:
+			templateID, err = config.GenerateEmailContent(event)
+			if err != nil {
+				return "", "", "", fmt.Errorf("failed to generate email content: %w", err)
+			}
+		}
+	}
+
+	title = "Workspace Budget Alert"
+	contentTmpl := "Dear {{.UserName}}, your workspace {{.Workspace}} has used {{.UsagePercent}}% of its monthly budget."
+	if budgetData.SoftStopped {
+		title = "Workspace Budget Exhausted"
+		contentTmpl = "Dear {{.UserName}}, your workspace {{.Workspace}} has used up its monthly budget and has been suspended. Raise the budget to resume it."
+	}
+	content = g.formatContent(method, contentTmpl, map[string]any{
+		"UserName":     event.Recipient.UserName,
+		"Workspace":    budgetData.Workspace,
+		"UsagePercent": budgetData.UsagePercent,
+	})
+	return title, content, templateID, nil
+}
+
+// generateUserAlertRuleContent 生成用户告警规则通知内容
+func (g *DefaultContentGenerator) generateUserAlertRuleContent(
+	method NotificationMethod,
+	event *NotificationEvent,
+) (title, content, templateID string, err error) {
+	var alertData UserAlertRuleEventData
+	dataBytes, err := json.Marshal(event.EventData)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal alert rule event data: %w", err)
+	}
+	if err := json.Unmarshal(dataBytes, &alertData); err != nil {
+		return "", "", "", fmt.Errorf("failed to parse alert rule event data: %w", err)
+	}
+
+	if config, exists := g.config[method]; exists {
+		templateID = config.GetVMSTemplateID(EventTypeUserAlertRule)
+		switch method {
+		case NotificationMethodSMS:
+			templateID = config.GetSMSTemplateCode(EventTypeUserAlertRule)
+		case NotificationMethodEmail:
+			templateID, err = config.GenerateEmailContent(event)
+			if err != nil {
+				return "", "", "", fmt.Errorf("failed to generate email content: %w", err)
+			}
+		}
+	}
+
+	title = fmt.Sprintf("[%s] Alert %s Firing", alertData.Severity, alertData.RuleName)
+	contentTmpl := "Dear {{.UserName}}, the alert {{.RuleName}} of {{.Resource}} in {{.Namespace}} is firing: {{.Condition}}, current value {{.Value}}."
+	if alertData.Status == "resolved" {
+		title = fmt.Sprintf("[%s] Alert %s Resolved", alertData.Severity, alertData.RuleName)
+		contentTmpl = "Dear {{.UserName}}, the alert {{.RuleName}} of {{.Resource}} in {{.Namespace}} is resolved, current value {{.Value}}."
+	}
+	content = g.formatContent(method, contentTmpl, map[string]any{
+		"UserName":  event.Recipient.UserName,
+		"RuleName":  alertData.RuleName,
+		"Resource":  alertData.Resource,
+		"Namespace": alertData.Namespace,
+		"Condition": alertData.Condition,
+		"Value":     alertData.Value,
+	})
+	return title, content, templateID, nil
+}
+
+// generateBillingStatementContent 生成月度账单通知内容
+func (g *DefaultContentGenerator) generateBillingStatementContent(
+	method NotificationMethod,
+	event *NotificationEvent,
+) (title, content, templateID string, err error) {
+	var statementData BillingStatementEventData
+	dataBytes, err := json.Marshal(event.EventData)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to marshal billing statement event data: %w", err)
+	}
+	if err := json.Unmarshal(dataBytes, &statementData); err != nil {
+		return "", "", "", fmt.Errorf("failed to parse billing statement event data: %w", err)
+	}
+
+	if config, exists := g.config[method]; exists && method == NotificationMethodEmail {
+		templateID, err = config.GenerateEmailContent(event)
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to generate email content: %w", err)
+		}
+	}
+
+	title = fmt.Sprintf("Billing Statement %s", statementData.Period)
+	content = g.formatContent(method,
+		"Dear {{.UserName}}, your billing statement of {{.Period}} in {{.RegionDomain}} is attached, the total cost is {{.TotalAmount}} with {{.CreditsAmount}} paid by credits.",
+		map[string]any{
+			"UserName":      event.Recipient.UserName,
+			"Period":        statementData.Period,
+			"RegionDomain":  statementData.RegionDomain,
+			"TotalAmount":   statementData.TotalAmount,
+			"CreditsAmount": statementData.CreditsAmount,
+		})
+	return title, content, templateID, nil
+}
+
 // generateTrafficContent 生成流量相关通知内容
 func (g *DefaultContentGenerator) generateTrafficContent(
 	method NotificationMethod,
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"strconv"
 	"strings"
@@ -256,6 +257,12 @@ func (p *EmailProvider) Send(
 	m.SetAddressHeader("From", p.Config.FromEmail, p.Config.FromName)
 	m.SetHeader("Subject", message.Title)
 	m.SetBody("text/html", emailContent)
+	for _, attachment := range message.Attachments {
+		m.Attach(attachment.Name, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(attachment.Content)
+			return err
+		}))
+	}
 
 	// 创建邮件发送器
 	d := gomail.NewDialer(
@@ -516,6 +523,26 @@ var eventConfigs = map[EventType]EventConfig{
 		Recommendation: "https://usw.sealos.io/?openapp=system-costcenter&region=%s&workspace=%s",
 		DatesFormat:    "Until %s",
 	},
+	EventTypeWorkspaceBudgetAlert: {
+		TitleTemplate:  "%s Region %s Workspace Budget %s",
+		AlertTemplate:  "This is a heads-up that the spending of the %s workspace in the %s region has reached %s of its monthly budget.",
+		Content:        "Please review the cost of your workspace, or raise its budget to avoid service disruption.",
+		BorderColor:    "#ffa500",
+		Recommendation: "https://usw.sealos.io/?openapp=system-costcenter&region=%s&workspace=%s",
+	},
+	EventTypeUserAlertRule: {
+		TitleTemplate: "[%s] %s Region %s Alert %s %s",
+		AlertTemplate: "The alert %s of %s in the %s namespace of the %s region is %s: %s, the current value is %s.",
+		Content:       "Please check the resource in the console. You can change or disable the rule in the alert settings.",
+		BorderColor:   "#ffa500",
+	},
+	EventTypeBillingStatement: {
+		TitleTemplate:  "%s Region Billing Statement %s",
+		AlertTemplate:  "Your billing statement of %s in the %s region is attached. The total cost is %s, %s of it was paid with credits and %s with the balance.",
+		Content:        "You can export statements of any time range in the cost center, or unsubscribe from the monthly statement in the billing settings.",
+		BorderColor:    "#0884dd",
+		Recommendation: "https://usw.sealos.io/?openapp=system-costcenter&region=%s",
+	},
 	EventTypeWorkspaceSubscriptionCreatedSuccess: {
 		TitleTemplate: "%s Region %s Space Subscription Created Successfully",
 		AlertTemplate: `Welcome to Sealos! 
@@ -687,6 +714,109 @@ func generateEmailContent(event *NotificationEvent) (*EmailData, error) {
 			data.Content = "Please upgrade your plan immediately to ensure continued operation."
 			data.BorderColor = "#ff0000"
 		}
+	case EventTypeWorkspaceBudgetAlert:
+		var budgetData WorkspaceBudgetEventData
+		dataBytes, err := json.Marshal(event.EventData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal budget event data: %w", err)
+		}
+		if err := json.Unmarshal(dataBytes, &budgetData); err != nil {
+			return nil, fmt.Errorf("failed to parse budget event data: %w", err)
+		}
+		titleSuffix := "Alert"
+		if budgetData.SoftStopped {
+			titleSuffix = "Exhausted"
+			data.Content = "The workspace has been suspended. Raise its budget or wait for the next month to resume it."
+			data.BorderColor = "#ff0000"
+		}
+		data.Title = fmt.Sprintf(
+			config.TitleTemplate,
+			budgetData.RegionDomain,
+			budgetData.Workspace,
+			titleSuffix,
+		)
+		data.AlertMessage = fmt.Sprintf(
+			strings.ReplaceAll(
+				config.AlertTemplate,
+				`%s`,
+				`<span class="region-text" style="font-family: Arial, Helvetica, sans-serif;letter-spacing: 0.25px;font-weight: 600;color: #333;">%s</span>`,
+			),
+			budgetData.Workspace,
+			budgetData.RegionDomain,
+			strconv.FormatInt(budgetData.UsagePercent, 10)+"%",
+		)
+		data.Recommendation = fmt.Sprintf(
+			config.Recommendation,
+			budgetData.RegionDomain,
+			budgetData.Workspace,
+		)
+	case EventTypeUserAlertRule:
+		var alertData UserAlertRuleEventData
+		dataBytes, err := json.Marshal(event.EventData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal alert rule event data: %w", err)
+		}
+		if err := json.Unmarshal(dataBytes, &alertData); err != nil {
+			return nil, fmt.Errorf("failed to parse alert rule event data: %w", err)
+		}
+		status := "Firing"
+		switch {
+		case alertData.Status == "resolved":
+			status = "Resolved"
+			data.Content = "No action is needed."
+			data.BorderColor = "#00a854"
+		case alertData.Severity == "critical":
+			data.BorderColor = "#ff0000"
+		}
+		data.Title = fmt.Sprintf(
+			config.TitleTemplate,
+			alertData.Severity,
+			alertData.RegionDomain,
+			alertData.Namespace,
+			alertData.RuleName,
+			status,
+		)
+		data.AlertMessage = fmt.Sprintf(
+			strings.ReplaceAll(
+				config.AlertTemplate,
+				`%s`,
+				`<span class="region-text" style="font-family: Arial, Helvetica, sans-serif;letter-spacing: 0.25px;font-weight: 600;color: #333;">%s</span>`,
+			),
+			alertData.RuleName,
+			alertData.Resource,
+			alertData.Namespace,
+			alertData.RegionDomain,
+			strings.ToLower(status),
+			alertData.Condition,
+			strconv.FormatFloat(alertData.Value, 'f', -1, 64),
+		)
+	case EventTypeBillingStatement:
+		var statementData BillingStatementEventData
+		dataBytes, err := json.Marshal(event.EventData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal billing statement event data: %w", err)
+		}
+		if err := json.Unmarshal(dataBytes, &statementData); err != nil {
+			return nil, fmt.Errorf("failed to parse billing statement event data: %w", err)
+		}
+		data.Title = fmt.Sprintf(
+			config.TitleTemplate,
+			statementData.RegionDomain,
+			statementData.Period,
+		)
+		data.AlertMessage = fmt.Sprintf(
+			strings.ReplaceAll(
+				config.AlertTemplate,
+				`%s`,
+				`<span class="region-text" style="font-family: Arial, Helvetica, sans-serif;letter-spacing: 0.25px;font-weight: 600;color: #333;">%s</span>`,
+			),
+			statementData.Period,
+			statementData.RegionDomain,
+			statementData.TotalAmount,
+			statementData.CreditsAmount,
+			statementData.NetAmount,
+		)
+		data.Recommendation = fmt.Sprintf(config.Recommendation, statementData.RegionDomain)
 	case EventTypeWorkspaceSubscriptionDebt:
 		var subData WorkspaceSubscriptionDebtEventData
 		dataBytes, err := json.Marshal(event.EventData)
@@ -1043,16 +1173,17 @@ func (m *ProviderManager) SendEvent(
 
 		// 创建通知消息
 		message := &NotificationMessage{
-			UserUID:    event.UserUID,
-			EventType:  event.EventType,
-			Method:     method,
-			Priority:   event.Priority,
-			Title:      title,
-			Content:    content,
-			Recipient:  event.Recipient,
-			EventData:  event.EventData,
-			TemplateID: templateID,
-			Timestamp:  event.Timestamp,
+			UserUID:     event.UserUID,
+			EventType:   event.EventType,
+			Method:      method,
+			Priority:    event.Priority,
+			Title:       title,
+			Content:     content,
+			Recipient:   event.Recipient,
+			EventData:   event.EventData,
+			TemplateID:  templateID,
+			Timestamp:   event.Timestamp,
+			Attachments: event.Attachments,
 		}
 
 		// 发送通知
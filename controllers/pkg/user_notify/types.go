@@ -77,6 +77,15 @@ const (
 	EventTypeTrafficStatusChange EventType = "traffic_status_change"
 	EventTypeTrafficUsageAlert   EventType = "traffic_usage_alert"
 
+	// 工作空间预算告警
+	EventTypeWorkspaceBudgetAlert EventType = "workspace_budget_alert"
+
+	// 用户自定义告警规则
+	EventTypeUserAlertRule EventType = "user_alert_rule"
+
+	// 月度账单
+	EventTypeBillingStatement EventType = "billing_statement"
+
 	// 债务预警事件
 	// 债务到期
 	EventTypeWorkspaceSubscriptionDebt EventType = "workspace_subscription_debt"
@@ -100,6 +109,14 @@ type NotificationEvent struct {
 	Timestamp time.Time                   `json:"timestamp"`
 	// Whether to ignore users without contact information, the default is false to ignore
 	NotIgnoreIfNoContact bool `json:"ignore_if_no_contact,omitempty"`
+	// Attachments are only sent by email
+	Attachments []NotificationAttachment `json:"-"`
+}
+
+// NotificationAttachment 通知附件
+type NotificationAttachment struct {
+	Name    string
+	Content []byte
 }
 
 type EventData interface {
@@ -125,6 +142,59 @@ func (t *WorkspaceSubscriptionTrafficEventData) GetType() EventType {
 	return t.Type
 }
 
+func (t *WorkspaceBudgetEventData) ToMap() map[string]any {
+	return map[string]any{
+		"type":            t.Type,
+		"region_domain":   t.RegionDomain,
+		"workspace_name":  t.Workspace,
+		"used_percentage": t.UsagePercent,
+		"threshold":       t.Threshold,
+		"monthly_limit":   t.MonthlyLimit,
+		"spent":           t.Spent,
+		"soft_stopped":    t.SoftStopped,
+	}
+}
+
+func (t *WorkspaceBudgetEventData) GetType() EventType {
+	return t.Type
+}
+
+func (t *UserAlertRuleEventData) ToMap() map[string]any {
+	return map[string]any{
+		"type":          t.Type,
+		"region_domain": t.RegionDomain,
+		"namespace":     t.Namespace,
+		"rule_name":     t.RuleName,
+		"resource":      t.Resource,
+		"template":      t.Template,
+		"severity":      t.Severity,
+		"status":        t.Status,
+		"condition":     t.Condition,
+		"value":         t.Value,
+		"labels":        t.Labels,
+	}
+}
+
+func (t *UserAlertRuleEventData) GetType() EventType {
+	return t.Type
+}
+
+func (t *BillingStatementEventData) ToMap() map[string]any {
+	return map[string]any{
+		"type":           t.Type,
+		"region_domain":  t.RegionDomain,
+		"period":         t.Period,
+		"format":         t.Format,
+		"total_amount":   t.TotalAmount,
+		"credits_amount": t.CreditsAmount,
+		"net_amount":     t.NetAmount,
+	}
+}
+
+func (t *BillingStatementEventData) GetType() EventType {
+	return t.Type
+}
+
 func (t *DebtEventData) ToMap() map[string]any {
 	return map[string]any{
 		"type":           t.Type,
@@ -191,16 +261,17 @@ func (t *CustomEventData) ToMap() map[string]any {
 
 // NotificationMessage 通知消息结构（内部处理）
 type NotificationMessage struct {
-	UserUID    uuid.UUID                   `json:"user_uid"`
-	EventType  EventType                   `json:"event_type"`
-	Method     NotificationMethod          `json:"method"`
-	Priority   NotificationPriority        `json:"priority"`
-	Title      string                      `json:"title"`
-	Content    string                      `json:"content"`
-	Recipient  types.NotificationRecipient `json:"recipient"`
-	EventData  map[string]any              `json:"event_data"`
-	TemplateID string                      `json:"template_id,omitempty"`
-	Timestamp  time.Time                   `json:"timestamp"`
+	UserUID     uuid.UUID                   `json:"user_uid"`
+	EventType   EventType                   `json:"event_type"`
+	Method      NotificationMethod          `json:"method"`
+	Priority    NotificationPriority        `json:"priority"`
+	Title       string                      `json:"title"`
+	Content     string                      `json:"content"`
+	Recipient   types.NotificationRecipient `json:"recipient"`
+	EventData   map[string]any              `json:"event_data"`
+	TemplateID  string                      `json:"template_id,omitempty"`
+	Timestamp   time.Time                   `json:"timestamp"`
+	Attachments []NotificationAttachment    `json:"-"`
 }
 
 // NotificationResult 通知发送结果
@@ -386,6 +457,48 @@ type WorkspaceSubscriptionTrafficEventData struct {
 	Features       []string                     `json:"features,omitempty"`
 }
 
+// WorkspaceBudgetEventData 工作空间预算事件数据
+type WorkspaceBudgetEventData struct {
+	Type         EventType `json:"-"`
+	RegionDomain string    `json:"region_domain"`
+	Workspace    string    `json:"workspace_name"`
+	UsagePercent int64     `json:"used_percentage"`
+	Threshold    int64     `json:"threshold"`
+	MonthlyLimit int64     `json:"monthly_limit"`
+	Spent        int64     `json:"spent"`
+	SoftStopped  bool      `json:"soft_stopped,omitempty"`
+}
+
+// UserAlertRuleEventData 用户告警规则事件数据
+type UserAlertRuleEventData struct {
+	Type         EventType `json:"-"`
+	RegionDomain string    `json:"region_domain"`
+	Namespace    string    `json:"namespace"`
+	RuleName     string    `json:"rule_name"`
+	Resource     string    `json:"resource"`
+	Template     string    `json:"template"`
+	Severity     string    `json:"severity"`
+	// Status is firing or resolved
+	Status string `json:"status"`
+	// Condition is the readable condition of the rule, like "cpu > 80 for 5m"
+	Condition string  `json:"condition"`
+	Value     float64 `json:"value"`
+	Labels    string  `json:"labels,omitempty"`
+}
+
+// BillingStatementEventData 月度账单事件数据
+type BillingStatementEventData struct {
+	Type         EventType `json:"-"`
+	RegionDomain string    `json:"region_domain"`
+	// Period is the month of the statement, like 2006-01
+	Period string `json:"period"`
+	Format string `json:"format"`
+	// The amounts are formatted in the currency unit
+	TotalAmount   string `json:"total_amount"`
+	CreditsAmount string `json:"credits_amount"`
+	NetAmount     string `json:"net_amount"`
+}
+
 // CustomEventData 自定义事件数据
 type CustomEventData struct {
 	Type      EventType      `json:"-"`
@@ -164,6 +164,8 @@ type EmailDebtRender struct {
 	Language    string // 语言: "zh" 或 "en"
 	GraceReason []string
 	Balance     int64 // 当前余额
+	// 按当前用量预计余额耗尽的天数，nil 表示无法估算
+	RunsOutInDays *int64
 }
 
 type DebtGraceReason string
@@ -189,6 +191,10 @@ func (e *EmailDebtRender) SetBalance(balance int64) {
 	e.Balance = balance
 }
 
+func (e *EmailDebtRender) SetRunsOutInDays(days int64) {
+	e.RunsOutInDays = &days
+}
+
 func (e *EmailDebtRender) GetSubject() string {
 	if e.Language == "zh" {
 		return e.getSubjectZH()
@@ -230,6 +236,10 @@ func (e *EmailDebtRender) Build() map[string]any {
 		"Balance":        e.Balance,      // 原始值（兼容旧代码）
 		"BalanceInUnits": balanceInUnits, // 格式化后的值
 	}
+	if e.RunsOutInDays != nil {
+		build["RunsOutEstimated"] = true
+		build["RunsOutInDays"] = *e.RunsOutInDays
+	}
 	if e.Type == "CriticalBalancePeriod" {
 		build["CreditsAvailable"] = "1"
 	}
@@ -280,7 +290,7 @@ const DebtEmailTemplateEN = `<!DOCTYPE html>
 
                                 {{if eq .Type "LowBalancePeriod"}}
                                 <p class="alert-message" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 16px; color: #333;">
-                                    Your account balance is running low. You have <strong>${{printf "%.2f" .BalanceInUnits}} USD</strong> in credits remaining.
+                                    Your account balance is running low. You have <strong>${{printf "%.2f" .BalanceInUnits}} USD</strong> in credits remaining.{{if .RunsOutEstimated}} At your current usage, it runs out {{if eq .RunsOutInDays 0}}within a day{{else}}in about <strong>{{.RunsOutInDays}} days</strong>{{end}}.{{end}}
                                 </p>
                                 <p class="details" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 32px; color: #333;">
                                     To avoid any service disruption, please consider topping up your account soon. Your resources will continue to function normally, but we recommend maintaining a sufficient balance.
@@ -290,7 +300,7 @@ const DebtEmailTemplateEN = `<!DOCTYPE html>
                                 {{if eq .Type "CriticalBalancePeriod"}}
                                 <p class="alert-message" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 16px; color: #dc2626; background-color: #fef2f2; border-radius: 8px; line-height: 1.44; padding: 16px;">
                                     <strong>⚠️ Critical: Your account balance is critically low.</strong><br>
-                                    You have <strong>${{printf "%.2f" .BalanceInUnits}} USD</strong> in credits remaining.
+                                    You have <strong>${{printf "%.2f" .BalanceInUnits}} USD</strong> in credits remaining.{{if .RunsOutEstimated}} At your current usage, it runs out {{if eq .RunsOutInDays 0}}within a day{{else}}in about <strong>{{.RunsOutInDays}} days</strong>{{end}}.{{end}}
                                 </p>
                                 <p class="details" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 32px; color: #333;">
                                     Please top up your account as soon as possible to prevent service suspension. Your resources may be affected if the balance reaches zero.
@@ -384,7 +394,7 @@ const DebtEmailTemplateZH = `<!DOCTYPE html>
 
                                 {{if eq .Type "LowBalancePeriod"}}
                                 <p class="alert-message" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 16px; color: #333;">
-                                    您的账户余额不足。您当前的余额为 <strong>{{printf "%.2f" .BalanceInUnits}} 元</strong>。
+                                    您的账户余额不足。您当前的余额为 <strong>{{printf "%.2f" .BalanceInUnits}} 元</strong>。{{if .RunsOutEstimated}}按当前用量，预计余额将在{{if eq .RunsOutInDays 0}}一天内{{else}}约 <strong>{{.RunsOutInDays}} 天</strong>后{{end}}耗尽。{{end}}
                                 </p>
                                 <p class="details" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 32px; color: #333;">
                                     为避免影响您的正常使用，请及时充值。您的资源目前可以正常使用，但我们建议保持充足的余额以确保服务稳定。
@@ -394,7 +404,7 @@ const DebtEmailTemplateZH = `<!DOCTYPE html>
                                 {{if eq .Type "CriticalBalancePeriod"}}
                                 <p class="alert-message" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 16px; color: #dc2626; background-color: #fef2f2; border-radius: 8px; line-height: 1.44; padding: 16px;">
                                     <strong>⚠️ 紧急提醒：您的账户余额即将耗尽。</strong><br>
-                                    您当前的余额为 <strong>{{printf "%.2f" .BalanceInUnits}} 元</strong>。
+                                    您当前的余额为 <strong>{{printf "%.2f" .BalanceInUnits}} 元</strong>。{{if .RunsOutEstimated}}按当前用量，预计余额将在{{if eq .RunsOutInDays 0}}一天内{{else}}约 <strong>{{.RunsOutInDays}} 天</strong>后{{end}}耗尽。{{end}}
                                 </p>
                                 <p class="details" style="font-family: Arial, Helvetica, sans-serif; letter-spacing: 0.25px; font-size: 16px; margin-bottom: 32px; color: #333;">
                                     请尽快充值以防止服务暂停。如果余额归零，您的资源可能会受到影响。
@@ -101,6 +101,7 @@ const (
 	ObjectStorageInstance              = "OBJECT_STORAGE_INSTANCE"
 	ConcurrentLimit                    = "CONCURRENT_LIMIT"
 	envEphemeralStorageChargeThreshold = "EPHEMERAL_STORAGE_CHARGE_THRESHOLD"
+	envNodeUsageInterval               = "NODE_USAGE_INTERVAL"
 )
 
 const (
@@ -113,6 +114,7 @@ var (
 	ephemeralStorageChargeThreshold = resource.MustParse(
 		env.GetEnvWithDefault(envEphemeralStorageChargeThreshold, "10Gi"),
 	)
+	nodeUsageInterval = env.GetDurationEnvWithDefault(envNodeUsageInterval, 10*time.Minute)
 )
 
 const (
@@ -195,6 +197,9 @@ func InitIndexField(mgr ctrl.Manager) error {
 
 func (r *MonitorReconciler) StartReconciler(ctx context.Context) error {
 	r.startPeriodicReconcile()
+	if nodeUsageInterval > 0 {
+		r.startMonitorNodeUsage()
+	}
 	if r.TrafficClient != nil || r.ObjStorageClient != nil {
 		r.startMonitorTraffic()
 	}
@@ -224,6 +229,62 @@ func (r *MonitorReconciler) startPeriodicReconcile() {
 	}()
 }
 
+func (r *MonitorReconciler) startMonitorNodeUsage() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(nodeUsageInterval)
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.monitorNodeUsage(); err != nil {
+					r.Error(err, "failed to monitor node usage")
+				}
+			case <-r.stopCh:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// monitorNodeUsage records the capacity, allocated and billed resources of every node,
+// the pods of the normal user namespaces are billed the same way as monitorPodResourceUsage.
+func (r *MonitorReconciler) monitorNodeUsage() error {
+	ctx := context.Background()
+	nodeList := &corev1.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	namespaceList, err := r.getNamespaceList()
+	if err != nil {
+		return fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	filterNormalNamespace(namespaceList)
+	billed := make(map[string]struct{}, len(namespaceList.Items))
+	for i := range namespaceList.Items {
+		billed[namespaceList.Items[i].Name] = struct{}{}
+	}
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	builder := resources.NewNodeUsageBuilder(time.Now())
+	r.gpuMutex.RLock()
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		model := r.gpuNodeAlias[node.Name]
+		builder.AddNode(node, model, r.gpuAliasCard[model])
+	}
+	r.gpuMutex.RUnlock()
+	for i := range podList.Items {
+		_, ok := billed[podList.Items[i].Namespace]
+		builder.AddPod(&podList.Items[i], ok)
+	}
+	return r.DBClient.InsertNodeUsage(ctx, builder.Usages()...)
+}
+
 func (r *MonitorReconciler) getNamespaceList() (*corev1.NamespaceList, error) {
 	namespaceList := &corev1.NamespaceList{}
 	req, err := labels.NewRequirement(userv1.UserLabelOwnerKey, selection.Exists, nil)
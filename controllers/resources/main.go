@@ -238,6 +238,9 @@ func main() {
 	if err != nil {
 		reconciler.Error(err, "failed to create ttl traffic time series")
 	}
+	if err = reconciler.DBClient.CreateNodeUsageTimeSeriesIfNotExist(); err != nil {
+		reconciler.Error(err, "failed to create node usage time series")
+	}
 	// timer creates tomorrow's timing table in advance to ensure that tomorrow's table exists
 	// Execute immediately and then every 24 hours.
 	time.AfterFunc(time.Until(getNextMidnight()), func() {
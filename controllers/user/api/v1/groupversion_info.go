@@ -47,6 +47,22 @@ const (
 	UserAnnotationDisplayKey = "user.sealos.io/display-name"
 )
 
+const (
+	// UserSystemNamespace is the namespace of the service accounts of sealos users
+	UserSystemNamespace = "user-system"
+	// ClusterAdminGroup is the group of the cluster admins, who can review any request
+	ClusterAdminGroup = "system:masters"
+)
+
+const (
+	// ApprovedByAnnotationKey is set on an Operationrequest by the Owner who approves it
+	ApprovedByAnnotationKey = "user.sealos.io/approved-by"
+	// RejectedByAnnotationKey is set on an Operationrequest by the Owner who rejects it
+	RejectedByAnnotationKey = "user.sealos.io/rejected-by"
+	// RoleBindingExpiresAtAnnotationKey is the RFC3339 time a granted role binding is deprived
+	RoleBindingExpiresAtAnnotationKey = "user.sealos.io/expires-at"
+)
+
 const (
 	UgNameLabelKey        = "user.sealos.io/usergroup.name"
 	UgRoleLabelKey        = "user.sealos.io/usergroup.role"
@@ -25,10 +25,18 @@ type OperationrequestSpec struct {
 	// Namespace is the workspace that needs to be operated.
 	Namespace string `json:"namespace,omitempty"`
 	User      string `json:"user,omitempty"`
-	// +kubebuilder:validation:Enum=Owner;Manager;Developer
+	// +kubebuilder:validation:Enum=Owner;Manager;Developer;Viewer
 	Role RoleType `json:"role,omitempty"`
 	// +kubebuilder:validation:Enum=Grant;Update;Deprive
 	Action ActionType `json:"action,omitempty"`
+	// ExpiresAt makes a Grant or Update temporary, the role is deprived automatically
+	// when the time is reached. Empty means the role never expires.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// ApprovalRequired keeps the request Pending until an Owner of the namespace approves it
+	// with the user.sealos.io/approved-by annotation.
+	// +optional
+	ApprovalRequired bool `json:"approvalRequired,omitempty"`
 }
 
 type ActionType string
@@ -45,6 +53,12 @@ type OperationrequestStatus struct {
 	//+kubebuilder:default:=Pending
 	//+kubebuilder:validation:Enum=Pending;Processing;Completed;Failed
 	Phase RequestPhase `json:"phase,omitempty"`
+	// ApprovedBy is the Owner who approved the request.
+	// +optional
+	ApprovedBy string `json:"approvedBy,omitempty"`
+	// Message is the reason of the current phase.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type RequestPhase string
@@ -61,6 +75,7 @@ const (
 //+kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.namespace"
 //+kubebuilder:printcolumn:name="User",type="string",JSONPath=".spec.user"
 //+kubebuilder:printcolumn:name="Role",type="string",JSONPath=".spec.role"
+//+kubebuilder:printcolumn:name="Expires",type="date",JSONPath=".spec.expiresAt"
 //+kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 //+kubebuilder:object:root=true
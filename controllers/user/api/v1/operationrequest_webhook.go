@@ -19,7 +19,12 @@ package v1
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -81,6 +86,14 @@ func (r ReqValidator) ValidateCreate(
 
 	// todo check request, _ := admission.RequestFromContext(ctx), request.UserInfo.Username if legal
 
+	if err := validateRequestSpec(req.Spec); err != nil {
+		return admission.Warnings{err.Error()}, err
+	}
+	// a request created as already reviewed is checked as if it was reviewed after creation
+	if err := validateReviewers(ctx, &Operationrequest{}, req); err != nil {
+		return admission.Warnings{err.Error()}, err
+	}
+
 	// list all requests in the same namespace with a same owner
 	var reqList OperationrequestList
 	err := r.List(
@@ -114,7 +127,7 @@ func (r ReqValidator) ValidateCreate(
 }
 
 func (r ReqValidator) ValidateUpdate(
-	_ context.Context,
+	ctx context.Context,
 	oldObj, newObj runtime.Object,
 ) (admission.Warnings, error) {
 	// todo check request, _ := admission.RequestFromContext(ctx), request.UserInfo.Username if legal
@@ -134,16 +147,76 @@ func (r ReqValidator) ValidateUpdate(
 				"obj convert Operationrequest error",
 			)
 	}
-	if oldReq.Spec != newReq.Spec {
+	if !equality.Semantic.DeepEqual(oldReq.Spec, newReq.Spec) {
 		return admission.Warnings{
 				"operation request spec do not support update",
 			}, errors.New(
 				"operation request spec do not support update",
 			)
 	}
+	if err := validateReviewers(ctx, oldReq, newReq); err != nil {
+		return admission.Warnings{err.Error()}, err
+	}
 	return admission.Warnings{}, nil
 }
 
+func validateRequestSpec(spec OperationrequestSpec) error {
+	if spec.ExpiresAt == nil {
+		return nil
+	}
+	if spec.Action != Grant && spec.Action != Update {
+		return fmt.Errorf("expiresAt is only supported by the %s and %s actions", Grant, Update)
+	}
+	if spec.Role == OwnerRoleType {
+		return errors.New("the Owner role can not be granted temporarily")
+	}
+	if !spec.ExpiresAt.After(time.Now()) {
+		return errors.New("expiresAt must be in the future")
+	}
+	return nil
+}
+
+func validateReviewers(ctx context.Context, oldReq, newReq *Operationrequest) error {
+	for _, key := range []string{ApprovedByAnnotationKey, RejectedByAnnotationKey} {
+		if err := validateReviewer(ctx, oldReq, newReq, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateReviewer makes sure a sealos user can only approve or reject a request in their own name,
+// whether the reviewer is an Owner of the namespace is checked by the controller.
+// Any other identity is rejected unless it is a cluster admin.
+func validateReviewer(ctx context.Context, oldReq, newReq *Operationrequest, key string) error {
+	reviewer := newReq.Annotations[key]
+	if reviewer == "" || reviewer == oldReq.Annotations[key] {
+		return nil
+	}
+	if oldReq.Status.Phase != "" && oldReq.Status.Phase != RequestPending {
+		return fmt.Errorf("request in phase %s can not be reviewed", oldReq.Status.Phase)
+	}
+	request, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return fmt.Errorf("can not get the reviewer of the request: %w", err)
+	}
+	// sealos users are the service accounts in the user system namespace
+	name, ok := strings.CutPrefix(
+		request.UserInfo.Username,
+		"system:serviceaccount:"+UserSystemNamespace+":",
+	)
+	if ok {
+		if name != reviewer {
+			return fmt.Errorf("%s can not review the request as %s", name, reviewer)
+		}
+		return nil
+	}
+	if slices.Contains(request.UserInfo.Groups, ClusterAdminGroup) {
+		return nil
+	}
+	return fmt.Errorf("%s can not review the request", request.UserInfo.Username)
+}
+
 func (r ReqValidator) ValidateDelete(
 	_ context.Context,
 	_ runtime.Object,
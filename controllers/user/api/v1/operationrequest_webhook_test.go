@@ -0,0 +1,252 @@
+/*
+Copyright 2022 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestValidateReviewer(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(phase RequestPhase, annotations map[string]string) *Operationrequest {
+		return &Operationrequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "grant-bob",
+				Namespace:   "ns-alice",
+				Annotations: annotations,
+			},
+			Spec: OperationrequestSpec{
+				Namespace:        "ns-alice",
+				User:             "bob",
+				Role:             DeveloperRoleType,
+				Action:           Grant,
+				ApprovalRequired: true,
+			},
+			Status: OperationrequestStatus{Phase: phase},
+		}
+	}
+	withUser := func(username string, groups ...string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: username, Groups: groups},
+			},
+		})
+	}
+	approvedBy := func(reviewer string) map[string]string {
+		return map[string]string{ApprovedByAnnotationKey: reviewer}
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		oldReq  *Operationrequest
+		newReq  *Operationrequest
+		wantErr bool
+	}{
+		{
+			name:   "no reviewer",
+			ctx:    context.Background(),
+			oldReq: newRequest(RequestPending, nil),
+			newReq: newRequest(RequestPending, nil),
+		},
+		{
+			name:   "reviewer unchanged",
+			ctx:    context.Background(),
+			oldReq: newRequest(RequestPending, approvedBy("alice")),
+			newReq: newRequest(RequestPending, approvedBy("alice")),
+		},
+		{
+			name:   "user approves in their own name",
+			ctx:    withUser("system:serviceaccount:user-system:alice"),
+			oldReq: newRequest(RequestPending, nil),
+			newReq: newRequest(RequestPending, approvedBy("alice")),
+		},
+		{
+			name:    "user approves in the name of the owner",
+			ctx:     withUser("system:serviceaccount:user-system:bob"),
+			oldReq:  newRequest(RequestPending, nil),
+			newReq:  newRequest(RequestPending, approvedBy("alice")),
+			wantErr: true,
+		},
+		{
+			name:    "service account of the request namespace",
+			ctx:     withUser("system:serviceaccount:ns-alice:alice"),
+			oldReq:  newRequest(RequestPending, nil),
+			newReq:  newRequest(RequestPending, approvedBy("alice")),
+			wantErr: true,
+		},
+		{
+			name:    "unknown identity",
+			ctx:     withUser("bob", "system:authenticated"),
+			oldReq:  newRequest(RequestPending, nil),
+			newReq:  newRequest(RequestPending, approvedBy("alice")),
+			wantErr: true,
+		},
+		{
+			name:   "cluster admin",
+			ctx:    withUser("kubernetes-admin", ClusterAdminGroup, "system:authenticated"),
+			oldReq: newRequest(RequestPending, nil),
+			newReq: newRequest(RequestPending, approvedBy("alice")),
+		},
+		{
+			name:    "no admission request",
+			ctx:     context.Background(),
+			oldReq:  newRequest(RequestPending, nil),
+			newReq:  newRequest(RequestPending, approvedBy("alice")),
+			wantErr: true,
+		},
+		{
+			name:    "request already completed",
+			ctx:     withUser("system:serviceaccount:user-system:alice"),
+			oldReq:  newRequest(RequestCompleted, nil),
+			newReq:  newRequest(RequestCompleted, approvedBy("alice")),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateReviewer(tt.ctx, tt.oldReq, tt.newReq, ApprovedByAnnotationKey)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateReviewer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReqValidatorValidateCreateReviewer(t *testing.T) {
+	t.Parallel()
+
+	scheme := runtime.NewScheme()
+	if err := AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	validator := ReqValidator{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+	withUser := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		annotations map[string]string
+		wantErr     bool
+	}{
+		{
+			name: "not reviewed",
+			ctx:  withUser("system:serviceaccount:user-system:bob"),
+		},
+		{
+			name:        "requester approves in the name of the owner",
+			ctx:         withUser("system:serviceaccount:user-system:bob"),
+			annotations: map[string]string{ApprovedByAnnotationKey: "alice"},
+			wantErr:     true,
+		},
+		{
+			name:        "requester rejects in the name of the owner",
+			ctx:         withUser("system:serviceaccount:user-system:bob"),
+			annotations: map[string]string{RejectedByAnnotationKey: "alice"},
+			wantErr:     true,
+		},
+		{
+			name:        "owner creates an approved request",
+			ctx:         withUser("system:serviceaccount:user-system:alice"),
+			annotations: map[string]string{ApprovedByAnnotationKey: "alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := &Operationrequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "grant-bob",
+					Namespace:   "ns-alice",
+					Annotations: tt.annotations,
+				},
+				Spec: OperationrequestSpec{
+					Namespace:        "ns-alice",
+					User:             "bob",
+					Role:             DeveloperRoleType,
+					Action:           Grant,
+					ApprovalRequired: true,
+				},
+			}
+			_, err := validator.ValidateCreate(tt.ctx, req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCreate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRequestSpec(t *testing.T) {
+	t.Parallel()
+
+	future := metav1.NewTime(time.Now().Add(time.Hour))
+	past := metav1.NewTime(time.Now().Add(-time.Hour))
+	tests := []struct {
+		name    string
+		spec    OperationrequestSpec
+		wantErr bool
+	}{
+		{
+			name: "permanent grant",
+			spec: OperationrequestSpec{Action: Grant, Role: DeveloperRoleType},
+		},
+		{
+			name: "temporary grant",
+			spec: OperationrequestSpec{Action: Grant, Role: DeveloperRoleType, ExpiresAt: &future},
+		},
+		{
+			name:    "temporary deprive",
+			spec:    OperationrequestSpec{Action: Deprive, Role: DeveloperRoleType, ExpiresAt: &future},
+			wantErr: true,
+		},
+		{
+			name:    "temporary owner",
+			spec:    OperationrequestSpec{Action: Grant, Role: OwnerRoleType, ExpiresAt: &future},
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			spec:    OperationrequestSpec{Action: Update, Role: DeveloperRoleType, ExpiresAt: &past},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := validateRequestSpec(tt.spec); (err != nil) != tt.wantErr {
+				t.Errorf("validateRequestSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
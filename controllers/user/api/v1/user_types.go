@@ -48,6 +48,9 @@ const (
 	OwnerRoleType     RoleType = "Owner"
 	ManagerRoleType   RoleType = "Manager"
 	DeveloperRoleType RoleType = "Developer"
+	// ViewerRoleType can only read the resources of the namespace which carry no credentials,
+	// secrets, configmaps, pods, workloads, devboxes and terminals are not readable
+	ViewerRoleType RoleType = "Viewer"
 )
 
 type UserPhase string
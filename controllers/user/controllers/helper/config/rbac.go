@@ -25,7 +25,7 @@ import (
 )
 
 func GetUserSystemNamespace() string {
-	return "user-system"
+	return userv1.UserSystemNamespace
 }
 
 func GetDefaultNamespace() string {
@@ -80,6 +80,27 @@ func GetUserRole(roleType userv1.RoleType) []rbacv1.PolicyRule {
 				Verbs:     []string{"list", "watch", "get"},
 			},
 		}
+	case userv1.ViewerRoleType:
+		// secrets, pod logs and every kind which may carry credentials are left out on purpose:
+		// configmaps and the env of pods and workloads, devboxes and terminals holding user tokens
+		return []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Resources: []string{
+					"services", "endpoints", "persistentvolumeclaims",
+					"events", "resourcequotas", "limitranges", "serviceaccounts",
+				},
+				Verbs: []string{"list", "watch", "get"},
+			},
+			{
+				APIGroups: []string{
+					"autoscaling", "networking.k8s.io", "events.k8s.io",
+					"app.sealos.io", "apps.kubeblocks.io", "dataprotection.kubeblocks.io",
+				},
+				Resources: []string{"*"},
+				Verbs:     []string{"list", "watch", "get"},
+			},
+		}
 	default:
 		return []rbacv1.PolicyRule{}
 	}
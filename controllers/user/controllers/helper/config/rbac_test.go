@@ -0,0 +1,68 @@
+/*
+Copyright 2023 cuisongliu@qq.com.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"slices"
+	"testing"
+
+	userv1 "github.com/labring/sealos/controllers/user/api/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// allows reports whether one of the rules grants verb on the resource of group.
+func allows(rules []rbacv1.PolicyRule, group, resource, verb string) bool {
+	match := func(values []string, v string) bool {
+		return slices.Contains(values, rbacv1.ResourceAll) || slices.Contains(values, v)
+	}
+	for _, rule := range rules {
+		if match(rule.APIGroups, group) && match(rule.Resources, resource) && match(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetUserRoleViewer(t *testing.T) {
+	rules := GetUserRole(userv1.ViewerRoleType)
+	tests := []struct {
+		group    string
+		resource string
+		verb     string
+		want     bool
+	}{
+		{group: "", resource: "services", verb: "get", want: true},
+		{group: "", resource: "persistentvolumeclaims", verb: "list", want: true},
+		{group: "app.sealos.io", resource: "apps", verb: "get", want: true},
+		{group: "apps.kubeblocks.io", resource: "clusters", verb: "watch", want: true},
+		{group: "", resource: "services", verb: "delete", want: false},
+		{group: "terminal.sealos.io", resource: "terminals", verb: "get", want: false},
+		{group: "terminal.sealos.io", resource: "terminals", verb: "list", want: false},
+		{group: "devbox.sealos.io", resource: "devboxes", verb: "get", want: false},
+		{group: "", resource: "secrets", verb: "get", want: false},
+		{group: "", resource: "configmaps", verb: "get", want: false},
+		{group: "", resource: "pods", verb: "get", want: false},
+		{group: "", resource: "pods/log", verb: "get", want: false},
+		{group: "apps", resource: "deployments", verb: "get", want: false},
+		{group: "batch", resource: "cronjobs", verb: "get", want: false},
+	}
+	for _, tt := range tests {
+		if got := allows(rules, tt.group, tt.resource, tt.verb); got != tt.want {
+			t.Errorf("viewer %s %s.%s = %v, want %v", tt.verb, tt.resource, tt.group, got, tt.want)
+		}
+	}
+}
@@ -158,6 +158,23 @@ func (r *OperationReqReconciler) reconcile(
 		return ctrl.Result{}, nil
 	}
 
+	// wait for an Owner of the namespace to review the request
+	if request.Spec.ApprovalRequired && request.Status.ApprovedBy == "" {
+		approved, result, err := r.review(ctx, request)
+		if !approved || err != nil {
+			return result, err
+		}
+	}
+	// a temporary role is not granted once it is expired, e.g. after a late approval
+	if request.Spec.ExpiresAt != nil && !request.Spec.ExpiresAt.After(time.Now()) {
+		r.Logger.V(1).Info("request role is expired, update status to failed", getLog(request)...)
+		request.Status.Message = "the role expired before it was granted"
+		if err := r.updateRequestStatus(ctx, request, userv1.RequestFailed); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// update OperationRequest status to processing
 	err := r.updateRequestStatus(ctx, request, userv1.RequestProcessing)
 	if err != nil {
@@ -214,12 +231,10 @@ func (r *OperationReqReconciler) reconcile(
 			request.Spec.Role,
 			request.Spec.User,
 		)
-		if _, err := ctrl.CreateOrUpdate(
-			ctx,
-			r.Client,
-			rolebinding,
-			setUpOwnerReferenceFc,
-		); err != nil {
+		if _, err := ctrl.CreateOrUpdate(ctx, r.Client, rolebinding, func() error {
+			setRoleBindingExpiration(rolebinding, request)
+			return setUpOwnerReferenceFc()
+		}); err != nil {
 			r.Recorder.Eventf(
 				request,
 				v1.EventTypeWarning,
@@ -325,6 +340,13 @@ func (r *OperationReqReconciler) reconcile(
 	}
 
 	// update OperationRequest status to completed
+	if request.Spec.ExpiresAt != nil && request.Spec.Action != userv1.Deprive {
+		request.Status.Message = fmt.Sprintf(
+			"role %s expires at %s",
+			request.Spec.Role,
+			request.Spec.ExpiresAt.UTC().Format(time.RFC3339),
+		)
+	}
 	err = r.updateRequestStatus(ctx, request, userv1.RequestCompleted)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -341,6 +363,72 @@ func (r *OperationReqReconciler) reconcile(
 	return ctrl.Result{RequeueAfter: OperationReqRequeueDuration}, nil
 }
 
+// review checks the approved-by and rejected-by annotations of a request that requires approval,
+// approved is true once an Owner of the namespace approved it.
+func (r *OperationReqReconciler) review(
+	ctx context.Context,
+	request *userv1.Operationrequest,
+) (approved bool, result ctrl.Result, err error) {
+	pending := ctrl.Result{RequeueAfter: r.untilExpired(request)}
+	if rejecter := request.Annotations[userv1.RejectedByAnnotationKey]; rejecter != "" {
+		isOwner, err := r.isNamespaceOwner(ctx, request.Spec.Namespace, rejecter)
+		if err != nil {
+			return false, ctrl.Result{}, err
+		}
+		if isOwner {
+			r.Recorder.Eventf(request, v1.EventTypeNormal, "Rejected",
+				"Request is rejected by %s", rejecter)
+			request.Status.Message = "rejected by " + rejecter
+			return false, ctrl.Result{}, r.updateRequestStatus(ctx, request, userv1.RequestFailed)
+		}
+		r.Recorder.Eventf(request, v1.EventTypeWarning, "InvalidReviewer",
+			"%s is not an owner of namespace %s", rejecter, request.Spec.Namespace)
+	}
+	approver := request.Annotations[userv1.ApprovedByAnnotationKey]
+	if approver == "" {
+		r.Logger.V(1).Info("request is waiting for approval", getLog(request)...)
+		return false, pending, nil
+	}
+	isOwner, err := r.isNamespaceOwner(ctx, request.Spec.Namespace, approver)
+	if err != nil {
+		return false, ctrl.Result{}, err
+	}
+	if !isOwner {
+		r.Recorder.Eventf(request, v1.EventTypeWarning, "InvalidReviewer",
+			"%s is not an owner of namespace %s", approver, request.Spec.Namespace)
+		return false, pending, nil
+	}
+	r.Recorder.Eventf(request, v1.EventTypeNormal, "Approved",
+		"Request is approved by %s", approver)
+	request.Status.ApprovedBy = approver
+	return true, ctrl.Result{}, nil
+}
+
+// isNamespaceOwner returns true if the user owns the namespace, the owner is kept on the
+// annotation of the User of the namespace.
+func (r *OperationReqReconciler) isNamespaceOwner(
+	ctx context.Context,
+	namespace, user string,
+) (bool, error) {
+	nsUser := &userv1.User{}
+	if err := r.Get(
+		ctx,
+		client.ObjectKey{Name: config.GetUserNameByNamespace(namespace)},
+		nsUser,
+	); err != nil {
+		return false, err
+	}
+	return nsUser.Annotations[userv1.UserAnnotationOwnerKey] == user, nil
+}
+
+// untilExpired returns the time left before the request is expired
+func (r *OperationReqReconciler) untilExpired(request *userv1.Operationrequest) time.Duration {
+	if wait := time.Until(request.CreationTimestamp.Add(r.expirationTime)); wait > time.Second {
+		return wait
+	}
+	return time.Second
+}
+
 // isRetained returns true if the request is isCompleted and exist for retention time
 func (r *OperationReqReconciler) isRetained(request *userv1.Operationrequest) bool {
 	if request.Status.Phase == userv1.RequestCompleted &&
@@ -413,7 +501,7 @@ func (r *OperationReqReconciler) updateRequestStatus(
 }
 
 func conventRequestToRolebinding(request *userv1.Operationrequest) *rbacv1.RoleBinding {
-	return &rbacv1.RoleBinding{
+	rolebinding := &rbacv1.RoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      config.GetGroupRoleBindingName(request.Spec.User),
 			Namespace: request.Spec.Namespace,
@@ -437,6 +525,8 @@ func conventRequestToRolebinding(request *userv1.Operationrequest) *rbacv1.RoleB
 			APIGroup: rbacv1.GroupName,
 		},
 	}
+	setRoleBindingExpiration(rolebinding, request)
+	return rolebinding
 }
 
 func getLog(request *userv1.Operationrequest, kv ...any) []any {
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	userv1 "github.com/labring/sealos/controllers/user/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestOperationReqReview(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		annotations  map[string]string
+		wantApproved bool
+		wantPhase    userv1.RequestPhase
+	}{
+		{
+			name:      "waiting for approval",
+			wantPhase: userv1.RequestPending,
+		},
+		{
+			name:         "approved by the owner",
+			annotations:  map[string]string{userv1.ApprovedByAnnotationKey: "alice"},
+			wantApproved: true,
+			wantPhase:    userv1.RequestPending,
+		},
+		{
+			name:        "approved by a non owner",
+			annotations: map[string]string{userv1.ApprovedByAnnotationKey: "bob"},
+			wantPhase:   userv1.RequestPending,
+		},
+		{
+			name:        "rejected by the owner",
+			annotations: map[string]string{userv1.RejectedByAnnotationKey: "alice"},
+			wantPhase:   userv1.RequestFailed,
+		},
+		{
+			name: "rejected by a non owner and approved by the owner",
+			annotations: map[string]string{
+				userv1.RejectedByAnnotationKey: "bob",
+				userv1.ApprovedByAnnotationKey: "alice",
+			},
+			wantApproved: true,
+			wantPhase:    userv1.RequestPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			scheme := runtime.NewScheme()
+			if err := userv1.AddToScheme(scheme); err != nil {
+				t.Fatalf("add scheme: %v", err)
+			}
+			nsUser := &userv1.User{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "alice",
+					Annotations: map[string]string{userv1.UserAnnotationOwnerKey: "alice"},
+				},
+			}
+			request := &userv1.Operationrequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "grant-bob",
+					Namespace:         userv1.UserSystemNamespace,
+					Annotations:       tt.annotations,
+					CreationTimestamp: metav1.Now(),
+				},
+				Spec: userv1.OperationrequestSpec{
+					Namespace:        "ns-alice",
+					User:             "bob",
+					Role:             userv1.DeveloperRoleType,
+					Action:           userv1.Grant,
+					ApprovalRequired: true,
+				},
+				Status: userv1.OperationrequestStatus{Phase: userv1.RequestPending},
+			}
+			c := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(nsUser, request).
+				WithStatusSubresource(request).
+				Build()
+			r := &OperationReqReconciler{
+				Client:         c,
+				Logger:         logr.Discard(),
+				Recorder:       record.NewFakeRecorder(10),
+				expirationTime: time.Hour,
+			}
+
+			approved, result, err := r.review(context.Background(), request)
+			if err != nil {
+				t.Fatalf("review() error = %v", err)
+			}
+			if approved != tt.wantApproved {
+				t.Errorf("review() approved = %v, want %v", approved, tt.wantApproved)
+			}
+			if !approved && tt.wantPhase == userv1.RequestPending && result.RequeueAfter == 0 {
+				t.Errorf("review() pending request is not requeued")
+			}
+			if approved && request.Status.ApprovedBy != "alice" {
+				t.Errorf("review() approvedBy = %q, want alice", request.Status.ApprovedBy)
+			}
+			got := &userv1.Operationrequest{}
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(request), got); err != nil {
+				t.Fatalf("get request: %v", err)
+			}
+			if got.Status.Phase != tt.wantPhase {
+				t.Errorf("review() phase = %s, want %s", got.Status.Phase, tt.wantPhase)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 labring.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	userv1 "github.com/labring/sealos/controllers/user/api/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// RoleBindingExpirationReconciler deprives the roles granted by a temporary Operationrequest,
+// the expiration is kept on the RoleBinding so it survives the deletion of the request.
+type RoleBindingExpirationReconciler struct {
+	client.Client
+
+	Logger   logr.Logger
+	Recorder record.EventRecorder
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RoleBindingExpirationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	const controllerName = "rolebinding_expiration_controller"
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	r.Logger = ctrl.Log.WithName(controllerName)
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor(controllerName)
+	}
+	r.Logger.V(1).Info("init reconcile rolebinding expiration controller")
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		For(&rbacv1.RoleBinding{}, builder.WithPredicates(predicate.NewPredicateFuncs(
+			func(obj client.Object) bool {
+				_, ok := obj.GetAnnotations()[userv1.RoleBindingExpiresAtAnnotationKey]
+				return ok
+			},
+		))).
+		Complete(r)
+}
+
+func (r *RoleBindingExpirationReconciler) Reconcile(
+	ctx context.Context,
+	req ctrl.Request,
+) (ctrl.Result, error) {
+	rolebinding := &rbacv1.RoleBinding{}
+	if err := r.Get(ctx, req.NamespacedName, rolebinding); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	expiresAt, ok, err := roleBindingExpiresAt(rolebinding)
+	if err != nil {
+		// a malformed expiration is never retried, it needs to be fixed by hand
+		r.Logger.Error(err, "invalid rolebinding expiration",
+			"rolebinding", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	if !ok || rolebinding.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+	if wait := time.Until(expiresAt); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	r.Logger.V(1).Info("deprive expired rolebinding", "rolebinding", req.NamespacedName,
+		"role", rolebinding.RoleRef.Name, "expiresAt", expiresAt)
+	if err := r.Delete(ctx, rolebinding, client.Preconditions{
+		UID:             &rolebinding.UID,
+		ResourceVersion: &rolebinding.ResourceVersion,
+	}); client.IgnoreNotFound(err) != nil {
+		return ctrl.Result{}, err
+	}
+	r.Recorder.Eventf(
+		rolebinding,
+		v1.EventTypeNormal,
+		"Expired",
+		"Deprive role %s from user %s, it expired at %s",
+		rolebinding.RoleRef.Name,
+		rolebinding.Annotations[userAnnotationOwnerKey],
+		expiresAt.Format(time.RFC3339),
+	)
+	return ctrl.Result{}, nil
+}
+
+// roleBindingExpiresAt returns the time the role binding is deprived, ok is false if it never
+// expires.
+func roleBindingExpiresAt(rolebinding *rbacv1.RoleBinding) (time.Time, bool, error) {
+	value, ok := rolebinding.Annotations[userv1.RoleBindingExpiresAtAnnotationKey]
+	if !ok || value == "" {
+		return time.Time{}, false, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return expiresAt, true, nil
+}
+
+// setRoleBindingExpiration marks the role binding to be deprived when the request expires,
+// a request without expiration makes the role permanent again.
+func setRoleBindingExpiration(rolebinding *rbacv1.RoleBinding, request *userv1.Operationrequest) {
+	if request.Spec.ExpiresAt == nil {
+		delete(rolebinding.Annotations, userv1.RoleBindingExpiresAtAnnotationKey)
+		return
+	}
+	if rolebinding.Annotations == nil {
+		rolebinding.Annotations = map[string]string{}
+	}
+	rolebinding.Annotations[userv1.RoleBindingExpiresAtAnnotationKey] = request.Spec.ExpiresAt.UTC().
+		Format(time.RFC3339)
+}
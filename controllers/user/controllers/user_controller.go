@@ -403,10 +403,11 @@ func (r *UserReconciler) syncRole(ctx context.Context, user *userv1.User, _ *use
 			r.saveCondition(user, roleCondition.DeepCopy())
 		}
 	}()
-	// create three roles
+	// create four roles
 	r.createRole(ctx, roleCondition, user, userv1.OwnerRoleType)
 	r.createRole(ctx, roleCondition, user, userv1.ManagerRoleType)
 	r.createRole(ctx, roleCondition, user, userv1.DeveloperRoleType)
+	r.createRole(ctx, roleCondition, user, userv1.ViewerRoleType)
 }
 
 func (r *UserReconciler) createRole(
@@ -276,6 +276,10 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if err = (&controllers.RoleBindingExpirationReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RoleBindingExpiration")
+		os.Exit(1)
+	}
 	if err = (&controllers.DeleteRequestReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
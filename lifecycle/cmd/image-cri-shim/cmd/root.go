@@ -221,8 +221,16 @@ func startCacheStatsReporter(ctx context.Context, imgShim shim.Shim, interval ti
 				}
 			case <-tick:
 				stats := imgShim.CacheStats()
-				logger.Info("cache stats: image_hits=%d image_misses=%d domain_hits=%d domain_misses=%d image_evictions=%d domain_evictions=%d invalidations=%d generated_at=%s",
-					stats.ImageHits, stats.ImageMisses, stats.DomainHits, stats.DomainMisses, stats.ImageEvictions, stats.DomainEvictions, stats.Invalidations, stats.GeneratedAt.Format(time.RFC3339))
+				logger.Info("cache stats: image_hits=%d image_misses=%d domain_hits=%d domain_misses=%d image_evictions=%d domain_evictions=%d invalidations=%d pull_fallbacks=%d generated_at=%s",
+					stats.ImageHits, stats.ImageMisses, stats.DomainHits, stats.DomainMisses, stats.ImageEvictions, stats.DomainEvictions, stats.Invalidations, stats.PullFallbacks, stats.GeneratedAt.Format(time.RFC3339))
+				for _, reg := range stats.Registries {
+					var until string
+					if !reg.Healthy {
+						until = reg.UnhealthyUntil.Format(time.RFC3339)
+					}
+					logger.Info("registry stats: registry=%s healthy=%t pulls=%d failures=%d consecutive_failures=%d avg_latency=%s unhealthy_until=%s",
+						reg.Domain, reg.Healthy, reg.Pulls, reg.Failures, reg.ConsecutiveFailures, reg.AvgLatency.Round(time.Millisecond), until)
+				}
 			}
 		}
 	}()
@@ -27,6 +27,8 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/labring/lvscare/care"
+
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/ipvs"
 	"github.com/labring/sealos/pkg/utils/file"
@@ -87,7 +89,9 @@ func newLvscareCmd() *cobra.Command {
 
 func genNewPod(obj lvscarePod) error {
 	fileName := fmt.Sprintf("%s.%s", obj.name, constants.YamlFileSuffix)
-	yaml, err := ipvs.LvsStaticPodYaml(obj.vip, obj.master, obj.image, obj.name, obj.options)
+	// the pod runs on this node, use the proxy mode its kernel supports
+	options := ipvs.WithProxyMode(obj.options, care.DetectMode)
+	yaml, err := ipvs.LvsStaticPodYaml(obj.vip, obj.master, obj.image, obj.name, options)
 	if err != nil {
 		return err
 	}
@@ -99,6 +103,10 @@ func genNewPod(obj lvscarePod) error {
 	if err = file.MkDirs(staticPodPath); err != nil {
 		return fmt.Errorf("init dir is error: %v", err)
 	}
+	// the static pod takes over the virtual server from the proxy started by `ipvs --run-once`
+	if err = care.StopBootstrapProxy(obj.vip); err != nil {
+		return err
+	}
 	err = os.WriteFile(path.Join(staticPodPath, fileName), []byte(yaml), 0755)
 	if err != nil {
 		return err
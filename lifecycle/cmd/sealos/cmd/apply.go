@@ -15,35 +15,74 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"github.com/labring/sealos/pkg/apply"
+	"github.com/labring/sealos/pkg/checker"
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
 var clusterFile string
 
+const exampleApply = `
+apply a Clusterfile:
+	sealos apply -f Clusterfile
+print the changes to be applied as json, without touching any host:
+	sealos apply -f Clusterfile --plan -o json
+`
+
 func newApplyCmd() *cobra.Command {
 	applyArgs := &apply.Args{}
+	planArgs := &planFlags{}
 	// applyCmd represents the apply command
 	var applyCmd = &cobra.Command{
 		Use:     "apply",
 		Short:   "Run cloud images within a kubernetes cluster with Clusterfile",
-		Example: `sealos apply -f Clusterfile`,
+		Example: exampleApply,
 		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateSkipPreflight(cmd); err != nil {
+				return err
+			}
+			return planArgs.validate()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			applier, err := apply.NewApplierFromFile(cmd, clusterFile, applyArgs)
 			if err != nil {
 				return err
 			}
+			if planArgs.plan {
+				return printPlan(os.Stdout, applier, planArgs.output)
+			}
 			return applier.Apply()
 		},
 		PostRun: func(cmd *cobra.Command, args []string) {
-			logger.Info(getContact())
+			if !planArgs.plan {
+				logger.Info(getContact())
+			}
 		},
 	}
 	setRequireBuildahAnnotation(applyCmd)
 	applyCmd.Flags().StringVarP(&clusterFile, "Clusterfile", "f", "Clusterfile", "apply a kubernetes cluster")
 	applyArgs.RegisterFlags(applyCmd.Flags())
+	planArgs.register(applyCmd)
+	registerSkipPreflightFlag(applyCmd)
 	return applyCmd
 }
+
+func registerSkipPreflightFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("skip-preflight", nil, fmt.Sprintf("preflight checks to skip before creating a cluster, one or more of %s",
+		strings.Join(append(checker.PreflightChecks(), checker.PreflightAll), ", ")))
+}
+
+func validateSkipPreflight(cmd *cobra.Command) error {
+	skip, err := cmd.Flags().GetStringSlice("skip-preflight")
+	if err != nil {
+		return err
+	}
+	return checker.ValidatePreflightSkips(skip)
+}
@@ -50,6 +50,10 @@ func newCertCmd() *cobra.Command {
     1. sealos cert --alt-names 39.105.169.253
     2. edit .kube/config, set the apiserver address as 39.105.169.253, (don't forget to open the security group port for 6443, if you using public cloud)
     3. kubectl get pod, to check if it works or not
+
+    Check the expiry of certs on every host, and renew the ones close to expiry:
+	sealos cert check
+	sealos cert renew --auto --before 30d
 		`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(renewTargets) != 0 {
@@ -63,41 +67,12 @@ func newCertCmd() *cobra.Command {
 				return errors.New("--groups requires --renew")
 			}
 
-			processor.SyncNewVersionConfig(clusterName)
-
-			clusterPath := constants.Clusterfile(clusterName)
-			pathResolver := constants.NewPathResolver(clusterName)
-
-			var runtimeConfigPath string
-
-			for _, f := range []string{
-				path.Join(pathResolver.ConfigsPath(), "kubeadm-init.yaml"),
-				path.Join(pathResolver.EtcPath(), "kubeadm-init.yaml"),
-				path.Join(pathResolver.ConfigsPath(), "k3s-init.yaml"),
-			} {
-				if fileutils.IsExist(f) {
-					runtimeConfigPath = f
-					break
-				}
-			}
-			if runtimeConfigPath == "" {
-				logger.Warn("cannot locate the default runtime config file")
-			}
-			var opts []clusterfile.OptionFunc
-			if runtimeConfigPath != "" {
-				opts = append(opts, clusterfile.WithCustomRuntimeConfigFiles([]string{runtimeConfigPath}))
-			}
-			cf := clusterfile.NewClusterFile(clusterPath, opts...)
-			if err := cf.Process(); err != nil {
+			cf, rt, err := newClusterRuntime(clusterName)
+			if err != nil {
 				return err
 			}
-
 			logger.Info("update certs for cluster %s", cf.GetCluster().GetName())
 
-			rt, err := factory.New(cf.GetCluster(), cf.GetRuntimeConfig())
-			if err != nil {
-				return fmt.Errorf("create runtime failed: %v", err)
-			}
 			if cm, ok := rt.(runtime.CertManager); ok {
 				if len(renewTargets) != 0 {
 					renewOpts := runtime.CertRenewOptions{Targets: renewTargets}
@@ -116,6 +91,8 @@ func newCertCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.AddCommand(newCertCheckCmd())
+	cmd.AddCommand(newCertRenewCmd())
 	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to applied exec action")
 	cmd.Flags().StringSliceVar(&altNames, "alt-names", []string{}, "add extra Subject Alternative Names for certs, domain or ip, eg. sealos.io or 10.103.97.2")
 	cmd.Flags().StringSliceVar(&renewTargets, "renew", nil, "renew local cert targets; local admin.conf is synced to node $HOME/.kube/config, super-admin.conf stays local only")
@@ -124,6 +101,44 @@ func newCertCmd() *cobra.Command {
 	return cmd
 }
 
+// newClusterRuntime loads the Clusterfile and the runtime config of an existing cluster.
+func newClusterRuntime(clusterName string) (clusterfile.Interface, runtime.Interface, error) {
+	processor.SyncNewVersionConfig(clusterName)
+
+	clusterPath := constants.Clusterfile(clusterName)
+	pathResolver := constants.NewPathResolver(clusterName)
+
+	var runtimeConfigPath string
+
+	for _, f := range []string{
+		path.Join(pathResolver.ConfigsPath(), "kubeadm-init.yaml"),
+		path.Join(pathResolver.EtcPath(), "kubeadm-init.yaml"),
+		path.Join(pathResolver.ConfigsPath(), "k3s-init.yaml"),
+	} {
+		if fileutils.IsExist(f) {
+			runtimeConfigPath = f
+			break
+		}
+	}
+	if runtimeConfigPath == "" {
+		logger.Warn("cannot locate the default runtime config file")
+	}
+	var opts []clusterfile.OptionFunc
+	if runtimeConfigPath != "" {
+		opts = append(opts, clusterfile.WithCustomRuntimeConfigFiles([]string{runtimeConfigPath}))
+	}
+	cf := clusterfile.NewClusterFile(clusterPath, opts...)
+	if err := cf.Process(); err != nil {
+		return nil, nil, err
+	}
+
+	rt, err := factory.New(cf.GetCluster(), cf.GetRuntimeConfig())
+	if err != nil {
+		return nil, nil, fmt.Errorf("create runtime failed: %v", err)
+	}
+	return cf, rt, nil
+}
+
 func normalizeFlagValues(values []string) []string {
 	normalized := make([]string, 0, len(values))
 	seen := make(map[string]struct{}, len(values))
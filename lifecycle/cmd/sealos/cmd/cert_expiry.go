@@ -0,0 +1,176 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+var exampleCertCheck = `
+print the expiry of certs on every host of the default cluster:
+	sealos cert check
+print the expiry as json:
+	sealos cert check -o json
+`
+
+var exampleCertRenew = `
+renew the certs of masters expiring within 30 days and restart the control plane one master at a time:
+	sealos cert renew --auto --before 30d
+`
+
+func newCertCheckCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:     "check",
+		Short:   "Print the expiry of certs on every host of the cluster",
+		Example: exampleCertCheck,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case "text", "json":
+				return nil
+			}
+			return fmt.Errorf("unsupported output format %q, must be one of text or json", output)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cm, err := newCertExpiryManager(clusterName)
+			if err != nil {
+				return err
+			}
+			expiries, err := cm.CheckCerts()
+			if err != nil {
+				return err
+			}
+			return printCertExpiries(os.Stdout, expiries, time.Now(), output)
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to check")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "output format, one of 'text' or 'json'")
+	return cmd
+}
+
+func newCertRenewCmd() *cobra.Command {
+	var auto bool
+	var before string
+	cmd := &cobra.Command{
+		Use:     "renew",
+		Short:   "Renew the certs of masters which are close to expiry",
+		Example: exampleCertRenew,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !auto {
+				return errors.New("--auto is required, use sealos cert --renew to renew local cert targets")
+			}
+			window, err := parseExpiryWindow(before)
+			if err != nil {
+				return err
+			}
+			cm, err := newCertExpiryManager(clusterName)
+			if err != nil {
+				return err
+			}
+			renewed, err := cm.RenewExpiringCerts(window)
+			if len(renewed) != 0 {
+				if perr := printCertExpiries(os.Stdout, renewed, time.Now(), "text"); perr != nil {
+					logger.Warn("failed to print renewed certs: %v", perr)
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if len(renewed) == 0 {
+				logger.Info("no cert of masters expires within %s", before)
+				return nil
+			}
+			logger.Info("%d certs are renewed", len(renewed))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to renew certs")
+	cmd.Flags().BoolVar(&auto, "auto", false, "renew only the certs expiring within --before")
+	cmd.Flags().StringVar(&before, "before", "30d", "renew the certs expiring within this duration, eg. 30d or 720h")
+	return cmd
+}
+
+func newCertExpiryManager(clusterName string) (runtime.CertExpiryManager, error) {
+	cf, rt, err := newClusterRuntime(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	cm, ok := rt.(runtime.CertExpiryManager)
+	if !ok {
+		return nil, errors.New("cert check and renew are not supported for distribution " + cf.GetCluster().GetDistribution())
+	}
+	return cm, nil
+}
+
+// parseExpiryWindow accepts a number of days like 30d besides the units of time.ParseDuration.
+func parseExpiryWindow(s string) (time.Duration, error) {
+	var (
+		d   time.Duration
+		err error
+	)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q, eg. 30d or 720h", s)
+	}
+	return d, nil
+}
+
+func printCertExpiries(w io.Writer, expiries []runtime.CertExpiry, now time.Time, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(expiries, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tNAME\tEXPIRES\tRESIDUAL\tCA")
+	for _, e := range expiries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%t\n", e.Host, e.Name, e.ExpiresAt.Format(time.RFC3339), residualTime(e.ExpiresAt.Sub(now)), e.CA)
+	}
+	return tw.Flush()
+}
+
+func residualTime(d time.Duration) string {
+	if d <= 0 {
+		return "expired"
+	}
+	if days := int(d.Hours() / 24); days >= 1 {
+		return fmt.Sprintf("%dd", days)
+	}
+	return d.Truncate(time.Minute).String()
+}
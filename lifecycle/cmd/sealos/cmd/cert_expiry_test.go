@@ -0,0 +1,52 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiryWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "days", s: "30d", want: 30 * 24 * time.Hour},
+		{name: "one day", s: "1d", want: 24 * time.Hour},
+		{name: "hours", s: "720h", want: 720 * time.Hour},
+		{name: "mixed units", s: "1h30m", want: 90 * time.Minute},
+		{name: "zero days", s: "0d", wantErr: true},
+		{name: "negative days", s: "-1d", wantErr: true},
+		{name: "negative duration", s: "-2h", wantErr: true},
+		{name: "fractional days", s: "1.5d", wantErr: true},
+		{name: "days without number", s: "d", wantErr: true},
+		{name: "no unit", s: "30", wantErr: true},
+		{name: "empty", s: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExpiryWindow(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseExpiryWindow(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseExpiryWindow(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/utils/confirm"
+	fileutils "github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+var exampleEtcdBackup = `
+take a snapshot of etcd of the default cluster:
+	sealos etcd backup
+take a snapshot with a specified name:
+	sealos etcd backup --name before-upgrade
+`
+
+var exampleEtcdRestore = `
+restore etcd of the default cluster from a snapshot taken by sealos etcd backup:
+	sealos etcd restore before-upgrade
+restore etcd from a snapshot file:
+	sealos etcd restore /root/backups/etcd.db
+`
+
+func newEtcdCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Back up and restore etcd of the cluster",
+	}
+	cmd.AddCommand(newEtcdBackupCmd())
+	cmd.AddCommand(newEtcdRestoreCmd())
+	return cmd
+}
+
+func newEtcdBackupCmd() *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Use:     "backup",
+		Short:   "Take a snapshot of etcd from master0",
+		Example: exampleEtcdBackup,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if strings.ContainsAny(name, `/\`) {
+				return fmt.Errorf("invalid snapshot name %s", name)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			em, err := newEtcdManager(clusterName)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				name = "etcd-" + time.Now().Format("20060102150405")
+			}
+			snapshot, err := em.BackupEtcd(name)
+			if err != nil {
+				return err
+			}
+			logger.Info("etcd snapshot %s saved to %s, revision %d, keys %d, size %d bytes",
+				snapshot.Name, snapshot.Path, snapshot.Revision, snapshot.TotalKeys, snapshot.Size)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to back up")
+	cmd.Flags().StringVar(&name, "name", "", "name of the snapshot, default is etcd-<timestamp>")
+	return cmd
+}
+
+func newEtcdRestoreCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:     "restore <snapshot>",
+		Short:   "Restore etcd of all masters from a snapshot and restart the control plane",
+		Example: exampleEtcdRestore,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			snapshot := resolveEtcdSnapshot(clusterName, args[0])
+			if !force {
+				prompt := fmt.Sprintf("the control plane will be stopped and the data of etcd will be replaced by %s, are you sure?", snapshot)
+				if pass, err := confirm.Confirm(prompt, "you have canceled to restore etcd !"); err != nil {
+					return err
+				} else if !pass {
+					return nil
+				}
+			}
+			em, err := newEtcdManager(clusterName)
+			if err != nil {
+				return err
+			}
+			if err := em.RestoreEtcd(snapshot); err != nil {
+				return err
+			}
+			logger.Info("etcd is restored from %s", snapshot)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to restore")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "restore without confirmation")
+	return cmd
+}
+
+func newEtcdManager(clusterName string) (runtime.EtcdManager, error) {
+	cf, rt, err := newClusterRuntime(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	em, ok := rt.(runtime.EtcdManager)
+	if !ok {
+		return nil, errors.New("etcd backup and restore are not supported for distribution " + cf.GetCluster().GetDistribution())
+	}
+	return em, nil
+}
+
+// resolveEtcdSnapshot accepts a snapshot file or the name of a snapshot taken by `sealos etcd backup`.
+func resolveEtcdSnapshot(clusterName, snapshot string) string {
+	if fileutils.IsFile(snapshot) {
+		return snapshot
+	}
+	return filepath.Join(constants.NewPathResolver(clusterName).EtcdBackupsPath(), snapshot+".db")
+}
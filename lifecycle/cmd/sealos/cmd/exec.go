@@ -18,18 +18,31 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/ssh"
+	"github.com/labring/sealos/pkg/system"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
 )
 
 var clusterName string
 
+const defaultExecRunsLimit = 20
+
 var exampleExec = `
 exec to default cluster: default
 	sealos exec "cat /etc/hosts"
@@ -39,13 +52,43 @@ set role label to exec cmd:
     sealos exec -c my-cluster -r master,node "cat /etc/hosts"
 set ips to exec cmd:
     sealos exec -c my-cluster --ips 172.16.1.38 "cat /etc/hosts"
+run on at most 20 nodes at the same time, keep going if some of them failed:
+    sealos exec --parallel 20 --continue-on-error -o json "df -h /var/lib"
 `
 
+type batchFlags struct {
+	parallel        int
+	continueOnError bool
+	output          string
+}
+
+func (f *batchFlags) register(cmd *cobra.Command, verb string) {
+	cmd.Flags().IntVar(&f.parallel, "parallel", 0, fmt.Sprintf("max number of nodes to %s at the same time, 0 means no limit", verb))
+	cmd.Flags().BoolVar(&f.continueOnError, "continue-on-error", false, "keep going on the other nodes if one of them failed")
+	cmd.Flags().StringVarP(&f.output, "output", "o", "text", "output format of the summary, one of 'text' or 'json'")
+}
+
+func (f *batchFlags) validate() error {
+	if f.parallel < 0 {
+		return fmt.Errorf("--parallel must not be negative")
+	}
+	switch f.output {
+	case "text", "json":
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q, must be one of text or json", f.output)
+}
+
+func (f *batchFlags) options() exec.BatchOptions {
+	return exec.BatchOptions{Parallel: f.parallel, ContinueOnError: f.continueOnError}
+}
+
 func newExecCmd() *cobra.Command {
 	var (
 		roles   []string
 		ips     []string
 		cluster *v2.Cluster
+		flags   batchFlags
 	)
 	var execCmd = &cobra.Command{
 		Use:     "exec",
@@ -54,9 +97,12 @@ func newExecCmd() *cobra.Command {
 		Args:    cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			targets := getTargets(cluster, ips, roles)
-			return runCommand(cluster, targets, args)
+			return runCommand(cluster, targets, args, flags)
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err = flags.validate(); err != nil {
+				return err
+			}
 			cluster, err = clusterfile.GetClusterFromName(clusterName)
 			return
 		},
@@ -64,6 +110,7 @@ func newExecCmd() *cobra.Command {
 	execCmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to run commands")
 	execCmd.Flags().StringSliceVarP(&roles, "roles", "r", []string{}, "run command on nodes with role")
 	execCmd.Flags().StringSliceVar(&ips, "ips", []string{}, "run command on nodes with ip address")
+	flags.register(execCmd, "run command on")
 	return execCmd
 }
 
@@ -81,17 +128,86 @@ func getTargets(cluster *v2.Cluster, ips []string, roles []string) []string {
 	return targets
 }
 
-func runCommand(cluster *v2.Cluster, targets []string, args []string) error {
+func runCommand(cluster *v2.Cluster, targets []string, args []string, flags batchFlags) error {
 	execer, err := exec.New(ssh.NewCacheClientFromCluster(cluster, true))
 	if err != nil {
 		return err
 	}
-	eg, _ := errgroup.WithContext(context.Background())
-	for _, ipAddr := range targets {
-		ip := ipAddr
-		eg.Go(func() error {
-			return execer.CmdAsync(ip, args...)
-		})
+	opts := flags.options()
+	runsDir := constants.NewPathResolver(cluster.GetName()).ExecRunsPath()
+	keep := execRunsLimit()
+	if keep > 0 {
+		opts.OutputDir = filepath.Join(runsDir, time.Now().Format("20060102-150405"))
+	}
+	if flags.output == "text" {
+		opts.Stream = os.Stdout
+	}
+	results := exec.RunBatch(context.Background(), targets, opts, func(_ context.Context, host string, stdout, stderr io.Writer) error {
+		ctx, cancel := ssh.GetTimeoutContext()
+		defer cancel()
+		return ssh.CmdWithWriters(ctx, execer, host, stdout, stderr, args...)
+	})
+	if err = exec.PruneRuns(runsDir, keep); err != nil {
+		logger.Warn("failed to remove the outputs of old runs in %s: %v", runsDir, err)
+	}
+	return printHostResults(os.Stdout, opts.OutputDir, results, flags.output)
+}
+
+// execRunsLimit returns how many outputs of the exec runs are kept for a cluster.
+func execRunsLimit() int {
+	v, err := system.Get(system.ExecRunsLimitConfigKey)
+	if err != nil {
+		return defaultExecRunsLimit
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		logger.Debug("failed to parse exec runs limit %s: %v, using default", v, err)
+		return defaultExecRunsLimit
+	}
+	return n
+}
+
+type batchSummary struct {
+	RunDir  string            `json:"runDir,omitempty"`
+	Results []exec.HostResult `json:"results"`
+}
+
+// printHostResults prints the summary of a batch, and returns an error if any host did not succeed.
+func printHostResults(w io.Writer, runDir string, results []exec.HostResult, format string) error {
+	var failed int
+	for _, r := range results {
+		if r.Status != exec.HostSucceeded {
+			failed++
+		}
+	}
+	if format == "json" {
+		data, err := json.MarshalIndent(batchSummary{RunDir: runDir, Results: results}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "HOST\tSTATUS\tEXIT CODE\tDURATION\tERROR")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", r.Host, r.Status, r.ExitCode, r.Duration, firstLine(r.Error))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if runDir != "" {
+			fmt.Fprintf(w, "stdout and stderr of every node are saved in %s\n", runDir)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed on %d of %d node(s)", failed, len(results))
+	}
+	return nil
+}
+
+func firstLine(s string) string {
+	if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+		return s[:idx]
 	}
-	return eg.Wait()
+	return s
 }
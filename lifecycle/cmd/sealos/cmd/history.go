@@ -0,0 +1,96 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/apply"
+	"github.com/labring/sealos/pkg/history"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+var exampleHistory = `
+list recorded revisions of the default cluster:
+	sealos history
+list recorded revisions of a specified cluster:
+	sealos history -c my-cluster
+`
+
+var exampleRollback = `
+restore the images and configs recorded in revision 3 of the default cluster:
+	sealos rollback --to 3
+`
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "history",
+		Short:   "List the recorded revisions of a cluster",
+		Example: exampleHistory,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			revisions, err := history.NewStore(clusterName).List()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "REVISION\tCREATED\tPHASE\tIMAGES\tMESSAGE")
+			for _, r := range revisions {
+				message, _, _ := strings.Cut(r.Message, "\n")
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", r.Revision, r.Timestamp.Format("2006-01-02 15:04:05"),
+					r.Phase, strings.Join(r.Images, ","), message)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to list revisions")
+	return cmd
+}
+
+func newRollbackCmd() *cobra.Command {
+	var revision int
+	cmd := &cobra.Command{
+		Use:     "rollback",
+		Short:   "Roll back the images and configs of a cluster to a recorded revision",
+		Example: exampleRollback,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if revision <= 0 {
+				return errors.New("--to must be a revision listed by `sealos history`")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applier, err := apply.NewRollbackApplier(cmd, clusterName, revision)
+			if err != nil {
+				return err
+			}
+			return applier.Apply()
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			logger.Info(getContact())
+		},
+	}
+	setRequireBuildahAnnotation(cmd)
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to roll back")
+	cmd.Flags().IntVar(&revision, "to", 0, "revision to roll back to")
+	return cmd
+}
@@ -0,0 +1,152 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/ssh"
+	"github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const exampleKnownHosts = `
+list recorded host keys of the default cluster:
+	sealos known-hosts list
+record the current host key of a reinstalled node:
+	sealos known-hosts refresh 172.16.1.38
+refresh host keys of every node in the cluster:
+	sealos known-hosts refresh --all
+forget the host key of a node:
+	sealos known-hosts forget -c my-cluster 172.16.1.38
+`
+
+func newKnownHostsCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "known-hosts",
+		Short:   "Manage recorded ssh host keys of cluster nodes",
+		Example: exampleKnownHosts,
+	}
+	cmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to manage host keys")
+	cmd.AddCommand(newKnownHostsListCmd())
+	cmd.AddCommand(newKnownHostsRefreshCmd())
+	cmd.AddCommand(newKnownHostsForgetCmd())
+	return cmd
+}
+
+func knownHostsStore() *ssh.KnownHostsStore {
+	return ssh.NewKnownHostsStore(constants.NewPathResolver(clusterName).KnownHostsFile())
+}
+
+func newKnownHostsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List recorded host keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hosts, err := knownHostsStore().List()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "HOST\tTYPE\tFINGERPRINT")
+			for _, h := range hosts {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", h.Host, h.Type, h.Fingerprint)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newKnownHostsRefreshCmd() *cobra.Command {
+	var (
+		all     bool
+		timeout time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "refresh [HOST...]",
+		Short: "Fetch and record the current host keys, replacing the recorded ones",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("either specify hosts or --all")
+			}
+			cluster, err := clusterfile.GetClusterFromName(clusterName)
+			if err != nil {
+				return err
+			}
+			targets := args
+			if all {
+				targets = cluster.GetAllIPS()
+			}
+			store := knownHostsStore()
+			for _, target := range targets {
+				h, err := store.Refresh(resolveSSHAddress(cluster, target), timeout)
+				if err != nil {
+					return err
+				}
+				logger.Info("recorded host key %s %s of %s", h.Type, h.Fingerprint, h.Host)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "refresh host keys of all nodes in the cluster")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "ssh connection establish timeout")
+	return cmd
+}
+
+func newKnownHostsForgetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "forget HOST...",
+		Short: "Remove recorded host keys, they will be trusted again on next connection",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cluster, err := clusterfile.GetClusterFromName(clusterName)
+			if err != nil {
+				logger.Debug("cannot load cluster %s, using hosts as they are: %v", clusterName, err)
+			}
+			targets := make([]string, 0, len(args))
+			for _, arg := range args {
+				targets = append(targets, resolveSSHAddress(cluster, arg))
+			}
+			n, err := knownHostsStore().Forget(targets...)
+			if err != nil {
+				return err
+			}
+			logger.Info("removed %d host key(s)", n)
+			return nil
+		},
+	}
+}
+
+// resolveSSHAddress completes a bare ip with the ssh port recorded in the Clusterfile.
+func resolveSSHAddress(cluster *v1beta1.Cluster, host string) string {
+	if cluster == nil || iputils.GetHostIP(host) != host {
+		return host
+	}
+	for _, addr := range cluster.GetAllIPS() {
+		if iputils.GetHostIP(addr) == host {
+			return addr
+		}
+	}
+	return host
+}
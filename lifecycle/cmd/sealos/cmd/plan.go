@@ -0,0 +1,105 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/apply/applydrivers"
+)
+
+type planFlags struct {
+	plan   bool
+	output string
+}
+
+func (f *planFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.plan, "plan", false, "print the changes to be applied to the cluster without touching any host")
+	cmd.Flags().StringVarP(&f.output, "output", "o", "text", "output format of the plan, one of 'text' or 'json'")
+}
+
+func (f *planFlags) validate() error {
+	switch f.output {
+	case "text", "json":
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q, must be one of text or json", f.output)
+}
+
+func printPlan(w io.Writer, applier applydrivers.Interface, format string) error {
+	plan, err := applier.Plan()
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	fmt.Fprintf(w, "Plan to %s cluster %s\n", plan.Action, plan.Cluster)
+	if plan.IsEmpty() {
+		fmt.Fprintln(w, "\nNo changes.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if !plan.Hosts.IsEmpty() {
+		fmt.Fprintln(tw, "\nHosts:")
+		for _, h := range []struct {
+			sign, role string
+			hosts      []string
+		}{
+			{"+", "master", plan.Hosts.AddMasters},
+			{"-", "master", plan.Hosts.DeleteMasters},
+			{"+", "node", plan.Hosts.AddNodes},
+			{"-", "node", plan.Hosts.DeleteNodes},
+		} {
+			for _, host := range h.hosts {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", h.sign, h.role, host)
+			}
+		}
+	}
+	if len(plan.Images) > 0 {
+		fmt.Fprintln(tw, "\nImages to mount:")
+		for _, img := range plan.Images {
+			sign := "+"
+			if img.Override {
+				sign = "~"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", sign, img.Type, img.Image)
+		}
+	}
+	if len(plan.RegistrySync) > 0 {
+		fmt.Fprintf(tw, "\nRegistry sync targets:\n  %s\n", strings.Join(plan.RegistrySync, ", "))
+	}
+	if len(plan.Commands) > 0 {
+		fmt.Fprintln(tw, "\nCommands:")
+		for _, c := range plan.Commands {
+			for _, cmd := range c.Commands {
+				fmt.Fprintf(tw, "  %s\t%s\t%s\n", c.Host, c.Image, cmd)
+			}
+		}
+	}
+	return tw.Flush()
+}
@@ -62,9 +62,12 @@ func init() {
 			Commands: []*cobra.Command{
 				newApplyCmd(),
 				newCertCmd(),
+				newEtcdCmd(),
 				newRunCmd(),
 				newResetCmd(),
 				newStatusCmd(),
+				newHistoryCmd(),
+				newRollbackCmd(),
 			},
 		},
 		{
@@ -79,6 +82,7 @@ func init() {
 			Commands: []*cobra.Command{
 				newExecCmd(),
 				newScpCmd(),
+				newKnownHostsCmd(),
 			},
 		},
 		{
@@ -16,6 +16,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -51,6 +52,9 @@ create cluster to your baremetal server, appoint the iplist:
 create a cluster with custom environment variables:
 	sealos run -e DashBoardPort=8443 mydashboard:latest  --masters 192.168.0.2,192.168.0.3,192.168.0.4 \
 	--nodes 192.168.0.5,192.168.0.6,192.168.0.7 --passwd 'xxx'
+
+print the changes to be applied without touching any host:
+	sealos run labring/helm:v3.8.2 --plan
 `
 
 func newRunCmd() *cobra.Command {
@@ -59,6 +63,7 @@ func newRunCmd() *cobra.Command {
 		SSH:     &apply.SSH{},
 	}
 	var transport string
+	planArgs := &planFlags{}
 	var runCmd = &cobra.Command{
 		Use:     "run",
 		Short:   "Run cloud native applications with ease, with or without a existing cluster",
@@ -74,13 +79,24 @@ func newRunCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if planArgs.plan {
+				return printPlan(os.Stdout, applier, planArgs.output)
+			}
 			return applier.Apply()
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := planArgs.validate(); err != nil {
+				return err
+			}
+			if err := validateSkipPreflight(cmd); err != nil {
+				return err
+			}
 			return buildah.ValidateTransport(transport)
 		},
 		PostRun: func(cmd *cobra.Command, args []string) {
-			logger.Info(getContact())
+			if !planArgs.plan {
+				logger.Info(getContact())
+			}
 		},
 	}
 	setRequireBuildahAnnotation(runCmd)
@@ -90,6 +106,8 @@ func newRunCmd() *cobra.Command {
 		logger.Fatal(err)
 	}
 	runCmd.Flags().BoolVarP(&processor.ForceOverride, "force", "f", false, "force override app in this cluster")
+	planArgs.register(runCmd)
+	registerSkipPreflightFlag(runCmd)
 	runCmd.Flags().StringVarP(&transport, "transport", "t", buildah.OCIArchive,
 		fmt.Sprintf("load image transport from tar archive file.(optional value: %s, %s)", buildah.OCIArchive, buildah.DockerArchive))
 	return runCmd
@@ -18,9 +18,10 @@ package cmd
 
 import (
 	"context"
+	"io"
+	"os"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/labring/sealos/pkg/clusterfile"
 	"github.com/labring/sealos/pkg/exec"
@@ -45,6 +46,7 @@ func newScpCmd() *cobra.Command {
 		roles   []string
 		ips     []string
 		cluster *v1beta1.Cluster
+		flags   batchFlags
 	)
 	var scpCmd = &cobra.Command{
 		Use:     "scp",
@@ -53,9 +55,12 @@ func newScpCmd() *cobra.Command {
 		Args:    cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			targets := getTargets(cluster, ips, roles)
-			return runCopy(cluster, targets, args)
+			return runCopy(cluster, targets, args, flags)
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			if err = flags.validate(); err != nil {
+				return err
+			}
 			cluster, err = clusterfile.GetClusterFromName(clusterName)
 			return
 		},
@@ -63,24 +68,23 @@ func newScpCmd() *cobra.Command {
 	scpCmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to run scp action")
 	scpCmd.Flags().StringSliceVarP(&roles, "roles", "r", []string{}, "copy file to nodes with role")
 	scpCmd.Flags().StringSliceVar(&ips, "ips", []string{}, "copy file to nodes with ip address")
+	flags.register(scpCmd, "copy file to")
 	return scpCmd
 }
 
-func runCopy(cluster *v1beta1.Cluster, targets []string, args []string) error {
+func runCopy(cluster *v1beta1.Cluster, targets []string, args []string, flags batchFlags) error {
 	execer, err := exec.New(ssh.NewCacheClientFromCluster(cluster, true))
 	if err != nil {
 		return err
 	}
-	eg, _ := errgroup.WithContext(context.Background())
-	for _, ipAddr := range targets {
-		ip := ipAddr
-		eg.Go(func() error {
-			return execer.Copy(ip, args[0], args[1])
-		})
-	}
-	if err = eg.Wait(); err != nil {
+	results := exec.RunBatch(context.Background(), targets, flags.options(), func(_ context.Context, host string, _, _ io.Writer) error {
+		return execer.Copy(host, args[0], args[1])
+	})
+	if err = printHostResults(os.Stdout, "", results, flags.output); err != nil {
 		return err
 	}
-	logger.Info("transfers files success")
+	if flags.output == "text" {
+		logger.Info("transfers files success")
+	}
 	return nil
 }
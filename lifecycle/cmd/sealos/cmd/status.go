@@ -15,29 +15,85 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 
 	"github.com/labring/sealos/pkg/checker"
 	"github.com/labring/sealos/pkg/clusterfile"
-
-	"github.com/spf13/cobra"
+	"github.com/labring/sealos/pkg/utils/logger"
 )
 
+const exampleStatus = `
+print status of the default cluster:
+	sealos status
+print status as json, exit with non-zero code if any component is unhealthy:
+	sealos status -o json
+`
+
 // newStatusCmd
 func newStatusCmd() *cobra.Command {
+	var outputFormat string
 	checkCmd := &cobra.Command{
-		Use:   "status",
-		Short: "state of sealos",
-		Args:  cobra.NoArgs,
+		Use:     "status",
+		Short:   "state of sealos",
+		Example: exampleStatus,
+		Args:    cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			switch outputFormat {
+			case "text", "json", "yaml":
+				return nil
+			}
+			return fmt.Errorf("--output must be 'text', 'json' or 'yaml'")
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cluster, err := clusterfile.GetClusterFromName(clusterName)
 			if err != nil {
 				return fmt.Errorf("get default cluster failed, %v", err)
 			}
-			list := []checker.Interface{checker.NewRegistryChecker(), checker.NewCRIShimChecker(), checker.NewCRICtlChecker(), checker.NewInitSystemChecker(), checker.NewNodeChecker(), checker.NewPodChecker(), checker.NewSvcChecker(), checker.NewClusterChecker()}
-			return checker.RunCheckList(list, cluster, checker.PhasePost)
+			cmd.SilenceUsage = true
+			list := []checker.Reporter{checker.NewRegistryChecker(), checker.NewCRIShimChecker(), checker.NewCRICtlChecker(), checker.NewInitSystemChecker(), checker.NewNodeChecker(), checker.NewPodChecker(), checker.NewSvcChecker(), checker.NewClusterChecker()}
+			report := checker.RunReportList(list, cluster, checker.PhasePost)
+			if err = printStatusReport(report, outputFormat); err != nil {
+				return err
+			}
+			if !report.Healthy {
+				return fmt.Errorf("cluster %s is unhealthy, %d check(s) failed", cluster.GetName(), len(report.Failures()))
+			}
+			return nil
 		},
 	}
 	checkCmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to applied status action")
+	checkCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "One of 'text', 'json' or 'yaml'")
 	return checkCmd
 }
+
+func printStatusReport(report *checker.StatusReport, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(os.Stdout, string(data))
+	default:
+		for _, r := range report.Checkers {
+			if err := r.Print(); err != nil {
+				logger.Error("error output %s: %+v", r.Checker, err)
+			}
+		}
+		for _, f := range report.Failures() {
+			fmt.Fprintf(os.Stdout, "[%s] %s/%s: %s\n", f.Checker, f.Node, f.Component, f.Message)
+		}
+	}
+	return nil
+}
@@ -20,8 +20,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
-	"github.com/labring/sealos/pkg/runtime/k3s"
+	"github.com/labring/sealos/pkg/runtime/factory"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/labring/sealos/pkg/clusterfile"
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/history"
 	"github.com/labring/sealos/pkg/ssh"
 	"github.com/labring/sealos/pkg/system"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
@@ -80,6 +82,8 @@ type Applier struct {
 	ClusterCurrent *v2.Cluster
 	ClusterFile    clusterfile.Interface
 	RunNewImages   []string
+	// RollbackRevision restores the configs recorded in the revision before reconciling the cluster.
+	RollbackRevision int
 }
 
 func (c *Applier) Apply() error {
@@ -111,6 +115,9 @@ func (c *Applier) Apply() error {
 		}
 		c.ClusterDesired.CreationTimestamp = metav1.Now()
 	} else {
+		if clusterErr = c.recordHistory(); clusterErr != nil {
+			return clusterErr
+		}
 		clusterErr, appErr = c.reconcileCluster()
 		c.ClusterDesired.CreationTimestamp = c.ClusterCurrent.CreationTimestamp
 	}
@@ -128,7 +135,7 @@ func (c *Applier) getWriteBackObjects() []interface{} {
 	distribution := c.ClusterFile.GetCluster().GetDistribution()
 	if runtimeConfig := c.ClusterFile.GetRuntimeConfig(); runtimeConfig != nil {
 		if components := runtimeConfig.GetComponents(); len(components) > 0 {
-			if distribution == k3s.Distribution {
+			if factory.WriteBackConfig(distribution) {
 				obj = append(obj, components...)
 			}
 		}
@@ -320,6 +327,33 @@ func (c *Applier) saveClusterFile() {
 	}
 }
 
+// recordHistory snapshots the saved Clusterfile and rendered configs before the cluster is changed,
+// so that it can be rolled back to. A rollback restores the configs of its revision after the snapshot.
+func (c *Applier) recordHistory() error {
+	var message string
+	switch {
+	case c.RollbackRevision > 0:
+		message = fmt.Sprintf("before rollback to revision %d", c.RollbackRevision)
+	case len(c.RunNewImages) > 0:
+		message = "before run " + strings.Join(c.RunNewImages, ",")
+	}
+	pathResolver := constants.NewPathResolver(c.ClusterCurrent.Name)
+	store := history.NewStore(c.ClusterCurrent.Name)
+	rev, err := store.Record(history.NewRevision(c.ClusterCurrent, message),
+		constants.Clusterfile(c.ClusterCurrent.Name), pathResolver.EtcPath())
+	if err != nil {
+		logger.Warn("failed to record cluster history: %v", err)
+	} else if rev != nil {
+		logger.Debug("recorded cluster revision %d", rev.Revision)
+	}
+	if c.RollbackRevision > 0 {
+		if err = store.RestoreConfigs(c.RollbackRevision, pathResolver.EtcPath()); err != nil {
+			return processor.NewPreProcessError(fmt.Errorf("failed to restore configs of revision %d: %w", c.RollbackRevision, err))
+		}
+	}
+	return nil
+}
+
 func (c *Applier) applyAfter() {
 	c.saveClusterFile()
 	c.syncWorkdir()
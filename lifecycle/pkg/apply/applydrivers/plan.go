@@ -0,0 +1,166 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applydrivers
+
+import (
+	"fmt"
+
+	"github.com/labring/sealos/pkg/apply/processor"
+	"github.com/labring/sealos/pkg/buildah"
+	"github.com/labring/sealos/pkg/guest"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/maps"
+)
+
+type PlanAction string
+
+const (
+	PlanActionCreate PlanAction = "create"
+	PlanActionUpdate PlanAction = "update"
+)
+
+// pendingContainer stands in for the name of a working container that is only created on apply.
+const pendingContainer = "<pending>"
+
+type PlanHosts struct {
+	AddMasters    []string `json:"addMasters,omitempty"`
+	DeleteMasters []string `json:"deleteMasters,omitempty"`
+	AddNodes      []string `json:"addNodes,omitempty"`
+	DeleteNodes   []string `json:"deleteNodes,omitempty"`
+}
+
+func (h PlanHosts) IsEmpty() bool {
+	return len(h.AddMasters) == 0 && len(h.DeleteMasters) == 0 && len(h.AddNodes) == 0 && len(h.DeleteNodes) == 0
+}
+
+type PlanImage struct {
+	Image    string       `json:"image"`
+	Type     v2.ImageType `json:"type"`
+	Override bool         `json:"override,omitempty"`
+}
+
+// Plan is what an apply would change in the cluster, computed without touching any host.
+type Plan struct {
+	Cluster      string               `json:"cluster"`
+	Action       PlanAction           `json:"action"`
+	Hosts        PlanHosts            `json:"hosts"`
+	Images       []PlanImage          `json:"images"`
+	Commands     []guest.HostCommands `json:"commands"`
+	RegistrySync []string             `json:"registrySync"`
+}
+
+func (p *Plan) IsEmpty() bool {
+	return p.Hosts.IsEmpty() && len(p.Images) == 0 && len(p.Commands) == 0
+}
+
+type imageInspector interface {
+	InspectImage(imgName string, opts ...string) (*buildah.InspectOutput, error)
+}
+
+// Plan works out the host changes, images to mount, guest commands and registry sync targets of Apply.
+// Images are inspected from local storage or the remote registry, nothing is pulled or mounted.
+func (c *Applier) Plan() (*Plan, error) {
+	bder, err := buildah.New(c.ClusterDesired.Name)
+	if err != nil {
+		return nil, err
+	}
+	return c.plan(bder)
+}
+
+func (c *Applier) plan(inspector imageInspector) (*Plan, error) {
+	desired := c.ClusterDesired
+	plan := &Plan{
+		Cluster:      desired.Name,
+		Images:       make([]PlanImage, 0),
+		Commands:     make([]guest.HostCommands, 0),
+		RegistrySync: make([]string, 0),
+	}
+	extraEnvs := processor.GetEnvs(c.Context)
+
+	if c.ClusterCurrent == nil || c.ClusterCurrent.CreationTimestamp.IsZero() {
+		plan.Action = PlanActionCreate
+		plan.Hosts.AddMasters = desired.GetMasterIPAndPortList()
+		plan.Hosts.AddNodes = desired.GetNodeIPAndPortList()
+		envs := maps.Merge(maps.FromSlice(desired.Spec.Env), extraEnvs)
+		mounts, err := planMounts(inspector, desired.Spec.Image, envs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range mounts {
+			plan.Images = append(plan.Images, PlanImage{Image: mounts[i].ImageName, Type: mounts[i].Type})
+		}
+		if len(mounts) > 0 {
+			plan.RegistrySync = desired.GetRegistryIPAndPortList()
+		}
+		plan.Commands = guest.Commands(desired, mounts, desired.GetAllIPS())
+		return plan, nil
+	}
+
+	plan.Action = PlanActionUpdate
+	images := make([]string, 0)
+	overrides := make(map[string]bool)
+	for _, img := range c.RunNewImages {
+		if _, mount := c.ClusterCurrent.FindImage(img); mount != nil {
+			if !processor.ForceOverride {
+				continue
+			}
+			overrides[img] = true
+		}
+		images = append(images, img)
+	}
+	mounts, err := planMounts(inspector, images, extraEnvs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mounts {
+		plan.Images = append(plan.Images, PlanImage{Image: mounts[i].ImageName, Type: mounts[i].Type, Override: overrides[mounts[i].ImageName]})
+	}
+	if len(mounts) > 0 {
+		plan.RegistrySync = desired.GetRegistryIPAndPortList()
+	}
+	plan.Commands = append(plan.Commands, guest.Commands(desired, mounts, desired.GetAllIPS())...)
+
+	mj, md := iputils.GetDiffHosts(c.ClusterCurrent.GetMasterIPAndPortList(), desired.GetMasterIPAndPortList())
+	nj, nd := iputils.GetDiffHosts(c.ClusterCurrent.GetNodeIPAndPortList(), desired.GetNodeIPAndPortList())
+	plan.Hosts = PlanHosts{AddMasters: mj, DeleteMasters: md, AddNodes: nj, DeleteNodes: nd}
+	if joined := append(append([]string{}, mj...), nj...); len(joined) > 0 {
+		// joined hosts only run the rootfs and patch images which are mounted already
+		baseMounts := make([]v2.MountImage, 0)
+		for _, m := range c.ClusterCurrent.Status.Mounts {
+			if !m.IsApplication() {
+				baseMounts = append(baseMounts, m)
+			}
+		}
+		plan.Commands = append(plan.Commands, guest.Commands(desired, baseMounts, joined)...)
+	}
+	return plan, nil
+}
+
+func planMounts(inspector imageInspector, images []string, envs map[string]string) ([]v2.MountImage, error) {
+	mounts := make([]v2.MountImage, 0, len(images))
+	for _, img := range images {
+		mount := &v2.MountImage{
+			Name:      pendingContainer,
+			ImageName: img,
+		}
+		if err := processor.OCIToImageMount(inspector, mount); err != nil {
+			return nil, fmt.Errorf("failed to inspect image %s: %w", img, err)
+		}
+		mount.Env = maps.Merge(mount.Env, envs)
+		mounts = append(mounts, *mount)
+	}
+	return mounts, nil
+}
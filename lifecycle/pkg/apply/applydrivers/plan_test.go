@@ -0,0 +1,137 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applydrivers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	ociv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/labring/sealos/pkg/buildah"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+type fakeInspector map[string]ociv1.ImageConfig
+
+func (f fakeInspector) InspectImage(name string, _ ...string) (*buildah.InspectOutput, error) {
+	config, ok := f[name]
+	if !ok {
+		return nil, fmt.Errorf("image %s not found", name)
+	}
+	return &buildah.InspectOutput{OCIv1: &ociv1.Image{Config: config}}, nil
+}
+
+func newPlanCluster(masters, nodes []string, images ...string) *v2.Cluster {
+	return &v2.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v2.ClusterSpec{
+			Image: images,
+			Hosts: []v2.Host{
+				{IPS: masters, Roles: []string{v2.MASTER}},
+				{IPS: nodes, Roles: []string{v2.NODE}},
+			},
+		},
+	}
+}
+
+func TestApplier_plan(t *testing.T) {
+	inspector := fakeInspector{
+		"kubernetes:v1.25.0": {Labels: map[string]string{"sealos.io.type": "rootfs"}, Cmd: []string{"bash init.sh"}},
+		"helm:v3.8.2":        {Cmd: []string{"cp -a opt/helm /usr/bin/"}},
+	}
+
+	t.Run("create", func(t *testing.T) {
+		applier := &Applier{
+			Context:        context.Background(),
+			ClusterDesired: newPlanCluster([]string{"10.0.0.1:22"}, []string{"10.0.0.2:22"}, "kubernetes:v1.25.0", "helm:v3.8.2"),
+		}
+		plan, err := applier.plan(inspector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.Action != PlanActionCreate {
+			t.Errorf("action = %s, want %s", plan.Action, PlanActionCreate)
+		}
+		wantHosts := PlanHosts{AddMasters: []string{"10.0.0.1:22"}, AddNodes: []string{"10.0.0.2:22"}}
+		if !reflect.DeepEqual(plan.Hosts, wantHosts) {
+			t.Errorf("hosts = %+v, want %+v", plan.Hosts, wantHosts)
+		}
+		wantImages := []PlanImage{{Image: "kubernetes:v1.25.0", Type: v2.RootfsImage}, {Image: "helm:v3.8.2", Type: v2.AppImage}}
+		if !reflect.DeepEqual(plan.Images, wantImages) {
+			t.Errorf("images = %+v, want %+v", plan.Images, wantImages)
+		}
+		var hosts []string
+		for _, c := range plan.Commands {
+			hosts = append(hosts, c.Host+"/"+c.Image)
+		}
+		wantCommands := []string{"10.0.0.1:22/kubernetes:v1.25.0", "10.0.0.2:22/kubernetes:v1.25.0", "10.0.0.1:22/helm:v3.8.2"}
+		if !reflect.DeepEqual(hosts, wantCommands) {
+			t.Errorf("commands = %v, want %v", hosts, wantCommands)
+		}
+		if !reflect.DeepEqual(plan.RegistrySync, []string{"10.0.0.1:22"}) {
+			t.Errorf("registry sync = %v", plan.RegistrySync)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		current := newPlanCluster([]string{"10.0.0.1:22"}, []string{"10.0.0.2:22"}, "kubernetes:v1.25.0")
+		current.CreationTimestamp = metav1.Now()
+		current.Status.Mounts = []v2.MountImage{{Name: "rootfs", ImageName: "kubernetes:v1.25.0", Type: v2.RootfsImage, Cmd: []string{"bash init.sh"}}}
+		applier := &Applier{
+			Context:        context.Background(),
+			ClusterCurrent: current,
+			ClusterDesired: newPlanCluster([]string{"10.0.0.1:22"}, []string{"10.0.0.3:22"}, "kubernetes:v1.25.0", "helm:v3.8.2"),
+			RunNewImages:   []string{"kubernetes:v1.25.0", "helm:v3.8.2"},
+		}
+		plan, err := applier.plan(inspector)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plan.Action != PlanActionUpdate {
+			t.Errorf("action = %s, want %s", plan.Action, PlanActionUpdate)
+		}
+		wantHosts := PlanHosts{AddMasters: []string{}, DeleteMasters: []string{}, AddNodes: []string{"10.0.0.3:22"}, DeleteNodes: []string{"10.0.0.2:22"}}
+		if !reflect.DeepEqual(plan.Hosts.AddNodes, wantHosts.AddNodes) || !reflect.DeepEqual(plan.Hosts.DeleteNodes, wantHosts.DeleteNodes) ||
+			len(plan.Hosts.AddMasters) != 0 || len(plan.Hosts.DeleteMasters) != 0 {
+			t.Errorf("hosts = %+v, want %+v", plan.Hosts, wantHosts)
+		}
+		wantImages := []PlanImage{{Image: "helm:v3.8.2", Type: v2.AppImage}}
+		if !reflect.DeepEqual(plan.Images, wantImages) {
+			t.Errorf("images = %+v, want %+v", plan.Images, wantImages)
+		}
+		var hosts []string
+		for _, c := range plan.Commands {
+			hosts = append(hosts, c.Host+"/"+c.Image)
+		}
+		wantCommands := []string{"10.0.0.1:22/helm:v3.8.2", "10.0.0.3:22/kubernetes:v1.25.0"}
+		if !reflect.DeepEqual(hosts, wantCommands) {
+			t.Errorf("commands = %v, want %v", hosts, wantCommands)
+		}
+	})
+
+	t.Run("unknown image", func(t *testing.T) {
+		applier := &Applier{
+			Context:        context.Background(),
+			ClusterDesired: newPlanCluster([]string{"10.0.0.1:22"}, nil, "calico:v3.24.1"),
+		}
+		if _, err := applier.plan(inspector); err == nil {
+			t.Error("expected an error for an image which cannot be inspected")
+		}
+	})
+}
@@ -51,6 +51,7 @@ type SSH struct {
 	Pk         string
 	PkPassword string
 	Port       uint16
+	KnownHosts string
 }
 
 func (s *SSH) RegisterFlags(fs *pflag.FlagSet) {
@@ -60,6 +61,7 @@ func (s *SSH) RegisterFlags(fs *pflag.FlagSet) {
 		"selects a file from which the identity (private key) for public key authentication is read")
 	fs.StringVar(&s.PkPassword, "pk-passwd", "", "passphrase for decrypting a PEM encoded private key")
 	fs.Uint16Var(&s.Port, "port", 22, "port to connect to on the remote host")
+	fs.StringVar(&s.KnownHosts, "ssh-known-hosts", "", "ssh host key verification policy, one of tofu, strict, replace or off, defaults to tofu")
 }
 
 type RunArgs struct {
@@ -18,33 +18,46 @@ package processor
 
 import "context"
 
-var (
+// the keys must be of distinct types, values of the same empty struct type are equal
+type (
 	commandKey struct{}
 	envKey     struct{}
 )
 
-//nolint:staticcheck
 func WithCommands(ctx context.Context, commands []string) context.Context {
-	return context.WithValue(ctx, commandKey, commands)
+	return context.WithValue(ctx, commandKey{}, commands)
 }
 
 func GetCommands(ctx context.Context) []string {
-	v := ctx.Value(commandKey)
+	v := ctx.Value(commandKey{})
 	if v != nil {
 		return v.([]string)
 	}
 	return nil
 }
 
-//nolint:staticcheck
 func WithEnvs(ctx context.Context, envs map[string]string) context.Context {
-	return context.WithValue(ctx, envKey, envs)
+	return context.WithValue(ctx, envKey{}, envs)
 }
 
 func GetEnvs(ctx context.Context) map[string]string {
-	v := ctx.Value(commandKey)
+	v := ctx.Value(envKey{})
 	if v != nil {
 		return v.(map[string]string)
 	}
 	return nil
 }
+
+type skipPreflightKey struct{}
+
+func WithSkipPreflight(ctx context.Context, checks []string) context.Context {
+	return context.WithValue(ctx, skipPreflightKey{}, checks)
+}
+
+func GetSkipPreflight(ctx context.Context) []string {
+	v := ctx.Value(skipPreflightKey{})
+	if v != nil {
+		return v.([]string)
+	}
+	return nil
+}
@@ -42,6 +42,8 @@ type CreateProcessor struct {
 	Runtime     runtime.Interface
 	Guest       guest.Interface
 	ExtraEnvs   map[string]string // parsing from CLI arguments
+	// SkipPreflight are the names of preflight checks not to run
+	SkipPreflight []string
 }
 
 func (c *CreateProcessor) Execute(cluster *v2.Cluster) error {
@@ -85,6 +87,14 @@ func (c *CreateProcessor) Check(cluster *v2.Cluster) error {
 	// the order doesn't matter
 	ips = append(ips, cluster.GetMasterIPAndPortList()...)
 	ips = append(ips, cluster.GetNodeIPAndPortList()...)
+	// run all preflight checks before failing, so that problems of every host are reported at once
+	report := checker.RunReportList([]checker.Reporter{checker.NewPreflightChecker(ips, c.SkipPreflight)}, cluster, checker.PhasePre)
+	if failures := report.Failures(); len(failures) > 0 {
+		for _, f := range failures {
+			logger.Error("preflight check %s failed on %s: %s", f.Component, f.Node, f.Message)
+		}
+		return NewCheckError(fmt.Errorf("%d preflight check(s) failed, fix them or skip with --skip-preflight", len(failures)))
+	}
 	return NewCheckError(checker.RunCheckList([]checker.Interface{checker.NewIPsHostChecker(ips), checker.NewContainerdChecker(ips)}, cluster, checker.PhasePre))
 }
 
@@ -190,5 +200,7 @@ func NewCreateProcessor(ctx context.Context, name string, clusterFile clusterfil
 		Buildah:     bder,
 		Guest:       gs,
 		ExtraEnvs:   GetEnvs(ctx),
+
+		SkipPreflight: GetSkipPreflight(ctx),
 	}, nil
 }
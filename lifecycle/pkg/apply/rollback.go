@@ -0,0 +1,147 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/labring/sealos/pkg/apply/applydrivers"
+	"github.com/labring/sealos/pkg/buildah"
+	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/history"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+type rollbackApplier struct {
+	applydrivers.Interface
+	clusterName string
+	pruned      []v2.MountImage
+}
+
+// NewRollbackApplier returns an applier which brings the images and rendered configs of the cluster back to
+// a recorded revision. Images mounted in the revision but not currently are run again, images mounted since
+// then are unmounted, the apps they installed are left as they are.
+func NewRollbackApplier(cmd *cobra.Command, clusterName string, revision int) (applydrivers.Interface, error) {
+	store := history.NewStore(clusterName)
+	if _, err := store.Get(revision); err != nil {
+		return nil, err
+	}
+	cf := clusterfile.NewClusterFile(constants.Clusterfile(clusterName))
+	if err := cf.Process(); err != nil {
+		return nil, err
+	}
+	target := clusterfile.NewClusterFile(store.ClusterfilePath(revision))
+	if err := target.Process(); err != nil {
+		return nil, fmt.Errorf("failed to load Clusterfile of revision %d: %w", revision, err)
+	}
+	cluster := cf.GetCluster().DeepCopy()
+	images, pruned, err := rollbackImages(cluster, target.GetCluster())
+	if err != nil {
+		return nil, err
+	}
+	if len(images) == 0 && len(pruned) == 0 {
+		logger.Info("the images of cluster %s are the same as revision %d", clusterName, revision)
+	}
+	for _, img := range images {
+		logger.Info("image %s will be run again", img)
+	}
+	for _, m := range pruned {
+		logger.Info("image %s will be unmounted", m.ImageName)
+	}
+	applier := &applydrivers.Applier{
+		Context:          withCommonContext(cmd.Context(), cmd),
+		ClusterDesired:   cluster,
+		ClusterCurrent:   cf.GetCluster(),
+		ClusterFile:      cf,
+		RunNewImages:     images,
+		RollbackRevision: revision,
+	}
+	return &rollbackApplier{Interface: applier, clusterName: clusterName, pruned: pruned}, nil
+}
+
+// rollbackImages removes the mounts that are not in target from current and returns them,
+// together with the images of target that have to be run again.
+func rollbackImages(current, target *v2.Cluster) ([]string, []v2.MountImage, error) {
+	if current == nil || target == nil {
+		return nil, nil, fmt.Errorf("cluster cannot be empty")
+	}
+	currentRoot, targetRoot := current.GetRootfsImage(), target.GetRootfsImage()
+	if currentRoot != nil && targetRoot != nil && currentRoot.ImageName != targetRoot.ImageName {
+		return nil, nil, fmt.Errorf("rolling back the cluster image from %s to %s is not supported", currentRoot.ImageName, targetRoot.ImageName)
+	}
+	targetImages := sets.NewString()
+	for _, m := range target.Status.Mounts {
+		targetImages.Insert(m.ImageName)
+	}
+	var (
+		kept   []v2.MountImage
+		pruned []v2.MountImage
+	)
+	currentImages := sets.NewString()
+	for _, m := range current.Status.Mounts {
+		if m.IsRootFs() || targetImages.Has(m.ImageName) {
+			kept = append(kept, m)
+			currentImages.Insert(m.ImageName)
+			continue
+		}
+		pruned = append(pruned, m)
+	}
+	current.Status.Mounts = kept
+
+	prunedImages := sets.NewString()
+	for _, m := range pruned {
+		prunedImages.Insert(m.ImageName)
+	}
+	specImages := make(v2.ImageList, 0, len(current.Spec.Image))
+	for _, img := range current.Spec.Image {
+		if !prunedImages.Has(img) {
+			specImages = append(specImages, img)
+		}
+	}
+	current.Spec.Image = specImages
+
+	var images []string
+	for _, m := range target.Status.Mounts {
+		if !currentImages.Has(m.ImageName) {
+			images = append(images, m.ImageName)
+		}
+	}
+	return images, pruned, nil
+}
+
+func (r *rollbackApplier) Apply() error {
+	if err := r.Interface.Apply(); err != nil {
+		return err
+	}
+	if len(r.pruned) == 0 {
+		return nil
+	}
+	bder, err := buildah.New(r.clusterName)
+	if err != nil {
+		logger.Warn("failed to remove unmounted images: %v", err)
+		return nil
+	}
+	for _, m := range r.pruned {
+		if err = bder.Delete(m.Name); err != nil {
+			logger.Warn("failed to remove mount %s of image %s: %v", m.Name, m.ImageName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func newMount(name string, rootfs bool) v2.MountImage {
+	m := v2.MountImage{Name: "ctr-" + name, ImageName: name, Type: v2.AppImage}
+	if rootfs {
+		m.Type = v2.RootfsImage
+	}
+	return m
+}
+
+func TestRollbackImages(t *testing.T) {
+	current := &v2.Cluster{}
+	current.Spec.Image = []string{"kubernetes:v1.27.7", "calico:v3.26", "app:v2", "monitor:v1"}
+	current.Status.Mounts = []v2.MountImage{
+		newMount("kubernetes:v1.27.7", true), newMount("calico:v3.26", false),
+		newMount("app:v2", false), newMount("monitor:v1", false),
+	}
+	target := &v2.Cluster{}
+	target.Status.Mounts = []v2.MountImage{
+		newMount("kubernetes:v1.27.7", true), newMount("calico:v3.26", false), newMount("app:v1", false),
+	}
+
+	images, pruned, err := rollbackImages(current, target)
+	if err != nil {
+		t.Fatalf("rollbackImages: %v", err)
+	}
+	if !reflect.DeepEqual(images, []string{"app:v1"}) {
+		t.Errorf("unexpected images to run %v", images)
+	}
+	if len(pruned) != 2 || pruned[0].ImageName != "app:v2" || pruned[1].ImageName != "monitor:v1" {
+		t.Errorf("unexpected pruned mounts %+v", pruned)
+	}
+	if !reflect.DeepEqual(current.Spec.Image, v2.ImageList{"kubernetes:v1.27.7", "calico:v3.26"}) {
+		t.Errorf("unexpected spec images %v", current.Spec.Image)
+	}
+	if len(current.Status.Mounts) != 2 {
+		t.Errorf("unexpected mounts %+v", current.Status.Mounts)
+	}
+
+	target.Status.Mounts[0] = newMount("kubernetes:v1.26.9", true)
+	if _, _, err = rollbackImages(current, target); err == nil {
+		t.Error("expected error when rolling back the cluster image")
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"runtime"
 	"strconv"
 
 	"github.com/spf13/cobra"
@@ -87,6 +88,10 @@ func withCommonContext(ctx context.Context, cmd *cobra.Command) context.Context
 		v, _ := cmd.Flags().GetStringSlice("env")
 		ctx = processor.WithEnvs(ctx, maps.FromSlice(v))
 	}
+	if flagChanged(cmd, "skip-preflight") {
+		v, _ := cmd.Flags().GetStringSlice("skip-preflight")
+		ctx = processor.WithSkipPreflight(ctx, v)
+	}
 	return ctx
 }
 
@@ -124,26 +129,49 @@ func (r *ClusterArgs) runArgs(cmd *cobra.Command, args *RunArgs, imageList []str
 	nodes := stringsutil.FilterNonEmptyFromString(args.Cluster.Nodes, ",")
 	r.hosts = []v2.Host{}
 
-	sshClient := ssh.NewCacheClientFromCluster(r.cluster, true)
-	execer, err := exec.New(sshClient)
-	if err != nil {
-		return err
+	hostArch := r.knownHostArch
+	if !flagChanged(cmd, "plan") {
+		sshClient := ssh.NewCacheClientFromCluster(r.cluster, true)
+		execer, err := exec.New(sshClient)
+		if err != nil {
+			return err
+		}
+		hostArch = func(_, addr string) string {
+			return GetHostArch(execer, addr)
+		}
 	}
 	if len(masters) > 0 {
 		host, port := iputils.GetHostIPAndPortOrDefault(masters[0], defaultPort)
 		master0addr := net.JoinHostPort(host, port)
-		r.setHostWithIpsPort(masters, []string{v2.MASTER, GetHostArch(execer, master0addr)})
+		r.setHostWithIpsPort(masters, []string{v2.MASTER, hostArch(v2.MASTER, master0addr)})
 	}
 	if len(nodes) > 0 {
 		host, port := iputils.GetHostIPAndPortOrDefault(nodes[0], defaultPort)
 		node0addr := net.JoinHostPort(host, port)
-		r.setHostWithIpsPort(nodes, []string{v2.NODE, GetHostArch(execer, node0addr)})
+		r.setHostWithIpsPort(nodes, []string{v2.NODE, hostArch(v2.NODE, node0addr)})
 	}
 	r.cluster.Spec.Hosts = append(r.cluster.Spec.Hosts, r.hosts...)
 
 	return nil
 }
 
+// knownHostArch returns the architecture recorded in the Clusterfile for hosts
+// of the given role without connecting to them, which is what --plan needs.
+// It falls back to the architecture of the local host for a new cluster.
+func (r *ClusterArgs) knownHostArch(role, _ string) string {
+	for _, host := range r.cluster.Spec.Hosts {
+		if !slices.Contains(host.Roles, role) {
+			continue
+		}
+		for _, arch := range []v2.Arch{v2.AMD64, v2.ARM64} {
+			if slices.Contains(host.Roles, string(arch)) {
+				return string(arch)
+			}
+		}
+	}
+	return runtime.GOARCH
+}
+
 func (r *ClusterArgs) setHostWithIpsPort(ips []string, roles []string) {
 	defaultPort := defaultSSHPort(r.cluster.Spec.SSH.Port)
 	hostMap := map[string]*v2.Host{}
@@ -16,6 +16,7 @@ package apply
 
 import (
 	"reflect"
+	"runtime"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -129,6 +130,32 @@ func TestClusterArgs_setHostWithIpsPort(t *testing.T) {
 	}
 }
 
+func TestClusterArgs_knownHostArch(t *testing.T) {
+	hosts := []v2.Host{
+		{IPS: []string{"192.168.0.2:22"}, Roles: []string{v2.MASTER, string(v2.ARM64)}},
+		{IPS: []string{"192.168.0.3:22"}, Roles: []string{v2.NODE, string(v2.AMD64)}},
+	}
+	tests := []struct {
+		name  string
+		hosts []v2.Host
+		role  string
+		want  string
+	}{
+		{name: "master from clusterfile", hosts: hosts, role: v2.MASTER, want: string(v2.ARM64)},
+		{name: "node from clusterfile", hosts: hosts, role: v2.NODE, want: string(v2.AMD64)},
+		{name: "new cluster", role: v2.MASTER, want: runtime.GOARCH},
+		{name: "role without arch", hosts: []v2.Host{{IPS: []string{"192.168.0.2:22"}, Roles: []string{v2.NODE}}}, role: v2.NODE, want: runtime.GOARCH},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ClusterArgs{cluster: &v2.Cluster{Spec: v2.ClusterSpec{Hosts: tt.hosts}}}
+			if got := r.knownHostArch(tt.role, "192.168.0.4:22"); got != tt.want {
+				t.Errorf("knownHostArch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNewApplierFromArgs(t *testing.T) {
 	addr, _ := iputils.ListLocalHostAddrs()
 	Default := "Default"
@@ -94,6 +94,10 @@ func getSSHFromCommand(cmd *cobra.Command) *v2.SSH {
 		ret.Port, _ = fs.GetUint16("port")
 		changed = true
 	}
+	if flagChanged(cmd, "ssh-known-hosts") {
+		ret.KnownHosts, _ = fs.GetString("ssh-known-hosts")
+		changed = true
+	}
 	if changed {
 		return ret
 	}
@@ -172,7 +176,10 @@ func verifyAndSetNodes(cmd *cobra.Command, cluster *v2.Cluster, scaleArgs *Scale
 			global := cluster.Spec.SSH.DeepCopy()
 			ssh.OverSSHConfig(global, override)
 
-			sshClient := ssh.MustNewClient(global, true)
+			sshClient, err := ssh.NewClientWithKnownHosts(cluster, global, true)
+			if err != nil {
+				return nil, err
+			}
 			execer, err := exec.New(sshClient)
 			if err != nil {
 				return nil, err
@@ -145,7 +145,10 @@ func CheckAndInitialize(cluster *v2.Cluster) error {
 	}
 
 	if len(cluster.Spec.Hosts) == 0 {
-		sshClient := ssh.MustNewClient(cluster.Spec.SSH.DeepCopy(), true)
+		sshClient, err := ssh.NewClientWithKnownHosts(cluster, cluster.Spec.SSH.DeepCopy(), true)
+		if err != nil {
+			return err
+		}
 		execer, err := exec.New(sshClient)
 		if err != nil {
 			return err
@@ -0,0 +1,142 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	kubernetesDir = "/etc/kubernetes"
+	// KubeletClientCertFile is rotated by kubelet itself, it cannot be renewed by kubeadm
+	KubeletClientCertFile = "/var/lib/kubelet/pki/kubelet-client-current.pem"
+	KubeletClientCertName = "kubelet-client"
+
+	certFileMarker = "==> "
+)
+
+// CertFile is a certificate on a host, Name is the name used by `kubeadm certs renew`.
+type CertFile struct {
+	Name       string
+	Path       string
+	CA         bool
+	KubeConfig bool
+}
+
+func certFileFromConfig(cfg Config, ca bool) CertFile {
+	name := cfg.BaseName
+	if cfg.DefaultPath == kubeDefaultCertEtcdPath {
+		name = "etcd-" + name
+	}
+	return CertFile{
+		Name: name,
+		Path: path.Join(cfg.DefaultPath, cfg.BaseName+".crt"),
+		CA:   ca,
+	}
+}
+
+// ControlPlaneCertFiles returns the certificates and kubeconfig files which kubeadm creates on a master.
+func ControlPlaneCertFiles(kubeVersion string) []CertFile {
+	var files []CertFile
+	for _, cfg := range CaList(KubeDefaultCertPath, kubeDefaultCertEtcdPath) {
+		files = append(files, certFileFromConfig(cfg, true))
+	}
+	for _, cfg := range List(KubeDefaultCertPath, kubeDefaultCertEtcdPath, resolveLocalIdentityModel(kubeVersion)) {
+		files = append(files, certFileFromConfig(cfg, false))
+	}
+	kubeConfigs := []string{adminKubeConfigFileName, controllerManagerKubeConfigFileName, schedulerKubeConfigFileName}
+	if usesClusterAdminsIdentityModel(kubeVersion) {
+		kubeConfigs = append(kubeConfigs, superAdminKubeConfigFileName)
+	}
+	for _, name := range kubeConfigs {
+		files = append(files, CertFile{Name: name, Path: path.Join(kubernetesDir, name), KubeConfig: true})
+	}
+	return append(files, NodeCertFiles()...)
+}
+
+// NodeCertFiles returns the certificates on every node of the cluster.
+func NodeCertFiles() []CertFile {
+	return []CertFile{{Name: KubeletClientCertName, Path: KubeletClientCertFile}}
+}
+
+// ReadCertFilesCmd returns a shell command printing the certificates of files, each one follows
+// a marker line with its path. Only the certificate blocks are printed, private keys never leave the host.
+func ReadCertFilesCmd(files []CertFile) string {
+	var sb strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&sb, "echo '%s%s'; ", certFileMarker, f.Path)
+		if f.KubeConfig {
+			fmt.Fprintf(&sb, `if [ -f %[1]s ]; then sed -n 's/^ *client-certificate-data: *//p' %[1]s | base64 -d; echo; fi; `, f.Path)
+		} else {
+			fmt.Fprintf(&sb, `if [ -f %[1]s ]; then sed -n '/-----BEGIN CERTIFICATE-----/,/-----END CERTIFICATE-----/p' %[1]s; fi; `, f.Path)
+		}
+	}
+	sb.WriteString("true")
+	return sb.String()
+}
+
+// ParseCertFilesOutput parses the output of ReadCertFilesCmd into the certificate of every path,
+// the files which don't exist on the host are left out.
+func ParseCertFilesOutput(out string) (map[string]*x509.Certificate, error) {
+	blocks := map[string]*strings.Builder{}
+	var current *strings.Builder
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, certFileMarker) {
+			current = &strings.Builder{}
+			blocks[strings.TrimSpace(strings.TrimPrefix(line, certFileMarker))] = current
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+			current.WriteString("\n")
+		}
+	}
+
+	certs := make(map[string]*x509.Certificate, len(blocks))
+	for file, data := range blocks {
+		if strings.TrimSpace(data.String()) == "" {
+			continue
+		}
+		c, err := firstCertificate([]byte(data.String()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %s: %v", file, err)
+		}
+		certs[file] = c
+	}
+	return certs, nil
+}
+
+func firstCertificate(data []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found")
+		}
+		if block.Type == CertificateBlockType {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// ExpiresWithin reports whether c expires before now plus d.
+func ExpiresWithin(c *x509.Certificate, now time.Time, d time.Duration) bool {
+	return c.NotAfter.Before(now.Add(d))
+}
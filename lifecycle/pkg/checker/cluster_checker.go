@@ -43,21 +43,30 @@ type ClusterStatus struct {
 	KubeletErr            string
 }
 
+func (n *ClusterChecker) Name() string {
+	return "cluster"
+}
+
 func (n *ClusterChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *ClusterChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
 
 	// checker if all the node is ready
 	data := constants.NewPathResolver(cluster.Name)
 	c, err := kubernetes.NewKubernetesClient(data.AdminFile(), "")
 	if err != nil {
-		return err
+		return report, err
 	}
 	ke := kubernetes.NewKubeExpansion(c.Kubernetes())
 	nodes, err := c.Kubernetes().CoreV1().Nodes().List(context.Background(), v1.ListOptions{})
 	if err != nil {
-		return err
+		return report, err
 	}
 	healthyClient := kubernetes.NewKubeHealthy(c.Kubernetes(), 30*time.Second)
 	var NodeList []ClusterStatus
@@ -71,32 +80,39 @@ func (n *ClusterChecker) Check(cluster *v2.Cluster, phase string) error {
 		if isControlPlaneNode(node) {
 			apiPod, err := ke.FetchStaticPod(ctx, node.Name, kubernetes.KubeAPIServer)
 			if err != nil {
-				return err
+				return report, err
 			}
 			cStatus.KubeAPIServer = healthyClient.ForHealthyPod(apiPod)
+			report.add(ip, kubernetes.KubeAPIServer, statusOf(getPodReadyStatus(*apiPod) == nil), cStatus.KubeAPIServer)
 
 			controllerPod, err := ke.FetchStaticPod(ctx, node.Name, kubernetes.KubeControllerManager)
 			if err != nil {
-				return err
+				return report, err
 			}
 			cStatus.KubeControllerManager = healthyClient.ForHealthyPod(controllerPod)
+			report.add(ip, kubernetes.KubeControllerManager, statusOf(getPodReadyStatus(*controllerPod) == nil), cStatus.KubeControllerManager)
 
 			schedulerPod, err := ke.FetchStaticPod(ctx, node.Name, kubernetes.KubeScheduler)
 			if err != nil {
-				return err
+				return report, err
 			}
 			cStatus.KubeScheduler = healthyClient.ForHealthyPod(schedulerPod)
+			report.add(ip, kubernetes.KubeScheduler, statusOf(getPodReadyStatus(*schedulerPod) == nil), cStatus.KubeScheduler)
 		}
 
 		if err = healthyClient.ForHealthyKubelet(5*time.Second, ip); err != nil {
 			cStatus.KubeletErr = err.Error()
+			report.add(ip, "kubelet", StatusFailed, cStatus.KubeletErr)
 		} else {
 			cStatus.KubeletErr = Nil
+			report.add(ip, "kubelet", StatusOK, "")
 		}
 		NodeList = append(NodeList, cStatus)
 	}
 
-	return n.Output(NodeList)
+	report.Detail = NodeList
+	report.output = func() error { return n.Output(NodeList) }
+	return report, nil
 }
 
 func isControlPlaneNode(node corev1.Node) bool {
@@ -132,6 +148,6 @@ Cluster Status
 	return tpl.Execute(os.Stdout, map[string][]ClusterStatus{"ClusterStatusList": clusterStatus})
 }
 
-func NewClusterChecker() Interface {
+func NewClusterChecker() Reporter {
 	return &ClusterChecker{}
 }
@@ -27,6 +27,7 @@ import (
 
 	"github.com/labring/sealos/pkg/template"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
@@ -39,16 +40,24 @@ type CRIShimStatus struct {
 	Error     string
 }
 
-func (n *CRIShimChecker) Check(_ *v2.Cluster, phase string) error {
+func (n *CRIShimChecker) Name() string {
+	return "image-cri-shim"
+}
+
+func (n *CRIShimChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *CRIShimChecker) Report(_ *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
 	status := &CRIShimStatus{}
+	report.Detail = status
+	report.output = func() error { return n.Output(status) }
 	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
-		}
+		report.add(iputils.GetLocalIpv4(), "image-cri-shim", statusOf(status.Error == Nil), status.Error)
 	}()
 
 	if shimCfg, err := types.Unmarshal(types.DefaultImageCRIShimConfig); err != nil {
@@ -74,8 +83,10 @@ func (n *CRIShimChecker) Check(_ *v2.Cluster, phase string) error {
 		}
 	}
 
-	status.Error = Nil
-	return nil
+	if status.Error == "" {
+		status.Error = Nil
+	}
+	return report, nil
 }
 
 func (n *CRIShimChecker) Output(status *CRIShimStatus) error {
@@ -103,6 +114,6 @@ CRIShim Service Status
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewCRIShimChecker() Interface {
+func NewCRIShimChecker() Reporter {
 	return &CRIShimChecker{}
 }
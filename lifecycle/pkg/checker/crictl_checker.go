@@ -34,6 +34,7 @@ import (
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	exec2 "github.com/labring/sealos/pkg/utils/exec"
 	fileutil "github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/iputils"
 	"github.com/labring/sealos/pkg/utils/logger"
 	"github.com/labring/sealos/pkg/utils/yaml"
 )
@@ -58,16 +59,24 @@ type CRICtlStatus struct {
 	Error               string
 }
 
+func (n *CRICtlChecker) Name() string {
+	return "crictl"
+}
+
 func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *CRICtlChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
 	status := &CRICtlStatus{}
+	report.Detail = status
+	report.output = func() error { return n.Output(status) }
 	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
-		}
+		report.add(iputils.GetLocalIpv4(), "cri", statusOf(status.Error == Nil), status.Error)
 	}()
 
 	criShimConfig := "/etc/crictl.yaml"
@@ -83,7 +92,7 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 	crictlPath, err := execer.LookPath("crictl")
 	if err != nil {
 		status.Error = fmt.Errorf("error looking for path of crictl: %w", err).Error()
-		return nil
+		return report, nil
 	}
 
 	imageList, err := n.getCRICtlImageList(crictlPath)
@@ -110,7 +119,7 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 	sshCtx := ssh.NewCacheClientFromCluster(cluster, false)
 	sshCtx, err = exec.New(sshCtx)
 	if err != nil {
-		return err
+		return report, err
 	}
 	root := constants.NewPathResolver(cluster.Name).RootFSPath()
 	regInfo := helpers.GetRegistryInfo(sshCtx, root, cluster.GetRegistryIPAndPort())
@@ -126,8 +135,10 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 		status.Error = fmt.Errorf("pull shim image error: %w", err).Error()
 	}
 	status.ImageShimPullStatus = shimStatus
-	status.Error = Nil
-	return nil
+	if status.Error == "" {
+		status.Error = Nil
+	}
+	return report, nil
 }
 
 func (n *CRICtlChecker) Output(status *CRICtlStatus) error {
@@ -160,7 +171,7 @@ CRI Status
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewCRICtlChecker() Interface {
+func NewCRICtlChecker() Reporter {
 	return &CRICtlChecker{}
 }
 
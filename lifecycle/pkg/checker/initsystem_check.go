@@ -24,9 +24,15 @@ import (
 	"github.com/labring/sealos/pkg/template"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/initsystem"
+	"github.com/labring/sealos/pkg/utils/iputils"
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
+const (
+	serviceNotExists        = "NotExists"
+	serviceEnabledAndActive = "Enable && Active"
+)
+
 type InitSystemChecker struct {
 }
 
@@ -40,35 +46,51 @@ type InitSystemStatus struct {
 	ServiceList []systemStatus
 }
 
-func (n *InitSystemChecker) Check(_ *v2.Cluster, phase string) error {
+func (n *InitSystemChecker) Name() string {
+	return "initsystem"
+}
+
+func (n *InitSystemChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *InitSystemChecker) Report(_ *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
+	node := iputils.GetLocalIpv4()
 	status := &InitSystemStatus{}
+	report.Detail = status
+	report.output = func() error { return n.Output(status) }
 
-	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
-		}
-	}()
 	initsystemvar, err := initsystem.GetInitSystem()
 	if err != nil {
 		status.Error = fmt.Errorf("get initsystem error: %w", err).Error()
-		return nil
+		report.add(node, "initsystem", StatusFailed, status.Error)
+		return report, nil
 	}
 
 	serviceNames := []string{"kubelet", "containerd", "cri-docker", "docker", "registry", "image-cri-shim"}
 	status.ServiceList = make([]systemStatus, 0)
 	for _, sn := range serviceNames {
+		s := n.checkInitSystem(initsystemvar, sn)
 		status.ServiceList = append(status.ServiceList, systemStatus{
 			Name:   sn,
-			Status: n.checkInitSystem(initsystemvar, sn),
+			Status: s,
 		})
+		switch s {
+		case serviceNotExists:
+			report.add(node, sn, StatusSkipped, s)
+		case serviceEnabledAndActive:
+			report.add(node, sn, StatusOK, s)
+		default:
+			report.add(node, sn, StatusFailed, s)
+		}
 	}
 
 	status.Error = Nil
-	return nil
+	return report, nil
 }
 
 func (n *InitSystemChecker) Output(status *InitSystemStatus) error {
@@ -92,13 +114,13 @@ Systemd Service Status
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewInitSystemChecker() Interface {
+func NewInitSystemChecker() Reporter {
 	return &InitSystemChecker{}
 }
 
 func (n *InitSystemChecker) checkInitSystem(system initsystem.InitSystem, name string) (status string) {
 	if !system.ServiceExists(name) {
-		status = "NotExists"
+		status = serviceNotExists
 	} else {
 		var enable, subStatus string
 		if !system.ServiceIsEnabled(name) {
@@ -44,19 +44,28 @@ type NodeClusterStatus struct {
 	NotReadyNodeList []string
 }
 
+func (n *NodeChecker) Name() string {
+	return "node"
+}
+
 func (n *NodeChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *NodeChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
 	// checker if all the node is ready
 	data := constants.NewPathResolver(cluster.Name)
 	c, err := kubernetes.NewKubernetesClient(data.AdminFile(), "")
 	if err != nil {
-		return err
+		return report, err
 	}
 	nodes, err := c.Kubernetes().CoreV1().Nodes().List(context.Background(), v1.ListOptions{})
 	if err != nil {
-		return err
+		return report, err
 	}
 	var notReadyNodeList []string
 	var readyCount uint32
@@ -64,6 +73,7 @@ func (n *NodeChecker) Check(cluster *v2.Cluster, phase string) error {
 	var notReadyCount uint32
 	for _, node := range nodes.Items {
 		nodeIP, nodePhase := getNodeStatus(node)
+		report.add(nodeIP, "node", statusOf(nodePhase == ReadyNodeStatus), nodePhase)
 		if nodePhase != ReadyNodeStatus {
 			notReadyCount++
 			notReadyNodeList = append(notReadyNodeList, nodeIP)
@@ -78,7 +88,9 @@ func (n *NodeChecker) Check(cluster *v2.Cluster, phase string) error {
 		NodeCount:        nodeCount,
 		NotReadyNodeList: notReadyNodeList,
 	}
-	return n.Output(nodeClusterStatus)
+	report.Detail = nodeClusterStatus
+	report.output = func() error { return n.Output(nodeClusterStatus) }
+	return report, nil
 }
 
 func (n *NodeChecker) Output(nodeCLusterStatus NodeClusterStatus) error {
@@ -127,6 +139,6 @@ func getNodeStatus(node corev1.Node) (IP string, Phase string) {
 	return IP, Phase
 }
 
-func NewNodeChecker() Interface {
+func NewNodeChecker() Reporter {
 	return &NodeChecker{}
 }
@@ -17,6 +17,7 @@ package checker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	corev1 "k8s.io/api/core/v1"
@@ -41,26 +42,35 @@ type PodNamespaceStatus struct {
 	NotRunningPodList []*corev1.Pod
 }
 
-var PodNamespaceStatusList []PodNamespaceStatus
+func (n *PodChecker) Name() string {
+	return "pod"
+}
 
 func (n *PodChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *PodChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
 	// checker if all the node is ready
 	data := constants.NewPathResolver(cluster.Name)
 	c, err := kubernetes.NewKubernetesClient(data.AdminFile(), "")
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	n.client = c
 
 	nsList, err := n.client.Kubernetes().CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return err
+		return report, err
 	}
 
+	var podNamespaceStatusList []PodNamespaceStatus
+
 	for _, podNamespace := range nsList.Items {
 		var runningCount uint32
 		var notRunningCount uint32
@@ -68,11 +78,15 @@ func (n *PodChecker) Check(cluster *v2.Cluster, phase string) error {
 		var notRunningPodList []*corev1.Pod
 		namespacePodList, err := n.client.Kubernetes().CoreV1().Pods(podNamespace.Name).List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
-			return err
+			return report, err
 		}
 
 		for _, pod := range namespacePodList.Items {
 			if err := getPodReadyStatus(pod); err != nil {
+				// completed pods of jobs are never ready, they are not a failure
+				if pod.Status.Phase != corev1.PodSucceeded {
+					report.add(pod.Status.HostIP, "pod", StatusFailed, fmt.Sprintf("pod %s/%s is not ready", pod.Namespace, pod.Name))
+				}
 				notRunningCount++
 				newPod := pod
 				notRunningPodList = append(notRunningPodList, &newPod)
@@ -88,9 +102,14 @@ func (n *PodChecker) Check(cluster *v2.Cluster, phase string) error {
 			PodCount:          podCount,
 			NotRunningPodList: notRunningPodList,
 		}
-		PodNamespaceStatusList = append(PodNamespaceStatusList, podNamespaceStatus)
+		podNamespaceStatusList = append(podNamespaceStatusList, podNamespaceStatus)
+		if notRunningCount == 0 {
+			report.add(ClusterScope, "pod", StatusOK, fmt.Sprintf("namespace %s: %d/%d pods ready", podNamespace.Name, runningCount, podCount))
+		}
 	}
-	return n.Output(PodNamespaceStatusList)
+	report.Detail = podNamespaceStatusList
+	report.output = func() error { return n.Output(podNamespaceStatusList) }
+	return report, nil
 }
 
 func (n *PodChecker) Output(podNamespaceStatusList []PodNamespaceStatus) error {
@@ -127,6 +146,6 @@ func getPodReadyStatus(pod corev1.Pod) error {
 	return &NotFindReadyTypeError{}
 }
 
-func NewPodChecker() Interface {
+func NewPodChecker() Reporter {
 	return &PodChecker{}
 }
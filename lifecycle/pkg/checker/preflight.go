@@ -0,0 +1,431 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/ssh"
+	"github.com/labring/sealos/pkg/system"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	PreflightKernelModules = "kernel-modules"
+	PreflightSwap          = "swap"
+	PreflightPorts         = "ports"
+	PreflightClockSkew     = "clock-skew"
+	PreflightDisk          = "disk"
+	PreflightHostname      = "hostname"
+	PreflightCgroupDriver  = "cgroup-driver"
+
+	// PreflightAll skips every preflight check.
+	PreflightAll = "all"
+)
+
+const (
+	maxClockSkewSeconds = 5
+	registryPort        = 5050
+)
+
+var (
+	requiredKernelModules = []string{"br_netfilter", "ip_vs"}
+	masterPorts           = []int{6443, 2379, 10250}
+	nodePorts             = []int{10250}
+)
+
+type hostOutput struct {
+	host string
+	out  string
+}
+
+// preflightCheck runs command on every host and verifies the outputs of all of them together,
+// so that cluster wide problems like duplicate hostnames are reported on each offending host.
+type preflightCheck struct {
+	name    string
+	command func() string
+	verify  func(cluster *v2.Cluster, outputs []hostOutput, report *Report)
+}
+
+var preflightChecks = []preflightCheck{
+	{name: PreflightKernelModules, command: kernelModulesCommand, verify: verifyKernelModules},
+	{name: PreflightSwap, command: func() string { return "awk 'NR>1{print $1}' /proc/swaps" }, verify: verifySwap},
+	{name: PreflightPorts, command: func() string { return "ss -Hltn 2>/dev/null || netstat -ltn 2>/dev/null" }, verify: verifyPorts},
+	{name: PreflightClockSkew, command: func() string { return "date +%s" }, verify: verifyClockSkew},
+	{name: PreflightDisk, command: diskCommand, verify: verifyDisk},
+	{name: PreflightHostname, command: func() string { return "hostname" }, verify: verifyHostname},
+	{name: PreflightCgroupDriver, command: cgroupDriverCommand, verify: verifyCgroupDriver},
+}
+
+// PreflightChecks returns the names of all preflight checks.
+func PreflightChecks() []string {
+	names := make([]string, 0, len(preflightChecks))
+	for _, c := range preflightChecks {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// ValidatePreflightSkips returns an error if any of the names is not a preflight check.
+func ValidatePreflightSkips(names []string) error {
+	known := append(PreflightChecks(), PreflightAll)
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown preflight check %q, must be one of %s", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// PreflightChecker checks that hosts are fit to join a cluster before anything is installed on them.
+type PreflightChecker struct {
+	IPs  []string
+	Skip []string
+
+	execer exec.Interface
+}
+
+func NewPreflightChecker(ips []string, skip []string) Reporter {
+	return &PreflightChecker{IPs: ips, Skip: skip}
+}
+
+func (p *PreflightChecker) Name() string {
+	return "preflight"
+}
+
+func (p *PreflightChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(p, cluster, phase)
+}
+
+func (p *PreflightChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: p.Name()}
+	if phase != PhasePre {
+		return report, nil
+	}
+	if err := ValidatePreflightSkips(p.Skip); err != nil {
+		return nil, err
+	}
+	execer := p.execer
+	if execer == nil {
+		var err error
+		if execer, err = exec.New(ssh.NewCacheClientFromCluster(cluster, false)); err != nil {
+			return nil, err
+		}
+	}
+	logger.Info("checker:preflight %v", p.IPs)
+	for _, c := range preflightChecks {
+		if slices.Contains(p.Skip, PreflightAll) || slices.Contains(p.Skip, c.name) {
+			report.add(ClusterScope, c.name, StatusSkipped, "skipped by user")
+			continue
+		}
+		outputs := make([]hostOutput, 0, len(p.IPs))
+		for _, r := range runOnHosts(execer, p.IPs, c.command()) {
+			if r.err != nil {
+				report.add(iputils.GetHostIP(r.host), c.name, StatusFailed, fmt.Sprintf("failed to run check: %v", r.err))
+				continue
+			}
+			outputs = append(outputs, hostOutput{host: r.host, out: r.out})
+		}
+		c.verify(cluster, outputs, report)
+	}
+	report.output = func() error {
+		for _, r := range report.Results {
+			if r.Status == StatusFailed {
+				logger.Error("preflight %s on %s: %s", r.Component, r.Node, r.Message)
+			}
+		}
+		return nil
+	}
+	return report, nil
+}
+
+type hostResult struct {
+	host string
+	out  string
+	err  error
+}
+
+// runOnHosts runs the command on all hosts in parallel and keeps the order of hosts.
+func runOnHosts(execer exec.Interface, hosts []string, command string) []hostResult {
+	ret := make([]hostResult, len(hosts))
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			out, err := execer.Cmd(hosts[i], command)
+			ret[i] = hostResult{host: hosts[i], out: strings.TrimSpace(string(out)), err: err}
+		}(i)
+	}
+	wg.Wait()
+	return ret
+}
+
+func kernelModulesCommand() string {
+	return fmt.Sprintf("for m in %s; do [ -d /sys/module/$m ] || modinfo $m >/dev/null 2>&1 || echo $m; done",
+		strings.Join(requiredKernelModules, " "))
+}
+
+func verifyKernelModules(_ *v2.Cluster, outputs []hostOutput, report *Report) {
+	for _, o := range outputs {
+		if o.out != "" {
+			report.add(iputils.GetHostIP(o.host), PreflightKernelModules, StatusFailed,
+				fmt.Sprintf("kernel modules are not available: %s", strings.Join(strings.Fields(o.out), ", ")))
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightKernelModules, StatusOK, "")
+	}
+}
+
+func verifySwap(_ *v2.Cluster, outputs []hostOutput, report *Report) {
+	for _, o := range outputs {
+		if o.out != "" {
+			report.add(iputils.GetHostIP(o.host), PreflightSwap, StatusFailed,
+				fmt.Sprintf("swap is enabled on %s, turn it off with swapoff -a", strings.Join(strings.Fields(o.out), ", ")))
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightSwap, StatusOK, "")
+	}
+}
+
+func requiredPorts(cluster *v2.Cluster, host string) []int {
+	var ports []int
+	if slices.Contains(cluster.GetMasterIPAndPortList(), host) {
+		ports = append(ports, masterPorts...)
+	} else {
+		ports = append(ports, nodePorts...)
+	}
+	if slices.Contains(cluster.GetRegistryIPAndPortList(), host) {
+		ports = append(ports, registryPort)
+	}
+	return ports
+}
+
+// listeningPorts parses the local address column of `ss -Hltn` or `netstat -ltn`, headers are ignored.
+func listeningPorts(out string) map[int]bool {
+	ports := map[int]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		addr := fields[3]
+		idx := strings.LastIndex(addr, ":")
+		if idx < 0 {
+			continue
+		}
+		if port, err := strconv.Atoi(addr[idx+1:]); err == nil {
+			ports[port] = true
+		}
+	}
+	return ports
+}
+
+func verifyPorts(cluster *v2.Cluster, outputs []hostOutput, report *Report) {
+	for _, o := range outputs {
+		listening := listeningPorts(o.out)
+		var conflicts []string
+		for _, port := range requiredPorts(cluster, o.host) {
+			if listening[port] {
+				conflicts = append(conflicts, strconv.Itoa(port))
+			}
+		}
+		if len(conflicts) > 0 {
+			report.add(iputils.GetHostIP(o.host), PreflightPorts, StatusFailed,
+				fmt.Sprintf("ports are already in use: %s", strings.Join(conflicts, ", ")))
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightPorts, StatusOK, "")
+	}
+}
+
+// verifyClockSkew compares the clock of every host with the first one, which is master0 on create.
+func verifyClockSkew(_ *v2.Cluster, outputs []hostOutput, report *Report) {
+	var base int64
+	for i, o := range outputs {
+		ts, err := strconv.ParseInt(o.out, 10, 64)
+		if err != nil {
+			report.add(iputils.GetHostIP(o.host), PreflightClockSkew, StatusFailed, fmt.Sprintf("invalid timestamp %q", o.out))
+			continue
+		}
+		if i == 0 {
+			base = ts
+		}
+		if skew := ts - base; math.Abs(float64(skew)) > maxClockSkewSeconds {
+			report.add(iputils.GetHostIP(o.host), PreflightClockSkew, StatusFailed,
+				fmt.Sprintf("clock differs from %s by %ds, at most %ds is allowed", iputils.GetHostIP(outputs[0].host), skew, maxClockSkewSeconds))
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightClockSkew, StatusOK, "")
+	}
+}
+
+// diskCommand prints the free KiB of the file system holding the data root, or its closest existing parent.
+func diskCommand() string {
+	return fmt.Sprintf(`d=%s; while [ ! -d "$d" ]; do d=$(dirname "$d"); done; df -Pk "$d" | awk 'NR==2{print $4}'`,
+		constants.DefaultClusterRootFsDir)
+}
+
+func minFreeDiskGiB() int64 {
+	v, _ := system.Get(system.PreflightMinFreeDiskConfigKey)
+	gib, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		logger.Warn("invalid %s %q, using 10", system.PreflightMinFreeDiskConfigKey, v)
+		return 10
+	}
+	return gib
+}
+
+func verifyDisk(_ *v2.Cluster, outputs []hostOutput, report *Report) {
+	minGiB := minFreeDiskGiB()
+	for _, o := range outputs {
+		kib, err := strconv.ParseInt(o.out, 10, 64)
+		if err != nil {
+			report.add(iputils.GetHostIP(o.host), PreflightDisk, StatusFailed, fmt.Sprintf("invalid free disk size %q", o.out))
+			continue
+		}
+		if free := kib >> 20; free < minGiB {
+			report.add(iputils.GetHostIP(o.host), PreflightDisk, StatusFailed,
+				fmt.Sprintf("%dGiB free under %s, at least %dGiB is required", free, constants.DefaultClusterRootFsDir, minGiB))
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightDisk, StatusOK, "")
+	}
+}
+
+func verifyHostname(_ *v2.Cluster, outputs []hostOutput, report *Report) {
+	hosts := map[string][]string{}
+	for _, o := range outputs {
+		name := strings.ToLower(o.out)
+		hosts[name] = append(hosts[name], iputils.GetHostIP(o.host))
+	}
+	for _, o := range outputs {
+		same := hosts[strings.ToLower(o.out)]
+		if len(same) > 1 {
+			others := make([]string, 0, len(same)-1)
+			for _, ip := range same {
+				if ip != iputils.GetHostIP(o.host) {
+					others = append(others, ip)
+				}
+			}
+			sort.Strings(others)
+			report.add(iputils.GetHostIP(o.host), PreflightHostname, StatusFailed,
+				fmt.Sprintf("hostname %s is also used by %s", o.out, strings.Join(others, ", ")))
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightHostname, StatusOK, "")
+	}
+}
+
+const (
+	cgroupDriverSystemd  = "systemd"
+	cgroupDriverCgroupfs = "cgroupfs"
+
+	containerdConfigFile = "/etc/containerd/config.toml"
+	kubeletConfigFile    = "/var/lib/kubelet/config.yaml"
+)
+
+// cgroupDriverCommand prints the init system, the SystemdCgroup option of an existing containerd
+// and the cgroupDriver of an existing kubelet as key=value lines, the last two only if configured.
+func cgroupDriverCommand() string {
+	return fmt.Sprintf(`if [ -d /run/systemd/system ]; then echo init=%[1]s; else echo init=%[2]s; fi
+[ -f %[3]s ] && awk -F= '$1 ~ /^[ \t]*SystemdCgroup[ \t]*$/ {gsub(/[ \t"]/, "", $2); v=$2} END {if (v != "") print "containerd=" v}' %[3]s
+[ -f %[4]s ] && awk '$1 == "cgroupDriver:" {print "kubelet=" $2}' %[4]s
+true`, cgroupDriverSystemd, cgroupDriverCgroupfs, containerdConfigFile, kubeletConfigFile)
+}
+
+// hostCgroupDriver is what a host is configured with, empty if it is not configured.
+type hostCgroupDriver struct {
+	init       string
+	containerd string
+	kubelet    string
+}
+
+func parseCgroupDriver(out string) hostCgroupDriver {
+	var d hostCgroupDriver
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		switch key {
+		case "init":
+			d.init = value
+		case "containerd":
+			switch value {
+			case "true":
+				d.containerd = cgroupDriverSystemd
+			case "false":
+				d.containerd = cgroupDriverCgroupfs
+			}
+		case "kubelet":
+			d.kubelet = value
+		}
+	}
+	return d
+}
+
+// driver returns the cgroup driver the host runs with, a configured container runtime or
+// kubelet takes precedence over the init system.
+func (d hostCgroupDriver) driver() string {
+	switch {
+	case d.containerd != "":
+		return d.containerd
+	case d.kubelet != "":
+		return d.kubelet
+	default:
+		return d.init
+	}
+}
+
+// verifyCgroupDriver makes sure the container runtime and kubelet of every host agree on the
+// cgroup driver, and all hosts use the same one, the kubelet configuration is rendered once
+// with the driver detected on master0.
+func verifyCgroupDriver(_ *v2.Cluster, outputs []hostOutput, report *Report) {
+	if len(outputs) == 0 {
+		return
+	}
+	want := parseCgroupDriver(outputs[0].out).driver()
+	for _, o := range outputs {
+		d := parseCgroupDriver(o.out)
+		var msg string
+		switch {
+		case d.containerd != "" && d.kubelet != "" && d.containerd != d.kubelet:
+			msg = fmt.Sprintf("containerd uses the %s cgroup driver, but kubelet is configured with %s in %s",
+				d.containerd, d.kubelet, kubeletConfigFile)
+		case d.driver() == cgroupDriverSystemd && d.init != cgroupDriverSystemd:
+			msg = "the systemd cgroup driver is configured, but systemd is not the init system"
+		case d.driver() != want:
+			msg = fmt.Sprintf("cgroup driver is %s, but %s on %s", d.driver(), want, iputils.GetHostIP(outputs[0].host))
+		}
+		if msg != "" {
+			report.add(iputils.GetHostIP(o.host), PreflightCgroupDriver, StatusFailed, msg)
+			continue
+		}
+		report.add(iputils.GetHostIP(o.host), PreflightCgroupDriver, StatusOK, "")
+	}
+}
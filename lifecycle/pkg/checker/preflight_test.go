@@ -0,0 +1,196 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos/pkg/exec"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+// fakeExec answers commands by the first word of the command.
+type fakeExec struct {
+	exec.Interface
+	outputs map[string]map[string]string
+}
+
+func (f *fakeExec) Cmd(host, cmd string) ([]byte, error) {
+	for prefix, out := range f.outputs[host] {
+		if strings.HasPrefix(cmd, prefix) {
+			return []byte(out), nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected command %q on %s", cmd, host)
+}
+
+func healthyHost(hostname string) map[string]string {
+	return map[string]string{
+		"for m in": "",
+		"awk":      "",
+		"ss":       "LISTEN 0 128 0.0.0.0:22 0.0.0.0:*\n",
+		"date":     "1700000000\n",
+		"d=":       "104857600\n",
+		"hostname": hostname + "\n",
+		"if [":     "init=systemd\n",
+	}
+}
+
+func TestPreflightChecker_Report(t *testing.T) {
+	cluster := &v2.Cluster{Spec: v2.ClusterSpec{Hosts: []v2.Host{
+		{IPS: []string{"10.0.0.1:22"}, Roles: []string{v2.MASTER}},
+		{IPS: []string{"10.0.0.2:22", "10.0.0.3:22"}, Roles: []string{v2.NODE}},
+	}}}
+	ips := []string{"10.0.0.1:22", "10.0.0.2:22", "10.0.0.3:22"}
+
+	newExec := func() *fakeExec {
+		return &fakeExec{outputs: map[string]map[string]string{
+			"10.0.0.1:22": healthyHost("master"),
+			"10.0.0.2:22": healthyHost("node-1"),
+			"10.0.0.3:22": healthyHost("node-2"),
+		}}
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		report, err := (&PreflightChecker{IPs: ips, execer: newExec()}).Report(cluster, PhasePre)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range report.Results {
+			if r.Status != StatusOK {
+				t.Errorf("unexpected result %+v", r)
+			}
+		}
+		if want := len(ips) * len(preflightChecks); len(report.Results) != want {
+			t.Errorf("got %d results, want %d", len(report.Results), want)
+		}
+	})
+
+	t.Run("failures of all hosts", func(t *testing.T) {
+		fake := newExec()
+		fake.outputs["10.0.0.1:22"]["ss"] = "LISTEN 0 4096 *:6443 *:*\nLISTEN 0 4096 127.0.0.1:2379 0.0.0.0:*\n"
+		fake.outputs["10.0.0.1:22"]["d="] = "1048576\n"
+		fake.outputs["10.0.0.2:22"]["for m in"] = "ip_vs\n"
+		fake.outputs["10.0.0.2:22"]["awk"] = "/dev/dm-1\n"
+		fake.outputs["10.0.0.3:22"]["hostname"] = "NODE-1\n"
+		fake.outputs["10.0.0.3:22"]["date"] = "1700000030\n"
+		fake.outputs["10.0.0.3:22"]["if ["] = "init=cgroupfs\n"
+
+		report, err := (&PreflightChecker{IPs: ips, execer: fake}).Report(cluster, PhasePre)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range report.Results {
+			if r.Status == StatusFailed {
+				got = append(got, r.Node+"/"+r.Component)
+			}
+		}
+		sort.Strings(got)
+		want := []string{
+			"10.0.0.1/disk", "10.0.0.1/ports",
+			"10.0.0.2/hostname", "10.0.0.2/kernel-modules", "10.0.0.2/swap",
+			"10.0.0.3/cgroup-driver", "10.0.0.3/clock-skew", "10.0.0.3/hostname",
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("failures = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		fake := newExec()
+		fake.outputs["10.0.0.2:22"]["awk"] = "/dev/dm-1\n"
+		report, err := (&PreflightChecker{IPs: ips, Skip: []string{PreflightSwap}, execer: fake}).Report(cluster, PhasePre)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range report.Results {
+			if r.Component == PreflightSwap && r.Status != StatusSkipped {
+				t.Errorf("swap check should be skipped, got %+v", r)
+			}
+		}
+	})
+
+	t.Run("unknown skip", func(t *testing.T) {
+		if _, err := (&PreflightChecker{IPs: ips, Skip: []string{"selinux"}, execer: newExec()}).Report(cluster, PhasePre); err == nil {
+			t.Error("expected an error for an unknown preflight check")
+		}
+	})
+}
+
+func TestVerifyCgroupDriver(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []string
+		want    []ResultStatus
+	}{
+		{
+			name:    "init system only",
+			outputs: []string{"init=systemd", "init=systemd"},
+			want:    []ResultStatus{StatusOK, StatusOK},
+		},
+		{
+			name:    "different init systems",
+			outputs: []string{"init=systemd", "init=cgroupfs"},
+			want:    []ResultStatus{StatusOK, StatusFailed},
+		},
+		{
+			name:    "containerd takes precedence over the init system",
+			outputs: []string{"init=systemd\ncontainerd=false", "init=cgroupfs"},
+			want:    []ResultStatus{StatusOK, StatusOK},
+		},
+		{
+			name:    "containerd differs from master0",
+			outputs: []string{"init=systemd", "init=systemd\ncontainerd=false"},
+			want:    []ResultStatus{StatusOK, StatusFailed},
+		},
+		{
+			name:    "containerd and kubelet disagree",
+			outputs: []string{"init=systemd\ncontainerd=true\nkubelet=cgroupfs", "init=systemd"},
+			want:    []ResultStatus{StatusFailed, StatusOK},
+		},
+		{
+			name:    "containerd and kubelet agree",
+			outputs: []string{"init=systemd\ncontainerd=true\nkubelet=systemd", "init=systemd\nkubelet=systemd"},
+			want:    []ResultStatus{StatusOK, StatusOK},
+		},
+		{
+			name:    "systemd driver without systemd",
+			outputs: []string{"init=cgroupfs\nkubelet=systemd"},
+			want:    []ResultStatus{StatusFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs := make([]hostOutput, 0, len(tt.outputs))
+			for i, out := range tt.outputs {
+				outputs = append(outputs, hostOutput{host: fmt.Sprintf("10.0.0.%d:22", i+1), out: out})
+			}
+			report := &Report{}
+			verifyCgroupDriver(nil, outputs, report)
+			if len(report.Results) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(report.Results), len(tt.want))
+			}
+			for i, r := range report.Results {
+				if r.Status != tt.want[i] {
+					t.Errorf("host %d: status = %s (%s), want %s", i, r.Status, r.Message, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -48,28 +48,38 @@ type RegistryStatus struct {
 	DebugPort      string
 	Storage        string
 	Delete         bool
-	Htpasswd       string
+	Htpasswd       string `json:"-"`
 	RegistryDomain string
-	Auth           string
+	Auth           string `json:"-"`
 	Ping           string
 	Error          string
 }
 
+func (n *RegistryChecker) Name() string {
+	return "registry"
+}
+
 func (n *RegistryChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *RegistryChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
+	node := cluster.GetRegistryIP()
 	localAddr, _ := iputils.ListLocalHostAddrs()
-	if !iputils.IsLocalIP(cluster.GetRegistryIP(), localAddr) {
-		logger.Info("current registry ip is %s,not local addr,skip check.", cluster.GetRegistryIP())
-		return nil
+	if !iputils.IsLocalIP(node, localAddr) {
+		logger.Debug("current registry ip is %s,not local addr,skip check.", node)
+		report.add(node, "registry", StatusSkipped, "registry is not running on local host")
+		return report, nil
 	}
 	status := &RegistryStatus{}
+	report.Detail = status
+	report.output = func() error { return n.Output(status) }
 	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
-		}
+		report.add(node, "registry", statusOf(status.Error == Nil), status.Error)
 	}()
 
 	registryConfig := "/etc/registry/registry_config.yml"
@@ -91,7 +101,8 @@ func (n *RegistryChecker) Check(cluster *v2.Cluster, phase string) error {
 	sshCtx := ssh.NewCacheClientFromCluster(cluster, false)
 	execer, err := exec.New(sshCtx)
 	if err != nil {
-		return err
+		status.Error = err.Error()
+		return report, nil
 	}
 	root := constants.NewPathResolver(cluster.Name).RootFSPath()
 	regInfo := helpers.GetRegistryInfo(execer, root, cluster.GetRegistryIPAndPort())
@@ -104,11 +115,13 @@ func (n *RegistryChecker) Check(cluster *v2.Cluster, phase string) error {
 	_, err = crane.NewRegistry(status.RegistryDomain, cfg)
 	if err != nil {
 		status.Error = fmt.Errorf("get registry interface error: %w", err).Error()
-		return nil
+		return report, nil
 	}
 	status.Ping = "ok"
-	status.Error = Nil
-	return nil
+	if status.Error == "" {
+		status.Error = Nil
+	}
+	return report, nil
 }
 
 func (n *RegistryChecker) Output(status *RegistryStatus) error {
@@ -134,6 +147,6 @@ func (n *RegistryChecker) Output(status *RegistryStatus) error {
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewRegistryChecker() Interface {
+func NewRegistryChecker() Reporter {
 	return &RegistryChecker{}
 }
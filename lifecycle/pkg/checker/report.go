@@ -0,0 +1,144 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+type ResultStatus string
+
+const (
+	StatusOK      ResultStatus = "ok"
+	StatusFailed  ResultStatus = "failed"
+	StatusSkipped ResultStatus = "skipped"
+)
+
+// ClusterScope is the node name of results which do not belong to a single node.
+const ClusterScope = "cluster"
+
+// Result is the outcome of checking one component on one node.
+type Result struct {
+	Node      string       `json:"node"`
+	Component string       `json:"component"`
+	Checker   string       `json:"checker"`
+	Status    ResultStatus `json:"status"`
+	Message   string       `json:"message,omitempty"`
+}
+
+// Report is what a checker returns: the flattened results plus its own typed status.
+type Report struct {
+	Checker string      `json:"checker"`
+	Results []Result    `json:"results"`
+	Detail  interface{} `json:"detail,omitempty"`
+
+	output func() error
+}
+
+// Print renders the report with the text template of its checker.
+func (r *Report) Print() error {
+	if r.output == nil {
+		return nil
+	}
+	return r.output()
+}
+
+func (r *Report) add(node, component string, status ResultStatus, message string) {
+	if node == "" {
+		node = ClusterScope
+	}
+	r.Results = append(r.Results, Result{
+		Node:      node,
+		Component: component,
+		Checker:   r.Checker,
+		Status:    status,
+		Message:   message,
+	})
+}
+
+// Reporter is implemented by checkers which can return typed results instead of only printing them.
+type Reporter interface {
+	Interface
+	Name() string
+	Report(cluster *v2.Cluster, phase string) (*Report, error)
+}
+
+// StatusReport aggregates the reports of all checkers, grouped by node and component.
+type StatusReport struct {
+	Cluster  string                         `json:"cluster"`
+	Healthy  bool                           `json:"healthy"`
+	Nodes    map[string]map[string][]Result `json:"nodes"`
+	Checkers []*Report                      `json:"checkers"`
+}
+
+// RunReportList runs every reporter, a failing reporter does not stop the others.
+func RunReportList(list []Reporter, cluster *v2.Cluster, phase string) *StatusReport {
+	ret := &StatusReport{
+		Cluster: cluster.GetName(),
+		Healthy: true,
+		Nodes:   map[string]map[string][]Result{},
+	}
+	for _, l := range list {
+		report, err := l.Report(cluster, phase)
+		if report == nil {
+			report = &Report{Checker: l.Name()}
+		}
+		if err != nil {
+			report.add(ClusterScope, l.Name(), StatusFailed, fmt.Sprintf("failed to run checker: %v", err))
+		}
+		ret.Checkers = append(ret.Checkers, report)
+		for _, r := range report.Results {
+			if r.Status == StatusFailed {
+				ret.Healthy = false
+			}
+			if ret.Nodes[r.Node] == nil {
+				ret.Nodes[r.Node] = map[string][]Result{}
+			}
+			ret.Nodes[r.Node][r.Component] = append(ret.Nodes[r.Node][r.Component], r)
+		}
+	}
+	return ret
+}
+
+// Failures returns all failed results.
+func (s *StatusReport) Failures() []Result {
+	var ret []Result
+	for _, c := range s.Checkers {
+		for _, r := range c.Results {
+			if r.Status == StatusFailed {
+				ret = append(ret, r)
+			}
+		}
+	}
+	return ret
+}
+
+func statusOf(ok bool) ResultStatus {
+	if ok {
+		return StatusOK
+	}
+	return StatusFailed
+}
+
+// checkWithReport implements Interface for reporters by printing the report as text.
+func checkWithReport(r Reporter, cluster *v2.Cluster, phase string) error {
+	report, err := r.Report(cluster, phase)
+	if err != nil {
+		return err
+	}
+	return report.Print()
+}
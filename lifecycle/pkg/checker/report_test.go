@@ -0,0 +1,80 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"errors"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+type fakeReporter struct {
+	name    string
+	results []Result
+	err     error
+}
+
+func (f *fakeReporter) Name() string { return f.name }
+
+func (f *fakeReporter) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(f, cluster, phase)
+}
+
+func (f *fakeReporter) Report(_ *v2.Cluster, _ string) (*Report, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	report := &Report{Checker: f.name}
+	for _, r := range f.results {
+		report.add(r.Node, r.Component, r.Status, r.Message)
+	}
+	return report, nil
+}
+
+func TestRunReportList(t *testing.T) {
+	cluster := &v2.Cluster{}
+	cluster.Name = "default"
+
+	healthy := RunReportList([]Reporter{
+		&fakeReporter{name: "node", results: []Result{
+			{Node: "10.0.0.1", Component: "node", Status: StatusOK},
+			{Node: "10.0.0.2", Component: "node", Status: StatusOK},
+		}},
+		&fakeReporter{name: "registry", results: []Result{
+			{Component: "registry", Status: StatusSkipped},
+		}},
+	}, cluster, PhasePost)
+	if !healthy.Healthy {
+		t.Fatalf("expected healthy report, got failures %+v", healthy.Failures())
+	}
+	if len(healthy.Nodes) != 3 || len(healthy.Nodes[ClusterScope]["registry"]) != 1 {
+		t.Fatalf("unexpected grouping: %+v", healthy.Nodes)
+	}
+
+	unhealthy := RunReportList([]Reporter{
+		&fakeReporter{name: "node", results: []Result{
+			{Node: "10.0.0.1", Component: "node", Status: StatusFailed, Message: "NotReady"},
+		}},
+		&fakeReporter{name: "pod", err: errors.New("connection refused")},
+	}, cluster, PhasePost)
+	if unhealthy.Healthy {
+		t.Fatal("expected unhealthy report")
+	}
+	failures := unhealthy.Failures()
+	if len(failures) != 2 || failures[1].Checker != "pod" || failures[1].Node != ClusterScope {
+		t.Fatalf("unexpected failures: %+v", failures)
+	}
+}
@@ -17,6 +17,7 @@ package checker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/labring/sealos/pkg/template"
@@ -46,15 +47,24 @@ type SvcClusterStatus struct {
 	SvcNamespaceStatusList []*SvcNamespaceStatus
 }
 
+func (n *SvcChecker) Name() string {
+	return "service"
+}
+
 func (n *SvcChecker) Check(cluster *v2.Cluster, phase string) error {
+	return checkWithReport(n, cluster, phase)
+}
+
+func (n *SvcChecker) Report(cluster *v2.Cluster, phase string) (*Report, error) {
+	report := &Report{Checker: n.Name()}
 	if phase != PhasePost {
-		return nil
+		return report, nil
 	}
 	// checker if all the node is ready
 	data := constants.NewPathResolver(cluster.Name)
 	c, err := kubernetes.NewKubernetesClient(data.AdminFile(), "")
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	n.client = c
@@ -63,21 +73,20 @@ func (n *SvcChecker) Check(cluster *v2.Cluster, phase string) error {
 
 	nsList, err := n.client.Kubernetes().CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	var svcNamespaceStatusList []*SvcNamespaceStatus
-	if err != nil {
-		return err
-	}
 	for _, svcNamespace := range nsList.Items {
 		namespaceSVCList, err := n.client.Kubernetes().CoreV1().Services(svcNamespace.Name).List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
+			report.add(ClusterScope, "service", StatusFailed, fmt.Sprintf("failed to list services in namespace %s: %v", svcNamespace.Name, err))
 			break
 		}
 
 		namespaceEPList, err := n.client.Kubernetes().CoreV1().Endpoints(svcNamespace.Name).List(context.TODO(), metav1.ListOptions{})
 		if err != nil {
+			report.add(ClusterScope, "service", StatusFailed, fmt.Sprintf("failed to list endpoints in namespace %s: %v", svcNamespace.Name, err))
 			break
 		}
 
@@ -85,11 +94,18 @@ func (n *SvcChecker) Check(cluster *v2.Cluster, phase string) error {
 		var unhaelthService []string
 		var endpointCount = 0
 
+		var failedCount = 0
+
 		for _, service := range namespaceSVCList.Items {
 			if IsExistEndpoint(namespaceEPList, service.Name) {
 				endpointCount++
 			} else {
 				unhaelthService = append(unhaelthService, service.Name)
+				// services without selector manage their endpoints by themselves
+				if service.Spec.Type != corev1.ServiceTypeExternalName && len(service.Spec.Selector) > 0 {
+					failedCount++
+					report.add(ClusterScope, "service", StatusFailed, fmt.Sprintf("service %s/%s has no endpoints", svcNamespace.Name, service.Name))
+				}
 			}
 		}
 		svcNamespaceStatus := SvcNamespaceStatus{
@@ -99,8 +115,13 @@ func (n *SvcChecker) Check(cluster *v2.Cluster, phase string) error {
 			UnhealthServiceList: unhaelthService,
 		}
 		svcNamespaceStatusList = append(svcNamespaceStatusList, &svcNamespaceStatus)
+		if failedCount == 0 {
+			report.add(ClusterScope, "service", StatusOK, fmt.Sprintf("namespace %s: %d/%d services healthy", svcNamespace.Name, endpointCount, serviceCount))
+		}
 	}
-	return n.Output(svcNamespaceStatusList)
+	report.Detail = svcNamespaceStatusList
+	report.output = func() error { return n.Output(svcNamespaceStatusList) }
+	return report, nil
 }
 
 func (n *SvcChecker) Output(svcNamespaceStatusList []*SvcNamespaceStatus) error {
@@ -135,6 +156,6 @@ func IsExistEndpoint(endpointList *corev1.EndpointsList, serviceName string) boo
 	return false
 }
 
-func NewSvcChecker() Interface {
+func NewSvcChecker() Reporter {
 	return &SvcChecker{}
 }
@@ -18,17 +18,22 @@ import (
 	"bytes"
 	"errors"
 
+	"golang.org/x/exp/slices"
 	"helm.sh/helm/v3/pkg/cli/values"
 	"helm.sh/helm/v3/pkg/getter"
 
 	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/runtime"
 	"github.com/labring/sealos/pkg/runtime/decode"
+	"github.com/labring/sealos/pkg/runtime/factory"
 	"github.com/labring/sealos/pkg/runtime/k3s"
 	"github.com/labring/sealos/pkg/runtime/kubernetes/types"
+	"github.com/labring/sealos/pkg/runtime/rke2"
 	"github.com/labring/sealos/pkg/template"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	fileutil "github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/yaml"
 )
 
 var ErrClusterFileNotExists = errors.New("the cluster file is not exist")
@@ -148,9 +153,21 @@ func (c *ClusterFile) DecodeConfigs(data []byte) error {
 }
 
 func (c *ClusterFile) DecodeRuntimeConfig(data []byte) error {
-	// TODO: handling more types of runtime configuration
-	cfg, _ := k3s.ParseConfig(data)
-	if cfg != nil {
+	if c.cluster != nil {
+		if distribution := c.cluster.GetDistribution(); distribution != "" {
+			cfg, err := factory.ParseRuntimeConfig(distribution, data)
+			if err != nil {
+				return err
+			}
+			if cfg == nil {
+				return ErrTypeNotFound
+			}
+			c.runtimeConfig = cfg
+			return nil
+		}
+	}
+	// the distribution is unknown before the rootfs image is mounted, guess it from the data
+	if cfg := parseRancherConfig(data); cfg != nil {
 		c.runtimeConfig = cfg
 	} else {
 		kubeadmConfig, err := types.LoadKubeadmConfigs(string(data), false, decode.CRDFromString)
@@ -164,3 +181,39 @@ func (c *ClusterFile) DecodeRuntimeConfig(data []byte) error {
 	}
 	return nil
 }
+
+// parseRancherConfig returns the k3s or rke2 configuration in data, or nil if there is none.
+// The two share most options, data is taken as rke2 if it labels the nodes as rke2 or sets
+// more options known to rke2 than to k3s.
+func parseRancherConfig(data []byte) runtime.Config {
+	k3sCfg, _ := k3s.ParseConfig(data)
+	rke2Cfg, _ := rke2.ParseConfig(data)
+	switch {
+	case rke2Cfg == nil && k3sCfg == nil:
+		return nil
+	case rke2Cfg == nil:
+		return k3sCfg
+	case k3sCfg == nil:
+		return rke2Cfg
+	}
+	if rke2Cfg.AgentConfig != nil && slices.Contains(rke2Cfg.Labels, rke2.NodeLabel) {
+		return rke2Cfg
+	}
+	if countOptions(rke2Cfg) > countOptions(k3sCfg) {
+		return rke2Cfg
+	}
+	return k3sCfg
+}
+
+// countOptions returns the number of options set in cfg.
+func countOptions(cfg any) int {
+	out, err := yaml.Marshal(cfg)
+	if err != nil {
+		return 0
+	}
+	m, err := yaml.UnmarshalToMap(out)
+	if err != nil {
+		return 0
+	}
+	return len(m)
+}
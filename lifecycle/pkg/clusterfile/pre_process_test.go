@@ -0,0 +1,69 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterfile
+
+import (
+	"testing"
+
+	"github.com/labring/sealos/pkg/runtime/k3s"
+	"github.com/labring/sealos/pkg/runtime/rke2"
+)
+
+func TestDecodeRuntimeConfigWithoutDistribution(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "options shared by k3s and rke2",
+			data: "tls-san:\n- 10.0.0.1\ncluster-cidr:\n- 10.42.0.0/16\n",
+			want: k3s.Distribution,
+		},
+		{
+			name: "k3s only options",
+			data: "flannel-backend: wireguard\ntls-san:\n- 10.0.0.1\n",
+			want: k3s.Distribution,
+		},
+		{
+			name: "rke2 only options",
+			data: "cni:\n- cilium\ntls-san:\n- 10.0.0.1\n",
+			want: rke2.Distribution,
+		},
+		{
+			name: "nodes labeled as rke2",
+			data: "tls-san:\n- 10.0.0.1\nnode-label:\n- " + rke2.NodeLabel + "\n",
+			want: rke2.Distribution,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ClusterFile{}
+			if err := c.DecodeRuntimeConfig([]byte(tt.data)); err != nil {
+				t.Fatalf("DecodeRuntimeConfig() error = %v", err)
+			}
+			var got string
+			switch c.runtimeConfig.(type) {
+			case *k3s.Config:
+				got = k3s.Distribution
+			case *rke2.Config:
+				got = rke2.Distribution
+			}
+			if got != tt.want {
+				t.Errorf("DecodeRuntimeConfig() decoded %T, want a config of %s", c.runtimeConfig, tt.want)
+			}
+		})
+	}
+}
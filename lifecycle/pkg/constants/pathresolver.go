@@ -109,6 +109,10 @@ type PathResolver interface {
 	PkiPath() string
 	PkiEtcdPath() string
 	AdminFile() string
+	KnownHostsFile() string
+	HistoryPath() string
+	ExecRunsPath() string
+	EtcdBackupsPath() string
 	EtcPath() string
 	TmpPath() string
 }
@@ -172,6 +176,26 @@ func (d *defaultPathResolver) AdminFile() string {
 	return filepath.Join(d.EtcPath(), "admin.conf")
 }
 
+// $HOME/.$APP_NAME/$CLUSTER_NAME/etc/known_hosts
+func (d *defaultPathResolver) KnownHostsFile() string {
+	return filepath.Join(d.EtcPath(), "known_hosts")
+}
+
+// $HOME/.$APP_NAME/$CLUSTER_NAME/history
+func (d *defaultPathResolver) HistoryPath() string {
+	return filepath.Join(d.RunRoot(), "history")
+}
+
+// $HOME/.$APP_NAME/$CLUSTER_NAME/exec, output of `sealos exec` runs
+func (d *defaultPathResolver) ExecRunsPath() string {
+	return filepath.Join(d.RunRoot(), "exec")
+}
+
+// $HOME/.$APP_NAME/$CLUSTER_NAME/etcd-backups, snapshots taken by `sealos etcd backup`
+func (d *defaultPathResolver) EtcdBackupsPath() string {
+	return filepath.Join(d.RunRoot(), "etcd-backups")
+}
+
 func (d *defaultPathResolver) PkiPath() string {
 	return filepath.Join(d.RunRoot(), PkiDirName)
 }
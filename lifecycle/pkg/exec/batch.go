@@ -0,0 +1,247 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+type HostStatus string
+
+const (
+	HostSucceeded HostStatus = "succeeded"
+	HostFailed    HostStatus = "failed"
+	// HostSkipped hosts were not run because an earlier host failed.
+	HostSkipped HostStatus = "skipped"
+)
+
+// HostResult is the outcome of running on a single host of a batch.
+type HostResult struct {
+	Host   string     `json:"host"`
+	Status HostStatus `json:"status"`
+	// ExitCode is -1 if the host did not run to an exit status, like a connection failure
+	ExitCode int    `json:"exitCode"`
+	Duration string `json:"duration"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BatchOptions struct {
+	// Parallel is the max number of hosts run at the same time, no limit if it is not positive.
+	Parallel int
+	// ContinueOnError keeps starting hosts after one failed, otherwise the hosts not started yet are skipped.
+	ContinueOnError bool
+	// OutputDir captures stdout and stderr of every host into <host>.stdout and <host>.stderr under it if set.
+	OutputDir string
+	// Stream receives the output of every host line by line, prefixed by the host, if set.
+	Stream io.Writer
+}
+
+// BatchFunc runs on one host and writes its output to stdout and stderr.
+type BatchFunc func(ctx context.Context, host string, stdout, stderr io.Writer) error
+
+// RunBatch runs fn on every host with bounded concurrency, results are in the order of hosts.
+func RunBatch(ctx context.Context, hosts []string, opts BatchOptions, fn BatchFunc) []HostResult {
+	if opts.OutputDir != "" {
+		if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+			return failAll(hosts, err)
+		}
+	}
+	parallel := opts.Parallel
+	if parallel <= 0 || parallel > len(hosts) {
+		parallel = len(hosts)
+	}
+	results := make([]HostResult, len(hosts))
+	sem := make(chan struct{}, parallel)
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		stopped bool
+		stream  *lockedWriter
+	)
+	if opts.Stream != nil {
+		stream = &lockedWriter{w: opts.Stream}
+	}
+	for i := range hosts {
+		sem <- struct{}{}
+		mu.Lock()
+		stop := stopped && !opts.ContinueOnError
+		mu.Unlock()
+		if stop {
+			<-sem
+			results[i] = HostResult{Host: hosts[i], Status: HostSkipped, ExitCode: -1}
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = runHost(ctx, hosts[i], opts, stream, fn)
+			if results[i].Status == HostFailed {
+				mu.Lock()
+				stopped = true
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+func runHost(ctx context.Context, host string, opts BatchOptions, stream *lockedWriter, fn BatchFunc) HostResult {
+	ret := HostResult{Host: host}
+	var stdout, stderr []io.Writer
+	if opts.OutputDir != "" {
+		name := strings.ReplaceAll(host, ":", "_")
+		ret.Stdout = filepath.Join(opts.OutputDir, name+".stdout")
+		ret.Stderr = filepath.Join(opts.OutputDir, name+".stderr")
+		outFile, err := os.Create(ret.Stdout)
+		if err != nil {
+			return failedResult(ret, err)
+		}
+		defer outFile.Close()
+		errFile, err := os.Create(ret.Stderr)
+		if err != nil {
+			return failedResult(ret, err)
+		}
+		defer errFile.Close()
+		stdout, stderr = append(stdout, outFile), append(stderr, errFile)
+	}
+	if stream != nil {
+		outLines, errLines := &linePrefixWriter{prefix: host + "\t", w: stream}, &linePrefixWriter{prefix: host + "\t", w: stream}
+		defer outLines.Flush()
+		defer errLines.Flush()
+		stdout, stderr = append(stdout, outLines), append(stderr, errLines)
+	}
+	start := time.Now()
+	err := fn(ctx, host, io.MultiWriter(stdout...), io.MultiWriter(stderr...))
+	ret.Duration = time.Since(start).Round(time.Millisecond).String()
+	if err != nil {
+		return failedResult(ret, err)
+	}
+	ret.Status = HostSucceeded
+	return ret
+}
+
+func failedResult(ret HostResult, err error) HostResult {
+	ret.Status = HostFailed
+	ret.ExitCode = ExitCode(err)
+	ret.Error = err.Error()
+	return ret
+}
+
+func failAll(hosts []string, err error) []HostResult {
+	results := make([]HostResult, len(hosts))
+	for i := range hosts {
+		results[i] = failedResult(HostResult{Host: hosts[i]}, err)
+	}
+	return results
+}
+
+// ExitCode returns the exit status of a local or remote command error, 0 for nil and -1 if there is none.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var remoteErr *ssh.ExitError
+	if errors.As(err, &remoteErr) {
+		return remoteErr.ExitStatus()
+	}
+	var localErr *exec.ExitError
+	if errors.As(err, &localErr) {
+		return localErr.ExitCode()
+	}
+	return -1
+}
+
+// PruneRuns removes the oldest run directories under dir so that at most keep of them are left,
+// run directories are named so that the older ones sort first.
+func PruneRuns(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var runs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			runs = append(runs, e.Name())
+		}
+	}
+	sort.Strings(runs)
+	for i := 0; i < len(runs)-keep; i++ {
+		if err = os.RemoveAll(filepath.Join(dir, runs[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+// linePrefixWriter writes whole lines only, so that lines of different hosts do not interleave.
+type linePrefixWriter struct {
+	prefix string
+	w      io.Writer
+	buf    []byte
+}
+
+func (l *linePrefixWriter) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+	for {
+		idx := bytes.IndexByte(l.buf, '\n')
+		if idx < 0 {
+			return len(p), nil
+		}
+		if _, err := l.w.Write(append([]byte(l.prefix), l.buf[:idx+1]...)); err != nil {
+			return 0, err
+		}
+		l.buf = l.buf[idx+1:]
+	}
+}
+
+// Flush writes the last line which has no line break.
+func (l *linePrefixWriter) Flush() {
+	if len(l.buf) > 0 {
+		_, _ = l.w.Write(append([]byte(l.prefix), append(l.buf, '\n')...))
+		l.buf = nil
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	hosts := []string{"10.0.0.1:22", "10.0.0.2:22", "10.0.0.3:22", "10.0.0.4:22"}
+
+	t.Run("parallel limit and output", func(t *testing.T) {
+		var running, peak int32
+		dir := t.TempDir()
+		var stream bytes.Buffer
+		results := RunBatch(context.Background(), hosts, BatchOptions{Parallel: 2, OutputDir: dir, Stream: &stream},
+			func(_ context.Context, host string, stdout, stderr io.Writer) error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				fmt.Fprint(stdout, "hello\nworld")
+				fmt.Fprintln(stderr, "warn")
+				return nil
+			})
+		if peak > 2 {
+			t.Errorf("ran %d hosts at the same time, want at most 2", peak)
+		}
+		for i, r := range results {
+			if r.Host != hosts[i] || r.Status != HostSucceeded || r.ExitCode != 0 {
+				t.Errorf("unexpected result %+v", r)
+			}
+			data, err := os.ReadFile(r.Stdout)
+			if err != nil || string(data) != "hello\nworld" {
+				t.Errorf("stdout of %s = %q, %v", r.Host, data, err)
+			}
+			if data, err = os.ReadFile(r.Stderr); err != nil || string(data) != "warn\n" {
+				t.Errorf("stderr of %s = %q, %v", r.Host, data, err)
+			}
+		}
+		if lines := strings.Count(stream.String(), "\n"); lines != 3*len(hosts) {
+			t.Errorf("streamed %d lines, want %d:\n%s", lines, 3*len(hosts), stream.String())
+		}
+		if !strings.Contains(stream.String(), "10.0.0.1:22\tworld\n") {
+			t.Errorf("last line without line break is not flushed:\n%s", stream.String())
+		}
+	})
+
+	exitErr := exec.Command("/bin/sh", "-c", "exit 3").Run()
+	failSecond := func(_ context.Context, host string, _, _ io.Writer) error {
+		if host == hosts[1] {
+			return exitErr
+		}
+		return nil
+	}
+
+	t.Run("stop on error", func(t *testing.T) {
+		results := RunBatch(context.Background(), hosts, BatchOptions{Parallel: 1}, failSecond)
+		want := []HostStatus{HostSucceeded, HostFailed, HostSkipped, HostSkipped}
+		for i, r := range results {
+			if r.Status != want[i] {
+				t.Errorf("status of %s = %s, want %s", r.Host, r.Status, want[i])
+			}
+		}
+		if results[1].ExitCode != 3 {
+			t.Errorf("exit code = %d, want 3", results[1].ExitCode)
+		}
+	})
+
+	t.Run("continue on error", func(t *testing.T) {
+		results := RunBatch(context.Background(), hosts, BatchOptions{Parallel: 1, ContinueOnError: true}, failSecond)
+		want := []HostStatus{HostSucceeded, HostFailed, HostSucceeded, HostSucceeded}
+		for i, r := range results {
+			if r.Status != want[i] {
+				t.Errorf("status of %s = %s, want %s", r.Host, r.Status, want[i])
+			}
+		}
+	})
+}
+
+func TestPruneRuns(t *testing.T) {
+	runs := []string{"20231102-101500", "20231101-090000", "20231103-000000", "20231101-120000"}
+	tests := []struct {
+		name string
+		keep int
+		want []string
+	}{
+		{name: "keep all", keep: 5, want: []string{"20231101-090000", "20231101-120000", "20231102-101500", "20231103-000000"}},
+		{name: "keep the latest two", keep: 2, want: []string{"20231102-101500", "20231103-000000"}},
+		{name: "keep none", keep: 0, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, r := range runs {
+				if err := os.MkdirAll(filepath.Join(dir, r), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := PruneRuns(dir, tt.keep); err != nil {
+				t.Fatalf("PruneRuns() error = %v", err)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PruneRuns() left %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := PruneRuns(filepath.Join(t.TempDir(), "not-exist"), 1); err != nil {
+		t.Errorf("PruneRuns() on missing dir error = %v", err)
+	}
+}
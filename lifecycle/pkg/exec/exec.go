@@ -17,6 +17,7 @@ package exec
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -84,6 +85,17 @@ func (w *wrap) CmdAsyncWithContext(ctx context.Context, host string, commands ..
 	return w.inner.CmdAsyncWithContext(ctx, host, commands...)
 }
 
+func (w *wrap) CmdWithWriters(ctx context.Context, host string, stdout, stderr io.Writer, commands ...string) error {
+	if w.isLocal(host) {
+		// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
+		cmd := exec.CommandContext(ctx, "/bin/bash", "-c", strings.Join(commands, "; "))
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		return cmd.Run()
+	}
+	return ssh.CmdWithWriters(ctx, w.inner, host, stdout, stderr, commands...)
+}
+
 func (w *wrap) CmdAsync(host string, commands ...string) error {
 	ctx, cancel := ssh.GetTimeoutContext()
 	defer cancel()
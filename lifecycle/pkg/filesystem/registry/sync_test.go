@@ -41,13 +41,17 @@ func (testPathResolver) RootFSBinPath() string { return "/var/lib/sealos/data/de
 func (testPathResolver) RootFSSealctlPath() string {
 	return "/var/lib/sealos/data/default/rootfs/opt/sealctl"
 }
-func (testPathResolver) ConfigsPath() string { return "/var/lib/sealos/data/default/etc" }
-func (testPathResolver) RunRoot() string     { return "/var/lib/sealos/default" }
-func (testPathResolver) PkiPath() string     { return "/var/lib/sealos/default/pki" }
-func (testPathResolver) PkiEtcdPath() string { return "/var/lib/sealos/default/pki/etcd" }
-func (testPathResolver) AdminFile() string   { return "/var/lib/sealos/default/admin.conf" }
-func (testPathResolver) EtcPath() string     { return "/var/lib/sealos/default/etc" }
-func (testPathResolver) TmpPath() string     { return "/var/lib/sealos/default/tmp" }
+func (testPathResolver) ConfigsPath() string     { return "/var/lib/sealos/data/default/etc" }
+func (testPathResolver) RunRoot() string         { return "/var/lib/sealos/default" }
+func (testPathResolver) PkiPath() string         { return "/var/lib/sealos/default/pki" }
+func (testPathResolver) PkiEtcdPath() string     { return "/var/lib/sealos/default/pki/etcd" }
+func (testPathResolver) AdminFile() string       { return "/var/lib/sealos/default/admin.conf" }
+func (testPathResolver) EtcPath() string         { return "/var/lib/sealos/default/etc" }
+func (testPathResolver) KnownHostsFile() string  { return "/var/lib/sealos/default/etc/known_hosts" }
+func (testPathResolver) HistoryPath() string     { return "/var/lib/sealos/default/history" }
+func (testPathResolver) ExecRunsPath() string    { return "/var/lib/sealos/default/exec" }
+func (testPathResolver) EtcdBackupsPath() string { return "/var/lib/sealos/default/etcd-backups" }
+func (testPathResolver) TmpPath() string         { return "/var/lib/sealos/default/tmp" }
 
 func TestGetRegistryServeCommandIncludesSupportedFlags(t *testing.T) {
 	got := getRegistryServeCommand(testPathResolver{}, "5050", registryServeFlags{
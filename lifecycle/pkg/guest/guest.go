@@ -79,6 +79,33 @@ func (d *Default) Apply(cluster *v2.Cluster, mounts []v2.MountImage, targetHosts
 	return nil
 }
 
+// HostCommands are the commands that Apply runs for one image on one host.
+type HostCommands struct {
+	Host     string   `json:"host"`
+	Image    string   `json:"image"`
+	Commands []string `json:"commands"`
+}
+
+// Commands returns the commands Apply would run for the mounts on the target hosts, in the
+// same order, without connecting to any host.
+func Commands(cluster *v2.Cluster, mounts []v2.MountImage, targetHosts []string) []HostCommands {
+	envGetter := env.NewEnvProcessor(cluster)
+	ret := make([]HostCommands, 0)
+	for i, m := range mounts {
+		switch {
+		case m.IsRootFs(), m.IsPatch():
+			for _, node := range targetHosts {
+				envs := maps.Merge(m.Env, envGetter.Getenv(node))
+				ret = append(ret, HostCommands{Host: node, Image: m.ImageName, Commands: formalizeImageCommands(cluster, i, m, envs)})
+			}
+		case m.IsApplication():
+			envs := maps.Merge(m.Env, envGetter.Getenv(cluster.GetMaster0IP()))
+			ret = append(ret, HostCommands{Host: cluster.GetMaster0IPAndPort(), Image: m.ImageName, Commands: formalizeImageCommands(cluster, i, m, envs)})
+		}
+	}
+	return ret
+}
+
 func formalizeImageCommands(cluster *v2.Cluster, index int, m v2.MountImage, extraEnvs map[string]string) []string {
 	envs := maps.Merge(m.Env, extraEnvs)
 	envs = v2.MergeEnvWithBuiltinKeys(envs, m)
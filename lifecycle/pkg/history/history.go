@@ -0,0 +1,235 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/system"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	fileutil "github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/yaml"
+)
+
+const (
+	revisionFileName = "revision.yaml"
+	clusterfileName  = "Clusterfile"
+	configsDirName   = constants.EtcDirName
+	defaultLimit     = 10
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Revision describes a snapshot of the cluster taken before an apply changes it.
+type Revision struct {
+	Revision  int             `json:"revision"`
+	Timestamp metav1.Time     `json:"timestamp"`
+	Phase     v2.ClusterPhase `json:"phase"`
+	// Images are the mounted images in order, the first one is the rootfs image.
+	Images  []string `json:"images"`
+	Message string   `json:"message,omitempty"`
+}
+
+// Store keeps numbered snapshots of the Clusterfile and the rendered runtime configs.
+type Store struct {
+	dir   string
+	limit int
+}
+
+func NewStore(clusterName string) *Store {
+	limit := defaultLimit
+	if v, err := system.Get(system.HistoryLimitConfigKey); err == nil {
+		if n, err := strconv.Atoi(v); err == nil {
+			limit = n
+		} else {
+			logger.Debug("failed to parse history limit %s: %v, using default", v, err)
+		}
+	}
+	return newStore(constants.NewPathResolver(clusterName).HistoryPath(), limit)
+}
+
+func newStore(dir string, limit int) *Store {
+	return &Store{dir: dir, limit: limit}
+}
+
+// NewRevision returns an unnumbered revision describing the cluster.
+func NewRevision(cluster *v2.Cluster, message string) Revision {
+	images := make([]string, 0, len(cluster.Status.Mounts))
+	for _, m := range cluster.Status.Mounts {
+		images = append(images, m.ImageName)
+	}
+	return Revision{
+		Timestamp: metav1.Now(),
+		Phase:     cluster.Status.Phase,
+		Images:    images,
+		Message:   message,
+	}
+}
+
+// Record snapshots the clusterfile and the yaml files of etcDir as the next revision,
+// the oldest revisions beyond the limit are removed.
+func (s *Store) Record(rev Revision, clusterfile, etcDir string) (*Revision, error) {
+	if s.limit <= 0 {
+		return nil, nil
+	}
+	revisions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	rev.Revision = 1
+	if len(revisions) > 0 {
+		rev.Revision = revisions[len(revisions)-1].Revision + 1
+	}
+	tmp := filepath.Join(s.dir, fmt.Sprintf(".%d.tmp", rev.Revision))
+	if err = os.RemoveAll(tmp); err != nil {
+		return nil, err
+	}
+	if err = s.write(tmp, &rev, clusterfile, etcDir); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, err
+	}
+	if err = os.Rename(tmp, s.revisionDir(rev.Revision)); err != nil {
+		return nil, err
+	}
+	revisions = append(revisions, rev)
+	for i := 0; i < len(revisions)-s.limit; i++ {
+		if err = os.RemoveAll(s.revisionDir(revisions[i].Revision)); err != nil {
+			logger.Warn("failed to remove revision %d: %v", revisions[i].Revision, err)
+		}
+	}
+	return &rev, nil
+}
+
+func (s *Store) write(dir string, rev *Revision, clusterfile, etcDir string) error {
+	if err := fileutil.MkDirs(filepath.Join(dir, configsDirName)); err != nil {
+		return err
+	}
+	if err := fileutil.Copy(clusterfile, filepath.Join(dir, clusterfileName)); err != nil {
+		return fmt.Errorf("failed to snapshot Clusterfile: %w", err)
+	}
+	entries, err := os.ReadDir(etcDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() || !isConfigFile(e.Name()) {
+			continue
+		}
+		if err = fileutil.Copy(filepath.Join(etcDir, e.Name()), filepath.Join(dir, configsDirName, e.Name())); err != nil {
+			return fmt.Errorf("failed to snapshot config %s: %w", e.Name(), err)
+		}
+	}
+	return yaml.MarshalFile(filepath.Join(dir, revisionFileName), rev)
+}
+
+// isConfigFile filters the rendered runtime configs, credentials such as admin.conf are skipped.
+func isConfigFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// List returns all revisions in ascending order.
+func (s *Store) List() ([]Revision, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ret []Revision
+	for _, e := range entries {
+		// in-progress snapshots are named .N.tmp and skipped here
+		n, err := strconv.Atoi(e.Name())
+		if !e.IsDir() || err != nil {
+			continue
+		}
+		rev, err := s.Get(n)
+		if err != nil {
+			logger.Warn("skip broken revision %s: %v", e.Name(), err)
+			continue
+		}
+		ret = append(ret, *rev)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Revision < ret[j].Revision })
+	return ret, nil
+}
+
+func (s *Store) Get(revision int) (*Revision, error) {
+	path := filepath.Join(s.revisionDir(revision), revisionFileName)
+	if !fileutil.IsExist(path) {
+		return nil, fmt.Errorf("%w: %d", ErrRevisionNotFound, revision)
+	}
+	var rev Revision
+	if err := yaml.UnmarshalFile(path, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RestoreConfigs replaces the yaml files of etcDir with the configs recorded in revision,
+// other files of etcDir are left as they are.
+func (s *Store) RestoreConfigs(revision int, etcDir string) error {
+	if _, err := s.Get(revision); err != nil {
+		return err
+	}
+	configsDir := filepath.Join(s.revisionDir(revision), configsDirName)
+	recorded, err := os.ReadDir(configsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	current, err := os.ReadDir(etcDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, e := range current {
+		if e.IsDir() || !isConfigFile(e.Name()) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(etcDir, e.Name())); err != nil {
+			return err
+		}
+	}
+	if err = fileutil.MkDirs(etcDir); err != nil {
+		return err
+	}
+	for _, e := range recorded {
+		if e.IsDir() || !isConfigFile(e.Name()) {
+			continue
+		}
+		if err = fileutil.Copy(filepath.Join(configsDir, e.Name()), filepath.Join(etcDir, e.Name())); err != nil {
+			return fmt.Errorf("failed to restore config %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// ClusterfilePath returns the Clusterfile recorded in revision.
+func (s *Store) ClusterfilePath(revision int) string {
+	return filepath.Join(s.revisionDir(revision), clusterfileName)
+}
+
+func (s *Store) revisionDir(revision int) string {
+	return filepath.Join(s.dir, strconv.Itoa(revision))
+}
@@ -0,0 +1,140 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	clusterfile := filepath.Join(root, "Clusterfile")
+	etc := filepath.Join(root, "etc")
+	if err := os.MkdirAll(etc, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		clusterfile:                         "kind: Cluster",
+		filepath.Join(etc, "kubeadm.yaml"):  "kind: InitConfiguration",
+		filepath.Join(etc, "admin.conf"):    "secret",
+		filepath.Join(etc, "known_hosts"):   "keys",
+		filepath.Join(etc, "k3s-init.yaml"): "cluster-init: true",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cluster := &v2.Cluster{}
+	cluster.Status.Phase = v2.ClusterSuccess
+	cluster.Status.Mounts = []v2.MountImage{{ImageName: "labring/kubernetes:v1.27.7"}, {ImageName: "app:v1"}}
+
+	s := newStore(filepath.Join(root, "history"), 2)
+	for i := 1; i <= 3; i++ {
+		rev, err := s.Record(NewRevision(cluster, ""), clusterfile, etc)
+		if err != nil {
+			t.Fatalf("record: %v", err)
+		}
+		if rev.Revision != i {
+			t.Fatalf("expected revision %d, got %d", i, rev.Revision)
+		}
+	}
+
+	revisions, err := s.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[1].Revision != 3 {
+		t.Fatalf("expected revisions 2 and 3 to be kept, got %+v", revisions)
+	}
+	if !reflect.DeepEqual(revisions[1].Images, []string{"labring/kubernetes:v1.27.7", "app:v1"}) {
+		t.Errorf("unexpected images %v", revisions[1].Images)
+	}
+	if _, err = s.Get(1); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected pruned revision to be not found, got %v", err)
+	}
+
+	dir := filepath.Join(root, "history", "3", "etc")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"k3s-init.yaml", "kubeadm.yaml"}) {
+		t.Errorf("only rendered configs should be recorded, got %v", names)
+	}
+	if data, _ := os.ReadFile(s.ClusterfilePath(3)); string(data) != "kind: Cluster" {
+		t.Errorf("unexpected recorded Clusterfile %q", data)
+	}
+}
+
+func TestStoreDisabled(t *testing.T) {
+	s := newStore(filepath.Join(t.TempDir(), "history"), 0)
+	rev, err := s.Record(Revision{}, "not-exists", "not-exists")
+	if err != nil || rev != nil {
+		t.Fatalf("expected nothing recorded, got %v, %v", rev, err)
+	}
+}
+
+func TestStoreRestoreConfigs(t *testing.T) {
+	root := t.TempDir()
+	clusterfile := filepath.Join(root, "Clusterfile")
+	etc := filepath.Join(root, "etc")
+	if err := os.MkdirAll(etc, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(clusterfile, "kind: Cluster")
+	write(filepath.Join(etc, "k3s-init.yaml"), "v1")
+
+	s := newStore(filepath.Join(root, "history"), 2)
+	if _, err := s.Record(NewRevision(&v2.Cluster{}, ""), clusterfile, etc); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	write(filepath.Join(etc, "k3s-init.yaml"), "v2")
+	write(filepath.Join(etc, "k3s-join-master.yaml"), "added")
+	write(filepath.Join(etc, "admin.conf"), "secret")
+
+	if err := s.RestoreConfigs(1, etc); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	for name, want := range map[string]string{
+		"k3s-init.yaml": "v1",
+		"admin.conf":    "secret",
+	} {
+		if data, err := os.ReadFile(filepath.Join(etc, name)); err != nil || string(data) != want {
+			t.Errorf("expected %s to be %q, got %q, %v", name, want, data, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(etc, "k3s-join-master.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected config added after the revision to be removed, got %v", err)
+	}
+	if err := s.RestoreConfigs(2, etc); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("expected unknown revision to fail, got %v", err)
+	}
+}
@@ -16,6 +16,7 @@ package ipvs
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/labring/sealos/pkg/types/v1beta1"
 
@@ -30,6 +31,8 @@ import (
 
 const (
 	LvsCareCommand = "/usr/bin/lvscare"
+	// LvsCareDefaultMode is the IPVS mode lvscare runs in without the --mode flag
+	LvsCareDefaultMode = "route"
 )
 
 func LvsStaticPodYaml(vip string, masters []string, image, name string, options []string) (string, error) {
@@ -63,6 +66,21 @@ func LvsStaticPodYaml(vip string, masters []string, image, name string, options
 	return string(yaml), nil
 }
 
+// WithProxyMode appends the lvscare proxy mode returned by detect to the options, unless they
+// set the mode already. The default mode is not appended so the pods of IPVS nodes are unchanged.
+func WithProxyMode(options []string, detect func() string) []string {
+	for _, o := range options {
+		if o == "--mode" || strings.HasPrefix(o, "--mode=") {
+			return options
+		}
+	}
+	mode := detect()
+	if mode == "" || mode == LvsCareDefaultMode {
+		return options
+	}
+	return append(append([]string{}, options...), "--mode="+mode)
+}
+
 func PodToYaml(pod v1.Pod) ([]byte, error) {
 	codecs := scheme.Codecs
 	gv := v1.SchemeGroupVersion
@@ -15,6 +15,7 @@
 package ipvs
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/labring/sealos/pkg/constants"
@@ -96,3 +97,26 @@ func TestLvsStaticPodYaml(t *testing.T) {
 		})
 	}
 }
+
+func TestWithProxyMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []string
+		detected string
+		want     []string
+	}{
+		{"ipvs node keeps the default mode", []string{"--aa"}, LvsCareDefaultMode, []string{"--aa"}},
+		{"nftables node", []string{"--aa"}, "nftables", []string{"--aa", "--mode=nftables"}},
+		{"userspace node", nil, "userspace", []string{"--mode=userspace"}},
+		{"mode set by flag", []string{"--mode", "link"}, "userspace", []string{"--mode", "link"}},
+		{"mode set inline", []string{"--mode=link"}, "nftables", []string{"--mode=link"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithProxyMode(tt.options, func() string { return tt.detected })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithProxyMode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
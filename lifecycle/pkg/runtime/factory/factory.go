@@ -16,35 +16,98 @@ package factory
 
 import (
 	"errors"
-	"fmt"
 
 	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/runtime/decode"
 	"github.com/labring/sealos/pkg/runtime/k3s"
 	"github.com/labring/sealos/pkg/runtime/kubernetes"
 	"github.com/labring/sealos/pkg/runtime/kubernetes/types"
+	"github.com/labring/sealos/pkg/runtime/rke2"
 	"github.com/labring/sealos/pkg/types/v1beta1"
 )
 
+func init() {
+	MustRegister(&Distribution{
+		Name:   kubernetes.Distribution,
+		Labels: []string{"kubeadm", ""},
+		New: func(cluster *v1beta1.Cluster, cfg runtime.Config) (runtime.Interface, error) {
+			return kubernetes.New(cluster, cfg)
+		},
+		NewConfig: func() runtime.Config { return types.NewKubeadmConfig() },
+		ParseConfig: func(data []byte) (runtime.Config, error) {
+			cfg, err := types.LoadKubeadmConfigs(string(data), false, decode.CRDFromString)
+			if err != nil || cfg == nil {
+				return nil, err
+			}
+			return cfg, nil
+		},
+	})
+	MustRegister(&Distribution{
+		Name: k3s.Distribution,
+		New: func(cluster *v1beta1.Cluster, cfg runtime.Config) (runtime.Interface, error) {
+			return k3s.New(cluster, cfg)
+		},
+		NewConfig: func() runtime.Config { return &k3s.Config{} },
+		ParseConfig: func(data []byte) (runtime.Config, error) {
+			// an error means the data is not a configuration of k3s
+			if cfg, _ := k3s.ParseConfig(data); cfg != nil {
+				return cfg, nil
+			}
+			return nil, nil
+		},
+		WriteBackConfig: true,
+	})
+	MustRegister(&Distribution{
+		Name: rke2.Distribution,
+		New: func(cluster *v1beta1.Cluster, cfg runtime.Config) (runtime.Interface, error) {
+			return rke2.New(cluster, cfg)
+		},
+		NewConfig: func() runtime.Config { return &rke2.Config{} },
+		ParseConfig: func(data []byte) (runtime.Config, error) {
+			// an error means the data is not a configuration of rke2
+			if cfg, _ := rke2.ParseConfig(data); cfg != nil {
+				return cfg, nil
+			}
+			return nil, nil
+		},
+		WriteBackConfig: true,
+	})
+}
+
 func New(cluster *v1beta1.Cluster, cfg runtime.Config) (runtime.Interface, error) {
 	if cluster == nil {
 		return nil, errors.New("cluster cannot be null")
 	}
-	distribution := cluster.GetDistribution()
-	switch distribution {
-	case kubernetes.Distribution, "kubeadm", "":
-		return kubernetes.New(cluster, cfg)
-	case k3s.Distribution:
-		return k3s.New(cluster, cfg)
+	d, err := lookup(cluster.GetDistribution())
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("unsupported distribution %s", distribution)
+	return d.New(cluster, cfg)
 }
 
 func NewRuntimeConfig(distribution string) (runtime.Config, error) {
-	switch distribution {
-	case kubernetes.Distribution, "kubeadm", "":
-		return types.NewKubeadmConfig(), nil
-	case k3s.Distribution:
-		return &k3s.Config{}, nil
+	d, err := lookup(distribution)
+	if err != nil {
+		return nil, err
+	}
+	return d.NewConfig(), nil
+}
+
+// ParseRuntimeConfig parses the runtime configuration of distribution from Clusterfile data,
+// it returns nil if the distribution does not support or the data does not contain one.
+func ParseRuntimeConfig(distribution string, data []byte) (runtime.Config, error) {
+	d, err := lookup(distribution)
+	if err != nil {
+		return nil, err
+	}
+	if d.ParseConfig == nil {
+		return nil, nil
 	}
-	return nil, fmt.Errorf("unsupported distribution %s", distribution)
+	return d.ParseConfig(data)
+}
+
+// WriteBackConfig tells whether the runtime configuration of distribution is saved into the Clusterfile.
+func WriteBackConfig(distribution string) bool {
+	d, ok := Lookup(distribution)
+	return ok && d.WriteBackConfig
 }
@@ -0,0 +1,111 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+// Distribution describes a runtime implementation which can be plugged into sealos.
+type Distribution struct {
+	// Name is the canonical name of the distribution.
+	Name string
+	// Labels are the values of the sealos.io.distribution image label claimed by
+	// the distribution besides its name, an empty label claims unlabeled images.
+	Labels []string
+	// New creates the runtime of cluster, cfg is nil or created by NewConfig.
+	New func(cluster *v1beta1.Cluster, cfg runtime.Config) (runtime.Interface, error)
+	// NewConfig returns an empty runtime configuration.
+	NewConfig func() runtime.Config
+	// ParseConfig returns the runtime configuration found in Clusterfile data,
+	// or nil if there is none. It is optional.
+	ParseConfig func(data []byte) (runtime.Config, error)
+	// WriteBackConfig tells whether the runtime configuration is saved into
+	// the Clusterfile together with the Cluster.
+	WriteBackConfig bool
+}
+
+var (
+	registryMu    sync.RWMutex
+	distributions = map[string]*Distribution{}
+	labels        = map[string]*Distribution{}
+)
+
+// Register makes a distribution available for the images claimed by its name and labels.
+func Register(d *Distribution) error {
+	if d == nil || d.Name == "" {
+		return errors.New("distribution name cannot be empty")
+	}
+	if d.New == nil || d.NewConfig == nil {
+		return fmt.Errorf("distribution %s must provide New and NewConfig", d.Name)
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := distributions[d.Name]; ok {
+		return fmt.Errorf("distribution %s is already registered", d.Name)
+	}
+	claims := append([]string{d.Name}, d.Labels...)
+	for _, l := range claims {
+		if o, ok := labels[l]; ok {
+			return fmt.Errorf("label %q of distribution %s is already claimed by %s", l, d.Name, o.Name)
+		}
+	}
+	distributions[d.Name] = d
+	for _, l := range claims {
+		labels[l] = d
+	}
+	return nil
+}
+
+// MustRegister is like Register but panics if the distribution cannot be registered.
+func MustRegister(d *Distribution) {
+	if err := Register(d); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the distribution claiming the value of an image distribution label.
+func Lookup(label string) (*Distribution, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := labels[label]
+	return d, ok
+}
+
+// Registered returns the names of all registered distributions.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	ret := make([]string, 0, len(distributions))
+	for name := range distributions {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func lookup(label string) (*Distribution, error) {
+	d, ok := Lookup(label)
+	if !ok {
+		return nil, fmt.Errorf("unsupported distribution %s, registered distributions are %v", label, Registered())
+	}
+	return d, nil
+}
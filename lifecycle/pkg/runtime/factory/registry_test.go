@@ -0,0 +1,67 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/runtime/k3s"
+	"github.com/labring/sealos/pkg/runtime/kubernetes"
+	"github.com/labring/sealos/pkg/runtime/rke2"
+	"github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestLookupBuiltin(t *testing.T) {
+	for label, want := range map[string]string{
+		"":                      kubernetes.Distribution,
+		"kubeadm":               kubernetes.Distribution,
+		kubernetes.Distribution: kubernetes.Distribution,
+		k3s.Distribution:        k3s.Distribution,
+		rke2.Distribution:       rke2.Distribution,
+	} {
+		d, ok := Lookup(label)
+		if !ok || d.Name != want {
+			t.Errorf("Lookup(%q) = %v, want %s", label, d, want)
+		}
+	}
+	if _, err := NewRuntimeConfig("unknown"); err == nil {
+		t.Error("expected error for unregistered distribution")
+	}
+	if !WriteBackConfig(rke2.Distribution) || WriteBackConfig(kubernetes.Distribution) {
+		t.Error("unexpected write back config settings")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	newFn := func(*v1beta1.Cluster, runtime.Config) (runtime.Interface, error) { return nil, nil }
+	cfgFn := func() runtime.Config { return &rke2.Config{} }
+
+	if err := Register(&Distribution{Name: "no-k8s", Labels: []string{"containerd"}, New: newFn, NewConfig: cfgFn}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if d, ok := Lookup("containerd"); !ok || d.Name != "no-k8s" {
+		t.Fatalf("expected label to be claimed by no-k8s, got %v", d)
+	}
+	if err := Register(&Distribution{Name: "other", Labels: []string{"k3s"}, New: newFn, NewConfig: cfgFn}); err == nil {
+		t.Error("expected error for label already claimed")
+	}
+	if _, ok := Lookup("other"); ok {
+		t.Error("a rejected distribution must not be registered partially")
+	}
+	if err := Register(&Distribution{Name: "incomplete"}); err == nil {
+		t.Error("expected error for distribution without constructors")
+	}
+}
@@ -14,6 +14,8 @@
 
 package runtime
 
+import "time"
+
 type Interface interface {
 	Ruler
 	Init() error
@@ -38,6 +40,45 @@ type CertManager interface {
 	UpdateCertSANs(certSANs []string) error
 }
 
+// CertExpiry is the expiry of a certificate on a host.
+type CertExpiry struct {
+	Host      string    `json:"host"`
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	CA        bool      `json:"ca"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// CertExpiryManager checks the certificates of all hosts and renews the ones close to expiry.
+type CertExpiryManager interface {
+	CheckCerts() ([]CertExpiry, error)
+	// RenewExpiringCerts renews the certificates of masters which expire within before, the affected
+	// static pods are restarted one master at a time. It returns the renewed certificates.
+	RenewExpiringCerts(before time.Duration) ([]CertExpiry, error)
+}
+
+// EtcdSnapshot is the metadata stored next to a snapshot of etcd.
+type EtcdSnapshot struct {
+	Name              string    `json:"name"`
+	Cluster           string    `json:"cluster"`
+	Host              string    `json:"host"`
+	CreatedAt         time.Time `json:"createdAt"`
+	KubernetesVersion string    `json:"kubernetesVersion,omitempty"`
+	Revision          int64     `json:"revision"`
+	TotalKeys         int64     `json:"totalKeys"`
+	Size              int64     `json:"size"`
+	SHA256            string    `json:"sha256"`
+	// Path is the local snapshot file, it is not stored
+	Path string `json:"-"`
+}
+
+type EtcdManager interface {
+	// BackupEtcd takes a snapshot of etcd on master0 and stores it locally under the given name.
+	BackupEtcd(name string) (*EtcdSnapshot, error)
+	// RestoreEtcd restores etcd of all masters from a local snapshot file and restarts the control plane.
+	RestoreEtcd(snapshot string) error
+}
+
 type Config interface {
 	GetComponents() []any
 }
@@ -15,27 +15,12 @@
 package k3s
 
 import (
-	"bytes"
-	"fmt"
-	"os"
 	"path/filepath"
 
-	"github.com/emirpasic/gods/sets/linkedhashset"
-
-	"github.com/imdario/mergo"
-	netutils "k8s.io/utils/net"
-
 	"github.com/labring/sealos/pkg/constants"
-	fileutils "github.com/labring/sealos/pkg/utils/file"
-	"github.com/labring/sealos/pkg/utils/iputils"
-	"github.com/labring/sealos/pkg/utils/logger"
-	"github.com/labring/sealos/pkg/utils/yaml"
+	"github.com/labring/sealos/pkg/runtime/rancher"
 )
 
-var defaultMergeOpts = []func(*mergo.Config){
-	mergo.WithOverride,
-}
-
 func defaultingConfig(c *Config) *Config {
 	c.BindAddress = "0.0.0.0"
 	c.HTTPSPort = constants.DefaultAPIServerPort
@@ -76,70 +61,22 @@ func removeServerFlagsInAgentConfig(c *Config) *Config {
 
 type callback func(*Config) *Config
 
-func merge(dst, src *Config) error {
-	if src == nil || dst == nil {
-		return nil
-	}
-	return mergo.Merge(dst, src, defaultMergeOpts...)
-}
-
 func setClusterInit(c *Config) *Config {
 	c.ClusterInit = true
 	return c
 }
 
 func (k *K3s) merge(c *Config) *Config {
-	if err := func() error {
-		defaultCfg := filepath.Join(k.pathResolver.RootFSEtcPath(), defaultRootFsK3sFileName)
-		if !fileutils.IsExist(defaultCfg) {
-			return nil
-		}
-		data, err := os.ReadFile(defaultCfg)
-		if err != nil {
-			return err
-		}
-		parseCfg, err := ParseConfig(data)
-		if err != nil {
-			return err
-		}
-		return merge(c, parseCfg)
-	}(); err != nil {
-		logger.Error("failed to merge in place config file: %v", err)
-	}
-	if err := merge(c, k.config); err != nil {
-		logger.Error("failed to merge provide config: %v", err)
-	}
-	return c
+	return rancher.MergeConfig(c, filepath.Join(k.pathResolver.RootFSEtcPath(), defaultRootFsK3sFileName), k.config)
 }
 
 func (k *K3s) overrideCertSans(c *Config) *Config {
-	masterIPs := iputils.GetHostIPs(k.cluster.GetMasterIPList())
-	var certSans []string
-	certSans = append(certSans, "127.0.0.1")
-	certSans = append(certSans, constants.DefaultAPIServerDomain)
-	certSans = append(certSans, k.cluster.GetVIP())
-	certSans = append(certSans, masterIPs...)
-	certSans = append(certSans, c.TLSSan...)
-	certSans = append(certSans, c.ServiceCIDR...)
-	certSans = append(certSans, c.ClusterDomain)
-	c.TLSSan = certSans
+	c.TLSSan = rancher.CertSANs(k.cluster, c.TLSSan, c.ServiceCIDR, c.ClusterDomain)
 	return c
 }
 
 func (k *K3s) sealosCfg(c *Config) *Config {
-	vip := k.cluster.GetVIP()
-	kubeProxy := linkedhashset.New()
-	for _, v := range c.AgentConfig.ExtraKubeProxyArgs {
-		kubeProxy.Add(v)
-	}
-	kubeProxy.Add(fmt.Sprintf("%s=%s", "ipvs-exclude-cidrs", fmt.Sprintf("%s/32", vip)))
-	kubeProxy.Add(fmt.Sprintf("%s=%s", "proxy-mode", "ipvs"))
-
-	var allArgs []string
-	for _, v := range kubeProxy.Values() {
-		allArgs = append(allArgs, v.(string))
-	}
-	c.AgentConfig.ExtraKubeProxyArgs = allArgs
+	c.AgentConfig.ExtraKubeProxyArgs = rancher.KubeProxyArgs(c.AgentConfig.ExtraKubeProxyArgs, k.cluster.GetVIP())
 	return c
 }
 
@@ -147,14 +84,8 @@ func (k *K3s) overrideServerConfig(c *Config) *Config {
 	c.AgentConfig.TokenFile = filepath.Join(k.pathResolver.ConfigsPath(), "token")
 	c.AgentTokenFile = filepath.Join(k.pathResolver.ConfigsPath(), "agent-token")
 
-	if len(c.ClusterDNS) == 0 && len(c.ServiceCIDR) > 0 {
-		svcSubnetCIDR, err := netutils.ParseCIDRs(c.ServiceCIDR)
-		if err == nil {
-			clusterDNS, err := netutils.GetIndexedIP(svcSubnetCIDR[0], 10)
-			if err == nil {
-				c.ClusterDNS = []string{clusterDNS.String()}
-			}
-		}
+	if len(c.ClusterDNS) == 0 {
+		c.ClusterDNS = rancher.ClusterDNS(c.ServiceCIDR)
 	}
 	return c
 }
@@ -174,20 +105,5 @@ func (k *K3s) getInitConfig(callbacks ...callback) (*Config, error) {
 
 // ParseConfig return nil if data structure is not matched
 func ParseConfig(data []byte) (*Config, error) {
-	var cfg Config
-	if err := yaml.Unmarshal(bytes.NewBuffer(data), &cfg); err != nil {
-		return nil, err
-	}
-	out, err := yaml.Marshal(&cfg)
-	if err != nil {
-		return nil, err
-	}
-	isNil, err := yaml.IsNil(out)
-	if err != nil {
-		return nil, err
-	}
-	if isNil {
-		return nil, nil
-	}
-	return &cfg, nil
+	return rancher.ParseConfig[Config](data)
 }
@@ -26,6 +26,8 @@ const (
 	defaultJoinMastersFilename = "k3s-join-master.yaml"
 	defaultJoinNodesFilename   = "k3s-join-node.yaml"
 	k3sEtcStaticPod            = "/var/lib/rancher/k3s/agent/pod-manifests"
+	// kubeConfigServer is the server address in the kubeconfig written by k3s
+	kubeConfigServer = "https://0.0.0.0"
 )
 
 const (
@@ -26,6 +26,7 @@ import (
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/env"
 	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/runtime/rancher"
 	"github.com/labring/sealos/pkg/ssh"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/logger"
@@ -40,6 +41,7 @@ type K3s struct {
 	pathResolver constants.PathResolver
 	remoteUtil   *ssh.Remote
 	execer       exec.Interface
+	runner       *rancher.Runner
 }
 
 func New(cluster *v2.Cluster, config any) (*K3s, error) {
@@ -55,6 +57,7 @@ func New(cluster *v2.Cluster, config any) (*K3s, error) {
 		envInterface: env.NewEnvProcessor(cluster),
 		remoteUtil:   ssh.NewRemoteFromSSH(cluster.GetName(), execer),
 	}
+	k.runner = rancher.NewRunner(cluster, k.pathResolver, k.remoteUtil, execer, "kubectl")
 	if v, ok := config.(*Config); ok {
 		k.config = v
 	}
@@ -106,8 +109,17 @@ func (k *K3s) ScaleDown(masters []string, nodes []string) error {
 }
 
 func (k *K3s) Upgrade(version string) error {
-	logger.Info("trying to upgrade to version %s", version)
-	return nil
+	currVersion := k.getK3sVersionFromImage()
+	needUpgrade, err := rancher.CheckUpgradeVersion(currVersion, version)
+	if err != nil {
+		return err
+	}
+	if !needUpgrade {
+		logger.Info("skip upgrade because of same version")
+		return nil
+	}
+	logger.Info("start to upgrade k3s from %s to %s", currVersion, version)
+	return k.upgradeCluster(version)
 }
 
 func (k *K3s) GetRawConfig() ([]byte, error) {
@@ -143,13 +155,3 @@ func (k *K3s) SyncNodeIPVS(mastersIPList, nodeIPList []string) error {
 	}
 	return eg.Wait()
 }
-
-func (k *K3s) runPipelines(phase string, pipelines ...func() error) error {
-	logger.Info("starting %s", phase)
-	for i := range pipelines {
-		if err := pipelines[i](); err != nil {
-			return fmt.Errorf("failed to %s: %v", phase, err)
-		}
-	}
-	return nil
-}
@@ -18,8 +18,6 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/labring/sealos/pkg/utils/iputils"
-
 	"github.com/labring/sealos/pkg/utils/strings"
 
 	"golang.org/x/exp/slices"
@@ -88,10 +86,10 @@ func (k *K3s) deleteNode(node string) error {
 
 func (k *K3s) removeNode(ip string) error {
 	logger.Info("start to remove node from k3s %s", ip)
-	nodeName, err := k.execer.CmdToString(k.cluster.GetMaster0IPAndPort(), fmt.Sprintf("kubectl get nodes -o wide | awk '$6==\"%s\" {print $1}'", iputils.GetHostIP(ip)), "")
+	nodeName, err := k.runner.NodeName(ip)
 	if err != nil {
-		return fmt.Errorf("cannot get node with ip address %s: %v", ip, err)
+		return err
 	}
 	logger.Debug("found node name is %s, we will delete it", nodeName)
-	return k.execer.CmdAsync(k.cluster.GetMaster0IPAndPort(), fmt.Sprintf("kubectl delete node %s --ignore-not-found=true", nodeName))
+	return k.runner.Kubectl(fmt.Sprintf("delete node %s --ignore-not-found=true", nodeName))
 }
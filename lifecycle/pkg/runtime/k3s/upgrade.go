@@ -0,0 +1,90 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k3s
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/runtime/rancher"
+	"github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/file"
+)
+
+const (
+	installK3sCmd    = "cp -rf %s/k3s /usr/bin/k3s"
+	k3sServiceName   = "k3s"
+	nodeReadyTimeout = 5 * time.Minute
+)
+
+func (k *K3s) getK3sVersionFromImage() string {
+	img := k.cluster.GetRootfsImage()
+	if img == nil || img.Labels == nil {
+		return ""
+	}
+	return img.Labels[v1beta1.ImageKubeVersionKey]
+}
+
+func (k *K3s) upgradeCluster(version string) error {
+	if err := k.writeUpgradeConfigs(); err != nil {
+		return err
+	}
+	master0 := k.cluster.GetMaster0IPAndPort()
+	masters := k.cluster.GetMasterIPAndPortList()
+	nodes := k.cluster.GetNodeIPAndPortList()
+	for _, host := range rancher.UpgradeOrder(master0, masters, nodes) {
+		configFile := defaultJoinNodesFilename
+		switch {
+		case host == master0:
+			configFile = defaultInitFilename
+		case slices.Contains(masters, host):
+			configFile = defaultJoinMastersFilename
+		}
+		if err := k.runner.UpgradeNode(rancher.NodeUpgrade{
+			Host:         host,
+			Config:       filepath.Join(k.pathResolver.EtcPath(), configFile),
+			ConfigPath:   defaultK3sConfigPath,
+			InstallCmd:   fmt.Sprintf(installK3sCmd, k.pathResolver.RootFSBinPath()),
+			Service:      k3sServiceName,
+			Version:      version,
+			Drain:        len(masters)+len(nodes) > 1,
+			ReadyTimeout: nodeReadyTimeout,
+		}); err != nil {
+			return fmt.Errorf("upgrade node %s to %s: %w", host, version, err)
+		}
+	}
+	return nil
+}
+
+// writeUpgradeConfigs regenerates the server and agent config files so that
+// defaults shipped in the new rootfs k3s.yml are picked up.
+func (k *K3s) writeUpgradeConfigs() error {
+	defaultCallbacks := []callback{defaultingConfig, k.merge, k.sealosCfg, k.overrideCertSans, k.overrideServerConfig, setClusterInit}
+	raw, err := k.getRawInitConfig(defaultCallbacks...)
+	if err != nil {
+		return err
+	}
+	if err = file.WriteFile(filepath.Join(k.pathResolver.EtcPath(), defaultInitFilename), raw); err != nil {
+		return err
+	}
+	if _, err = k.writeJoinConfigWithCallbacks(serverMode); err != nil {
+		return err
+	}
+	_, err = k.writeJoinConfigWithCallbacks(agentMode, removeServerFlagsInAgentConfig)
+	return err
+}
@@ -0,0 +1,220 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/labring/sealos/pkg/cert"
+	"github.com/labring/sealos/pkg/client-go/kubernetes"
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const waitAPIServerReadyCmd = `for i in $(seq 1 %[1]d); do
+  if kubectl --kubeconfig ` + kubernetesEtc + `/admin.conf --server https://127.0.0.1:%[2]d get --raw /readyz >/dev/null 2>&1; then exit 0; fi
+  sleep 2
+done
+echo "timed out waiting for kube-apiserver to be ready" >&2; exit 1`
+
+// certStaticPods are the static pods which load the certificate when they start.
+var certStaticPods = map[string][]string{
+	"apiserver":                {"kube-apiserver"},
+	"apiserver-kubelet-client": {"kube-apiserver"},
+	"apiserver-etcd-client":    {"kube-apiserver"},
+	"front-proxy-client":       {"kube-apiserver"},
+	"etcd-server":              {"etcd"},
+	"etcd-peer":                {"etcd"},
+	"etcd-healthcheck-client":  {"etcd"},
+	ControllerConf:             {"kube-controller-manager"},
+	SchedulerConf:              {"kube-scheduler"},
+}
+
+// staticPodsToRestart returns the static pods using the certificates, in the order of controlPlaneStaticPods
+// so that etcd is back before kube-apiserver restarts.
+func staticPodsToRestart(names []string) []string {
+	affected := map[string]bool{}
+	for _, name := range names {
+		for _, pod := range certStaticPods[name] {
+			affected[pod] = true
+		}
+	}
+	var pods []string
+	for _, pod := range controlPlaneStaticPods {
+		if affected[pod] {
+			pods = append(pods, pod)
+		}
+	}
+	return pods
+}
+
+func (k *KubeadmRuntime) CheckCerts() ([]runtime.CertExpiry, error) {
+	masters := k.getMasterIPAndPortList()
+	nodes := k.getNodeIPAndPortList()
+	hosts := append(append([]string{}, masters...), nodes...)
+	results := make([][]runtime.CertExpiry, len(hosts))
+
+	controlPlaneFiles := cert.ControlPlaneCertFiles(k.localKubeVersion())
+	eg, _ := errgroup.WithContext(context.Background())
+	for i, host := range hosts {
+		i, host := i, host
+		files := cert.NodeCertFiles()
+		if i < len(masters) {
+			files = controlPlaneFiles
+		}
+		eg.Go(func() error {
+			expiries, err := k.checkHostCerts(host, files)
+			if err != nil {
+				return fmt.Errorf("failed to check certs of %s: %v", host, err)
+			}
+			results[i] = expiries
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	var expiries []runtime.CertExpiry
+	for _, r := range results {
+		expiries = append(expiries, r...)
+	}
+	return expiries, nil
+}
+
+func (k *KubeadmRuntime) checkHostCerts(host string, files []cert.CertFile) ([]runtime.CertExpiry, error) {
+	out, err := k.execer.CmdToString(host, cert.ReadCertFilesCmd(files), "\n")
+	if err != nil {
+		return nil, err
+	}
+	certs, err := cert.ParseCertFilesOutput(out)
+	if err != nil {
+		return nil, err
+	}
+	var expiries []runtime.CertExpiry
+	for _, f := range files {
+		c, ok := certs[f.Path]
+		if !ok {
+			logger.Debug("cert %s not found on %s, skip it", f.Path, host)
+			continue
+		}
+		expiries = append(expiries, runtime.CertExpiry{
+			Host:      iputils.GetHostIP(host),
+			Name:      f.Name,
+			Path:      f.Path,
+			CA:        f.CA,
+			ExpiresAt: c.NotAfter,
+		})
+	}
+	return expiries, nil
+}
+
+func (k *KubeadmRuntime) RenewExpiringCerts(before time.Duration) ([]runtime.CertExpiry, error) {
+	expiries, err := k.CheckCerts()
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(before)
+	expiring := map[string][]runtime.CertExpiry{}
+	for _, e := range expiries {
+		if e.ExpiresAt.After(deadline) {
+			continue
+		}
+		switch {
+		case e.CA:
+			logger.Warn("CA %s on %s expires at %s, it cannot be renewed by kubeadm", e.Name, e.Host, e.ExpiresAt.Format(time.RFC3339))
+		case e.Name == cert.KubeletClientCertName:
+			logger.Warn("client cert of kubelet on %s expires at %s, make sure rotateCertificates of kubelet is enabled", e.Host, e.ExpiresAt.Format(time.RFC3339))
+		default:
+			expiring[e.Host] = append(expiring[e.Host], e)
+		}
+	}
+
+	var renewed []runtime.CertExpiry
+	masters := k.getMasterIPAndPortList()
+	for i, master := range masters {
+		certs := expiring[iputils.GetHostIP(master)]
+		if len(certs) == 0 {
+			continue
+		}
+		names := make([]string, len(certs))
+		for j := range certs {
+			names[j] = certs[j].Name
+		}
+		logger.Info("start to renew certs %v on %s", names, master)
+		if err := k.renewCertsOnMaster(master, names); err != nil {
+			return renewed, fmt.Errorf("failed to renew certs on %s: %v", master, err)
+		}
+		renewed = append(renewed, certs...)
+		if i == 0 && containsRenewTarget(names, AdminConf) {
+			if err := k.fetchRenewedAdminKubeConfig(master); err != nil {
+				return renewed, err
+			}
+		}
+	}
+	if len(renewed) == 0 {
+		return nil, nil
+	}
+	return renewed, k.pingAPIServer()
+}
+
+// renewCertsOnMaster renews the certificates and restarts the static pods using them on a single master,
+// it returns after the static pods are running again.
+func (k *KubeadmRuntime) renewCertsOnMaster(master string, names []string) error {
+	cmds := make([]string, len(names))
+	for i, name := range names {
+		cmds[i] = fmt.Sprintf(renewCertCmd, name)
+	}
+	if err := k.sshCmdAsync(master, strings.Join(cmds, " && ")); err != nil {
+		return err
+	}
+
+	manifestsBackup := path.Join(k.pathResolver.Root(), "manifests")
+	for _, pod := range staticPodsToRestart(names) {
+		logger.Info("restart static pod %s on %s", pod, master)
+		moveOut := fmt.Sprintf(moveStaticPodsCmd, kubernetesEtcStaticPod, manifestsBackup, pod+".yaml")
+		moveIn := fmt.Sprintf(moveStaticPodsCmd, manifestsBackup, kubernetesEtcStaticPod, pod+".yaml")
+		waitStopped := fmt.Sprintf(waitContainersCmd, waitContainersRetries, pod, "n")
+		waitRunning := fmt.Sprintf(waitContainersCmd, waitContainersRetries, pod, "z")
+		if err := k.sshCmdAsyncSeq(master, moveOut, waitStopped); err != nil {
+			// always put the manifest back, otherwise the static pod is gone on this master
+			_ = k.sshCmdAsync(master, moveIn)
+			return fmt.Errorf("failed to stop %s: %v", pod, err)
+		}
+		start := []string{moveIn, waitRunning}
+		if pod == kubernetes.KubeAPIServer {
+			start = append(start, fmt.Sprintf(waitAPIServerReadyCmd, waitContainersRetries, k.getAPIServerPort()))
+		}
+		if err := k.sshCmdAsyncSeq(master, start...); err != nil {
+			return fmt.Errorf("failed to start %s: %v", pod, err)
+		}
+	}
+	return nil
+}
+
+// fetchRenewedAdminKubeConfig keeps the local admin.conf and the copies on nodes in sync with master0.
+func (k *KubeadmRuntime) fetchRenewedAdminKubeConfig(master0 string) error {
+	if err := k.sshFetch(master0, path.Join(kubernetesEtc, AdminConf), k.pathResolver.AdminFile()); err != nil {
+		return fmt.Errorf("failed to fetch renewed %s: %v", AdminConf, err)
+	}
+	k.clearKubeClient()
+	return k.syncLocalAdminKubeConfigCopies()
+}
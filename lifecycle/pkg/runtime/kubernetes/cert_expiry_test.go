@@ -0,0 +1,36 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStaticPodsToRestart(t *testing.T) {
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{AdminConf}, nil},
+		{[]string{"apiserver", "front-proxy-client"}, []string{"kube-apiserver"}},
+		{[]string{SchedulerConf, "apiserver-etcd-client", "etcd-peer"}, []string{"etcd", "kube-apiserver", "kube-scheduler"}},
+	}
+	for _, tt := range tests {
+		if got := staticPodsToRestart(tt.names); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("staticPodsToRestart(%v) = %v, want %v", tt.names, got, tt.want)
+		}
+	}
+}
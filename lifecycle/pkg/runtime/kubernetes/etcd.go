@@ -0,0 +1,344 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/labring/sealos/pkg/runtime"
+	fileutil "github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/hash"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	etcdPeerPort = 2380
+
+	// the etcd image has no shell, so etcdctl and etcdutl are copied out of the root of the running container
+	extractEtcdToolsCmd = `set -e
+id=$(crictl ps --name '^etcd$' --state running -q | head -n 1)
+if [ -z "$id" ]; then echo "etcd container is not running" >&2; exit 1; fi
+pid=$(crictl inspect --output go-template --template '{{.info.pid}}' "$id")
+mkdir -p %[1]s
+for tool in etcdctl etcdutl; do
+  if [ -f /proc/$pid/root/usr/local/bin/$tool ]; then install -m 0755 /proc/$pid/root/usr/local/bin/$tool %[1]s/$tool; fi
+done
+test -x %[1]s/etcdctl`
+	etcdSnapshotSaveCmd = "ETCDCTL_API=3 %s/etcdctl --endpoints=https://127.0.0.1:2379 " +
+		"--cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/healthcheck-client.crt " +
+		"--key=/etc/kubernetes/pki/etcd/healthcheck-client.key snapshot save %s"
+	// etcdutl replaces the offline subcommands of etcdctl since etcd v3.5
+	etcdSnapshotStatusCmd = "if [ -x %[1]s/etcdutl ]; then %[1]s/etcdutl snapshot status %[2]s -w json; " +
+		"else ETCDCTL_API=3 %[1]s/etcdctl snapshot status %[2]s -w json; fi"
+	etcdSnapshotRestoreCmd = `set -e
+rm -rf %[3]s
+if [ -x %[1]s/etcdutl ]; then tool="%[1]s/etcdutl"; else tool="env ETCDCTL_API=3 %[1]s/etcdctl"; fi
+$tool snapshot restore %[2]s --data-dir %[3]s --name %[4]s --initial-cluster %[5]s --initial-advertise-peer-urls %[6]s
+mkdir -p %[7]s %[8]s
+if [ -d %[7]s/member ]; then mv %[7]s/member %[8]s/; fi
+mv %[3]s/member %[7]s/member
+rm -rf %[3]s`
+	// etcdRollbackRestoreCmd puts the data kept by etcdSnapshotRestoreCmd back into the data dir
+	etcdRollbackRestoreCmd = `rm -rf %[1]s.restore
+if [ -d %[2]s/member ]; then mkdir -p %[1]s && rm -rf %[1]s/member && mv %[2]s/member %[1]s/member; fi
+rmdir %[2]s 2>/dev/null || true`
+	etcdDataDirCmd = `sed -n 's/.*--data-dir=\(.*\)/\1/p' ` + kubernetesEtcStaticPod + `/etcd.yaml | head -n 1`
+
+	moveStaticPodsCmd = `mkdir -p %[2]s; for f in %[3]s; do if [ -f %[1]s/$f ]; then mv %[1]s/$f %[2]s/; fi; done`
+	waitContainersCmd = `for i in $(seq 1 %[1]d); do
+  ok=1
+  for c in %[2]s; do
+    if [ -%[3]s "$(crictl ps --name "^$c\$" --state running -q)" ]; then ok=0; fi
+  done
+  if [ $ok -eq 1 ]; then exit 0; fi
+  sleep 2
+done
+echo "timed out waiting for %[2]s" >&2; exit 1`
+	waitContainersRetries = 90
+)
+
+var controlPlaneStaticPods = []string{"etcd", "kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+
+type etcdMember struct {
+	host    string
+	name    string
+	dataDir string
+}
+
+func (k *KubeadmRuntime) remoteEtcdDir() string {
+	return path.Join(k.pathResolver.Root(), "etcd")
+}
+
+func (k *KubeadmRuntime) localEtcdToolsDir() string {
+	return filepath.Join(k.pathResolver.EtcdBackupsPath(), "bin")
+}
+
+func (k *KubeadmRuntime) BackupEtcd(name string) (*runtime.EtcdSnapshot, error) {
+	master0 := k.getMaster0IPAndPort()
+	remoteDir := k.remoteEtcdDir()
+	remoteBin := path.Join(remoteDir, "bin")
+	remoteSnapshot := path.Join(remoteDir, name+".db")
+	localDir := k.pathResolver.EtcdBackupsPath()
+	localSnapshot := filepath.Join(localDir, name+".db")
+	if fileutil.IsExist(localSnapshot) {
+		return nil, fmt.Errorf("etcd snapshot %s already exists", localSnapshot)
+	}
+
+	logger.Info("start to take etcd snapshot %s on %s", name, master0)
+	if err := k.sshCmdAsync(master0, fmt.Sprintf(extractEtcdToolsCmd, remoteBin)); err != nil {
+		return nil, fmt.Errorf("failed to get etcdctl from the etcd container: %v", err)
+	}
+	if err := k.sshCmdAsync(master0, fmt.Sprintf(etcdSnapshotSaveCmd, remoteBin, remoteSnapshot)); err != nil {
+		return nil, fmt.Errorf("failed to save etcd snapshot: %v", err)
+	}
+	defer func() {
+		if err := k.sshCmdAsync(master0, "rm -f "+remoteSnapshot); err != nil {
+			logger.Warn("failed to remove etcd snapshot %s on %s: %v", remoteSnapshot, master0, err)
+		}
+	}()
+	out, err := k.sshCmdToString(master0, fmt.Sprintf(etcdSnapshotStatusCmd, remoteBin, remoteSnapshot))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of etcd snapshot: %v", err)
+	}
+	var status struct {
+		Revision  int64 `json:"revision"`
+		TotalKey  int64 `json:"totalKey"`
+		TotalSize int64 `json:"totalSize"`
+	}
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse status of etcd snapshot %q: %v", out, err)
+	}
+
+	if err := fileutil.MkDirs(localDir); err != nil {
+		return nil, err
+	}
+	if err := k.sshFetch(master0, remoteSnapshot, localSnapshot); err != nil {
+		return nil, fmt.Errorf("failed to fetch etcd snapshot: %v", err)
+	}
+	// keep the tools of this etcd version, restoring is possible when no etcd container is running
+	if err := k.fetchEtcdTools(master0, remoteBin); err != nil {
+		logger.Warn("failed to keep etcd tools locally: %v", err)
+	}
+
+	size, err := fileutil.GetFileSize(localSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &runtime.EtcdSnapshot{
+		Name:              name,
+		Cluster:           k.cluster.GetName(),
+		Host:              iputils.GetHostIP(master0),
+		CreatedAt:         time.Now(),
+		KubernetesVersion: k.getKubeVersionFromImage(),
+		Revision:          status.Revision,
+		TotalKeys:         status.TotalKey,
+		Size:              size,
+		SHA256:            hash.FileDigest(localSnapshot),
+		Path:              localSnapshot,
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := fileutil.WriteFile(etcdSnapshotMetaFile(localSnapshot), data); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (k *KubeadmRuntime) fetchEtcdTools(host, remoteBin string) error {
+	localBin := k.localEtcdToolsDir()
+	if err := fileutil.MkDirs(localBin); err != nil {
+		return err
+	}
+	out, err := k.execer.CmdToString(host, "ls "+remoteBin, " ")
+	if err != nil {
+		return err
+	}
+	for _, tool := range strings.Fields(out) {
+		if err := k.sshFetch(host, path.Join(remoteBin, tool), filepath.Join(localBin, tool)); err != nil {
+			return err
+		}
+		if err := os.Chmod(filepath.Join(localBin, tool), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func etcdPeerURL(host string) string {
+	return "https://" + net.JoinHostPort(iputils.GetHostIP(host), strconv.Itoa(etcdPeerPort))
+}
+
+func etcdSnapshotMetaFile(snapshot string) string {
+	return strings.TrimSuffix(snapshot, filepath.Ext(snapshot)) + ".json"
+}
+
+func (k *KubeadmRuntime) verifyEtcdSnapshot(snapshot string) error {
+	if !fileutil.IsFile(snapshot) {
+		return fmt.Errorf("etcd snapshot %s not found", snapshot)
+	}
+	data, err := os.ReadFile(etcdSnapshotMetaFile(snapshot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			logger.Warn("no metadata of etcd snapshot %s, skip checksum", snapshot)
+			return nil
+		}
+		return err
+	}
+	var meta runtime.EtcdSnapshot
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return fmt.Errorf("failed to parse metadata of etcd snapshot: %v", err)
+	}
+	if meta.Cluster != "" && meta.Cluster != k.cluster.GetName() {
+		return fmt.Errorf("etcd snapshot %s was taken from cluster %s", snapshot, meta.Cluster)
+	}
+	if digest := hash.FileDigest(snapshot); meta.SHA256 != "" && digest != meta.SHA256 {
+		return fmt.Errorf("checksum of etcd snapshot %s mismatch, expected %s, got %s", snapshot, meta.SHA256, digest)
+	}
+	return nil
+}
+
+func (k *KubeadmRuntime) RestoreEtcd(snapshot string) error {
+	if err := k.verifyEtcdSnapshot(snapshot); err != nil {
+		return err
+	}
+	masters := k.getMasterIPAndPortList()
+	localBin := k.localEtcdToolsDir()
+	remoteDir := k.remoteEtcdDir()
+	remoteBin := path.Join(remoteDir, "bin")
+	remoteSnapshot := path.Join(remoteDir, "restore.db")
+	manifestsBackup := path.Join(remoteDir, "manifests")
+	suffix := time.Now().Format("20060102150405")
+
+	if !fileutil.IsExist(filepath.Join(localBin, "etcdctl")) {
+		logger.Info("no local etcd tools, get them from the etcd container of %s", masters[0])
+		if err := k.sshCmdAsync(masters[0], fmt.Sprintf(extractEtcdToolsCmd, remoteBin)); err != nil {
+			return fmt.Errorf("failed to get etcdctl, take a backup while etcd is running to keep it locally: %v", err)
+		}
+		if err := k.fetchEtcdTools(masters[0], remoteBin); err != nil {
+			return err
+		}
+	}
+
+	members := make([]etcdMember, len(masters))
+	peers := make([]string, len(masters))
+	for i, master := range masters {
+		hostname, err := k.execHostname(master)
+		if err != nil {
+			return fmt.Errorf("get hostname of %s failed %v", master, err)
+		}
+		dataDir, err := k.sshCmdToString(master, etcdDataDirCmd)
+		if err != nil || strings.TrimSpace(dataDir) == "" {
+			dataDir = k.getEtcdDataDir()
+		}
+		members[i] = etcdMember{host: master, name: hostname, dataDir: strings.TrimSpace(dataDir)}
+		peers[i] = fmt.Sprintf("%s=%s", hostname, etcdPeerURL(master))
+	}
+	initialCluster := strings.Join(peers, ",")
+
+	staticPods := make([]string, len(controlPlaneStaticPods))
+	for i := range controlPlaneStaticPods {
+		staticPods[i] = controlPlaneStaticPods[i] + ".yaml"
+	}
+	stopControlPlane := fmt.Sprintf(moveStaticPodsCmd, kubernetesEtcStaticPod, manifestsBackup, strings.Join(staticPods, " "))
+	startControlPlane := fmt.Sprintf(moveStaticPodsCmd, manifestsBackup, kubernetesEtcStaticPod, strings.Join(staticPods, " "))
+	waitStopped := fmt.Sprintf(waitContainersCmd, waitContainersRetries, strings.Join(controlPlaneStaticPods, " "), "n")
+	waitRunning := fmt.Sprintf(waitContainersCmd, waitContainersRetries, "etcd kube-apiserver", "z")
+
+	onMasters := func(fn func(member etcdMember) error) error {
+		eg, _ := errgroup.WithContext(context.Background())
+		for _, member := range members {
+			member := member
+			eg.Go(func() error {
+				if err := fn(member); err != nil {
+					return fmt.Errorf("%s: %v", member.host, err)
+				}
+				return nil
+			})
+		}
+		return eg.Wait()
+	}
+
+	bringBack := func() error {
+		return onMasters(func(member etcdMember) error {
+			return k.sshCmdAsyncSeq(member.host, startControlPlane, waitRunning, "rm -f "+remoteSnapshot)
+		})
+	}
+
+	logger.Info("start to copy etcd snapshot %s to masters", snapshot)
+	if err := onMasters(func(member etcdMember) error {
+		if err := k.sshCopy(member.host, localBin, remoteBin); err != nil {
+			return err
+		}
+		return k.sshCopy(member.host, snapshot, remoteSnapshot)
+	}); err != nil {
+		return fmt.Errorf("failed to copy etcd snapshot: %v", err)
+	}
+
+	logger.Info("start to stop control plane static pods on masters")
+	if err := onMasters(func(member etcdMember) error {
+		return k.sshCmdAsyncSeq(member.host, stopControlPlane, waitStopped)
+	}); err != nil {
+		// put back what was stopped, the data of etcd is untouched
+		_ = onMasters(func(member etcdMember) error { return k.sshCmdAsync(member.host, startControlPlane) })
+		return fmt.Errorf("failed to stop control plane: %v", err)
+	}
+
+	logger.Info("start to restore etcd data on masters")
+	if err := onMasters(func(member etcdMember) error {
+		cmd := fmt.Sprintf(etcdSnapshotRestoreCmd, remoteBin, remoteSnapshot, member.dataDir+".restore",
+			member.name, initialCluster, etcdPeerURL(member.host),
+			member.dataDir, member.dataDir+".bak-"+suffix)
+		if err := k.sshCmdAsync(member.host, cmd); err != nil {
+			return err
+		}
+		logger.Info("etcd data of %s is restored, the previous data is kept in %s", member.host, member.dataDir+".bak-"+suffix)
+		return nil
+	}); err != nil {
+		// members with the restored data would form a new cluster next to the members with the previous data,
+		// put the previous data back on every member before the control plane is started again
+		logger.Error("failed to restore etcd, start to roll back etcd data on masters: %v", err)
+		if rollbackErr := onMasters(func(member etcdMember) error {
+			return k.sshCmdAsync(member.host,
+				fmt.Sprintf(etcdRollbackRestoreCmd, member.dataDir, member.dataDir+".bak-"+suffix))
+		}); rollbackErr != nil {
+			return fmt.Errorf("failed to restore etcd: %v, and failed to roll back etcd data, "+
+				"the control plane is kept stopped, the previous data is kept in <data-dir>.bak-%s: %v", err, suffix, rollbackErr)
+		}
+		if startErr := bringBack(); startErr != nil {
+			return fmt.Errorf("failed to restore etcd: %v, and failed to start control plane: %v", err, startErr)
+		}
+		return fmt.Errorf("failed to restore etcd, the previous data is rolled back: %v", err)
+	}
+
+	logger.Info("start to bring control plane back on masters")
+	if err := bringBack(); err != nil {
+		return fmt.Errorf("failed to start control plane: %v", err)
+	}
+	return k.pingAPIServer()
+}
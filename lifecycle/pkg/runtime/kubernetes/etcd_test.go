@@ -0,0 +1,134 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/utils/hash"
+)
+
+func TestEtcdPeerURL(t *testing.T) {
+	tests := map[string]string{
+		"192.168.0.2:22": "https://192.168.0.2:2380",
+		"192.168.0.2":    "https://192.168.0.2:2380",
+	}
+	for host, want := range tests {
+		if got := etcdPeerURL(host); got != want {
+			t.Errorf("etcdPeerURL(%s) = %s, want %s", host, got, want)
+		}
+	}
+}
+
+func TestVerifyEtcdSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "before-upgrade.db")
+	if err := os.WriteFile(snapshot, []byte("snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cluster := testCluster([]string{"192.168.0.2:22"})
+	cluster.Name = "default"
+	rt := &KubeadmRuntime{cluster: cluster}
+
+	writeMeta := func(meta runtime.EtcdSnapshot) {
+		data, _ := json.Marshal(meta)
+		if err := os.WriteFile(filepath.Join(dir, "before-upgrade.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := rt.verifyEtcdSnapshot(snapshot); err != nil {
+		t.Errorf("snapshot without metadata should be accepted, got %v", err)
+	}
+	if err := rt.verifyEtcdSnapshot(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected an error for a missing snapshot")
+	}
+
+	writeMeta(runtime.EtcdSnapshot{Cluster: "default", SHA256: hash.FileDigest(snapshot)})
+	if err := rt.verifyEtcdSnapshot(snapshot); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	writeMeta(runtime.EtcdSnapshot{Cluster: "default", SHA256: "0000"})
+	if err := rt.verifyEtcdSnapshot(snapshot); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+
+	writeMeta(runtime.EtcdSnapshot{Cluster: "other", SHA256: hash.FileDigest(snapshot)})
+	if err := rt.verifyEtcdSnapshot(snapshot); err == nil || !strings.Contains(err.Error(), "other") {
+		t.Errorf("expected an error for a snapshot of another cluster, got %v", err)
+	}
+}
+
+func TestEtcdRollbackRestoreCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		// restored and kept are the members in the data dir and the backup dir before the rollback
+		restored, kept bool
+		want           string
+	}{
+		{name: "restore finished", restored: true, kept: true, want: "previous"},
+		{name: "failed after the previous data is kept", kept: true, want: "previous"},
+		{name: "failed before the previous data is kept", restored: true, want: "restored"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dataDir := filepath.Join(dir, "etcd")
+			backupDir := dataDir + ".bak-20231001000000"
+			writeMember := func(dir, content string) {
+				if err := os.MkdirAll(filepath.Join(dir, "member"), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "member", "data"), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.restored {
+				writeMember(dataDir, "restored")
+			}
+			if tt.kept {
+				writeMember(backupDir, "previous")
+			} else if err := os.MkdirAll(backupDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			writeMember(dataDir+".restore", "partial")
+
+			cmd := fmt.Sprintf(etcdRollbackRestoreCmd, dataDir, backupDir)
+			if out, err := exec.Command("sh", "-c", cmd).CombinedOutput(); err != nil {
+				t.Fatalf("rollback failed: %v, %s", err, out)
+			}
+			data, err := os.ReadFile(filepath.Join(dataDir, "member", "data"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("expected %s data in the data dir, got %s", tt.want, data)
+			}
+			for _, leftover := range []string{backupDir, dataDir + ".restore"} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be removed, got %v", leftover, err)
+				}
+			}
+		})
+	}
+}
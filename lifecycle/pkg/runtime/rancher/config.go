@@ -0,0 +1,134 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rancher
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/emirpasic/gods/sets/linkedhashset"
+	"github.com/imdario/mergo"
+	netutils "k8s.io/utils/net"
+
+	"github.com/labring/sealos/pkg/constants"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	fileutils "github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/yaml"
+)
+
+var defaultMergeOpts = []func(*mergo.Config){
+	mergo.WithOverride,
+}
+
+// ParseConfig return nil if data structure is not matched
+func ParseConfig[T any](data []byte) (*T, error) {
+	var cfg T
+	if err := yaml.Unmarshal(bytes.NewBuffer(data), &cfg); err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	isNil, err := yaml.IsNil(out)
+	if err != nil {
+		return nil, err
+	}
+	if isNil {
+		return nil, nil
+	}
+	return &cfg, nil
+}
+
+func merge[T any](dst, src *T) error {
+	if src == nil || dst == nil {
+		return nil
+	}
+	return mergo.Merge(dst, src, defaultMergeOpts...)
+}
+
+// MergeConfig merges the config file shipped in the rootfs at rootfsConfig and then the config
+// provided in the Clusterfile into c, the later ones take precedence.
+func MergeConfig[T any](c *T, rootfsConfig string, provided *T) *T {
+	if err := func() error {
+		if !fileutils.IsExist(rootfsConfig) {
+			return nil
+		}
+		data, err := os.ReadFile(rootfsConfig)
+		if err != nil {
+			return err
+		}
+		parseCfg, err := ParseConfig[T](data)
+		if err != nil {
+			return err
+		}
+		return merge(c, parseCfg)
+	}(); err != nil {
+		logger.Error("failed to merge in place config file: %v", err)
+	}
+	if err := merge(c, provided); err != nil {
+		logger.Error("failed to merge provide config: %v", err)
+	}
+	return c
+}
+
+// CertSANs returns the names the api-server certificate is valid for, the configured tlsSAN included.
+func CertSANs(cluster *v2.Cluster, tlsSAN, serviceCIDR []string, clusterDomain string) []string {
+	masterIPs := iputils.GetHostIPs(cluster.GetMasterIPList())
+	var certSans []string
+	certSans = append(certSans, "127.0.0.1")
+	certSans = append(certSans, constants.DefaultAPIServerDomain)
+	certSans = append(certSans, cluster.GetVIP())
+	certSans = append(certSans, masterIPs...)
+	certSans = append(certSans, tlsSAN...)
+	certSans = append(certSans, serviceCIDR...)
+	certSans = append(certSans, clusterDomain)
+	return certSans
+}
+
+// KubeProxyArgs runs kube-proxy in ipvs mode without touching the virtual server of lvscare at vip.
+func KubeProxyArgs(args []string, vip string) []string {
+	kubeProxy := linkedhashset.New()
+	for _, v := range args {
+		kubeProxy.Add(v)
+	}
+	kubeProxy.Add(fmt.Sprintf("%s=%s", "ipvs-exclude-cidrs", fmt.Sprintf("%s/32", vip)))
+	kubeProxy.Add(fmt.Sprintf("%s=%s", "proxy-mode", "ipvs"))
+
+	var allArgs []string
+	for _, v := range kubeProxy.Values() {
+		allArgs = append(allArgs, v.(string))
+	}
+	return allArgs
+}
+
+// ClusterDNS returns the tenth address of the service CIDR like kubeadm, nil if it can't be computed.
+func ClusterDNS(serviceCIDR []string) []string {
+	if len(serviceCIDR) == 0 {
+		return nil
+	}
+	svcSubnetCIDR, err := netutils.ParseCIDRs(serviceCIDR)
+	if err != nil {
+		return nil
+	}
+	clusterDNS, err := netutils.GetIndexedIP(svcSubnetCIDR[0], 10)
+	if err != nil {
+		return nil
+	}
+	return []string{clusterDNS.String()}
+}
@@ -0,0 +1,175 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rancher holds what the k3s and rke2 runtimes have in common: both are configured by
+// a config.yaml on every node, run as a single service and bundle their own kubectl.
+package rancher
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/ssh"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/rand"
+)
+
+const (
+	getNodeNameArgs = "get nodes -o wide | awk '$6==\"%s\" {print $1}'"
+	pollInterval    = 5 * time.Second
+)
+
+// Runner runs the steps shared by the k3s and rke2 runtimes on the hosts of a cluster.
+type Runner struct {
+	cluster      *v2.Cluster
+	pathResolver constants.PathResolver
+	remoteUtil   *ssh.Remote
+	execer       exec.Interface
+	kubectl      string
+}
+
+// NewRunner returns a Runner, kubectl is the command to run kubectl with on master0.
+func NewRunner(cluster *v2.Cluster, pathResolver constants.PathResolver, remoteUtil *ssh.Remote,
+	execer exec.Interface, kubectl string) *Runner {
+	return &Runner{
+		cluster:      cluster,
+		pathResolver: pathResolver,
+		remoteUtil:   remoteUtil,
+		execer:       execer,
+		kubectl:      kubectl,
+	}
+}
+
+func RunPipelines(phase string, pipelines ...func() error) error {
+	logger.Info("starting %s", phase)
+	for i := range pipelines {
+		if err := pipelines[i](); err != nil {
+			return fmt.Errorf("failed to %s: %v", phase, err)
+		}
+	}
+	return nil
+}
+
+// Kubectl runs kubectl with args on master0.
+func (r *Runner) Kubectl(args string) error {
+	return r.execer.CmdAsync(r.cluster.GetMaster0IPAndPort(), r.kubectl+" "+args)
+}
+
+// RetryKubectl tolerates the short api-server outage while a server node restarts.
+func (r *Runner) RetryKubectl(args string) error {
+	timeout := time.Now().Add(time.Minute)
+	for {
+		err := r.Kubectl(args)
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return fmt.Errorf("run `kubectl %s` timeout within one minute: %w", args, err)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// NodeName returns the name of the node with the address of host.
+func (r *Runner) NodeName(host string) (string, error) {
+	nodeName, err := r.execer.CmdToString(r.cluster.GetMaster0IPAndPort(),
+		r.kubectl+" "+fmt.Sprintf(getNodeNameArgs, iputils.GetHostIP(host)), "")
+	if err != nil {
+		return "", fmt.Errorf("cannot get node with ip address %s: %v", host, err)
+	}
+	nodeName = strings.TrimSpace(nodeName)
+	if nodeName == "" {
+		return "", fmt.Errorf("cannot find node with ip address %s", host)
+	}
+	return nodeName, nil
+}
+
+func (r *Runner) generateRandomTokenFileIfNotExists(filename string) (string, error) {
+	fp := filepath.Join(r.pathResolver.EtcPath(), filepath.Base(filename))
+	if !file.IsExist(fp) {
+		logger.Debug("token file %s not exists, create new one", fp)
+		token, err := rand.CreateCertificateKey()
+		if err != nil {
+			return "", err
+		}
+		return fp, file.WriteFile(fp, []byte(token))
+	}
+	return fp, nil
+}
+
+// SendTokenFiles copies the token files into the configs dir of host, they are generated once per cluster.
+func (r *Runner) SendTokenFiles(host string, filenames ...string) error {
+	for _, filename := range filenames {
+		src, err := r.generateRandomTokenFileIfNotExists(filename)
+		if err != nil {
+			return fmt.Errorf("generate token: %v", err)
+		}
+		dst := filepath.Join(r.pathResolver.ConfigsPath(), filename)
+		if err = r.execer.Copy(host, src, dst); err != nil {
+			return fmt.Errorf("copy token file: %v", err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) EnableService(host, service string) error {
+	logger.Info("enable %s service on %s", service, host)
+	if err := r.remoteUtil.InitSystem(host).ServiceEnable(service); err != nil {
+		return err
+	}
+	return r.remoteUtil.InitSystem(host).ServiceStart(service)
+}
+
+// FetchKubeConfig pulls the kubeconfig written by the server on master0 into the admin file.
+func (r *Runner) FetchKubeConfig(path string) error {
+	return r.execer.Fetch(r.cluster.GetMaster0IPAndPort(), path, r.pathResolver.AdminFile())
+}
+
+// CopyKubeConfigToNodes copies the admin file to the hosts, the server address written by the
+// distribution, like https://127.0.0.1, is replaced with the api-server domain first.
+func (r *Runner) CopyKubeConfigToNodes(server string, hosts ...string) error {
+	src := r.pathResolver.AdminFile()
+	data, err := file.ReadAll(src)
+	if err != nil {
+		return errors.WithMessage(err, "read admin.config file failed")
+	}
+	newData := strings.ReplaceAll(string(data), server, fmt.Sprintf("https://%s", constants.DefaultAPIServerDomain))
+	if err = file.WriteFile(src, []byte(newData)); err != nil {
+		return errors.WithMessage(err, "write admin.config file failed")
+	}
+	eg, _ := errgroup.WithContext(context.Background())
+	for _, node := range hosts {
+		node := node
+		eg.Go(func() error {
+			home, err := r.execer.CmdToString(node, "echo $HOME", "")
+			if err != nil {
+				return err
+			}
+			dst := filepath.Join(home, ".kube", "config")
+			return r.execer.Copy(node, src, dst)
+		})
+	}
+	return eg.Wait()
+}
@@ -0,0 +1,133 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rancher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	drainNodeArgs  = "drain %s --ignore-daemonsets --delete-emptydir-data --force --timeout=%s"
+	nodeStatusArgs = `get node %s -o jsonpath='{.status.nodeInfo.kubeletVersion} {.status.conditions[?(@.type=="Ready")].status}'`
+	drainTimeout   = 5 * time.Minute
+)
+
+// CheckUpgradeVersion reports whether the cluster needs to move from current to target,
+// refusing downgrades and skipping more than one minor release like kubeadm does.
+func CheckUpgradeVersion(current, target string) (bool, error) {
+	v1, err := semver.NewVersion(target)
+	if err != nil {
+		return false, fmt.Errorf("parse target version %q: %w", target, err)
+	}
+	if current == "" {
+		return true, nil
+	}
+	v0, err := semver.NewVersion(current)
+	if err != nil {
+		return false, fmt.Errorf("parse current version %q: %w", current, err)
+	}
+	if v0.Equal(v1) && v0.Metadata() == v1.Metadata() {
+		return false, nil
+	}
+	if v0.GreaterThan(v1) {
+		return false, fmt.Errorf("cannot apply an older version %s than %s", target, current)
+	}
+	if v0.Major() != v1.Major() || v0.Minor()+1 < v1.Minor() {
+		return false, fmt.Errorf("cannot be upgraded across more than one minor releases, %s -> %s", current, target)
+	}
+	return true, nil
+}
+
+// UpgradeOrder returns servers first, starting with master0, and agents afterwards,
+// so that the control plane is always running the newest version before its agents.
+func UpgradeOrder(master0 string, masters, nodes []string) []string {
+	hosts := make([]string, 0, len(masters)+len(nodes))
+	hosts = append(hosts, master0)
+	for _, m := range masters {
+		if m != master0 {
+			hosts = append(hosts, m)
+		}
+	}
+	return append(hosts, nodes...)
+}
+
+// NodeUpgrade describes the in-place upgrade of a node.
+type NodeUpgrade struct {
+	Host string
+	// Config is the regenerated local config file, it replaces ConfigPath on the node.
+	Config     string
+	ConfigPath string
+	// InstallCmd installs the binary of the new rootfs on the node.
+	InstallCmd string
+	Service    string
+	Version    string
+	// Drain evicts the pods of the node first, a single node cluster is only cordoned.
+	Drain        bool
+	ReadyTimeout time.Duration
+}
+
+// UpgradeNode restarts the node with the new binary and config and waits for it to be ready with the new version.
+func (r *Runner) UpgradeNode(u NodeUpgrade) error {
+	nodeName, err := r.NodeName(u.Host)
+	if err != nil {
+		return err
+	}
+	return RunPipelines(fmt.Sprintf("upgrade node %s", nodeName),
+		func() error {
+			if !u.Drain {
+				return r.Kubectl(fmt.Sprintf("cordon %s", nodeName))
+			}
+			return r.Kubectl(fmt.Sprintf(drainNodeArgs, nodeName, drainTimeout))
+		},
+		func() error { return r.execer.Copy(u.Host, u.Config, u.ConfigPath) },
+		func() error { return r.execer.CmdAsync(u.Host, u.InstallCmd) },
+		func() error {
+			logger.Info("restart %s service on %s", u.Service, u.Host)
+			return r.remoteUtil.InitSystem(u.Host).ServiceRestart(u.Service)
+		},
+		func() error { return r.waitNodeReady(nodeName, u.Version, u.ReadyTimeout) },
+		func() error { return r.RetryKubectl(fmt.Sprintf("uncordon %s", nodeName)) },
+	)
+}
+
+func (r *Runner) waitNodeReady(nodeName, version string, readyTimeout time.Duration) error {
+	logger.Info("waiting for node %s to be ready with version %s", nodeName, version)
+	timeout := time.Now().Add(readyTimeout)
+	for {
+		out, err := r.execer.CmdToString(r.cluster.GetMaster0IPAndPort(), r.kubectl+" "+fmt.Sprintf(nodeStatusArgs, nodeName), "")
+		if err == nil && isNodeUpgraded(out, version) {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return fmt.Errorf("node %s is not ready with version %s within %s, last status: %q", nodeName, version, readyTimeout, out)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// isNodeUpgraded parses the output of nodeStatusArgs, "<kubeletVersion> <Ready status>".
+func isNodeUpgraded(out, version string) bool {
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return false
+	}
+	return fields[0] == version && fields[1] == "True"
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rancher
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckUpgradeVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		target  string
+		want    bool
+		wantErr bool
+	}{
+		{name: "same version", current: "v1.27.4+k3s1", target: "v1.27.4+k3s1", want: false},
+		{name: "k3s release bump", current: "v1.27.4+k3s1", target: "v1.27.4+k3s2", want: true},
+		{name: "patch upgrade", current: "v1.27.4+k3s1", target: "v1.27.7+k3s1", want: true},
+		{name: "minor upgrade", current: "v1.27.4+k3s1", target: "v1.28.2+k3s1", want: true},
+		{name: "skip minor", current: "v1.26.4+k3s1", target: "v1.28.2+k3s1", wantErr: true},
+		{name: "downgrade", current: "v1.28.2+k3s1", target: "v1.27.4+k3s1", wantErr: true},
+		{name: "unknown current", current: "", target: "v1.27.4+k3s1", want: true},
+		{name: "invalid target", current: "v1.27.4+k3s1", target: "latest", wantErr: true},
+		{name: "rke2 release bump", current: "v1.27.4+rke2r1", target: "v1.27.4+rke2r2", want: true},
+		{name: "rke2 minor upgrade", current: "v1.27.4+rke2r1", target: "v1.28.2+rke2r1", want: true},
+		{name: "rke2 skip minor", current: "v1.26.4+rke2r1", target: "v1.28.2+rke2r1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckUpgradeVersion(tt.current, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckUpgradeVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CheckUpgradeVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradeOrder(t *testing.T) {
+	got := UpgradeOrder("10.0.0.2:22",
+		[]string{"10.0.0.1:22", "10.0.0.2:22", "10.0.0.3:22"},
+		[]string{"10.0.0.4:22", "10.0.0.5:22"},
+	)
+	want := []string{"10.0.0.2:22", "10.0.0.1:22", "10.0.0.3:22", "10.0.0.4:22", "10.0.0.5:22"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UpgradeOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestIsNodeUpgraded(t *testing.T) {
+	if !isNodeUpgraded("v1.27.4+k3s1 True\n", "v1.27.4+k3s1") {
+		t.Error("expected node to be upgraded")
+	}
+	if isNodeUpgraded("v1.26.4+k3s1 True", "v1.27.4+k3s1") {
+		t.Error("expected old kubelet version to be rejected")
+	}
+	if isNodeUpgraded("v1.27.4+k3s1 Unknown", "v1.27.4+k3s1") {
+		t.Error("expected not ready node to be rejected")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/runtime/rancher"
+	"github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/yaml"
+)
+
+func (r *RKE2) initCallbacks() []callback {
+	return []callback{defaultingConfig, r.merge, r.sealosCfg, r.overrideCertSans, r.overrideServerConfig}
+}
+
+func (r *RKE2) initMaster0() error {
+	master0 := r.cluster.GetMaster0IPAndPort()
+	return rancher.RunPipelines("init master0",
+		func() error { return r.runner.SendTokenFiles(master0, "token", "agent-token") },
+		r.generateAndSendInitConfig,
+		func() error { return r.runner.EnableService(master0, serverServiceName) },
+		func() error { return r.runner.FetchKubeConfig(defaultKubeConfigPath) },
+		func() error {
+			return r.remoteUtil.HostsAdd(master0, iputils.GetHostIP(master0), constants.DefaultAPIServerDomain)
+		},
+		func() error { return r.runner.CopyKubeConfigToNodes(kubeConfigServer, master0) },
+	)
+}
+
+func (r *RKE2) joinMasters(masters []string) error {
+	if _, err := r.writeJoinConfigWithCallbacks(serverMode); err != nil {
+		return err
+	}
+	// servers join the embedded etcd cluster one by one
+	for _, master := range masters {
+		if err := r.joinMaster(master); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RKE2) writeJoinConfigWithCallbacks(runMode string, callbacks ...callback) (string, error) {
+	defaultCallbacks := []callback{defaultingConfig, r.merge, r.sealosCfg, r.overrideCertSans}
+	switch runMode {
+	case serverMode:
+		defaultCallbacks = append(defaultCallbacks, r.overrideServerConfig)
+	case agentMode:
+		defaultCallbacks = append(defaultCallbacks, r.overrideAgentConfig)
+	}
+	defaultCallbacks = append(defaultCallbacks, r.joinServerURL)
+	raw, err := r.getRawInitConfig(append(defaultCallbacks, callbacks...)...)
+	if err != nil {
+		return "", err
+	}
+	var filename string
+	switch runMode {
+	case serverMode:
+		filename = defaultJoinMastersFilename
+	case agentMode:
+		filename = defaultJoinNodesFilename
+	}
+	path := filepath.Join(r.pathResolver.EtcPath(), filename)
+	return path, file.WriteFile(path, raw)
+}
+
+func (r *RKE2) joinMaster(master string) error {
+	return rancher.RunPipelines(fmt.Sprintf("join master %s", master),
+		func() error { return r.runner.SendTokenFiles(master, "token", "agent-token") },
+		func() error {
+			return r.execer.Copy(master, filepath.Join(r.pathResolver.EtcPath(), defaultJoinMastersFilename), defaultRKE2ConfigPath)
+		},
+		func() error { return r.runner.EnableService(master, serverServiceName) },
+		func() error {
+			return r.remoteUtil.HostsAdd(master, iputils.GetHostIP(master), constants.DefaultAPIServerDomain)
+		},
+		func() error { return r.runner.CopyKubeConfigToNodes(kubeConfigServer, master) },
+	)
+}
+
+func (r *RKE2) joinNodes(nodes []string) error {
+	if _, err := r.writeJoinConfigWithCallbacks(agentMode, removeServerFlagsInAgentConfig); err != nil {
+		return err
+	}
+	for i := range nodes {
+		if err := r.joinNode(nodes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RKE2) joinNode(node string) error {
+	return rancher.RunPipelines(fmt.Sprintf("join node %s", node),
+		func() error {
+			return r.remoteUtil.IPVS(node, r.getVipAndPort(), r.getMasterIPListAndPort())
+		},
+		func() error { return r.runner.SendTokenFiles(node, "agent-token") },
+		func() error {
+			return r.execer.Copy(node, filepath.Join(r.pathResolver.EtcPath(), defaultJoinNodesFilename), defaultRKE2ConfigPath)
+		},
+		func() error { return r.runner.EnableService(node, agentServiceName) },
+		func() error { return r.runner.CopyKubeConfigToNodes(kubeConfigServer, node) },
+	)
+}
+
+func (r *RKE2) getMasterIPListAndPort() []string {
+	masters := make([]string, 0)
+	for _, master := range r.cluster.GetMasterIPList() {
+		masters = append(masters, fmt.Sprintf("%s:%d", master, constants.DefaultAPIServerPort))
+	}
+	return masters
+}
+
+func (r *RKE2) getVipAndPort() string {
+	return fmt.Sprintf("%s:%d", r.cluster.GetVIP(), constants.DefaultAPIServerPort)
+}
+
+func (r *RKE2) getRawInitConfig(callbacks ...callback) ([]byte, error) {
+	cfg, err := r.getInitConfig(callbacks...)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.MarshalConfigs(cfg)
+}
+
+func (r *RKE2) generateAndSendInitConfig() error {
+	src := filepath.Join(r.pathResolver.EtcPath(), defaultInitFilename)
+	if !file.IsExist(src) {
+		raw, err := r.getRawInitConfig(r.initCallbacks()...)
+		if err != nil {
+			return err
+		}
+		if err = file.WriteFile(src, raw); err != nil {
+			return err
+		}
+	}
+	return r.execer.Copy(r.cluster.GetMaster0IPAndPort(), src, defaultRKE2ConfigPath)
+}
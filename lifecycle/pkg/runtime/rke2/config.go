@@ -0,0 +1,103 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/runtime/rancher"
+)
+
+func defaultingConfig(c *Config) *Config {
+	c.BindAddress = "0.0.0.0"
+	c.ClusterCIDR = []string{"10.42.0.0/16"}
+	c.ServiceCIDR = []string{"10.96.0.0/16"}
+	c.ClusterDomain = constants.DefaultDNSDomain
+	c.KubeConfigMode = "0644"
+	c.DisableCloudController = true
+	defaultingAgentConfig(c)
+	return c
+}
+
+func defaultingAgentConfig(c *Config) *Config {
+	if c.AgentConfig == nil {
+		c.AgentConfig = &AgentConfig{}
+	}
+	c.AgentConfig.DataDir = defaultDataDir
+	c.AgentConfig.ExtraKubeProxyArgs = []string{}
+	c.AgentConfig.ExtraKubeletArgs = []string{}
+	c.AgentConfig.PrivateRegistry = defaultRegistryConfigPath
+	c.AgentConfig.Labels = []string{NodeLabel}
+	return c
+}
+
+// avoid unknown flags
+func removeServerFlagsInAgentConfig(c *Config) *Config {
+	agentConfig := *c.AgentConfig
+	return &Config{AgentConfig: &agentConfig}
+}
+
+type callback func(*Config) *Config
+
+func (r *RKE2) merge(c *Config) *Config {
+	return rancher.MergeConfig(c, filepath.Join(r.pathResolver.RootFSEtcPath(), defaultRootFsRKE2FileName), r.config)
+}
+
+func (r *RKE2) overrideCertSans(c *Config) *Config {
+	c.TLSSan = rancher.CertSANs(r.cluster, c.TLSSan, c.ServiceCIDR, c.ClusterDomain)
+	return c
+}
+
+func (r *RKE2) sealosCfg(c *Config) *Config {
+	c.AgentConfig.ExtraKubeProxyArgs = rancher.KubeProxyArgs(c.AgentConfig.ExtraKubeProxyArgs, r.cluster.GetVIP())
+	return c
+}
+
+func (r *RKE2) overrideServerConfig(c *Config) *Config {
+	c.AgentConfig.TokenFile = filepath.Join(r.pathResolver.ConfigsPath(), "token")
+	c.AgentTokenFile = filepath.Join(r.pathResolver.ConfigsPath(), "agent-token")
+
+	if len(c.ClusterDNS) == 0 {
+		c.ClusterDNS = rancher.ClusterDNS(c.ServiceCIDR)
+	}
+	return c
+}
+
+func (r *RKE2) overrideAgentConfig(c *Config) *Config {
+	c.AgentConfig.TokenFile = filepath.Join(r.pathResolver.ConfigsPath(), "agent-token")
+	return c
+}
+
+// joinServerURL points joining servers and agents to the supervisor of master0,
+// the agents discover the other servers and load balance to them by themselves.
+func (r *RKE2) joinServerURL(c *Config) *Config {
+	c.ServerURL = fmt.Sprintf("https://%s:%d", r.cluster.GetMaster0IP(), supervisorPort)
+	return c
+}
+
+func (r *RKE2) getInitConfig(callbacks ...callback) (*Config, error) {
+	cfg := &Config{}
+	for i := range callbacks {
+		cfg = callbacks[i](cfg)
+	}
+	return cfg, nil
+}
+
+// ParseConfig return nil if data structure is not matched
+func ParseConfig(data []byte) (*Config, error) {
+	return rancher.ParseConfig[Config](data)
+}
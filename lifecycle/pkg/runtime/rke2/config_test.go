@@ -0,0 +1,44 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte("cni:\n- cilium\ntls-san:\n- 10.0.0.1\nnode-label:\n- foo=bar\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if cfg == nil || len(cfg.CNI) != 1 || cfg.CNI[0] != "cilium" || cfg.AgentConfig == nil || cfg.Labels[0] != "foo=bar" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg, _ = ParseConfig([]byte("apiVersion: v1\nkind: ConfigMap\n")); cfg != nil {
+		t.Fatalf("expected nil config for unrelated data, got %+v", cfg)
+	}
+}
+
+func TestRemoveServerFlagsInAgentConfig(t *testing.T) {
+	c := defaultingConfig(&Config{})
+	c.ServerURL = "https://10.0.0.1:9345"
+	agent := removeServerFlagsInAgentConfig(c)
+	if agent.ClusterDomain != "" || len(agent.ClusterCIDR) != 0 {
+		t.Errorf("server flags should be removed: %+v", agent)
+	}
+	if agent.ServerURL != c.ServerURL || agent.DataDir != defaultDataDir {
+		t.Errorf("agent flags should be kept: %+v", agent.AgentConfig)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+const Distribution = "rke2"
+
+// NodeLabel is set on the nodes of rke2 clusters by the default config.
+const NodeLabel = "sealos.io/distribution=rke2"
+
+const (
+	defaultRKE2ConfigPath      = "/etc/rancher/rke2/config.yaml"
+	defaultRegistryConfigPath  = "/etc/rancher/rke2/registries.yaml"
+	defaultKubeConfigPath      = "/etc/rancher/rke2/rke2.yaml"
+	defaultDataDir             = "/var/lib/rancher/rke2"
+	defaultRootFsRKE2FileName  = "rke2.yml"
+	defaultInitFilename        = "rke2-init.yaml"
+	defaultJoinMastersFilename = "rke2-join-master.yaml"
+	defaultJoinNodesFilename   = "rke2-join-node.yaml"
+	rke2EtcStaticPod           = "/var/lib/rancher/rke2/agent/pod-manifests"
+	// kubeConfigServer is the server address in the kubeconfig written by rke2
+	kubeConfigServer = "https://127.0.0.1"
+	// supervisorPort is the port servers listen on for nodes to register,
+	// unlike k3s it is different from the kube-apiserver port.
+	supervisorPort = 9345
+)
+
+const (
+	serverMode = "server"
+	agentMode  = "agent"
+)
+
+const (
+	serverServiceName = "rke2-server"
+	agentServiceName  = "rke2-agent"
+)
@@ -0,0 +1,91 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+	stringsutil "github.com/labring/sealos/pkg/utils/strings"
+)
+
+// kubectl is not in PATH of rke2 servers, use the bundled one.
+const kubectlCmd = "/var/lib/rancher/rke2/bin/kubectl --kubeconfig " + defaultKubeConfigPath
+
+func (r *RKE2) resetNodes(nodes []string) error {
+	eg, _ := errgroup.WithContext(context.Background())
+	for i := range nodes {
+		node := nodes[i]
+		eg.Go(func() error {
+			return r.resetNode(node)
+		})
+	}
+	return eg.Wait()
+}
+
+func (r *RKE2) removeNodes(nodes []string) error {
+	eg, _ := errgroup.WithContext(context.Background())
+	for i := range nodes {
+		node := nodes[i]
+		eg.Go(func() error {
+			if err := r.deleteNode(node); err != nil {
+				return err
+			}
+			return r.resetNode(node)
+		})
+	}
+	return eg.Wait()
+}
+
+func (r *RKE2) resetNode(host string) error {
+	logger.Info("start to reset node: %s", host)
+	removeKubeConfig := "rm -rf $HOME/.kube"
+	if err := r.execer.CmdAsync(host, removeKubeConfig); err != nil {
+		logger.Error("failed to clean node, exec command %s failed, %v", removeKubeConfig, err)
+	}
+	if slices.Contains(r.cluster.GetNodeIPAndPortList(), host) {
+		if err := r.remoteUtil.IPVSClean(host, r.getVipAndPort()); err != nil {
+			logger.Error("failed to clear ipvs rules for node %s: %v", host, err)
+		}
+	}
+	return nil
+}
+
+func (r *RKE2) deleteNode(node string) error {
+	masterIPs := r.cluster.GetMasterIPList()
+	if slices.Contains(r.cluster.GetMasterIPAndPortList(), node) {
+		masterIPs = stringsutil.RemoveFromSlice(r.cluster.GetMasterIPList(), node)
+	}
+	if len(masterIPs) > 0 {
+		if err := r.removeNode(node); err != nil {
+			logger.Warn(fmt.Errorf("delete nodes %s failed %v", node, err))
+		}
+	}
+	return nil
+}
+
+func (r *RKE2) removeNode(ip string) error {
+	logger.Info("start to remove node from rke2 %s", ip)
+	nodeName, err := r.runner.NodeName(ip)
+	if err != nil {
+		return err
+	}
+	logger.Debug("found node name is %s, we will delete it", nodeName)
+	return r.runner.Kubectl(fmt.Sprintf("delete node %s --ignore-not-found=true", nodeName))
+}
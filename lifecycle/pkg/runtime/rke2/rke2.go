@@ -0,0 +1,151 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"context"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/runtime/rancher"
+	"github.com/labring/sealos/pkg/ssh"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/strings"
+	"github.com/labring/sealos/pkg/utils/yaml"
+)
+
+type RKE2 struct {
+	cluster *v2.Cluster
+	config  *Config
+
+	pathResolver constants.PathResolver
+	remoteUtil   *ssh.Remote
+	execer       exec.Interface
+	runner       *rancher.Runner
+}
+
+func New(cluster *v2.Cluster, config any) (*RKE2, error) {
+	sshClient := ssh.NewCacheClientFromCluster(cluster, true)
+	execer, err := exec.New(sshClient)
+	if err != nil {
+		return nil, err
+	}
+	r := &RKE2{
+		cluster:      cluster,
+		pathResolver: constants.NewPathResolver(cluster.GetName()),
+		execer:       execer,
+		remoteUtil:   ssh.NewRemoteFromSSH(cluster.GetName(), execer),
+	}
+	r.runner = rancher.NewRunner(cluster, r.pathResolver, r.remoteUtil, execer, kubectlCmd)
+	if v, ok := config.(*Config); ok {
+		r.config = v
+	}
+	return r, nil
+}
+
+func (r *RKE2) Init() error {
+	return r.initMaster0()
+}
+
+func (r *RKE2) Reset() error {
+	if err := r.resetNodes(r.cluster.GetNodeIPAndPortList()); err != nil {
+		logger.Error("resetting nodes: %v", err)
+	}
+	if err := r.resetNodes(r.cluster.GetMasterIPAndPortList()); err != nil {
+		logger.Error("resetting masters: %v", err)
+	}
+	return nil
+}
+
+func (r *RKE2) ScaleUp(masters []string, nodes []string) error {
+	if len(masters) != 0 {
+		logger.Info("%s will be added as master", masters)
+		if err := r.joinMasters(masters); err != nil {
+			return err
+		}
+	}
+	if len(nodes) != 0 {
+		logger.Info("%s will be added as worker", nodes)
+		if err := r.joinNodes(nodes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RKE2) ScaleDown(masters []string, nodes []string) error {
+	if len(masters) != 0 {
+		logger.Info("master %s will be deleted", masters)
+		if err := r.removeNodes(masters); err != nil {
+			return err
+		}
+	}
+	if len(nodes) != 0 {
+		logger.Info("worker %s will be deleted", nodes)
+		return r.removeNodes(nodes)
+	}
+	return nil
+}
+
+func (r *RKE2) Upgrade(version string) error {
+	currVersion := r.getRKE2VersionFromImage()
+	needUpgrade, err := rancher.CheckUpgradeVersion(currVersion, version)
+	if err != nil {
+		return err
+	}
+	if !needUpgrade {
+		logger.Info("skip upgrade because of same version")
+		return nil
+	}
+	logger.Info("start to upgrade rke2 from %s to %s", currVersion, version)
+	return r.upgradeCluster(version)
+}
+
+func (r *RKE2) GetRawConfig() ([]byte, error) {
+	cfg, err := r.getInitConfig(r.initCallbacks()...)
+	if err != nil {
+		return nil, err
+	}
+	cluster := r.cluster.DeepCopy()
+	cluster.Status = v2.ClusterStatus{}
+	return yaml.MarshalConfigs(cluster, cfg)
+}
+
+func (r *RKE2) SyncNodeIPVS(mastersIPList, nodeIPList []string) error {
+	mastersIPList = strings.RemoveDuplicate(mastersIPList)
+	masters := make([]string, 0)
+	for _, master := range mastersIPList {
+		masters = append(masters, fmt.Sprintf("%s:%d", iputils.GetHostIP(master), constants.DefaultAPIServerPort))
+	}
+	image := r.cluster.GetLvscareImage()
+	eg, _ := errgroup.WithContext(context.Background())
+	for _, node := range nodeIPList {
+		node := node
+		eg.Go(func() error {
+			logger.Info("start to sync lvscare static pod to node: %s master: %+v", node, masters)
+			err := r.remoteUtil.StaticPod(node, r.getVipAndPort(), constants.LvsCareStaticPodName, image, masters, rke2EtcStaticPod, "--health-status", "401")
+			if err != nil {
+				return fmt.Errorf("update lvscare static pod failed %s %v", node, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
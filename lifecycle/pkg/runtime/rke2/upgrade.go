@@ -0,0 +1,94 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/runtime/rancher"
+	"github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/file"
+)
+
+const (
+	installRKE2Cmd   = "cp -rf %s/rke2 /usr/local/bin/rke2"
+	nodeReadyTimeout = 10 * time.Minute
+)
+
+func (r *RKE2) getRKE2VersionFromImage() string {
+	img := r.cluster.GetRootfsImage()
+	if img == nil || img.Labels == nil {
+		return ""
+	}
+	return img.Labels[v1beta1.ImageKubeVersionKey]
+}
+
+// upgradeCluster upgrades master0 first, then the other servers and finally the agents, one node at a time.
+func (r *RKE2) upgradeCluster(version string) error {
+	if err := r.writeUpgradeConfigs(); err != nil {
+		return err
+	}
+	master0 := r.cluster.GetMaster0IPAndPort()
+	masters := r.cluster.GetMasterIPAndPortList()
+	hosts := rancher.UpgradeOrder(master0, masters, r.cluster.GetNodeIPAndPortList())
+	for _, host := range hosts {
+		configFile, service := nodeConfigAndService(host, master0, masters)
+		if err := r.runner.UpgradeNode(rancher.NodeUpgrade{
+			Host:         host,
+			Config:       filepath.Join(r.pathResolver.EtcPath(), configFile),
+			ConfigPath:   defaultRKE2ConfigPath,
+			InstallCmd:   fmt.Sprintf(installRKE2Cmd, r.pathResolver.RootFSBinPath()),
+			Service:      service,
+			Version:      version,
+			Drain:        len(hosts) > 1,
+			ReadyTimeout: nodeReadyTimeout,
+		}); err != nil {
+			return fmt.Errorf("upgrade node %s to %s: %w", host, version, err)
+		}
+	}
+	return nil
+}
+
+// nodeConfigAndService returns the config file and the service of host, servers run rke2-server
+// and agents run rke2-agent unlike k3s.
+func nodeConfigAndService(host, master0 string, masters []string) (string, string) {
+	switch {
+	case host == master0:
+		return defaultInitFilename, serverServiceName
+	case slices.Contains(masters, host):
+		return defaultJoinMastersFilename, serverServiceName
+	}
+	return defaultJoinNodesFilename, agentServiceName
+}
+
+// writeUpgradeConfigs regenerates the config files so that defaults shipped in the new rootfs rke2.yml are picked up.
+func (r *RKE2) writeUpgradeConfigs() error {
+	raw, err := r.getRawInitConfig(r.initCallbacks()...)
+	if err != nil {
+		return err
+	}
+	if err = file.WriteFile(filepath.Join(r.pathResolver.EtcPath(), defaultInitFilename), raw); err != nil {
+		return err
+	}
+	if _, err = r.writeJoinConfigWithCallbacks(serverMode); err != nil {
+		return err
+	}
+	_, err = r.writeJoinConfigWithCallbacks(agentMode, removeServerFlagsInAgentConfig)
+	return err
+}
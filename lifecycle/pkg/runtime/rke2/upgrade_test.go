@@ -0,0 +1,56 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rke2
+
+import (
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestNodeConfigAndService(t *testing.T) {
+	masters := []string{"10.0.0.1:22", "10.0.0.2:22"}
+	tests := []struct {
+		host    string
+		config  string
+		service string
+	}{
+		{host: "10.0.0.2:22", config: defaultInitFilename, service: serverServiceName},
+		{host: "10.0.0.1:22", config: defaultJoinMastersFilename, service: serverServiceName},
+		{host: "10.0.0.3:22", config: defaultJoinNodesFilename, service: agentServiceName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			config, service := nodeConfigAndService(tt.host, "10.0.0.2:22", masters)
+			if config != tt.config || service != tt.service {
+				t.Errorf("nodeConfigAndService() = %s, %s, want %s, %s", config, service, tt.config, tt.service)
+			}
+		})
+	}
+}
+
+func TestJoinServerURL(t *testing.T) {
+	cluster := &v2.Cluster{}
+	cluster.Spec.Hosts = []v2.Host{
+		{IPS: []string{"10.0.0.1:22", "10.0.0.2:22"}, Roles: []string{v2.MASTER}},
+		{IPS: []string{"10.0.0.3:22"}, Roles: []string{v2.NODE}},
+	}
+	r := &RKE2{cluster: cluster}
+	// the regenerated join configs point to the supervisor of master0 rather than the api-server
+	c := r.joinServerURL(defaultingConfig(&Config{}))
+	if want := "https://10.0.0.1:9345"; c.ServerURL != want {
+		t.Errorf("joinServerURL() = %s, want %s", c.ServerURL, want)
+	}
+}
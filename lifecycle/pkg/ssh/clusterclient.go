@@ -16,6 +16,7 @@ package ssh
 
 import (
 	"context"
+	"io"
 	"strings"
 	"sync"
 
@@ -50,6 +51,9 @@ func OverSSHConfig(original, override *v1beta1.SSH) {
 		if override.Port > 0 {
 			original.Port = override.Port
 		}
+		if override.KnownHosts != "" {
+			original.KnownHosts = override.KnownHosts
+		}
 	}
 }
 
@@ -70,6 +74,11 @@ func (cc *clusterClient) getSSHOptionForHost(host string) (*Option, error) {
 	}
 
 	opt := newOptionFromSSH(sshConfig, cc.isStdout)
+	hostKeyCallback, err := knownHostsCallback(cc.cluster, sshConfig.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	opt.hostKeyCallback = hostKeyCallback
 	cc.mutex.Lock()
 	cc.configs[host] = opt
 	cc.mutex.Unlock()
@@ -145,6 +154,14 @@ func (cc *clusterClient) CmdToString(host, cmd, sep string) (string, error) {
 	return client.CmdToString(host, cmd, sep)
 }
 
+func (cc *clusterClient) CmdWithWriters(ctx context.Context, host string, stdout, stderr io.Writer, cmds ...string) error {
+	client, err := cc.getClientForHost(host)
+	if err != nil {
+		return err
+	}
+	return CmdWithWriters(ctx, client, host, stdout, stderr, cmds...)
+}
+
 func (cc *clusterClient) Ping(host string) error {
 	client, err := cc.getClientForHost(host)
 	if err != nil {
@@ -0,0 +1,287 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	// HostKeyPolicyTOFU trusts a host key on first use and refuses to connect
+	// once the recorded key has changed. This is the default policy.
+	HostKeyPolicyTOFU = "tofu"
+	// HostKeyPolicyStrict only trusts the recorded host keys, the key of a new
+	// host must be recorded with `sealos known-hosts refresh` first.
+	HostKeyPolicyStrict = "strict"
+	// HostKeyPolicyReplace trusts a host key on first use and replaces a recorded
+	// key that has changed, printing a warning.
+	HostKeyPolicyReplace = "replace"
+	// HostKeyPolicyOff disables host key verification.
+	HostKeyPolicyOff = "off"
+)
+
+// knownHostsMu serializes access to known hosts files, clients for
+// different hosts of the same cluster are dialing concurrently.
+var knownHostsMu sync.Mutex
+
+var errHostKeyScanned = errors.New("host key scanned")
+
+// KnownHost is a host key recorded in the known hosts file.
+type KnownHost struct {
+	Host        string
+	Type        string
+	Fingerprint string
+}
+
+type knownHostEntry struct {
+	hosts []string
+	key   ssh.PublicKey
+}
+
+// KnownHostsStore is a known hosts file in OpenSSH format.
+type KnownHostsStore struct {
+	path string
+}
+
+func NewKnownHostsStore(path string) *KnownHostsStore {
+	return &KnownHostsStore{path: path}
+}
+
+func ValidateHostKeyPolicy(policy string) error {
+	switch policy {
+	case "", HostKeyPolicyTOFU, HostKeyPolicyStrict, HostKeyPolicyReplace, HostKeyPolicyOff:
+		return nil
+	}
+	return fmt.Errorf("unsupported ssh known hosts policy %q, must be one of %s, %s, %s or %s",
+		policy, HostKeyPolicyTOFU, HostKeyPolicyStrict, HostKeyPolicyReplace, HostKeyPolicyOff)
+}
+
+// knownHostsCallback returns a callback verifying host keys against the known hosts store of cluster.
+func knownHostsCallback(cluster *v1beta1.Cluster, policy string) (ssh.HostKeyCallback, error) {
+	store := NewKnownHostsStore(constants.NewPathResolver(cluster.GetName()).KnownHostsFile())
+	return store.HostKeyCallback(policy)
+}
+
+// HostKeyCallback returns a callback verifying host keys against the store with the given policy.
+func (s *KnownHostsStore) HostKeyCallback(policy string) (ssh.HostKeyCallback, error) {
+	if err := ValidateHostKeyPolicy(policy); err != nil {
+		return nil, err
+	}
+	if policy == HostKeyPolicyOff {
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec // explicitly requested by the user
+	}
+	if policy == "" {
+		policy = HostKeyPolicyTOFU
+	}
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		return s.check(knownhosts.Normalize(hostname), key, policy)
+	}, nil
+}
+
+func (s *KnownHostsStore) check(host string, key ssh.PublicKey, policy string) error {
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	var recorded []ssh.PublicKey
+	for _, e := range entries {
+		if containsHost(e.hosts, host) {
+			recorded = append(recorded, e.key)
+		}
+	}
+	for _, k := range recorded {
+		if keysEqual(k, key) {
+			return nil
+		}
+	}
+	if len(recorded) == 0 {
+		if policy == HostKeyPolicyStrict {
+			return fmt.Errorf("host key %s %s of %s is not recorded; "+
+				"run `sealos known-hosts refresh %s` to trust it", key.Type(), ssh.FingerprintSHA256(key), host, host)
+		}
+		logger.Debug("trust host key %s %s of %s on first use", key.Type(), ssh.FingerprintSHA256(key), host)
+		return s.save(append(entries, knownHostEntry{hosts: []string{host}, key: key}))
+	}
+	if policy != HostKeyPolicyReplace {
+		return fmt.Errorf("host key of %s has changed to %s %s, it may be a man-in-the-middle attack; "+
+			"run `sealos known-hosts refresh %s` if the host was reinstalled", host, key.Type(), ssh.FingerprintSHA256(key), host)
+	}
+	logger.Warn("host key of %s has changed to %s %s, replacing the recorded key", host, key.Type(), ssh.FingerprintSHA256(key))
+	entries = removeHosts(entries, host)
+	return s.save(append(entries, knownHostEntry{hosts: []string{host}, key: key}))
+}
+
+// List returns all recorded host keys.
+func (s *KnownHostsStore) List() ([]KnownHost, error) {
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	var ret []KnownHost
+	for _, e := range entries {
+		for _, h := range e.hosts {
+			ret = append(ret, KnownHost{Host: h, Type: e.key.Type(), Fingerprint: ssh.FingerprintSHA256(e.key)})
+		}
+	}
+	return ret, nil
+}
+
+// Forget removes the recorded keys of the given hosts and returns how many were removed.
+func (s *KnownHostsStore) Forget(hosts ...string) (int, error) {
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+	entries, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	before := len(entries)
+	for _, h := range hosts {
+		entries = removeHosts(entries, knownhosts.Normalize(h))
+	}
+	if len(entries) == before {
+		return 0, nil
+	}
+	return before - len(entries), s.save(entries)
+}
+
+// Refresh fetches the current key of host and records it, replacing any previous key.
+func (s *KnownHostsStore) Refresh(host string, timeout time.Duration) (KnownHost, error) {
+	ip, port := iputils.GetSSHHostIPAndPort(host)
+	addr := formalizeAddr(ip, port)
+	key, err := ScanHostKey(addr, timeout)
+	if err != nil {
+		return KnownHost{}, err
+	}
+	normalized := knownhosts.Normalize(addr)
+	knownHostsMu.Lock()
+	defer knownHostsMu.Unlock()
+	entries, err := s.load()
+	if err != nil {
+		return KnownHost{}, err
+	}
+	entries = append(removeHosts(entries, normalized), knownHostEntry{hosts: []string{normalized}, key: key})
+	return KnownHost{Host: normalized, Type: key.Type(), Fingerprint: ssh.FingerprintSHA256(key)}, s.save(entries)
+}
+
+// ScanHostKey returns the host key presented by the ssh server at addr without authenticating.
+func ScanHostKey(addr string, timeout time.Duration) (ssh.PublicKey, error) {
+	var hostKey ssh.PublicKey
+	config := &ssh.ClientConfig{
+		Timeout: timeout,
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errHostKeyScanned
+		},
+	}
+	conn, err := ssh.Dial("tcp", addr, config)
+	if conn != nil {
+		_ = conn.Close()
+	}
+	if hostKey != nil {
+		return hostKey, nil
+	}
+	return nil, fmt.Errorf("failed to scan host key of %s: %v", addr, err)
+}
+
+func (s *KnownHostsStore) load() ([]knownHostEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []knownHostEntry
+	for len(data) > 0 {
+		marker, hosts, key, _, rest, err := ssh.ParseKnownHosts(data)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse known hosts file %s: %v", s.path, err)
+		}
+		if marker == "" {
+			entries = append(entries, knownHostEntry{hosts: hosts, key: key})
+		}
+		data = rest
+	}
+	return entries, nil
+}
+
+func (s *KnownHostsStore) save(entries []knownHostEntry) error {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		buf.WriteString(knownhosts.Line(e.hosts, e.key))
+		buf.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func removeHosts(entries []knownHostEntry, host string) []knownHostEntry {
+	ret := entries[:0]
+	for _, e := range entries {
+		var hosts []string
+		for _, h := range e.hosts {
+			if h != host {
+				hosts = append(hosts, h)
+			}
+		}
+		if len(hosts) > 0 {
+			e.hosts = hosts
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+func containsHost(hosts []string, host string) bool {
+	for _, h := range hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+func keysEqual(a, b ssh.PublicKey) bool {
+	return bytes.Equal(a.Marshal(), b.Marshal())
+}
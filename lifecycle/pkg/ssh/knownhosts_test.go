@@ -0,0 +1,114 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("convert key: %v", err)
+	}
+	return key
+}
+
+func TestKnownHostsStore(t *testing.T) {
+	store := NewKnownHostsStore(filepath.Join(t.TempDir(), "etc", "known_hosts"))
+	key1, key2 := newTestHostKey(t), newTestHostKey(t)
+
+	tofu, err := store.HostKeyCallback("")
+	if err != nil {
+		t.Fatalf("tofu callback: %v", err)
+	}
+	if err = tofu("10.0.0.2:22", nil, key1); err != nil {
+		t.Fatalf("first use should be trusted: %v", err)
+	}
+	if err = tofu("10.0.0.2:22", nil, key1); err != nil {
+		t.Fatalf("recorded key should be accepted: %v", err)
+	}
+	if err = tofu("10.0.0.2:22", nil, key2); err == nil {
+		t.Fatal("changed key should be rejected in tofu mode")
+	}
+
+	strict, err := store.HostKeyCallback(HostKeyPolicyStrict)
+	if err != nil {
+		t.Fatalf("strict callback: %v", err)
+	}
+	if err = strict("10.0.0.3:2222", nil, key2); err == nil {
+		t.Fatal("unknown host should be rejected in strict mode")
+	}
+	if err = tofu("10.0.0.3:2222", nil, key2); err != nil {
+		t.Fatalf("first use of another host should be trusted: %v", err)
+	}
+	if err = strict("10.0.0.3:2222", nil, key2); err != nil {
+		t.Fatalf("recorded key should be accepted in strict mode: %v", err)
+	}
+	if err = strict("10.0.0.2:22", nil, key2); err == nil {
+		t.Fatal("changed key should be rejected in strict mode")
+	}
+
+	hosts, err := store.List()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0].Host != "10.0.0.2" || hosts[1].Host != "[10.0.0.3]:2222" {
+		t.Fatalf("unexpected hosts: %+v", hosts)
+	}
+
+	replace, err := store.HostKeyCallback(HostKeyPolicyReplace)
+	if err != nil {
+		t.Fatalf("replace callback: %v", err)
+	}
+	if err = replace("10.0.0.2:22", nil, key2); err != nil {
+		t.Fatalf("changed key should be replaced in replace mode: %v", err)
+	}
+	if err = tofu("10.0.0.2:22", nil, key2); err != nil {
+		t.Fatalf("replaced key should be accepted: %v", err)
+	}
+
+	n, err := store.Forget("10.0.0.2", "10.0.0.3:2222")
+	if err != nil {
+		t.Fatalf("forget: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 removed keys, got %d", n)
+	}
+	if hosts, _ = store.List(); len(hosts) != 0 {
+		t.Fatalf("expected empty store, got %+v", hosts)
+	}
+}
+
+func TestValidateHostKeyPolicy(t *testing.T) {
+	for _, p := range []string{"", HostKeyPolicyTOFU, HostKeyPolicyStrict, HostKeyPolicyReplace, HostKeyPolicyOff} {
+		if err := ValidateHostKeyPolicy(p); err != nil {
+			t.Errorf("policy %q should be valid: %v", p, err)
+		}
+	}
+	if err := ValidateHostKeyPolicy("yes"); err == nil {
+		t.Error("unknown policy should be rejected")
+	}
+}
@@ -62,7 +62,7 @@ func (s *Remote) Hostname(ip string) (string, error) {
 }
 
 func (s *Remote) IPVS(ip, vip string, masters []string) error {
-	ipvsTemplate := `ipvs --vs {{.vip}}  {{range $h := .masters}}--rs  {{$h}} {{end}} --health-path /healthz --health-schem https --run-once`
+	ipvsTemplate := `ipvs --vs {{.vip}}  {{range $h := .masters}}--rs  {{$h}} {{end}} --health-path /healthz --health-schem https --mode auto --run-once`
 	data := map[string]interface{}{
 		"vip":     vip,
 		"masters": masters,
@@ -74,7 +74,7 @@ func (s *Remote) IPVS(ip, vip string, masters []string) error {
 	return s.executeRemoteUtilSubcommand(ip, out)
 }
 func (s *Remote) IPVSClean(ip, vip string) error {
-	ipvsTemplate := `ipvs --vs {{.vip}}  -C`
+	ipvsTemplate := `ipvs --vs {{.vip}} --mode auto -C`
 	data := map[string]interface{}{
 		"vip": vip,
 		"ip":  iputils.GetHostIP(ip),
@@ -17,7 +17,9 @@ package ssh
 import (
 	"context"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -116,12 +118,35 @@ type Interface interface {
 	Ping(host string) error
 }
 
+// WritersInterface is implemented by the clients that can write standard output
+// and standard error of the commands to separated writers.
+type WritersInterface interface {
+	// CmdWithWriters exec commands on remote host, and write standard output and standard error to the writers
+	CmdWithWriters(ctx context.Context, host string, stdout, stderr io.Writer, cmds ...string) error
+}
+
+// CmdWithWriters exec commands on remote host with the client, if the client does not implement
+// WritersInterface, the combined standard output and standard error are written to stdout.
+func CmdWithWriters(ctx context.Context, client Interface, host string, stdout, stderr io.Writer, cmds ...string) error {
+	if c, ok := client.(WritersInterface); ok {
+		return c.CmdWithWriters(ctx, host, stdout, stderr, cmds...)
+	}
+	out, err := client.Cmd(host, strings.Join(cmds, "; "))
+	if _, werr := stdout.Write(out); werr != nil && err == nil {
+		err = werr
+	}
+	return err
+}
+
 type Client struct {
 	*ssh.ClientConfig
 	*Option
 }
 
-var _ Interface = &Client{}
+var (
+	_ Interface        = &Client{}
+	_ WritersInterface = &Client{}
+)
 
 var defaultCiphers = []string{
 	"aes128-ctr", "aes192-ctr", "aes256-ctr",
@@ -215,6 +240,18 @@ func MustNewClient(ssh *v2.SSH, isStdout bool) Interface {
 	return client
 }
 
+// NewClientWithKnownHosts returns a client connecting with ssh, the host keys are verified
+// against the known hosts store of the cluster, e.g. to probe the hosts joining it.
+func NewClientWithKnownHosts(cluster *v2.Cluster, ssh *v2.SSH, isStdout bool) (Interface, error) {
+	opt := newOptionFromSSH(ssh, isStdout)
+	hostKeyCallback, err := knownHostsCallback(cluster, ssh.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+	opt.hostKeyCallback = hostKeyCallback
+	return New(opt)
+}
+
 func NewCacheClientFromCluster(cluster *v2.Cluster, isStdout bool) Interface {
 	cc := &clusterClient{
 		cluster:  cluster,
@@ -24,6 +24,7 @@ import (
 	"strings"
 	"sync"
 
+	"golang.org/x/crypto/ssh"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/labring/sealos/pkg/utils/logger"
@@ -133,6 +134,40 @@ func (c *Client) Cmd(host, cmd string) ([]byte, error) {
 	return b.b.Bytes(), err
 }
 
+func (c *Client) CmdWithWriters(ctx context.Context, host string, stdout, stderr io.Writer, cmds ...string) error {
+	cmd := c.wrapCommands(cmds...)
+	logger.Debug("start to exec `%s` on %s", cmd, host)
+	client, session, err := c.Connect(host)
+	if err != nil {
+		return fmt.Errorf("failed to create ssh session for %s: %v", host, err)
+	}
+	defer client.Close()
+	defer session.Close()
+	in, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	session.Stdout = stdout
+	// sudo prompts for password on standard error
+	session.Stderr = &autoAnswerWriter{
+		in:        in,
+		out:       stderr,
+		answer:    []byte(c.password + "\n"),
+		condition: isSudoPrompt,
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- session.Run(cmd)
+	}()
+	select {
+	case <-ctx.Done():
+		_ = session.Signal(ssh.SIGKILL)
+		return ctx.Err()
+	case err = <-errCh:
+		return err
+	}
+}
+
 type withPrefixWriter struct {
 	prefix  string
 	newline bool
@@ -153,7 +188,9 @@ func (w *withPrefixWriter) Write(p []byte) (int, error) {
 }
 
 type autoAnswerWriter struct {
-	b          bytes.Buffer
+	b bytes.Buffer
+	// out receives the output instead of b if it is set
+	out        io.Writer
 	in         io.Writer
 	showPrompt bool
 	answer     []byte
@@ -173,6 +210,9 @@ func (w *autoAnswerWriter) Write(p []byte) (int, error) {
 	}
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	if w.out != nil {
+		return w.out.Write(p)
+	}
 	return w.b.Write(p)
 }
 
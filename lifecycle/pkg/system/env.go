@@ -102,18 +102,36 @@ var configOptions = []ConfigOption{
 		Description:  "maximum number of retry times for SSH operations",
 		DefaultValue: "5",
 	},
+	{
+		Key:          HistoryLimitConfigKey,
+		Description:  "maximum number of cluster snapshots kept for rollback, set to 0 to disable snapshots",
+		DefaultValue: "10",
+	},
+	{
+		Key:          ExecRunsLimitConfigKey,
+		Description:  "maximum number of `sealos exec` outputs kept per cluster, set to 0 to not save the outputs",
+		DefaultValue: "20",
+	},
+	{
+		Key:          PreflightMinFreeDiskConfigKey,
+		Description:  "minimum free disk in GiB under the data root that the preflight checks require on every host",
+		DefaultValue: "10",
+	},
 }
 
 const (
-	PromptConfigKey            = "PROMPT"
-	RuntimeRootConfigKey       = "RUNTIME_ROOT"
-	DataRootConfigKey          = "DATA_ROOT"
-	BuildahFormatConfigKey     = "BUILDAH_FORMAT"
-	BuildahLogLevelConfigKey   = "BUILDAH_LOG_LEVEL"
-	ContainerStorageConfEnvKey = "CONTAINERS_STORAGE_CONF"
-	SyncWorkDirEnvKey          = "SYNC_WORKDIR"
-	ExecutionTimeoutConfigKey  = "EXECUTION_TIMEOUT"
-	MaxRetryConfigKey          = "MAX_RETRY"
+	PromptConfigKey               = "PROMPT"
+	RuntimeRootConfigKey          = "RUNTIME_ROOT"
+	DataRootConfigKey             = "DATA_ROOT"
+	BuildahFormatConfigKey        = "BUILDAH_FORMAT"
+	BuildahLogLevelConfigKey      = "BUILDAH_LOG_LEVEL"
+	ContainerStorageConfEnvKey    = "CONTAINERS_STORAGE_CONF"
+	SyncWorkDirEnvKey             = "SYNC_WORKDIR"
+	ExecutionTimeoutConfigKey     = "EXECUTION_TIMEOUT"
+	MaxRetryConfigKey             = "MAX_RETRY"
+	HistoryLimitConfigKey         = "HISTORY_LIMIT"
+	ExecRunsLimitConfigKey        = "EXEC_RUNS_LIMIT"
+	PreflightMinFreeDiskConfigKey = "PREFLIGHT_MIN_FREE_DISK"
 )
 
 func (*envSystemConfig) getValueOrDefault(key string) (*ConfigOption, error) {
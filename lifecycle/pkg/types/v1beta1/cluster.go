@@ -223,6 +223,8 @@ type SSH struct {
 	Pk       string `json:"pk,omitempty"`
 	PkPasswd string `json:"pkPasswd,omitempty"`
 	Port     uint16 `json:"port,omitempty"`
+	// KnownHosts is the host key verification policy: tofu (default), strict, replace or off.
+	KnownHosts string `json:"knownHosts,omitempty"`
 }
 
 func (s *SSH) DefaultPort() uint16 {
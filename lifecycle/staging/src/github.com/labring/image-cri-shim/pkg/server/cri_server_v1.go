@@ -39,6 +39,7 @@ type v1ImageService struct {
 	cacheTTL     time.Duration
 	domainTTL    time.Duration
 	metrics      *cacheMetrics
+	health       *registryHealth
 }
 
 type cacheEntry struct {
@@ -67,7 +68,10 @@ type CacheStats struct {
 	ImageEvictions  uint64
 	DomainEvictions uint64
 	Invalidations   uint64
-	GeneratedAt     time.Time
+	// PullFallbacks counts pulls retried against the next registry after a registry failed.
+	PullFallbacks uint64
+	Registries    []RegistryStats
+	GeneratedAt   time.Time
 }
 
 type cacheMetrics struct {
@@ -78,6 +82,7 @@ type cacheMetrics struct {
 	imageEvictions  atomic.Uint64
 	domainEvictions atomic.Uint64
 	invalidations   atomic.Uint64
+	pullFallbacks   atomic.Uint64
 }
 
 const (
@@ -130,6 +135,7 @@ func (m *cacheMetrics) snapshot() CacheStats {
 		ImageEvictions:  m.imageEvictions.Load(),
 		DomainEvictions: m.domainEvictions.Load(),
 		Invalidations:   m.invalidations.Load(),
+		PullFallbacks:   m.pullFallbacks.Load(),
 		GeneratedAt:     time.Now(),
 	}
 }
@@ -176,6 +182,12 @@ func (m *cacheMetrics) recordInvalidation() {
 	}
 }
 
+func (m *cacheMetrics) recordPullFallback() {
+	if m != nil {
+		m.pullFallbacks.Add(1)
+	}
+}
+
 func newV1ImageService(
 	client api.ImageServiceClient,
 	authStore *AuthStore,
@@ -185,6 +197,7 @@ func newV1ImageService(
 		imageClient: client,
 		authStore:   authStore,
 		metrics:     newCacheMetrics(),
+		health:      newRegistryHealth(),
 	}
 	service.UpdateCacheOptions(cacheOpts)
 	if authStore != nil {
@@ -204,8 +217,11 @@ func (s *v1ImageService) UpdateCacheOptions(opts CacheOptions) {
 
 func (s *v1ImageService) rewriteImage(image, action string) (string, bool, *rtype.AuthConfig) {
 	if entry, ok := s.getCachedResult(image); ok {
-		s.logRewriteResult(action, image, entry.newImage, "cache", true, entry.found)
-		return entry.newImage, entry.found, entry.auth
+		// a cached rewrite to a registry in its backoff window is resolved again
+		if !entry.found || s.health.isHealthy(registryFromImage(entry.newImage)) {
+			s.logRewriteResult(action, image, entry.newImage, "cache", true, entry.found)
+			return entry.newImage, entry.found, entry.auth
+		}
 	}
 
 	if s.authStore == nil {
@@ -243,8 +259,8 @@ func (s *v1ImageService) rewriteImage(image, action string) (string, bool, *rtyp
 		})
 	}
 
-	// Try registries in priority order (sealos.hub first by default)
-	newImage, replaced, auth := ReplaceImageWithPriority(image, action, registries)
+	// Try registries in priority order (sealos.hub first by default), skipping unhealthy ones
+	newImage, replaced, auth := ReplaceImageWithPriority(image, action, s.health.filterHealthy(registries))
 	s.cacheResult(image, newImage, replaced, auth)
 
 	if replaced {
@@ -420,7 +436,9 @@ func (s *v1ImageService) logRewriteResult(
 }
 
 func (s *v1ImageService) CacheStats() CacheStats {
-	return s.metrics.snapshot()
+	stats := s.metrics.snapshot()
+	stats.Registries = s.health.snapshot()
+	return stats
 }
 
 func (s *v1ImageService) setMaxCacheSize(size int) {
@@ -527,18 +545,87 @@ func (s *v1ImageService) PullImage(ctx context.Context,
 	req *api.PullImageRequest,
 ) (*api.PullImageResponse, error) {
 	logger.Debug("PullImage begin: %+v", req)
-	if req.Image != nil {
-		originalImage := req.Image.Image
-		imageName := originalImage
-
-		// Apply priority-based image rewrite (sealos.hub first, then other registries)
-		if newImage, ok, auth := s.rewriteImage(imageName, "PullImage"); ok {
-			req.Image.Image = newImage
-			if auth != nil && req.Auth == nil {
-				req.Auth = ToV1AuthConfig(auth)
-			}
+	if req.Image == nil {
+		return s.pullImage(ctx, req)
+	}
+	originalImage := req.Image.Image
+	originalAuth := req.Auth
+
+	// Apply priority-based image rewrite (sealos.hub first, then other registries)
+	newImage, ok, auth := s.rewriteImage(originalImage, "PullImage")
+	if !ok {
+		return s.pullImage(ctx, req)
+	}
+	rsp, err := s.pullFromRegistry(ctx, req, newImage, auth, originalAuth)
+	if err == nil || ctx.Err() != nil || s.authStore == nil {
+		return rsp, err
+	}
+
+	// The registry failed, retry against the next healthy registries in priority order
+	failed := registryFromImage(newImage)
+	tried := map[string]bool{failed: true}
+	for _, entry := range s.authStore.GetSortedRegistries() {
+		if tried[entry.Domain] || !s.health.isHealthy(entry.Domain) {
+			continue
+		}
+		tried[entry.Domain] = true
+		candidate, replaced, candidateAuth := replaceImage(originalImage, "PullImage",
+			map[string]rtype.AuthConfig{entry.Domain: entry.Config})
+		if !replaced {
+			continue
+		}
+		s.metrics.recordPullFallback()
+		logger.Warn("pull fallback: image=%s failed_registry=%s next_registry=%s priority=%d",
+			originalImage, failed, entry.Domain, entry.Priority)
+		rsp, err = s.pullFromRegistry(ctx, req, candidate, candidateAuth, originalAuth)
+		if err == nil {
+			s.cacheResult(originalImage, candidate, true, candidateAuth)
+			return rsp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
 		}
+		failed = entry.Domain
 	}
+
+	// No registry could serve the image, fall back to the original image as if it was not rewritten
+	if !tried[extractDomainFromImage(originalImage)] {
+		s.metrics.recordPullFallback()
+		logger.Warn("pull fallback: image=%s failed_registry=%s next_registry=upstream", originalImage, failed)
+		req.Image.Image = originalImage
+		req.Auth = originalAuth
+		return s.pullImage(ctx, req)
+	}
+	return nil, err
+}
+
+// pullFromRegistry pulls the rewritten image and records the result to the health of its registry.
+func (s *v1ImageService) pullFromRegistry(ctx context.Context, req *api.PullImageRequest,
+	image string, auth *rtype.AuthConfig, originalAuth *api.AuthConfig,
+) (*api.PullImageResponse, error) {
+	req.Image.Image = image
+	req.Auth = originalAuth
+	if auth != nil && req.Auth == nil {
+		req.Auth = ToV1AuthConfig(auth)
+	}
+	domain := registryFromImage(image)
+	start := time.Now()
+	rsp, err := s.pullImage(ctx, req)
+	if err == nil {
+		s.health.recordSuccess(domain, time.Since(start))
+		return rsp, nil
+	}
+	// a canceled pull tells nothing about the registry
+	if ctx.Err() == nil {
+		until := s.health.recordFailure(domain, time.Since(start))
+		logger.Warn("registry %s marked unhealthy until %s", domain, until.Format(time.RFC3339))
+	}
+	return nil, err
+}
+
+func (s *v1ImageService) pullImage(ctx context.Context,
+	req *api.PullImageRequest,
+) (*api.PullImageResponse, error) {
 	logger.Debug("PullImage after: %+v", req)
 	rsp, err := s.imageClient.PullImage(ctx, req)
 	if err == nil {
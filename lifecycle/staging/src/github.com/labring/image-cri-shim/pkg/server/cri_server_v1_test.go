@@ -430,6 +430,9 @@ func TestPullImageInjectsAuthFromCRIConfig(t *testing.T) {
 
 type fakeImageClient struct {
 	lastPull *api.PullImageRequest
+	// pulls records every pulled image, pulls from failRegistries return an error.
+	pulls          []string
+	failRegistries map[string]bool
 }
 
 func (f *fakeImageClient) ListImages(
@@ -458,6 +461,10 @@ func (f *fakeImageClient) PullImage(
 	if in.GetImage() != nil {
 		ref = in.GetImage().GetImage()
 	}
+	f.pulls = append(f.pulls, ref)
+	if f.failRegistries[registryFromImage(ref)] {
+		return nil, errors.New("registry unavailable")
+	}
 	return &api.PullImageResponse{ImageRef: ref}, nil
 }
 
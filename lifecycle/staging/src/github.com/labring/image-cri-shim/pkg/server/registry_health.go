@@ -0,0 +1,168 @@
+// Copyright © 2025 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRegistryBackoff is how long a registry is skipped after its first pull failure.
+	// Every further consecutive failure doubles the window up to maxRegistryBackoff.
+	defaultRegistryBackoff = 30 * time.Second
+	maxRegistryBackoff     = 5 * time.Minute
+	// latencyWeight is the weight of the newest pull in the moving average of pull latency.
+	latencyWeight = 0.2
+)
+
+// RegistryStats are the pull counters of a single registry.
+type RegistryStats struct {
+	Domain              string
+	Healthy             bool
+	Pulls               uint64
+	Failures            uint64
+	ConsecutiveFailures int
+	AvgLatency          time.Duration
+	UnhealthyUntil      time.Time
+}
+
+type registryState struct {
+	pulls               uint64
+	failures            uint64
+	consecutiveFailures int
+	avgLatency          time.Duration
+	unhealthyUntil      time.Time
+}
+
+// registryHealth tracks pull results per registry, a registry which failed is
+// skipped for a backoff window so that pulls go to the next registry instead.
+type registryHealth struct {
+	mu      sync.Mutex
+	backoff time.Duration
+	states  map[string]*registryState
+	now     func() time.Time
+}
+
+func newRegistryHealth() *registryHealth {
+	return &registryHealth{
+		backoff: defaultRegistryBackoff,
+		states:  map[string]*registryState{},
+		now:     time.Now,
+	}
+}
+
+func (h *registryHealth) stateLocked(domain string) *registryState {
+	st, ok := h.states[domain]
+	if !ok {
+		st = &registryState{}
+		h.states[domain] = st
+	}
+	return st
+}
+
+// isHealthy reports whether the registry is out of its backoff window, unknown registries are healthy.
+func (h *registryHealth) isHealthy(domain string) bool {
+	if h == nil || domain == "" {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st, ok := h.states[domain]
+	return !ok || !h.now().Before(st.unhealthyUntil)
+}
+
+func (h *registryHealth) recordSuccess(domain string, latency time.Duration) {
+	if h == nil || domain == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := h.stateLocked(domain)
+	st.pulls++
+	st.consecutiveFailures = 0
+	st.unhealthyUntil = time.Time{}
+	st.observeLatency(latency)
+}
+
+// recordFailure marks the registry unhealthy and returns the end of its backoff window.
+func (h *registryHealth) recordFailure(domain string, latency time.Duration) time.Time {
+	if h == nil || domain == "" {
+		return time.Time{}
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	st := h.stateLocked(domain)
+	st.pulls++
+	st.failures++
+	st.consecutiveFailures++
+	st.observeLatency(latency)
+	backoff := h.backoff
+	for i := 1; i < st.consecutiveFailures && backoff < maxRegistryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRegistryBackoff {
+		backoff = maxRegistryBackoff
+	}
+	st.unhealthyUntil = h.now().Add(backoff)
+	return st.unhealthyUntil
+}
+
+func (st *registryState) observeLatency(latency time.Duration) {
+	if st.avgLatency == 0 {
+		st.avgLatency = latency
+		return
+	}
+	st.avgLatency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(st.avgLatency))
+}
+
+func (h *registryHealth) snapshot() []RegistryStats {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.now()
+	stats := make([]RegistryStats, 0, len(h.states))
+	for domain, st := range h.states {
+		stats = append(stats, RegistryStats{
+			Domain:              domain,
+			Healthy:             !now.Before(st.unhealthyUntil),
+			Pulls:               st.pulls,
+			Failures:            st.failures,
+			ConsecutiveFailures: st.consecutiveFailures,
+			AvgLatency:          st.avgLatency,
+			UnhealthyUntil:      st.unhealthyUntil,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Domain < stats[j].Domain })
+	return stats
+}
+
+// filterHealthy drops the registries in their backoff window, all of them are kept if none is healthy
+// so that a pull is still attempted.
+func (h *registryHealth) filterHealthy(registries []RegistryWithPriority) []RegistryWithPriority {
+	healthy := make([]RegistryWithPriority, 0, len(registries))
+	for _, reg := range registries {
+		if h.isHealthy(reg.Domain) {
+			healthy = append(healthy, reg)
+		}
+	}
+	if len(healthy) == 0 {
+		return registries
+	}
+	return healthy
+}
@@ -0,0 +1,132 @@
+// Copyright © 2025 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	rtype "github.com/docker/docker/api/types/registry"
+	"github.com/labring/image-cri-shim/pkg/types"
+	api "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func TestRegistryHealthBackoff(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	health := newRegistryHealth()
+	health.now = func() time.Time { return now }
+
+	if !health.isHealthy("mirror.example.com") {
+		t.Fatal("unknown registry should be healthy")
+	}
+	if until := health.recordFailure("mirror.example.com", time.Second); !until.Equal(now.Add(defaultRegistryBackoff)) {
+		t.Fatalf("first backoff until %s, want %s", until, now.Add(defaultRegistryBackoff))
+	}
+	if until := health.recordFailure("mirror.example.com", time.Second); !until.Equal(now.Add(2 * defaultRegistryBackoff)) {
+		t.Fatalf("second backoff until %s, want %s", until, now.Add(2*defaultRegistryBackoff))
+	}
+	for i := 0; i < 10; i++ {
+		health.recordFailure("mirror.example.com", time.Second)
+	}
+	if stats := health.snapshot(); len(stats) != 1 || stats[0].UnhealthyUntil != now.Add(maxRegistryBackoff) ||
+		stats[0].Healthy || stats[0].Failures != 12 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	now = now.Add(maxRegistryBackoff)
+	if !health.isHealthy("mirror.example.com") {
+		t.Fatal("registry should be healthy again after its backoff window")
+	}
+	health.recordSuccess("mirror.example.com", time.Second)
+	if stats := health.snapshot(); stats[0].ConsecutiveFailures != 0 || stats[0].Pulls != 13 {
+		t.Fatalf("unexpected stats after success %+v", stats)
+	}
+}
+
+func TestPullImageFallsBackToNextRegistry(t *testing.T) {
+	withManifestStub(t, func(_ *manifestStub) {
+		store := NewAuthStore(&types.ShimAuthConfig{
+			CRIConfigs: map[string]rtype.AuthConfig{
+				"mirror-a.example.com": {ServerAddress: "https://mirror-a.example.com", Username: "a"},
+				"mirror-b.example.com": {ServerAddress: "https://mirror-b.example.com", Username: "b"},
+			},
+			CRIPriorities: map[string]int{
+				"mirror-a.example.com": 900,
+				"mirror-b.example.com": 800,
+			},
+		})
+		client := &fakeImageClient{failRegistries: map[string]bool{"mirror-a.example.com": true}}
+		service := newV1ImageService(client, store, CacheOptions{})
+
+		pull := func() *api.PullImageResponse {
+			rsp, err := service.PullImage(context.Background(), &api.PullImageRequest{
+				Image: &api.ImageSpec{Image: "docker.io/library/nginx:1.25"},
+			})
+			if err != nil {
+				t.Fatalf("PullImage failed: %v", err)
+			}
+			return rsp
+		}
+
+		rsp := pull()
+		if !strings.HasPrefix(rsp.ImageRef, "mirror-b.example.com/") {
+			t.Fatalf("expected pull from mirror-b, got %s", rsp.ImageRef)
+		}
+		if len(client.pulls) != 2 || !strings.HasPrefix(client.pulls[0], "mirror-a.example.com/") {
+			t.Fatalf("expected mirror-a to be tried first, got %v", client.pulls)
+		}
+		if client.lastPull.Auth == nil || client.lastPull.Auth.Username != "b" {
+			t.Fatalf("expected auth of mirror-b, got %+v", client.lastPull.Auth)
+		}
+
+		// mirror-a is in its backoff window, the next pull goes to mirror-b directly
+		client.pulls = nil
+		pull()
+		if len(client.pulls) != 1 || !strings.HasPrefix(client.pulls[0], "mirror-b.example.com/") {
+			t.Fatalf("expected unhealthy mirror-a to be skipped, got %v", client.pulls)
+		}
+
+		stats := service.CacheStats()
+		if stats.PullFallbacks != 1 {
+			t.Fatalf("expected 1 pull fallback, got %d", stats.PullFallbacks)
+		}
+		if len(stats.Registries) != 2 || stats.Registries[0].Healthy || !stats.Registries[1].Healthy {
+			t.Fatalf("unexpected registry stats %+v", stats.Registries)
+		}
+	})
+}
+
+func TestPullImageFallsBackToUpstream(t *testing.T) {
+	withManifestStub(t, func(_ *manifestStub) {
+		store := NewAuthStore(&types.ShimAuthConfig{
+			CRIConfigs: map[string]rtype.AuthConfig{
+				"mirror-a.example.com": {ServerAddress: "https://mirror-a.example.com"},
+			},
+		})
+		client := &fakeImageClient{failRegistries: map[string]bool{"mirror-a.example.com": true}}
+		service := newV1ImageService(client, store, CacheOptions{})
+		rsp, err := service.PullImage(context.Background(), &api.PullImageRequest{
+			Image: &api.ImageSpec{Image: "docker.io/library/nginx:1.25"},
+		})
+		if err != nil {
+			t.Fatalf("PullImage failed: %v", err)
+		}
+		if rsp.ImageRef != "docker.io/library/nginx:1.25" || client.lastPull.Auth != nil {
+			t.Fatalf("expected upstream pull without mirror auth, got %s %+v", rsp.ImageRef, client.lastPull.Auth)
+		}
+	})
+}
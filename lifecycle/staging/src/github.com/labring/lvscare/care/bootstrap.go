@@ -0,0 +1,168 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// The userspace proxy only works while lvscare is running, so --run-once starts a detached
+// lvscare proxying the virtual server instead, until the lvscare static pod takes over and
+// stops it with StopBootstrapProxy.
+var bootstrapDir = "/run/lvscare"
+
+const (
+	bootstrapReadyTimeout = 30 * time.Second
+	bootstrapStopTimeout  = 10 * time.Second
+)
+
+func bootstrapFile(vs, ext string) string {
+	name := strings.NewReplacer(":", "_", "[", "", "]", "").Replace(vs)
+	return filepath.Join(bootstrapDir, name+ext)
+}
+
+// bootstrapProxyArgs returns the arguments of the detached lvscare, the same as the run-once
+// one except that it keeps running in userspace mode.
+func bootstrapProxyArgs(args []string) []string {
+	ret := make([]string, 0, len(args)+1)
+	for _, arg := range args {
+		if arg == "--run-once" || strings.HasPrefix(arg, "--run-once=") {
+			continue
+		}
+		ret = append(ret, arg)
+	}
+	return append(ret, "--mode="+userspaceMode)
+}
+
+func (r *runner) startBootstrapProxy() error {
+	if err := StopBootstrapProxy(r.VirtualServer); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(bootstrapDir, 0755); err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(bootstrapFile(r.VirtualServer, ".log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+	// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
+	cmd := exec.Command(exe, bootstrapProxyArgs(os.Args[1:])...)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	// survives the session of the caller, like the ssh session of sealos
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start userspace proxy: %v", err)
+	}
+	if err = os.WriteFile(bootstrapFile(r.VirtualServer, ".pid"), []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		_ = cmd.Process.Kill()
+		return err
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	deadline := time.Now().Add(bootstrapReadyTimeout)
+	for {
+		select {
+		case err = <-exited:
+			return fmt.Errorf("userspace proxy exited: %v, see %s", err, logFile.Name())
+		default:
+		}
+		if conn, err := net.DialTimeout("tcp", r.VirtualServer, time.Second); err == nil {
+			_ = conn.Close()
+			logger.Info("userspace proxy of %s is running as pid %d until the lvscare static pod takes over",
+				r.VirtualServer, cmd.Process.Pid)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			_ = cmd.Process.Kill()
+			return fmt.Errorf("userspace proxy is not listening on %s in %s, see %s",
+				r.VirtualServer, bootstrapReadyTimeout, logFile.Name())
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+// StopBootstrapProxy stops the lvscare started by --run-once in userspace mode for the virtual
+// server, if there is one.
+func StopBootstrapProxy(vs string) error {
+	pidFile := bootstrapFile(vs, ".pid")
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer os.Remove(pidFile)
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || !isBootstrapProxy(pid, vs) {
+		// a stale pid file, the pid may belong to another process now
+		return nil
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if err = proc.Signal(syscall.SIGTERM); err != nil {
+		if errors.Is(err, os.ErrProcessDone) {
+			return nil
+		}
+		return fmt.Errorf("failed to stop userspace proxy %d: %v", pid, err)
+	}
+	for deadline := time.Now().Add(bootstrapStopTimeout); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if !isBootstrapProxy(pid, vs) {
+			logger.Info("stopped userspace proxy %d of %s", pid, vs)
+			return nil
+		}
+	}
+	return fmt.Errorf("userspace proxy %d of %s is still running", pid, vs)
+}
+
+// isBootstrapProxy reports whether the process is running with the virtual server in its arguments.
+func isBootstrapProxy(pid int, vs string) bool {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return false
+	}
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	// a zombie has exited already
+	if err != nil || bytes.Contains(stat, []byte(") Z ")) {
+		return false
+	}
+	for _, arg := range bytes.Split(cmdline, []byte{0}) {
+		if string(arg) == vs || string(arg) == "--vs="+vs {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBootstrapProxyArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{
+			args: []string{"ipvs", "--vs", "10.103.97.2:6443", "--mode", "auto", "--run-once"},
+			want: []string{"ipvs", "--vs", "10.103.97.2:6443", "--mode", "auto", "--mode=userspace"},
+		},
+		{
+			args: []string{"care", "--vs=10.103.97.2:6443", "--run-once=true", "--mode=userspace"},
+			want: []string{"care", "--vs=10.103.97.2:6443", "--mode=userspace", "--mode=userspace"},
+		},
+	}
+	for _, tt := range tests {
+		if got := bootstrapProxyArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("bootstrapProxyArgs(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestBootstrapHelperProcess is the detached lvscare started by the run-once tests, it only
+// listens on the virtual server.
+func TestBootstrapHelperProcess(t *testing.T) {
+	if os.Getenv("LVSCARE_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	var vs string
+	for _, arg := range os.Args {
+		if v, ok := strings.CutPrefix(arg, "--vs="); ok {
+			vs = v
+		}
+	}
+	l, err := net.Listen("tcp", vs)
+	if err != nil {
+		os.Exit(1)
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			os.Exit(1)
+		}
+		_ = conn.Close()
+	}
+}
+
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestRunOnceUserspaceStartsBootstrapProxy(t *testing.T) {
+	bootstrapDir = t.TempDir()
+	t.Setenv("LVSCARE_WANT_HELPER_PROCESS", "1")
+	vs := freeAddr(t)
+	args := os.Args
+	os.Args = []string{args[0], "-test.run=TestBootstrapHelperProcess", "--", "--vs=" + vs, "--run-once"}
+	defer func() { os.Args = args }()
+
+	r := &runner{options: &options{VirtualServer: vs, Mode: userspaceMode, RunOnce: true}}
+	if err := r.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	conn, err := net.Dial("tcp", vs)
+	if err != nil {
+		t.Fatalf("virtual server is not served after run-once: %v", err)
+	}
+	_ = conn.Close()
+
+	// the static pod takes over
+	if err = StopBootstrapProxy(vs); err != nil {
+		t.Fatalf("StopBootstrapProxy() error = %v", err)
+	}
+	if _, err = os.Stat(bootstrapFile(vs, ".pid")); !os.IsNotExist(err) {
+		t.Errorf("expected the pid file to be removed, got %v", err)
+	}
+	if conn, err = net.Dial("tcp", vs); err == nil {
+		_ = conn.Close()
+		t.Error("expected the userspace proxy to be stopped")
+	}
+}
+
+func TestStopBootstrapProxy(t *testing.T) {
+	bootstrapDir = t.TempDir()
+	vs := "10.103.97.2:6443"
+	if err := StopBootstrapProxy(vs); err != nil {
+		t.Fatalf("StopBootstrapProxy() without a proxy error = %v", err)
+	}
+
+	// a stale pid file of another process is removed without signaling it
+	other := exec.Command("sleep", "30")
+	if err := other.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = other.Process.Kill()
+		_ = other.Wait()
+	}()
+	if err := os.WriteFile(bootstrapFile(vs, ".pid"), []byte(strconv.Itoa(other.Process.Pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := StopBootstrapProxy(vs); err != nil {
+		t.Fatalf("StopBootstrapProxy() with a stale pid file error = %v", err)
+	}
+	if !isRunning(other.Process.Pid) {
+		t.Error("expected the process of a stale pid file to keep running")
+	}
+	if _, err := os.Stat(bootstrapFile(vs, ".pid")); !os.IsNotExist(err) {
+		t.Errorf("expected the stale pid file to be removed, got %v", err)
+	}
+}
+
+func isRunning(pid int) bool {
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	return err == nil && !strings.Contains(string(stat), ") Z ")
+}
@@ -0,0 +1,58 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"os"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+	utilexec "k8s.io/utils/exec"
+)
+
+const procIPVS = "/proc/net/ip_vs"
+
+// DetectMode returns the proxy mode supported by the node: the IPVS route mode if the ip_vs
+// kernel module is available, then nftables if the nft command works, and the userspace proxy
+// that needs nothing from the kernel otherwise.
+func DetectMode() string {
+	exec := utilexec.New()
+	if ipvsSupported(exec) {
+		return routeMode
+	}
+	if nftablesSupported(exec) {
+		return nftablesMode
+	}
+	return userspaceMode
+}
+
+func ipvsSupported(exec utilexec.Interface) bool {
+	if _, err := os.Stat(procIPVS); err == nil {
+		return true
+	}
+	if out, err := exec.Command("modprobe", "ip_vs").CombinedOutput(); err != nil {
+		logger.Debug("ip_vs is not available: %v: %s", err, out)
+		return false
+	}
+	_, err := os.Stat(procIPVS)
+	return err == nil
+}
+
+func nftablesSupported(exec utilexec.Interface) bool {
+	if out, err := exec.Command("nft", "list", "tables").CombinedOutput(); err != nil {
+		logger.Debug("nftables is not available: %v: %s", err, out)
+		return false
+	}
+	return true
+}
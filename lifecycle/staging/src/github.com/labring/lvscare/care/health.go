@@ -0,0 +1,136 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const defaultWeight = 1
+
+// HealthConfig controls how probe results change the real servers of a virtual server.
+type HealthConfig struct {
+	// Rise is the number of consecutive successful probes for an unhealthy real server to receive traffic again.
+	Rise int
+	// Fall is the number of consecutive failed probes for a healthy real server to be drained.
+	Fall int
+	// DrainTimeout is how long a drained real server is kept for its connections to close before it is removed.
+	DrainTimeout time.Duration
+}
+
+// backend is a real server with its configured weight and the health state derived from its probes.
+type backend struct {
+	endpoint
+	weight int
+
+	mu         sync.Mutex
+	healthy    bool
+	successes  int
+	failures   int
+	drainSince time.Time
+}
+
+func newBackend(ep endpoint, weight int) *backend {
+	// real servers are added at startup, they are trusted until they fail
+	return &backend{endpoint: ep, weight: weight, healthy: true}
+}
+
+// observe records a probe result, it returns the health state after applying the rise and fall thresholds.
+func (b *backend) observe(cfg HealthConfig, probeErr error) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probeErr != nil {
+		b.successes = 0
+		b.failures++
+		if b.healthy && b.failures >= cfg.Fall {
+			b.healthy = false
+		}
+	} else {
+		b.failures = 0
+		b.successes++
+		if !b.healthy && b.successes >= cfg.Rise {
+			b.healthy = true
+			b.drainSince = time.Time{}
+		}
+	}
+	return b.healthy
+}
+
+// drained tells whether a real server with weight 0 can be removed, now is when the connections were counted.
+func (b *backend) drained(cfg HealthConfig, connections int, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.drainSince.IsZero() {
+		b.drainSince = now
+	}
+	return connections == 0 || now.Sub(b.drainSince) >= cfg.DrainTimeout
+}
+
+// drainTimedOut tells whether the drain timeout of a real server has passed since it started draining.
+func (b *backend) drainTimedOut(cfg HealthConfig, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.drainSince.IsZero() && now.Sub(b.drainSince) >= cfg.DrainTimeout
+}
+
+func (b *backend) startDrain(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drainSince = now
+}
+
+// parseRealServer parses a real server like 10.0.0.2:6443 or 10.0.0.2:6443@3, where 3 is its weight.
+func parseRealServer(s string) (endpoint, int, error) {
+	addr, weight := s, defaultWeight
+	if idx := strings.LastIndex(s, "@"); idx >= 0 {
+		addr = s[:idx]
+		w, err := strconv.Atoi(s[idx+1:])
+		if err != nil || w < 1 {
+			return endpoint{}, 0, fmt.Errorf("invalid weight of real server %s, must be a positive integer", s)
+		}
+		weight = w
+	}
+	ep, err := parseEndpoint(addr)
+	if err != nil {
+		return endpoint{}, 0, err
+	}
+	return ep, weight, nil
+}
+
+// probeBackend probes the real server of the virtual server vs and records the result, it
+// returns whether the real server is healthy after applying the rise and fall thresholds.
+func probeBackend(prober Prober, cfg HealthConfig, vs string, rs *backend) bool {
+	rsAddr := rs.String()
+	start := time.Now()
+	probeErr := prober.Probe(rs.IP, strconv.Itoa(int(rs.Port)))
+	probeDuration.WithLabelValues(vs, rsAddr).Observe(time.Since(start).Seconds())
+	if probeErr != nil {
+		logger.Debug("probe error: %v", probeErr)
+		probeFailures.WithLabelValues(vs, rsAddr).Inc()
+	}
+	healthy := rs.observe(cfg, probeErr)
+	if healthy {
+		backendHealthy.WithLabelValues(vs, rsAddr).Set(1)
+	} else {
+		backendHealthy.WithLabelValues(vs, rsAddr).Set(0)
+	}
+	return healthy
+}
@@ -0,0 +1,158 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRealServer(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    endpoint
+		weight  int
+		wantErr bool
+	}{
+		{in: "10.0.0.2:6443", want: endpoint{IP: "10.0.0.2", Port: 6443}, weight: defaultWeight},
+		{in: "10.0.0.2:6443@3", want: endpoint{IP: "10.0.0.2", Port: 6443}, weight: 3},
+		{in: "[fd00::2]:6443@2", want: endpoint{IP: "fd00::2", Port: 6443}, weight: 2},
+		{in: "10.0.0.2:6443@0", wantErr: true},
+		{in: "10.0.0.2:6443@-1", wantErr: true},
+		{in: "10.0.0.2:6443@", wantErr: true},
+		{in: "10.0.0.2:6443@x", wantErr: true},
+		{in: "10.0.0.2@3", wantErr: true},
+		{in: "10.0.0.2:70000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			ep, weight, err := parseRealServer(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRealServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ep != tt.want || weight != tt.weight {
+				t.Errorf("parseRealServer() = %v, %d, want %v, %d", ep, weight, tt.want, tt.weight)
+			}
+		})
+	}
+}
+
+func TestBackendObserve(t *testing.T) {
+	errProbe := errors.New("probe failed")
+	tests := []struct {
+		name   string
+		cfg    HealthConfig
+		probes []error
+		want   []bool
+	}{
+		{
+			name:   "fall and rise after one probe",
+			cfg:    HealthConfig{Rise: 1, Fall: 1},
+			probes: []error{nil, errProbe, nil},
+			want:   []bool{true, false, true},
+		},
+		{
+			name:   "healthy until fall failures in a row",
+			cfg:    HealthConfig{Rise: 1, Fall: 3},
+			probes: []error{errProbe, errProbe, errProbe, errProbe},
+			want:   []bool{true, true, false, false},
+		},
+		{
+			name:   "a success resets the failures",
+			cfg:    HealthConfig{Rise: 1, Fall: 2},
+			probes: []error{errProbe, nil, errProbe, nil},
+			want:   []bool{true, true, true, true},
+		},
+		{
+			name:   "unhealthy until rise successes in a row",
+			cfg:    HealthConfig{Rise: 2, Fall: 1},
+			probes: []error{errProbe, nil, errProbe, nil, nil},
+			want:   []bool{false, false, false, false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBackend(endpoint{IP: "10.0.0.2", Port: 6443}, defaultWeight)
+			for i, probeErr := range tt.probes {
+				if got := b.observe(tt.cfg, probeErr); got != tt.want[i] {
+					t.Fatalf("observe() after probe %d = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBackendDrain(t *testing.T) {
+	cfg := HealthConfig{Rise: 1, Fall: 1, DrainTimeout: time.Minute}
+	start := time.Now()
+	tests := []struct {
+		name        string
+		drainSince  time.Time
+		connections int
+		now         time.Time
+		drained     bool
+		timedOut    bool
+	}{
+		{
+			name:        "not draining yet",
+			connections: 1,
+			now:         start,
+		},
+		{
+			name:       "no connections left",
+			drainSince: start,
+			now:        start.Add(time.Second),
+			drained:    true,
+		},
+		{
+			name:        "connections open within the timeout",
+			drainSince:  start,
+			connections: 2,
+			now:         start.Add(30 * time.Second),
+		},
+		{
+			name:        "connections open after the timeout",
+			drainSince:  start,
+			connections: 2,
+			now:         start.Add(time.Minute),
+			drained:     true,
+			timedOut:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBackend(endpoint{IP: "10.0.0.2", Port: 6443}, defaultWeight)
+			if !tt.drainSince.IsZero() {
+				b.startDrain(tt.drainSince)
+			}
+			if got := b.drainTimedOut(cfg, tt.now); got != tt.timedOut {
+				t.Errorf("drainTimedOut() = %v, want %v", got, tt.timedOut)
+			}
+			if got := b.drained(cfg, tt.connections, tt.now); got != tt.drained {
+				t.Errorf("drained() = %v, want %v", got, tt.drained)
+			}
+		})
+	}
+
+	// a recovered real server drains from the start the next time
+	b := newBackend(endpoint{IP: "10.0.0.2", Port: 6443}, defaultWeight)
+	b.observe(cfg, errors.New("probe failed"))
+	b.startDrain(start)
+	b.observe(cfg, nil)
+	if b.drainTimedOut(cfg, start.Add(time.Hour)) {
+		t.Error("drain of a recovered real server is not reset")
+	}
+}
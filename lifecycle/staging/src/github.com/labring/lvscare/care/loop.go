@@ -0,0 +1,85 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// healthLoop runs the health checks of a proxier every interval, or at once when TryRun is
+// called. It is shared by all the proxy modes so they behave the same way.
+type healthLoop struct {
+	syncFn func() error
+	check  func()
+	ticker *time.Ticker
+	tryCh  chan struct{}
+	errCh  chan error
+}
+
+func newHealthLoop(interval time.Duration, syncFn func() error, check func()) *healthLoop {
+	return &healthLoop{
+		syncFn: syncFn,
+		check:  check,
+		ticker: time.NewTicker(interval),
+		tryCh:  make(chan struct{}, 1),
+		errCh:  make(chan error, 1),
+	}
+}
+
+func (l *healthLoop) RunLoop(ctx context.Context) error {
+	defer l.ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-l.ticker.C:
+			if err := l.TryRun(); err != nil {
+				logger.Error(err)
+			}
+		case _, ok := <-l.tryCh:
+			if l.syncFn != nil {
+				if err := l.syncFn(); err != nil {
+					return err
+				}
+			}
+			if ok {
+				l.check()
+			}
+		case err, ok := <-l.errCh:
+			if !ok {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (l *healthLoop) TryRun() error {
+	// preventing concurrent call
+	select {
+	case l.tryCh <- struct{}{}:
+		return nil
+	default:
+		return errors.New("currently unavailable, one task in the queue")
+	}
+}
+
+func (l *healthLoop) Stop() {
+	close(l.errCh)
+}
@@ -0,0 +1,91 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+
+	probeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: appName,
+		Name:      "probe_duration_seconds",
+		Help:      "Latency of health probes to real servers.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"virtual_server", "real_server"})
+	probeFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: appName,
+		Name:      "probe_failures_total",
+		Help:      "Number of failed health probes to real servers.",
+	}, []string{"virtual_server", "real_server"})
+	connectFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: appName,
+		Name:      "connect_failures_total",
+		Help:      "Number of connections the userspace proxy failed to open to real servers.",
+	}, []string{"virtual_server", "real_server"})
+	backendHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: appName,
+		Name:      "backend_healthy",
+		Help:      "Whether the real server is healthy after the rise and fall thresholds, 1 for healthy.",
+	}, []string{"virtual_server", "real_server"})
+	backendWeight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: appName,
+		Name:      "backend_weight",
+		Help:      "Weight of the real server in the proxy, 0 while draining, -1 if it is removed.",
+	}, []string{"virtual_server", "real_server"})
+	backendConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: appName,
+		Name:      "backend_connections",
+		Help:      "Connections of the real server in the proxy.",
+	}, []string{"virtual_server", "real_server", "state"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		probeDuration,
+		probeFailures,
+		connectFailures,
+		backendHealthy,
+		backendWeight,
+		backendConnections,
+	)
+}
+
+// serveMetrics serves the Prometheus metrics on addr until ctx is done.
+func serveMetrics(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	logger.Info("serving metrics on %s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+	utilexec "k8s.io/utils/exec"
+)
+
+const nftTable = appName
+
+// NewNftablesProxier returns a proxier that balances the virtual servers with nftables DNAT
+// rules, for nodes without the ip_vs kernel module. Only the healthy real servers are in the
+// rules, a real server with weight n is picked n times as often as one with weight 1. The
+// connections of a removed real server are kept until the drain timeout, then their conntrack
+// entries are flushed.
+func NewNftablesProxier(
+	interval time.Duration,
+	prober Prober,
+	health HealthConfig,
+	syncFn func() error,
+) Proxier {
+	exec := utilexec.New()
+	p := &nftablesProxier{
+		serviceMap: make(map[endpoint]map[string]*backend),
+		draining:   make(map[string]*backend),
+		prober:     prober,
+		health:     health,
+		apply: func(script string) error {
+			cmd := exec.Command("nft", "-f", "-")
+			cmd.SetStdin(strings.NewReader(script))
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to apply nftables rules: %v: %s", err, out)
+			}
+			return nil
+		},
+		flush: func(vs, rs endpoint) error {
+			out, err := exec.Command(
+				"conntrack", "-D", "-p", "tcp",
+				"--orig-dst", vs.IP, "--orig-port-dst", strconv.Itoa(int(vs.Port)),
+				"--reply-src", rs.IP, "--reply-port-src", strconv.Itoa(int(rs.Port)),
+			).CombinedOutput()
+			// conntrack exits with an error if no entry is deleted
+			if err != nil && !strings.Contains(string(out), "0 flow entries") {
+				return fmt.Errorf("failed to flush conntrack entries: %v: %s", err, out)
+			}
+			return nil
+		},
+	}
+	p.healthLoop = newHealthLoop(interval, syncFn, p.runCheck)
+	return p
+}
+
+type nftablesProxier struct {
+	*healthLoop
+	// apply runs a nft script atomically
+	apply func(script string) error
+	// flush deletes the conntrack entries of the connections from vs to rs
+	flush func(vs, rs endpoint) error
+
+	mu         sync.Mutex
+	serviceMap map[endpoint]map[string]*backend
+	healthy    map[string]bool
+	// draining are the unhealthy real servers whose connections are not flushed yet
+	draining map[string]*backend
+	applied  string
+
+	prober Prober
+	health HealthConfig
+}
+
+func (p *nftablesProxier) EnsureVirtualServer(vs string) error {
+	ep, err := parseNftablesEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.serviceMap[ep]; !ok {
+		p.serviceMap[ep] = make(map[string]*backend)
+	}
+	return p.syncRules()
+}
+
+func (p *nftablesProxier) DeleteVirtualServer(vs string) error {
+	ep, err := parseNftablesEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.serviceMap, ep)
+	if len(p.serviceMap) > 0 {
+		return p.syncRules()
+	}
+	// declaring the table first makes the deletion succeed even if it does not exist
+	if err := p.apply(fmt.Sprintf("table ip %s\ndelete table ip %s\n", nftTable, nftTable)); err != nil {
+		logger.Error("Failed to delete nftables table: %v", err)
+		return err
+	}
+	p.applied = ""
+	return nil
+}
+
+// EnsureRealServer adds the real server to the virtual server, rs may carry a weight like 10.0.0.2:6443@3.
+func (p *nftablesProxier) EnsureRealServer(vs, rs string) error {
+	vsEp, err := parseNftablesEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	rsEp, weight, err := parseRealServer(rs)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(rsEp.IP); ip == nil || ip.To4() == nil {
+		return fmt.Errorf("real server %s must be an IPv4 address in nftables mode", rs)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.serviceMap[vsEp]; !ok {
+		p.serviceMap[vsEp] = make(map[string]*backend)
+	}
+	p.serviceMap[vsEp][rsEp.String()] = newBackend(rsEp, weight)
+	backendHealthy.WithLabelValues(vs, rsEp.String()).Set(1)
+	backendWeight.WithLabelValues(vs, rsEp.String()).Set(float64(weight))
+	return p.syncRules()
+}
+
+func (p *nftablesProxier) DeleteRealServer(vs, rs string) error {
+	vsEp, err := parseNftablesEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	rsEp, _, err := parseRealServer(rs)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rsMap, ok := p.serviceMap[vsEp]; ok {
+		delete(rsMap, rsEp.String())
+	}
+	return p.syncRules()
+}
+
+func (p *nftablesProxier) runCheck() {
+	p.mu.Lock()
+	services := make(map[endpoint][]*backend, len(p.serviceMap))
+	for vs, rsMap := range p.serviceMap {
+		for _, rs := range rsMap {
+			services[vs] = append(services[vs], rs)
+		}
+	}
+	p.mu.Unlock()
+
+	healthy := make(map[string]bool)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for vs, backends := range services {
+		for _, rs := range backends {
+			wg.Add(1)
+			go func(vs string, rs *backend) {
+				defer wg.Done()
+				ok := probeBackend(p.prober, p.health, vs, rs)
+				mu.Lock()
+				healthy[vs+"/"+rs.String()] = ok
+				mu.Unlock()
+			}(vs.String(), rs)
+		}
+	}
+	wg.Wait()
+
+	now := time.Now()
+	p.mu.Lock()
+	for key, ok := range healthy {
+		if ok != p.isHealthy(key) {
+			vs, rsAddr, _ := strings.Cut(key, "/")
+			rs, found := p.lookup(vs, rsAddr)
+			if ok {
+				logger.Info("real server %s is healthy, adding it back", rsAddr)
+				delete(p.draining, key)
+			} else {
+				logger.Info("real server %s is unhealthy, removing it", rsAddr)
+				if found {
+					rs.startDrain(now)
+					p.draining[key] = rs
+				}
+			}
+			weight := -1.0
+			if found && ok {
+				weight = float64(rs.weight)
+			}
+			backendWeight.WithLabelValues(vs, rsAddr).Set(weight)
+		}
+	}
+	p.healthy = healthy
+	if err := p.syncRules(); err != nil {
+		logger.Warn("Failed to sync nftables rules: %v", err)
+	}
+	expired := p.expiredDrains(now)
+	p.mu.Unlock()
+
+	for key, rs := range expired {
+		vs, rsAddr, _ := strings.Cut(key, "/")
+		logger.Info("real server %s is drained, closing its connections", rsAddr)
+		vsEp, _ := parseEndpoint(vs)
+		if err := p.flush(vsEp, rs.endpoint); err != nil {
+			logger.Warn("Failed to close the connections of real server %s: %v", rsAddr, err)
+		}
+	}
+}
+
+// expiredDrains removes and returns the draining real servers whose drain timeout has passed,
+// a real server which is deleted meanwhile is dropped, p.mu must be held.
+func (p *nftablesProxier) expiredDrains(now time.Time) map[string]*backend {
+	expired := make(map[string]*backend)
+	for key, rs := range p.draining {
+		vs, rsAddr, _ := strings.Cut(key, "/")
+		if current, found := p.lookup(vs, rsAddr); !found || current != rs {
+			delete(p.draining, key)
+			continue
+		}
+		if rs.drainTimedOut(p.health, now) {
+			expired[key] = rs
+			delete(p.draining, key)
+		}
+	}
+	return expired
+}
+
+func (p *nftablesProxier) isHealthy(key string) bool {
+	if ok, found := p.healthy[key]; found {
+		return ok
+	}
+	// real servers are trusted until they fail
+	return true
+}
+
+func (p *nftablesProxier) lookup(vs, rs string) (*backend, bool) {
+	ep, err := parseEndpoint(vs)
+	if err != nil {
+		return nil, false
+	}
+	b, ok := p.serviceMap[ep][rs]
+	return b, ok
+}
+
+// syncRules applies the rules if they are changed since the last time, p.mu must be held.
+func (p *nftablesProxier) syncRules() error {
+	script := p.buildRules()
+	if script == p.applied {
+		return nil
+	}
+	logger.Debug("apply nftables rules:\n%s", script)
+	if err := p.apply(script); err != nil {
+		return err
+	}
+	p.applied = script
+	return nil
+}
+
+// buildRules renders the table of all the virtual servers, the table is flushed and created
+// again in one transaction so the rules are never partially applied.
+func (p *nftablesProxier) buildRules() string {
+	services := make([]endpoint, 0, len(p.serviceMap))
+	for vs := range p.serviceMap {
+		services = append(services, vs)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].String() < services[j].String() })
+
+	var rules []string
+	for _, vs := range services {
+		var targets []string
+		for _, rs := range sortedBackends(p.serviceMap[vs]) {
+			if !p.isHealthy(vs.String() + "/" + rs.String()) {
+				continue
+			}
+			for i := 0; i < rs.weight; i++ {
+				targets = append(targets, fmt.Sprintf("%d : %s . %d", len(targets), rs.IP, rs.Port))
+			}
+		}
+		if len(targets) == 0 {
+			// no healthy real server, leave the virtual server unreachable
+			continue
+		}
+		rules = append(rules, fmt.Sprintf(
+			"ip daddr %s tcp dport %d dnat ip addr . port to numgen random mod %d map { %s }",
+			vs.IP, vs.Port, len(targets), strings.Join(targets, ", "),
+		))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(
+		&sb,
+		"table ip %s\ndelete table ip %s\ntable ip %s {\n",
+		nftTable,
+		nftTable,
+		nftTable,
+	)
+	for _, chain := range []struct{ name, hook string }{
+		{"prerouting", "prerouting"},
+		{"output", "output"},
+	} {
+		fmt.Fprintf(&sb, "\tchain %s {\n\t\ttype nat hook %s priority -100; policy accept;\n",
+			chain.name, chain.hook)
+		for _, rule := range rules {
+			fmt.Fprintf(&sb, "\t\t%s\n", rule)
+		}
+		sb.WriteString("\t}\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func sortedBackends(rsMap map[string]*backend) []*backend {
+	backends := make([]*backend, 0, len(rsMap))
+	for _, rs := range rsMap {
+		backends = append(backends, rs)
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].String() < backends[j].String() })
+	return backends
+}
+
+func parseNftablesEndpoint(vs string) (endpoint, error) {
+	ep, err := parseEndpoint(vs)
+	if err != nil {
+		return endpoint{}, err
+	}
+	if ip := net.ParseIP(ep.IP); ip == nil || ip.To4() == nil {
+		return endpoint{}, fmt.Errorf(
+			"virtual server %s must be an IPv4 address in nftables mode",
+			vs,
+		)
+	}
+	return ep, nil
+}
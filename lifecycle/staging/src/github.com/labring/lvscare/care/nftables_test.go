@@ -0,0 +1,258 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeProber struct {
+	mu     sync.Mutex
+	failed map[string]bool
+}
+
+func (p *fakeProber) Probe(host, port string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failed[host+":"+port] {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (p *fakeProber) set(addr string, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failed == nil {
+		p.failed = map[string]bool{}
+	}
+	p.failed[addr] = failed
+}
+
+type flushed struct{ vs, rs string }
+
+func newTestNftablesProxier(prober Prober, health HealthConfig) (*nftablesProxier, *[]string, *[]flushed) {
+	var (
+		scripts []string
+		flushes []flushed
+	)
+	p := &nftablesProxier{
+		serviceMap: make(map[endpoint]map[string]*backend),
+		draining:   make(map[string]*backend),
+		prober:     prober,
+		health:     health,
+		apply: func(script string) error {
+			scripts = append(scripts, script)
+			return nil
+		},
+		flush: func(vs, rs endpoint) error {
+			flushes = append(flushes, flushed{vs.String(), rs.String()})
+			return nil
+		},
+	}
+	return p, &scripts, &flushes
+}
+
+func TestNftablesBuildRules(t *testing.T) {
+	const header = "table ip lvscare\ndelete table ip lvscare\ntable ip lvscare {\n"
+	chains := func(rules ...string) string {
+		var sb strings.Builder
+		sb.WriteString(header)
+		for _, chain := range []string{"prerouting", "output"} {
+			sb.WriteString("\tchain " + chain + " {\n\t\ttype nat hook " + chain +
+				" priority -100; policy accept;\n")
+			for _, rule := range rules {
+				sb.WriteString("\t\t" + rule + "\n")
+			}
+			sb.WriteString("\t}\n")
+		}
+		sb.WriteString("}\n")
+		return sb.String()
+	}
+
+	tests := []struct {
+		name      string
+		services  map[string][]string
+		unhealthy []string
+		want      string
+	}{
+		{
+			name: "no virtual server",
+			want: chains(),
+		},
+		{
+			name:     "virtual server without real servers",
+			services: map[string][]string{"10.103.97.2:6443": nil},
+			want:     chains(),
+		},
+		{
+			name: "real servers are sorted",
+			services: map[string][]string{
+				"10.103.97.2:6443": {"192.168.0.3:6443", "192.168.0.2:6443"},
+			},
+			want: chains("ip daddr 10.103.97.2 tcp dport 6443 dnat ip addr . port to numgen random mod 2 " +
+				"map { 0 : 192.168.0.2 . 6443, 1 : 192.168.0.3 . 6443 }"),
+		},
+		{
+			name: "weighted real servers are repeated",
+			services: map[string][]string{
+				"10.103.97.2:6443": {"192.168.0.2:6443@3", "192.168.0.3:6443"},
+			},
+			want: chains("ip daddr 10.103.97.2 tcp dport 6443 dnat ip addr . port to numgen random mod 4 " +
+				"map { 0 : 192.168.0.2 . 6443, 1 : 192.168.0.2 . 6443, 2 : 192.168.0.2 . 6443, " +
+				"3 : 192.168.0.3 . 6443 }"),
+		},
+		{
+			name: "unhealthy real servers are excluded",
+			services: map[string][]string{
+				"10.103.97.2:6443": {"192.168.0.2:6443", "192.168.0.3:6443"},
+			},
+			unhealthy: []string{"10.103.97.2:6443/192.168.0.2:6443"},
+			want: chains("ip daddr 10.103.97.2 tcp dport 6443 dnat ip addr . port to numgen random mod 1 " +
+				"map { 0 : 192.168.0.3 . 6443 }"),
+		},
+		{
+			name: "virtual server without healthy real servers is left out",
+			services: map[string][]string{
+				"10.103.97.2:6443": {"192.168.0.2:6443"},
+				"10.103.97.3:80":   {"192.168.0.2:80"},
+			},
+			unhealthy: []string{"10.103.97.2:6443/192.168.0.2:6443"},
+			want: chains("ip daddr 10.103.97.3 tcp dport 80 dnat ip addr . port to numgen random mod 1 " +
+				"map { 0 : 192.168.0.2 . 80 }"),
+		},
+		{
+			name: "virtual servers are sorted",
+			services: map[string][]string{
+				"10.103.97.3:80":   {"192.168.0.2:80"},
+				"10.103.97.2:6443": {"192.168.0.2:6443"},
+			},
+			want: chains(
+				"ip daddr 10.103.97.2 tcp dport 6443 dnat ip addr . port to numgen random mod 1 "+
+					"map { 0 : 192.168.0.2 . 6443 }",
+				"ip daddr 10.103.97.3 tcp dport 80 dnat ip addr . port to numgen random mod 1 "+
+					"map { 0 : 192.168.0.2 . 80 }",
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _, _ := newTestNftablesProxier(&fakeProber{}, HealthConfig{})
+			for vs, rss := range tt.services {
+				if err := p.EnsureVirtualServer(vs); err != nil {
+					t.Fatalf("EnsureVirtualServer(%s) error = %v", vs, err)
+				}
+				for _, rs := range rss {
+					if err := p.EnsureRealServer(vs, rs); err != nil {
+						t.Fatalf("EnsureRealServer(%s, %s) error = %v", vs, rs, err)
+					}
+				}
+			}
+			p.healthy = map[string]bool{}
+			for _, key := range tt.unhealthy {
+				p.healthy[key] = false
+			}
+			if got := p.buildRules(); got != tt.want {
+				t.Errorf("buildRules() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNftablesRejectsIPv6(t *testing.T) {
+	p, _, _ := newTestNftablesProxier(&fakeProber{}, HealthConfig{})
+	if err := p.EnsureVirtualServer("[fd00::1]:6443"); err == nil {
+		t.Error("EnsureVirtualServer() of an IPv6 virtual server should fail")
+	}
+	if err := p.EnsureRealServer("10.103.97.2:6443", "[fd00::2]:6443"); err == nil {
+		t.Error("EnsureRealServer() of an IPv6 real server should fail")
+	}
+}
+
+func TestNftablesSyncRulesOnlyOnChange(t *testing.T) {
+	p, scripts, _ := newTestNftablesProxier(&fakeProber{}, HealthConfig{Rise: 1, Fall: 1})
+	if err := p.EnsureRealServer("10.103.97.2:6443", "192.168.0.2:6443"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EnsureVirtualServer("10.103.97.2:6443"); err != nil {
+		t.Fatal(err)
+	}
+	p.runCheck()
+	if len(*scripts) != 1 {
+		t.Errorf("applied %d scripts, want 1", len(*scripts))
+	}
+	if err := p.DeleteVirtualServer("10.103.97.2:6443"); err != nil {
+		t.Fatal(err)
+	}
+	if got := (*scripts)[len(*scripts)-1]; got != "table ip lvscare\ndelete table ip lvscare\n" {
+		t.Errorf("deleting the last virtual server applied %q", got)
+	}
+}
+
+func TestNftablesDrainTimeout(t *testing.T) {
+	const (
+		vs = "10.103.97.2:6443"
+		rs = "192.168.0.2:6443"
+	)
+	prober := &fakeProber{}
+	p, scripts, flushes := newTestNftablesProxier(
+		prober,
+		HealthConfig{Rise: 1, Fall: 1, DrainTimeout: time.Hour},
+	)
+	if err := p.EnsureRealServer(vs, rs); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EnsureRealServer(vs, "192.168.0.3:6443"); err != nil {
+		t.Fatal(err)
+	}
+
+	prober.set(rs, true)
+	p.runCheck()
+	if got := (*scripts)[len(*scripts)-1]; strings.Contains(got, "192.168.0.2") {
+		t.Errorf("unhealthy real server is still in the rules:\n%s", got)
+	}
+	if len(*flushes) != 0 {
+		t.Fatalf("connections are flushed before the drain timeout: %v", *flushes)
+	}
+
+	// the drain timeout passes while the real server is still unhealthy
+	p.draining[vs+"/"+rs].startDrain(time.Now().Add(-2 * time.Hour))
+	p.runCheck()
+	if want := []flushed{{vs, rs}}; len(*flushes) != 1 || (*flushes)[0] != want[0] {
+		t.Fatalf("flushed %v, want %v", *flushes, want)
+	}
+	p.runCheck()
+	if len(*flushes) != 1 {
+		t.Errorf("connections are flushed again: %v", *flushes)
+	}
+
+	// a real server which recovers before the drain timeout keeps its connections
+	prober.set(rs, false)
+	p.runCheck()
+	prober.set(rs, true)
+	p.runCheck()
+	prober.set(rs, false)
+	p.runCheck()
+	if len(p.draining) != 0 {
+		t.Errorf("recovered real server is still draining")
+	}
+	if len(*flushes) != 1 {
+		t.Errorf("connections of a recovered real server are flushed: %v", *flushes)
+	}
+}
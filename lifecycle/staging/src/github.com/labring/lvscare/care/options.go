@@ -25,14 +25,19 @@ import (
 
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/utils/hosts"
+	"github.com/labring/sealos/pkg/utils/logger"
 	"github.com/spf13/pflag"
 	"k8s.io/klog/v2"
 )
 
 const (
-	appName   = "lvscare"
-	routeMode = "route"
-	linkMode  = "link"
+	appName       = "lvscare"
+	routeMode     = "route"
+	linkMode      = "link"
+	nftablesMode  = "nftables"
+	userspaceMode = "userspace"
+	// autoMode picks the first mode the node supports, see DetectMode
+	autoMode = "auto"
 )
 
 type options struct {
@@ -47,11 +52,15 @@ type options struct {
 	Interval      durationOrSecondValue
 	TargetIP      net.IP
 	MasqueradeBit int
+	Rise          int
+	Fall          int
+	DrainTimeout  time.Duration
+	MetricsAddr   string
 }
 
 func (o *options) RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.VirtualServer, "vs", "", "virtual server address, for example 169.254.0.1:6443")
-	fs.StringSliceVar(&o.RealServer, "rs", []string{}, "real server address like 192.168.0.2:6443")
+	fs.StringSliceVar(&o.RealServer, "rs", []string{}, "real server address like 192.168.0.2:6443, with an optional weight like 192.168.0.2:6443@3")
 	fs.StringVar(&o.scheduler, "scheduler", "rr", "proxier scheduler")
 	fs.StringVarP(
 		&o.IfaceName,
@@ -61,12 +70,18 @@ func (o *options) RegisterFlags(fs *pflag.FlagSet) {
 		"name of dummy interface to created, same behavior as kube-proxy",
 	)
 	fs.StringVar(&o.Logger, "logger", "INFO", "logger level: DEBG/INFO")
-	fs.StringVar(&o.Mode, "mode", routeMode, fmt.Sprintf("proxy mode: %s/%s", routeMode, linkMode))
-	fs.BoolVar(&o.RunOnce, "run-once", false, "create proxy rules and exit")
+	fs.StringVar(&o.Mode, "mode", routeMode, fmt.Sprintf(
+		"proxy mode: %s/%s use IPVS, %s uses nftables DNAT, %s proxies in lvscare itself, %s detects the mode supported by the node",
+		routeMode, linkMode, nftablesMode, userspaceMode, autoMode))
+	fs.BoolVar(&o.RunOnce, "run-once", false, "create proxy rules and exit, in userspace mode a detached lvscare proxies until the static pod takes over")
 	fs.BoolVarP(&o.CleanAndExit, "clean", "C", false, "clean existing rules and then exit")
 	fs.Var(&o.Interval, "interval", "health check interval")
-	fs.IPVar(&o.TargetIP, "ip", nil, "target ip as route gateway, use with route mode")
+	fs.IPVar(&o.TargetIP, "ip", nil, "target ip as route gateway, use with route or nftables mode")
 	fs.IntVar(&o.MasqueradeBit, "masqueradebit", 0, "IPTables masquerade bit")
+	fs.IntVar(&o.Rise, "rise", 1, "consecutive successful health checks before an unhealthy real server receives traffic again")
+	fs.IntVar(&o.Fall, "fall", 1, "consecutive failed health checks, or connections in userspace mode, before a healthy real server is drained")
+	fs.DurationVar(&o.DrainTimeout, "drain-timeout", 30*time.Second, "max time to wait for connections of a drained real server to close before removing it")
+	fs.StringVar(&o.MetricsAddr, "metrics-addr", "", "address to serve prometheus metrics on, like 127.0.0.1:9090, disabled if empty")
 
 	// set klog flag
 	if v := os.Getenv("ENABLE_KLOG_FLAGS"); len(v) > 0 {
@@ -90,7 +105,19 @@ func (o *options) ValidateAndSetDefaults() error {
 	default:
 		return fmt.Errorf(`invalid flag "scheduler=%s"`, o.scheduler)
 	}
-	if o.TargetIP == nil && o.Mode == routeMode {
+	for _, rs := range o.RealServer {
+		if _, _, err := parseRealServer(rs); err != nil {
+			return fmt.Errorf(`invalid flag "rs=%s": %v`, rs, err)
+		}
+	}
+	if o.Rise < 1 || o.Fall < 1 {
+		return errors.New(`flags "rise" and "fall" must be at least 1`)
+	}
+	if o.Mode == autoMode {
+		o.Mode = DetectMode()
+		logger.Info("detected proxy mode %s", o.Mode)
+	}
+	if o.TargetIP == nil && (o.Mode == routeMode || o.Mode == nftablesMode) {
 		hf := &hosts.HostFile{Path: constants.DefaultHostsPath}
 		if ip, ok := hf.HasDomain(constants.DefaultLvscareDomain); ok {
 			o.TargetIP = net.ParseIP(ip)
@@ -16,7 +16,6 @@ package care
 
 import (
 	"context"
-	"errors"
 	"net"
 	"strconv"
 	"sync"
@@ -48,31 +47,29 @@ func NewProxier(
 	scheduler string,
 	interval time.Duration,
 	prober Prober,
+	health HealthConfig,
 	syncFn func() error,
 ) Proxier {
-	return &realProxier{
+	p := &realProxier{
 		scheduler:  scheduler,
 		ipvsHandle: ipvs.New(),
-		syncFn:     syncFn,
-		serviceMap: make(map[endpoint]map[string]endpoint),
+		serviceMap: make(map[endpoint]map[string]*backend),
 		prober:     prober,
-		ticker:     time.NewTicker(interval),
-		tryCh:      make(chan struct{}, 1),
-		errCh:      make(chan error, 1),
+		health:     health,
 	}
+	p.healthLoop = newHealthLoop(interval, syncFn, p.runCheck)
+	return p
 }
 
 type realProxier struct {
+	*healthLoop
 	scheduler  string
 	ipvsHandle ipvs.Interface
-	syncFn     func() error
 
 	// for prober
-	serviceMap map[endpoint]map[string]endpoint
+	serviceMap map[endpoint]map[string]*backend
 	prober     Prober
-	ticker     *time.Ticker
-	tryCh      chan struct{}
-	errCh      chan error
+	health     HealthConfig
 }
 
 func (p *realProxier) ensureVirtualServer(vs *ipvs.VirtualServer) (*ipvs.VirtualServer, error) {
@@ -103,7 +100,7 @@ func (p *realProxier) EnsureVirtualServer(vs string) error {
 		return err
 	}
 	if _, ok := p.serviceMap[ep]; !ok {
-		p.serviceMap[ep] = make(map[string]endpoint)
+		p.serviceMap[ep] = make(map[string]*backend)
 	}
 	return nil
 }
@@ -148,19 +145,20 @@ func (p *realProxier) getServersByEndpoint(
 	if err != nil {
 		return nil, nil, err
 	}
-	rSrv, err := p.getRealServer(vSrv, p.buildRealServer(&rs))
+	rSrv, err := p.getRealServer(vSrv, p.buildRealServer(&rs, defaultWeight))
 	if err != nil {
 		return nil, nil, err
 	}
 	return vSrv, rSrv, nil
 }
 
+// EnsureRealServer adds the real server to the virtual server, rs may carry a weight like 10.0.0.2:6443@3.
 func (p *realProxier) EnsureRealServer(vs, rs string) error {
 	vsEp, err := parseEndpoint(vs)
 	if err != nil {
 		return err
 	}
-	rsEp, err := parseEndpoint(rs)
+	rsEp, weight, err := parseRealServer(rs)
 	if err != nil {
 		return err
 	}
@@ -170,13 +168,22 @@ func (p *realProxier) EnsureRealServer(vs, rs string) error {
 	}
 	defer func() {
 		if err == nil {
-			p.serviceMap[vsEp][rsEp.String()] = rsEp
+			p.serviceMap[vsEp][rsEp.String()] = newBackend(rsEp, weight)
+			backendHealthy.WithLabelValues(vs, rsEp.String()).Set(1)
+			backendWeight.WithLabelValues(vs, rsEp.String()).Set(float64(weight))
 		}
 	}()
 	if rSrv != nil {
+		if rSrv.Weight != weight {
+			rSrv.Weight = weight
+			if err = p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
+				logger.Error("Failed to update real server weight: %v", err)
+				return err
+			}
+		}
 		return nil
 	}
-	rSrv = p.buildRealServer(&rsEp)
+	rSrv = p.buildRealServer(&rsEp, weight)
 	if err = p.ipvsHandle.AddRealServer(vSrv, rSrv); err != nil {
 		logger.Error("Failed to add real server: %v", err)
 		return err
@@ -189,7 +196,7 @@ func (p *realProxier) DeleteRealServer(vs, rs string) error {
 	if err != nil {
 		return err
 	}
-	rsEp, err := parseEndpoint(rs)
+	rsEp, _, err := parseRealServer(rs)
 	if err != nil {
 		return err
 	}
@@ -207,88 +214,65 @@ func (p *realProxier) DeleteRealServer(vs, rs string) error {
 	return nil
 }
 
-func (p *realProxier) RunLoop(ctx context.Context) error {
-	defer p.ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case <-p.ticker.C:
-			if err := p.TryRun(); err != nil {
-				logger.Error(err)
-			}
-		case _, ok := <-p.tryCh:
-			if p.syncFn != nil {
-				if err := p.syncFn(); err != nil {
-					return err
-				}
-			}
-			if ok {
-				p.runCheck()
-			}
-		case err, ok := <-p.errCh:
-			if !ok {
-				return nil
-			}
-			return err
-		}
-	}
-}
-
-func (p *realProxier) TryRun() error {
-	// preventing concurrent call
-	select {
-	case p.tryCh <- struct{}{}:
-		return nil
-	default:
-		return errors.New("currently unavailable, one task in the queue")
-	}
-}
-
-func (p *realProxier) Stop() {
-	close(p.errCh)
-}
-
-func (p *realProxier) checkRealServer(wg *sync.WaitGroup, vSrv *ipvs.VirtualServer, rs endpoint) {
+func (p *realProxier) checkRealServer(wg *sync.WaitGroup, vSrv *ipvs.VirtualServer, rs *backend) {
 	defer wg.Done()
-	probeErr := p.prober.Probe(rs.IP, strconv.Itoa(int(rs.Port)))
-	rSrv, err := p.getRealServer(vSrv, p.buildRealServer(&rs))
+	vs, rsAddr := net.JoinHostPort(vSrv.Address.String(), strconv.Itoa(int(vSrv.Port))), rs.String()
+	healthy := probeBackend(p.prober, p.health, vs, rs)
+
+	rSrv, err := p.getRealServer(vSrv, p.buildRealServer(&rs.endpoint, rs.weight))
 	if err != nil {
 		logger.Warn("Failed to get real server: %v", err)
 		return
 	}
-	if probeErr != nil {
-		logger.Debug("probe error: %v", probeErr)
-		if rSrv != nil {
-			if rSrv.Weight != 0 {
-				logger.Debug("Trying to update wight to 0 for graceful termination")
-				rSrv.Weight = 0
-				if err = p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
-					logger.Warn("Failed to update real server wight: %v", err)
-				}
+	if rSrv != nil {
+		backendConnections.WithLabelValues(vs, rsAddr, "active").Set(float64(rSrv.ActiveConn))
+		backendConnections.WithLabelValues(vs, rsAddr, "inactive").Set(float64(rSrv.InactiveConn))
+	}
+	if !healthy {
+		if rSrv == nil {
+			return
+		}
+		if rSrv.Weight != 0 {
+			logger.Info("real server %s is unhealthy, draining it", rsAddr)
+			rSrv.Weight = 0
+			if err = p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
+				logger.Warn("Failed to update real server weight: %v", err)
 				return
 			}
-			logger.Debug("Trying to delete real server")
-			if err = p.ipvsHandle.DeleteRealServer(vSrv, rSrv); err != nil {
-				logger.Warn("Failed to delete real server: %v", err)
-			}
+			rs.startDrain(time.Now())
+			backendWeight.WithLabelValues(vs, rsAddr).Set(0)
+			return
+		}
+		if !rs.drained(p.health, rSrv.ActiveConn+rSrv.InactiveConn, time.Now()) {
+			logger.Debug("waiting for %d connections of real server %s to close", rSrv.ActiveConn+rSrv.InactiveConn, rsAddr)
+			return
 		}
+		logger.Info("real server %s is drained, deleting it", rsAddr)
+		if err = p.ipvsHandle.DeleteRealServer(vSrv, rSrv); err != nil {
+			logger.Warn("Failed to delete real server: %v", err)
+			return
+		}
+		backendWeight.WithLabelValues(vs, rsAddr).Set(-1)
 		return
 	}
 	if rSrv != nil {
-		if rSrv.Weight == 0 {
-			logger.Debug("Trying to update wight to 1 to receive traffic")
-			rSrv.Weight = 1
+		if rSrv.Weight != rs.weight {
+			logger.Info("real server %s is healthy, restoring weight to %d", rsAddr, rs.weight)
+			rSrv.Weight = rs.weight
 			if err = p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
-				logger.Warn("Failed to update real server wight: %v", err)
+				logger.Warn("Failed to update real server weight: %v", err)
+				return
 			}
+			backendWeight.WithLabelValues(vs, rsAddr).Set(float64(rs.weight))
 		}
 		return
 	}
-	logger.Debug("Trying to add real server back")
-	if err = p.ipvsHandle.AddRealServer(vSrv, p.buildRealServer(&rs)); err != nil {
+	logger.Info("real server %s is healthy, adding it back", rsAddr)
+	if err = p.ipvsHandle.AddRealServer(vSrv, p.buildRealServer(&rs.endpoint, rs.weight)); err != nil {
 		logger.Warn("Failed to add real server back: %v", err)
+		return
 	}
+	backendWeight.WithLabelValues(vs, rsAddr).Set(float64(rs.weight))
 }
 
 func (p *realProxier) runCheck() {
@@ -318,11 +302,11 @@ func (p *realProxier) buildVirtualServer(ep *endpoint) *ipvs.VirtualServer {
 	}
 }
 
-func (p *realProxier) buildRealServer(ep *endpoint) *ipvs.RealServer {
+func (p *realProxier) buildRealServer(ep *endpoint, weight int) *ipvs.RealServer {
 	return &ipvs.RealServer{
 		Address: net.ParseIP(ep.IP),
 		Port:    ep.Port,
-		Weight:  1,
+		Weight:  weight,
 	}
 }
 
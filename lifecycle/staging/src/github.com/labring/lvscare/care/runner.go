@@ -40,6 +40,16 @@ type runner struct {
 }
 
 func (r *runner) Run() (err error) {
+	if r.CleanAndExit {
+		if err := StopBootstrapProxy(r.VirtualServer); err != nil {
+			logger.Warn("failed to stop userspace proxy: %v", err)
+		}
+	}
+	if r.RunOnce && r.Mode == userspaceMode {
+		// a bound virtual IP without the listener would refuse connections, the node would
+		// wait for the virtual server forever
+		return r.startBootstrapProxy()
+	}
 	if !r.RunOnce {
 		defer func() {
 			cleanupErr := r.cleanup()
@@ -58,10 +68,10 @@ func (r *runner) Run() (err error) {
 	}
 
 	cleanVirtualServer := func() error {
-		logger.Info("delete IPVS service %s", r.VirtualServer)
+		logger.Info("delete virtual server %s", r.VirtualServer)
 		err := r.proxier.DeleteVirtualServer(r.VirtualServer)
 		if err != nil {
-			logger.Warn("failed to delete IPVS service: %v", err)
+			logger.Warn("failed to delete virtual server: %v", err)
 		}
 		return err
 	}
@@ -72,15 +82,22 @@ func (r *runner) Run() (err error) {
 		}
 		return err
 	}
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 2)
 	ctx := signals.SetupSignalHandler()
 	go func() {
 		errCh <- r.proxier.RunLoop(ctx)
 	}()
+	if r.MetricsAddr != "" {
+		go func() {
+			if err := serveMetrics(ctx, r.MetricsAddr); err != nil {
+				errCh <- fmt.Errorf("failed to serve metrics: %v", err)
+			}
+		}()
+	}
 	// fire at once, no need to check error here
 	_ = r.proxier.TryRun()
-	// ensure ipvs
-	if err := r.ensureIPVSRules(); err != nil {
+	// ensure proxy rules
+	if err := r.ensureProxyRules(); err != nil {
 		return err
 	}
 	if r.ruler != nil {
@@ -92,7 +109,7 @@ func (r *runner) Run() (err error) {
 }
 
 // run once at startup
-func (r *runner) ensureIPVSRules() error {
+func (r *runner) ensureProxyRules() error {
 	if err := r.proxier.EnsureVirtualServer(r.VirtualServer); err != nil {
 		return err
 	}
@@ -144,12 +161,27 @@ func (r *runner) ValidateAndSetDefaults() error {
 			}
 		}
 	}
-	r.proxier = NewProxier(
-		r.scheduler,
-		time.Duration(r.Interval),
-		r.prober,
-		r.periodicRun,
-	)
+	health := HealthConfig{Rise: r.Rise, Fall: r.Fall, DrainTimeout: r.DrainTimeout}
+	switch r.Mode {
+	case nftablesMode:
+		r.proxier = NewNftablesProxier(time.Duration(r.Interval), r.prober, health, r.periodicRun)
+	case userspaceMode:
+		r.proxier = NewUserspaceProxier(
+			r.IfaceName,
+			time.Duration(r.Interval),
+			r.prober,
+			health,
+			r.periodicRun,
+		)
+	default:
+		r.proxier = NewProxier(
+			r.scheduler,
+			time.Duration(r.Interval),
+			r.prober,
+			health,
+			r.periodicRun,
+		)
+	}
 	virtualIP, _, err := splitHostPort(r.VirtualServer)
 	if err != nil {
 		return err
@@ -157,7 +189,8 @@ func (r *runner) ValidateAndSetDefaults() error {
 
 	var ruler Ruler
 	switch r.Mode {
-	case routeMode:
+	case routeMode, nftablesMode:
+		// locally generated packets are routed before they are translated
 		if r.TargetIP == nil {
 			logger.Warn("running routeMode and Target IP is not valid IP, skipping")
 			break
@@ -169,7 +202,7 @@ func (r *runner) ValidateAndSetDefaults() error {
 			r.MasqueradeBit,
 			r.VirtualServer,
 		)
-	case "":
+	case userspaceMode, "":
 		// do nothing, disable ruler
 	default:
 		return fmt.Errorf("not yet support mode %s", r.Mode)
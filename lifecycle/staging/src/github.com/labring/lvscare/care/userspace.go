@@ -0,0 +1,377 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+	proxyipvs "k8s.io/kubernetes/pkg/proxy/ipvs"
+)
+
+const userspaceDialTimeout = 5 * time.Second
+
+// NewUserspaceProxier returns a proxier that listens on the virtual servers itself and copies
+// the connections to the real servers, for nodes without ip_vs or nftables. The virtual IPs
+// are bound to the dummy interface ifaceName, real servers are picked by weighted round robin.
+// Besides the probes, failed connections to a real server count towards its fall threshold.
+func NewUserspaceProxier(
+	ifaceName string,
+	interval time.Duration,
+	prober Prober,
+	health HealthConfig,
+	syncFn func() error,
+) Proxier {
+	p := &userspaceProxier{
+		ifaceName: ifaceName,
+		services:  make(map[endpoint]*userspaceService),
+		prober:    prober,
+		health:    health,
+	}
+	p.healthLoop = newHealthLoop(interval, syncFn, p.runCheck)
+	return p
+}
+
+type userspaceProxier struct {
+	*healthLoop
+	ifaceName string
+
+	mu       sync.Mutex
+	services map[endpoint]*userspaceService
+
+	prober Prober
+	health HealthConfig
+}
+
+type userspaceService struct {
+	vs       string
+	listener net.Listener
+	health   HealthConfig
+
+	mu       sync.Mutex
+	backends map[string]*userspaceBackend
+	next     int
+}
+
+type userspaceBackend struct {
+	*backend
+	// serving is false while the real server is drained or removed
+	serving bool
+	removed bool
+	// conns maps the proxied connections to their upstream connections
+	conns map[net.Conn]net.Conn
+}
+
+func netLinkHandle(ip string) proxyipvs.NetLinkHandle {
+	return proxyipvs.NewNetLinkHandle(net.ParseIP(ip).To4() == nil)
+}
+
+func (p *userspaceProxier) EnsureVirtualServer(vs string) error {
+	ep, err := parseEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.services[ep]; ok {
+		return nil
+	}
+	if err := ensureDummyDeviceAndAddresses(netLinkHandle(ep.IP), p.ifaceName, ep.IP); err != nil {
+		logger.Error("Failed to ensure dummy device: %v", err)
+		return err
+	}
+	listener, err := net.Listen("tcp", ep.String())
+	if err != nil {
+		logger.Error("Failed to listen on virtual server %s: %v", vs, err)
+		return err
+	}
+	svc := &userspaceService{
+		vs:       ep.String(),
+		listener: listener,
+		health:   p.health,
+		backends: make(map[string]*userspaceBackend),
+	}
+	p.services[ep] = svc
+	go svc.serve()
+	return nil
+}
+
+func (p *userspaceProxier) DeleteVirtualServer(vs string) error {
+	ep, err := parseEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if svc, ok := p.services[ep]; ok {
+		svc.close()
+		delete(p.services, ep)
+	}
+	nl := netLinkHandle(ep.IP)
+	if err := nl.UnbindAddress(ep.IP, p.ifaceName); err != nil {
+		logger.Debug("Failed to unbind address %s: %v", ep.IP, err)
+	}
+	if len(p.services) > 0 {
+		return nil
+	}
+	logger.Info("Deleting dummy device %s", p.ifaceName)
+	if err := nl.DeleteDummyDevice(p.ifaceName); err != nil {
+		logger.Error("Error deleting dummy device: %v", err)
+		return err
+	}
+	return nil
+}
+
+// EnsureRealServer adds the real server to the virtual server, rs may carry a weight like 10.0.0.2:6443@3.
+func (p *userspaceProxier) EnsureRealServer(vs, rs string) error {
+	svc, err := p.service(vs)
+	if err != nil {
+		return err
+	}
+	rsEp, weight, err := parseRealServer(rs)
+	if err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if b, ok := svc.backends[rsEp.String()]; ok {
+		b.weight = weight
+	} else {
+		svc.backends[rsEp.String()] = &userspaceBackend{
+			backend: newBackend(rsEp, weight),
+			serving: true,
+			conns:   make(map[net.Conn]net.Conn),
+		}
+	}
+	backendHealthy.WithLabelValues(vs, rsEp.String()).Set(1)
+	backendWeight.WithLabelValues(vs, rsEp.String()).Set(float64(weight))
+	return nil
+}
+
+func (p *userspaceProxier) DeleteRealServer(vs, rs string) error {
+	svc, err := p.service(vs)
+	if err != nil {
+		return err
+	}
+	rsEp, _, err := parseRealServer(rs)
+	if err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if b, ok := svc.backends[rsEp.String()]; ok {
+		b.closeConns()
+		delete(svc.backends, rsEp.String())
+	}
+	return nil
+}
+
+func (p *userspaceProxier) service(vs string) (*userspaceService, error) {
+	ep, err := parseEndpoint(vs)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	svc, ok := p.services[ep]
+	if !ok {
+		return nil, fmt.Errorf("virtual server %s is not found", vs)
+	}
+	return svc, nil
+}
+
+func (p *userspaceProxier) runCheck() {
+	p.mu.Lock()
+	services := make([]*userspaceService, 0, len(p.services))
+	for _, svc := range p.services {
+		services = append(services, svc)
+	}
+	p.mu.Unlock()
+
+	wg := &sync.WaitGroup{}
+	for _, svc := range services {
+		svc.mu.Lock()
+		for _, rs := range svc.backends {
+			wg.Add(1)
+			go p.checkRealServer(wg, svc, rs)
+		}
+		svc.mu.Unlock()
+	}
+	wg.Wait()
+}
+
+func (p *userspaceProxier) checkRealServer(
+	wg *sync.WaitGroup,
+	svc *userspaceService,
+	rs *userspaceBackend,
+) {
+	defer wg.Done()
+	rsAddr := rs.String()
+	healthy := probeBackend(p.prober, p.health, svc.vs, rs.backend)
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	backendConnections.WithLabelValues(svc.vs, rsAddr, "active").Set(float64(len(rs.conns)))
+	if healthy {
+		if !rs.serving {
+			logger.Info("real server %s is healthy, adding it back", rsAddr)
+			rs.serving, rs.removed = true, false
+			backendWeight.WithLabelValues(svc.vs, rsAddr).Set(float64(rs.weight))
+		}
+		return
+	}
+	if rs.serving {
+		svc.drain(rs)
+		return
+	}
+	if rs.removed {
+		return
+	}
+	if !rs.drained(p.health, len(rs.conns), time.Now()) {
+		logger.Debug("waiting for %d connections of real server %s to close", len(rs.conns), rsAddr)
+		return
+	}
+	logger.Info("real server %s is drained, closing its connections", rsAddr)
+	rs.closeConns()
+	rs.removed = true
+	backendWeight.WithLabelValues(svc.vs, rsAddr).Set(-1)
+}
+
+func (s *userspaceService) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				logger.Error("Failed to accept connection of virtual server %s: %v", s.vs, err)
+			}
+			return
+		}
+		go s.proxy(conn)
+	}
+}
+
+// pick returns the next serving real server by weighted round robin.
+func (s *userspaceService) pick() *userspaceBackend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var candidates []*userspaceBackend
+	for _, rs := range sortedUserspaceBackends(s.backends) {
+		if !rs.serving {
+			continue
+		}
+		for i := 0; i < rs.weight; i++ {
+			candidates = append(candidates, rs)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	s.next = (s.next + 1) % len(candidates)
+	return candidates[s.next]
+}
+
+func (s *userspaceService) proxy(conn net.Conn) {
+	defer conn.Close()
+	rs := s.pick()
+	if rs == nil {
+		logger.Warn("no healthy real server of virtual server %s", s.vs)
+		return
+	}
+	upstream, err := net.DialTimeout("tcp", rs.String(), userspaceDialTimeout)
+	if err != nil {
+		logger.Warn("Failed to connect to real server %s: %v", rs.String(), err)
+		s.observeFailure(rs, err)
+		return
+	}
+	defer upstream.Close()
+
+	s.mu.Lock()
+	rs.conns[conn] = upstream
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(rs.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	done := make(chan struct{}, 2)
+	pipe := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		if tcp, ok := dst.(*net.TCPConn); ok {
+			_ = tcp.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go pipe(upstream, conn)
+	go pipe(conn, upstream)
+	<-done
+	<-done
+}
+
+// observeFailure counts a failed connection to rs like a failed probe, so that a real server which
+// refuses connections is drained without waiting for the next check. Successful connections are
+// not counted, they would reset the failures of a real server whose health endpoint fails.
+func (s *userspaceService) observeFailure(rs *userspaceBackend, err error) {
+	rsAddr := rs.String()
+	connectFailures.WithLabelValues(s.vs, rsAddr).Inc()
+	if rs.observe(s.health, err) {
+		return
+	}
+	backendHealthy.WithLabelValues(s.vs, rsAddr).Set(0)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rs.serving {
+		s.drain(rs)
+	}
+}
+
+// drain stops sending new connections to rs, s.mu must be held.
+func (s *userspaceService) drain(rs *userspaceBackend) {
+	logger.Info("real server %s is unhealthy, draining it", rs.String())
+	rs.serving = false
+	rs.startDrain(time.Now())
+	backendWeight.WithLabelValues(s.vs, rs.String()).Set(0)
+}
+
+func (s *userspaceService) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rs := range s.backends {
+		rs.closeConns()
+	}
+}
+
+func (b *userspaceBackend) closeConns() {
+	for conn, upstream := range b.conns {
+		_ = conn.Close()
+		_ = upstream.Close()
+	}
+}
+
+func sortedUserspaceBackends(rsMap map[string]*userspaceBackend) []*userspaceBackend {
+	backends := make([]*userspaceBackend, 0, len(rsMap))
+	for _, rs := range rsMap {
+		backends = append(backends, rs)
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].String() < backends[j].String() })
+	return backends
+}
@@ -0,0 +1,298 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestUserspaceService starts a service on a local port, it is what EnsureVirtualServer does
+// without binding the virtual IP to the dummy interface.
+func newTestUserspaceService(t *testing.T, p *userspaceProxier) *userspaceService {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := parseEndpoint(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := &userspaceService{
+		vs:       ep.String(),
+		listener: listener,
+		health:   p.health,
+		backends: make(map[string]*userspaceBackend),
+	}
+	p.services[ep] = svc
+	go svc.serve()
+	t.Cleanup(svc.close)
+	return svc
+}
+
+// startEchoServer starts a real server which replies to every line with its name.
+func startEchoServer(t *testing.T, name string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					if _, err := io.WriteString(conn, name+"\n"); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestUserspacePick(t *testing.T) {
+	tests := []struct {
+		name     string
+		backends map[string]bool
+		weights  map[string]int
+		want     map[string]int
+	}{
+		{
+			name: "no real server",
+			want: map[string]int{},
+		},
+		{
+			name:     "round robin",
+			backends: map[string]bool{"10.0.0.2:6443": true, "10.0.0.3:6443": true},
+			want:     map[string]int{"10.0.0.2:6443": 1, "10.0.0.3:6443": 1},
+		},
+		{
+			name:     "weighted",
+			backends: map[string]bool{"10.0.0.2:6443": true, "10.0.0.3:6443": true},
+			weights:  map[string]int{"10.0.0.2:6443": 3},
+			want:     map[string]int{"10.0.0.2:6443": 3, "10.0.0.3:6443": 1},
+		},
+		{
+			name:     "real servers not serving are skipped",
+			backends: map[string]bool{"10.0.0.2:6443": false, "10.0.0.3:6443": true},
+			want:     map[string]int{"10.0.0.3:6443": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &userspaceService{backends: make(map[string]*userspaceBackend)}
+			total := 0
+			for addr, serving := range tt.backends {
+				ep, err := parseEndpoint(addr)
+				if err != nil {
+					t.Fatal(err)
+				}
+				weight := defaultWeight
+				if w, ok := tt.weights[addr]; ok {
+					weight = w
+				}
+				svc.backends[addr] = &userspaceBackend{backend: newBackend(ep, weight), serving: serving}
+			}
+			for _, n := range tt.want {
+				total += n
+			}
+			got := map[string]int{}
+			// a full cycle of picks hits every real server as often as its weight
+			for i := 0; i < total; i++ {
+				got[svc.pick().String()]++
+			}
+			if total == 0 && svc.pick() != nil {
+				t.Error("pick() should return nil without serving real servers")
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("pick() = %v, want %v", got, tt.want)
+			}
+			for addr, n := range tt.want {
+				if got[addr] != n {
+					t.Errorf("pick() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserspaceProxy(t *testing.T) {
+	p := NewUserspaceProxier("lvscare", time.Second, &fakeProber{}, HealthConfig{}, nil).(*userspaceProxier)
+	svc := newTestUserspaceService(t, p)
+	if err := p.EnsureRealServer(svc.vs, startEchoServer(t, "a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EnsureRealServer(svc.vs, startEchoServer(t, "b")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]int{}
+	for i := 0; i < 4; i++ {
+		got[request(t, svc.vs)]++
+	}
+	if got["a"] != 2 || got["b"] != 2 {
+		t.Errorf("requests are served by %v, want 2 by each real server", got)
+	}
+}
+
+func TestUserspaceCheckRealServer(t *testing.T) {
+	prober := &fakeProber{}
+	p := NewUserspaceProxier(
+		"lvscare",
+		time.Second,
+		prober,
+		HealthConfig{Rise: 1, Fall: 1, DrainTimeout: time.Hour},
+		nil,
+	).(*userspaceProxier)
+	svc := newTestUserspaceService(t, p)
+	rsAddr := startEchoServer(t, "a")
+	if err := p.EnsureRealServer(svc.vs, rsAddr); err != nil {
+		t.Fatal(err)
+	}
+	rs := svc.backends[rsAddr]
+
+	conn, err := net.Dial("tcp", svc.vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	if _, err := io.WriteString(conn, "ping\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func() {
+		wg := &sync.WaitGroup{}
+		wg.Add(1)
+		p.checkRealServer(wg, svc, rs)
+	}
+	state := func() (serving, removed bool, conns int) {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		return rs.serving, rs.removed, len(rs.conns)
+	}
+
+	prober.set(rsAddr, true)
+	check()
+	if serving, removed, conns := state(); serving || removed || conns != 1 {
+		t.Fatalf("after the first failure serving=%v removed=%v conns=%d, want draining with 1 connection",
+			serving, removed, conns)
+	}
+	check()
+	if _, removed, _ := state(); removed {
+		t.Fatal("real server with open connections is removed before the drain timeout")
+	}
+	if svc.pick() != nil {
+		t.Error("draining real server still receives new connections")
+	}
+
+	// the drain timeout passes, the open connection is closed
+	rs.startDrain(time.Now().Add(-2 * time.Hour))
+	check()
+	if _, removed, _ := state(); !removed {
+		t.Fatal("real server is not removed after the drain timeout")
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Error("connection of the removed real server is still open")
+	}
+
+	prober.set(rsAddr, false)
+	check()
+	if serving, removed, _ := state(); !serving || removed {
+		t.Errorf("recovered real server serving=%v removed=%v, want serving", serving, removed)
+	}
+}
+
+func TestUserspacePassiveHealth(t *testing.T) {
+	p := NewUserspaceProxier(
+		"lvscare",
+		time.Second,
+		&fakeProber{},
+		HealthConfig{Rise: 1, Fall: 2, DrainTimeout: time.Hour},
+		nil,
+	).(*userspaceProxier)
+	svc := newTestUserspaceService(t, p)
+	// a real server which refuses connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsAddr := listener.Addr().String()
+	_ = listener.Close()
+	if err := p.EnsureRealServer(svc.vs, rsAddr); err != nil {
+		t.Fatal(err)
+	}
+	rs := svc.backends[rsAddr]
+	serving := func() bool {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		return rs.serving
+	}
+
+	for i, want := range []bool{true, false} {
+		conn, err := net.Dial("tcp", svc.vs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		// the proxy closes the connection once it fails to connect to the real server
+		if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+			t.Fatalf("read from the proxy = %v, want EOF", err)
+		}
+		_ = conn.Close()
+		if got := serving(); got != want {
+			t.Fatalf("after %d failed connections serving = %v, want %v", i+1, got, want)
+		}
+	}
+	if svc.pick() != nil {
+		t.Error("real server refusing connections still receives new connections")
+	}
+}
+
+func request(t *testing.T, addr string) string {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := io.WriteString(conn, "ping\n"); err != nil {
+		t.Fatal(err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reply[:len(reply)-1]
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/labring/sealos/controllers/pkg/resources"
 	"github.com/labring/sealos/service/account/dao"
 	"github.com/labring/sealos/service/account/helper"
 	"github.com/sirupsen/logrus"
@@ -378,3 +379,37 @@ func AdminListRegions(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, result)
 }
+
+// AdminGetNodeUsage returns the latest capacity, allocated and billed resources of the nodes.
+// @Summary Get node usage for admin
+// @Tags AdminRead
+// @Produce json
+// @Param at query string false "RFC3339 report time, defaults to now"
+// @Param node query string false "Node name"
+// @Success 200 {object} helper.AdminNodeUsageResp
+// @Router /admin/v1alpha1/node-usage [get]
+func AdminGetNodeUsage(c *gin.Context) {
+	if err := authenticateAdminRequest(c); err != nil {
+		adminReadUnauthorized(c, err)
+		return
+	}
+	at, err := adminReadTime(c, "at")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helper.ErrorMessage{Error: err.Error()})
+		return
+	}
+	if at == nil {
+		now := time.Now().UTC()
+		at = &now
+	}
+	nodes, err := dao.DBClient.GetLatestNodeUsage(*at, c.Query("node"))
+	if err != nil {
+		adminReadFailure(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, helper.AdminNodeUsageResp{
+		Time:    *at,
+		Summary: resources.SummarizeNodeUsage(nodes),
+		Nodes:   nodes,
+	})
+}
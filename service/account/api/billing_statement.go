@@ -0,0 +1,375 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+	usernotify "github.com/labring/sealos/controllers/pkg/user_notify"
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// billingStatementDelay is how long after the end of a month its statement is sent, so the
+// billings of the last hour of the month are settled.
+const billingStatementDelay = 2 * time.Hour
+
+// buildBillingStatement sums the costs of the owner in the local region within
+// [startTime, endTime). The credits are per user, they are only applied to the statement of all
+// workspaces.
+func buildBillingStatement(
+	owner string,
+	userUID uuid.UUID,
+	namespace string,
+	startTime, endTime time.Time,
+) (*helper.BillingStatement, error) {
+	items, err := dao.DBClient.GetBillingStatementItems(owner, namespace, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	region := dao.DBClient.GetLocalRegion()
+	statement := &helper.BillingStatement{
+		RegionDomain: region.Domain,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		Items:        items,
+	}
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		statement.TotalAmount += item.Amount
+		if !seen[item.Namespace] {
+			seen[item.Namespace] = true
+			namespaces = append(namespaces, item.Namespace)
+		}
+	}
+	if len(namespaces) > 0 {
+		workspaces, err := dao.DBClient.GetWorkspaceName(namespaces)
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[string]string, len(workspaces))
+		for _, workspace := range workspaces {
+			names[workspace[0]] = workspace[1]
+		}
+		for i := range statement.Items {
+			statement.Items[i].Workspace = names[statement.Items[i].Namespace]
+		}
+	}
+	if namespace == "" && userUID != uuid.Nil {
+		credits, err := dao.DBClient.GetUsedCredits(userUID, region.UID, startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+		statement.CreditsAmount = min(credits, statement.TotalAmount)
+	}
+	statement.NetAmount = statement.TotalAmount - statement.CreditsAmount
+	return statement, nil
+}
+
+// ExportBillingStatement
+// @Summary Export billing statement
+// @Description Export the costs of the user in the current region within the time range as a CSV or XLSX file, with a row per workspace, app and property followed by the totals and the credits applied
+// @Tags BillingStatement
+// @Accept json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param request body helper.ExportBillingStatementReq true "Export billing statement request"
+// @Success 200 {file} file "billing statement"
+// @Failure 400 {object} helper.ErrorMessage "failed to parse request"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to export billing statement"
+// @Router /account/v1alpha1/billing-statement/export [post]
+func ExportBillingStatement(c *gin.Context) {
+	req, err := helper.ParseExportBillingStatementReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateRequest(c, req); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	statement, err := buildBillingStatement(
+		req.Owner,
+		req.UserUID,
+		req.Namespace,
+		req.StartTime,
+		req.EndTime,
+	)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to export billing statement: %v", err)},
+		)
+		return
+	}
+	var buf bytes.Buffer
+	contentType, err := writeBillingStatement(&buf, statement, req.Format)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to export billing statement: %v", err)},
+		)
+		return
+	}
+	period := req.StartTime.UTC().Format(time.DateOnly) + "_" +
+		req.EndTime.UTC().Format(time.DateOnly)
+	fileName := billingStatementFileName(statement.RegionDomain, period, req.Format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func billingStatementFileName(
+	regionDomain, period string,
+	format types.BillingStatementFormat,
+) string {
+	return fmt.Sprintf("billing-statement-%s-%s.%s", regionDomain, period, format)
+}
+
+// GetBillingStatementSubscription
+// @Summary Get billing statement subscription
+// @Description Get the monthly billing statement subscription of the user in the current region
+// @Tags BillingStatement
+// @Produce json
+// @Success 200 {object} map[string]interface{} "successfully get billing statement subscription"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to get billing statement subscription"
+// @Router /account/v1alpha1/billing-statement/subscription/get [post]
+func GetBillingStatementSubscription(c *gin.Context) {
+	auth, err := ParseAuthTokenUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	sub, err := dao.DBClient.GetBillingStatementSubscription(
+		auth.UserUID,
+		dao.DBClient.GetLocalRegion().Domain,
+	)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{
+				Error: fmt.Sprintf("failed to get billing statement subscription: %v", err),
+			},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"subscription": sub,
+	})
+}
+
+// SetBillingStatementSubscription
+// @Summary Set billing statement subscription
+// @Description Opt in to or out of the monthly billing statement of the current region, the statement of a month is emailed to the email bound to the account at the beginning of the next month
+// @Tags BillingStatement
+// @Accept json
+// @Produce json
+// @Param request body helper.SetBillingStatementSubscriptionReq true "Set billing statement subscription request"
+// @Success 200 {object} map[string]interface{} "successfully set billing statement subscription"
+// @Failure 400 {object} helper.ErrorMessage "failed to parse request"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to set billing statement subscription"
+// @Router /account/v1alpha1/billing-statement/subscription/set [post]
+func SetBillingStatementSubscription(c *gin.Context) {
+	req, err := helper.ParseSetBillingStatementSubscriptionReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateRequest(c, req); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	email, err := billingStatementEmail(req.UserUID, req.Email)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("invalid email: %v", err)},
+		)
+		return
+	}
+	sub := &types.BillingStatementSubscription{
+		UserUID:      req.UserUID,
+		RegionDomain: dao.DBClient.GetLocalRegion().Domain,
+		Email:        email,
+		Format:       req.Format,
+		IsEnabled:    *req.IsEnabled,
+	}
+	if err := dao.DBClient.SetBillingStatementSubscription(sub); err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{
+				Error: fmt.Sprintf("failed to set billing statement subscription: %v", err),
+			},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// billingStatementEmail returns the email the statements of the user are sent to. The statements
+// carry financial data, so only the email bound to the account is accepted, it is used if email
+// is empty.
+func billingStatementEmail(userUID uuid.UUID, email string) (string, error) {
+	nr, err := dao.DBClient.GetNotificationRecipient(userUID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the email of the user: %w", err)
+	}
+	if nr == nil || nr.Email == "" {
+		return "", errors.New("no email is bound to the account")
+	}
+	if email != "" && !strings.EqualFold(email, nr.Email) {
+		return "", errors.New("only the email bound to the account is accepted")
+	}
+	return nr.Email, nil
+}
+
+// BillingStatementSender emails the statement of the previous month to the users subscribed in
+// the local region.
+type BillingStatementSender struct {
+	interval time.Duration
+}
+
+func NewBillingStatementSender(interval time.Duration) *BillingStatementSender {
+	return &BillingStatementSender{interval: interval}
+}
+
+// Start checks for the due statements every interval until ctx is done.
+func (s *BillingStatementSender) Start(ctx context.Context) {
+	logrus.Infof("Starting billing statement sender, interval: %s", s.interval)
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logrus.Info("Stopping billing statement sender")
+				return
+			case now := <-ticker.C:
+				s.send(ctx, now)
+			}
+		}
+	}()
+}
+
+func (s *BillingStatementSender) send(ctx context.Context, now time.Time) {
+	if dao.UserContactProvider == nil || dao.UserNotificationService == nil {
+		return
+	}
+	period := types.PreviousBillingStatementPeriod(now.Add(-billingStatementDelay))
+	subs, err := dao.DBClient.ListDueBillingStatementSubscriptions(
+		dao.DBClient.GetLocalRegion().Domain,
+		period,
+	)
+	if err != nil {
+		logrus.Errorf("Failed to list billing statement subscriptions: %v", err)
+		return
+	}
+	for i := range subs {
+		if err := sendBillingStatement(ctx, &subs[i], period); err != nil {
+			// the statement is sent again at the next check
+			logrus.Errorf(
+				"Failed to send billing statement %s of user %s: %v",
+				period, subs[i].UserUID, err,
+			)
+			continue
+		}
+		if err := dao.DBClient.SetBillingStatementSubscriptionPeriod(
+			subs[i].ID,
+			period,
+		); err != nil {
+			logrus.Errorf(
+				"Failed to update billing statement subscription %s: %v",
+				subs[i].ID, err,
+			)
+		}
+	}
+}
+
+func sendBillingStatement(
+	ctx context.Context,
+	sub *types.BillingStatementSubscription,
+	period string,
+) error {
+	startTime, endTime, err := types.ParseBillingStatementPeriod(period)
+	if err != nil {
+		return err
+	}
+	owner, err := dao.DBClient.GetUserCrName(types.UserQueryOpts{UID: sub.UserUID})
+	if err != nil {
+		return fmt.Errorf("failed to get user cr name: %w", err)
+	}
+	statement, err := buildBillingStatement(owner, sub.UserUID, "", startTime, endTime)
+	if err != nil {
+		return fmt.Errorf("failed to build billing statement: %w", err)
+	}
+	var buf bytes.Buffer
+	if _, err := writeBillingStatement(&buf, statement, sub.Format); err != nil {
+		return fmt.Errorf("failed to write billing statement: %w", err)
+	}
+
+	// the statement goes to the email bound to the account now, it may have changed since the
+	// subscription was set
+	nr, err := dao.DBClient.GetNotificationRecipient(sub.UserUID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification recipient: %w", err)
+	}
+	if nr == nil || nr.Email == "" {
+		return errors.New("no email is bound to the account")
+	}
+	recipient := &types.NotificationRecipient{
+		UserUID:  sub.UserUID,
+		UserName: nr.UserName,
+		Email:    nr.Email,
+	}
+	dao.UserContactProvider.SetUserContact(sub.UserUID, recipient)
+	defer dao.UserContactProvider.RemoveUserContact(sub.UserUID)
+	eventData := &usernotify.BillingStatementEventData{
+		Type:          usernotify.EventTypeBillingStatement,
+		RegionDomain:  statement.RegionDomain,
+		Period:        period,
+		Format:        string(sub.Format),
+		TotalAmount:   billingAmount(statement.TotalAmount).String(),
+		CreditsAmount: billingAmount(statement.CreditsAmount).String(),
+		NetAmount:     billingAmount(statement.NetAmount).String(),
+	}
+	_, err = dao.UserNotificationService.SendEventNotification(ctx, &usernotify.NotificationEvent{
+		UserUID:   sub.UserUID,
+		EventType: eventData.GetType(),
+		EventData: eventData.ToMap(),
+		Methods:   []usernotify.NotificationMethod{usernotify.NotificationMethodEmail},
+		Attachments: []usernotify.NotificationAttachment{{
+			Name:    billingStatementFileName(statement.RegionDomain, period, sub.Format),
+			Content: buf.Bytes(),
+		}},
+	})
+	return err
+}
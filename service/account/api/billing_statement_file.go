@@ -0,0 +1,209 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/service/account/helper"
+)
+
+// billingAmountUnit is the billing amount of one currency unit.
+const billingAmountUnit = 1_000_000
+
+// billingAmount is a cell holding a billing amount, it is written in the currency unit.
+type billingAmount int64
+
+func (a billingAmount) String() string {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%06d", v%billingAmountUnit), "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, v/billingAmountUnit, frac)
+}
+
+var billingStatementHeader = []string{
+	"Workspace", "Workspace Name", "App Type", "App Name", "Property", "Used", "Unit", "Amount",
+}
+
+// billingStatementRows returns the rows of the statement below the header, the cells are strings,
+// int64 usages, billing amounts or nil for blank cells. The items are followed by the total of
+// every workspace and the totals of the statement.
+func billingStatementRows(statement *helper.BillingStatement) [][]any {
+	rows := make([][]any, 0, len(statement.Items)+8)
+	var (
+		workspaceTotals []helper.BillingStatementItem
+		last            *helper.BillingStatementItem
+	)
+	for _, item := range statement.Items {
+		rows = append(rows, []any{
+			item.Namespace, item.Workspace, item.AppType, item.AppName, item.Property,
+			item.Used, item.Unit, billingAmount(item.Amount),
+		})
+		if last == nil || last.Namespace != item.Namespace {
+			workspaceTotals = append(workspaceTotals, helper.BillingStatementItem{
+				Namespace: item.Namespace,
+				Workspace: item.Workspace,
+			})
+			last = &workspaceTotals[len(workspaceTotals)-1]
+		}
+		last.Amount += item.Amount
+	}
+	// a blank row between the items and the totals
+	rows = append(rows, make([]any, len(billingStatementHeader)))
+	for _, total := range workspaceTotals {
+		rows = append(rows, []any{
+			total.Namespace, total.Workspace, "", "", "Workspace Total", "", "",
+			billingAmount(total.Amount),
+		})
+	}
+	for _, total := range []struct {
+		name   string
+		amount int64
+	}{
+		{"Total", statement.TotalAmount},
+		{"Credits Applied", -statement.CreditsAmount},
+		{"Net Amount", statement.NetAmount},
+	} {
+		rows = append(rows, []any{"", "", "", "", total.name, "", "", billingAmount(total.amount)})
+	}
+	return rows
+}
+
+// writeBillingStatement writes the statement to w in format and returns the content type.
+func writeBillingStatement(
+	w io.Writer,
+	statement *helper.BillingStatement,
+	format types.BillingStatementFormat,
+) (string, error) {
+	rows := billingStatementRows(statement)
+	switch format {
+	case types.BillingStatementFormatCSV:
+		return "text/csv", writeBillingStatementCSV(w, rows)
+	case types.BillingStatementFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			writeBillingStatementXLSX(w, rows)
+	}
+	return "", fmt.Errorf("unsupported billing statement format: %s", format)
+}
+
+func writeBillingStatementCSV(w io.Writer, rows [][]any) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(billingStatementHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case string:
+				record[i] = v
+			case int64:
+				record[i] = strconv.FormatInt(v, 10)
+			case billingAmount:
+				record[i] = v.String()
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// The parts of a workbook with a single sheet, the sheet holds inline strings so the workbook
+// needs neither a shared string table nor styles.
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Statement" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+)
+
+func writeBillingStatementXLSX(w io.Writer, rows [][]any) error {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`,
+	)
+	header := make([]any, len(billingStatementHeader))
+	for i := range billingStatementHeader {
+		header[i] = billingStatementHeader[i]
+	}
+	for r, row := range append([][]any{header}, rows...) {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			switch v := cell.(type) {
+			case string:
+				if v == "" {
+					continue
+				}
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				if err := xml.EscapeText(&sheet, []byte(v)); err != nil {
+					return err
+				}
+				sheet.WriteString(`</t></is></c>`)
+			case int64:
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%d</v></c>`, ref, v)
+			case billingAmount:
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, v.String())
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	zw := zip.NewWriter(w)
+	for _, part := range []struct {
+		name    string
+		content []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRels)},
+		{"xl/workbook.xml", []byte(xlsxWorkbook)},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/worksheets/sheet1.xml", sheet.Bytes()},
+	} {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(part.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xlsxColumn returns the column name of the zero based index, like A, Z and AA.
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/service/account/helper"
+)
+
+func newTestBillingStatement() *helper.BillingStatement {
+	return &helper.BillingStatement{
+		RegionDomain: "test.sealos.io",
+		Items: []helper.BillingStatementItem{
+			{
+				Namespace: "ns-a", Workspace: "team", AppType: "app", AppName: "web",
+				Property: "cpu", Used: 2000, Unit: "1m", Amount: 1_500_000,
+			},
+			{
+				Namespace: "ns-a", Workspace: "team", AppType: "app", AppName: "web",
+				Property: "memory", Used: 1024, Unit: "1Mi", Amount: 250_000,
+			},
+			{
+				Namespace: "ns-b", Workspace: "a, \"quoted\" name", AppType: "db",
+				AppName: "pg", Property: "storage", Used: 10, Unit: "1Gi", Amount: 3_000_000,
+			},
+		},
+		TotalAmount:   4_750_000,
+		CreditsAmount: 1_000_000,
+		NetAmount:     3_750_000,
+	}
+}
+
+func TestBillingAmountString(t *testing.T) {
+	for amount, want := range map[int64]string{
+		0:          "0.00",
+		1_500_000:  "1.50",
+		1_234_567:  "1.234567",
+		10:         "0.00001",
+		-2_000_000: "-2.00",
+	} {
+		if got := billingAmount(amount).String(); got != want {
+			t.Errorf("billingAmount(%d) = %s, want %s", amount, got, want)
+		}
+	}
+}
+
+func TestWriteBillingStatementCSV(t *testing.T) {
+	var buf bytes.Buffer
+	contentType, err := writeBillingStatement(
+		&buf,
+		newTestBillingStatement(),
+		types.BillingStatementFormatCSV,
+	)
+	if err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	if contentType != "text/csv" {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	// header, 3 items, a blank row, 2 workspace totals and 3 statement totals
+	if len(records) != 10 {
+		t.Fatalf("expected 10 records, got %d: %v", len(records), records)
+	}
+	if got := strings.Join(records[1], ","); got != "ns-a,team,app,web,cpu,2000,1m,1.50" {
+		t.Errorf("unexpected item record: %s", got)
+	}
+	if records[3][1] != `a, "quoted" name` {
+		t.Errorf("workspace name is not escaped: %q", records[3][1])
+	}
+	for i, want := range map[int][]string{
+		5: {"ns-a", "Workspace Total", "1.75"},
+		6: {"ns-b", "Workspace Total", "3.00"},
+		7: {"", "Total", "4.75"},
+		8: {"", "Credits Applied", "-1.00"},
+		9: {"", "Net Amount", "3.75"},
+	} {
+		got := []string{records[i][0], records[i][4], records[i][7]}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("record %d = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestWriteBillingStatementXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := writeBillingStatement(
+		&buf,
+		newTestBillingStatement(),
+		types.BillingStatementFormatXLSX,
+	); err != nil {
+		t.Fatalf("write xlsx: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(content)
+	}
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels",
+	} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" t="inlineStr"><is><t xml:space="preserve">Workspace</t></is></c>`,
+		`<c r="F2"><v>2000</v></c>`,
+		`<c r="H2"><v>1.50</v></c>`,
+		`a, &#34;quoted&#34; name`,
+		`<c r="H10"><v>3.75</v></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet does not contain %s", want)
+		}
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 7: "H", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
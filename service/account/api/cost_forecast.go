@@ -0,0 +1,62 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labring/sealos/controllers/pkg/resources"
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+)
+
+// GetCostForecast
+// @Summary Get cost forecast
+// @Description Project the daily and monthly cost of the workspaces owned by the user from their latest resource usage, and estimate when the balance including active credits runs out
+// @Tags CostForecast
+// @Accept json
+// @Produce json
+// @Param request body helper.AuthBase true "auth request"
+// @Success 200 {object} helper.CostForecastResp "successfully get cost forecast"
+// @Failure 401 {object} map[string]interface{} "authenticate error"
+// @Failure 500 {object} map[string]interface{} "failed to get cost forecast"
+// @Router /account/v1alpha1/cost-forecast [post]
+func GetCostForecast(c *gin.Context) {
+	req := &helper.AuthBase{}
+	if err := authenticateRequest(c, req); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	nsList, err := getOwnNsListWithClt(dao.K8sManager.GetClient(), req.Owner)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to get own namespace list: %v", err)},
+		)
+		return
+	}
+	now := time.Now()
+	monitors, err := dao.DBClient.GetLatestMonitors(nsList, now)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to get latest monitors: %v", err)},
+		)
+		return
+	}
+	account, err := dao.DBClient.GetAccountWithCredits(req.UserUID)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to get account: %v", err)},
+		)
+		return
+	}
+	forecast := resources.NewCostForecast(monitors, resources.DefaultPropertyTypeLS)
+	usableBalance := account.Balance - account.DeductionBalance + account.UsableCredits
+	c.JSON(http.StatusOK, helper.NewCostForecastResp(forecast, usableBalance, now))
+}
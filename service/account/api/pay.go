@@ -10,6 +10,7 @@ import (
 	responsePay "github.com/alipay/global-open-sdk-go/com/alipay/api/response/pay"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/pay"
 	"github.com/labring/sealos/controllers/pkg/types"
 	"github.com/labring/sealos/controllers/pkg/utils"
 	"github.com/labring/sealos/service/account/dao"
@@ -240,9 +241,66 @@ func CreateCardPay(c *gin.Context) {
 		}
 		return
 	}
+	if string(req.Method) == pay.MethodSandbox && dao.SandboxPayment != nil {
+		createSandboxPay(c, req)
+		return
+	}
 	SetErrorResp(c, http.StatusBadGateway, gin.H{"error": "unsupported payment method"})
 }
 
+// createSandboxPay creates a payment of the sandbox, it is paid through the returned url and
+// notified to NewPayNotifyHandler like a card payment.
+func createSandboxPay(c *gin.Context, req *helper.CreatePayReq) {
+	tradeNo, codeURL, err := dao.SandboxPayment.CreatePayment(req.Amount, req.UserUID.String(), "")
+	if err != nil {
+		SetErrorResp(
+			c,
+			http.StatusConflict,
+			gin.H{"error": fmt.Sprint("failed to create payment: ", err)},
+		)
+		return
+	}
+	paymentID, err := gonanoid.New(12)
+	if err != nil {
+		SetErrorResp(
+			c,
+			http.StatusInternalServerError,
+			gin.H{"error": fmt.Sprint("failed to create payment id: ", err)},
+		)
+		return
+	}
+	err = dao.DBClient.GetGlobalDB().Model(&types.PaymentOrder{}).Create(&types.PaymentOrder{
+		ID: paymentID,
+		PaymentRaw: types.PaymentRaw{
+			UserUID:      req.UserUID,
+			Amount:       req.Amount,
+			Method:       req.Method,
+			RegionUID:    dao.DBClient.GetLocalRegion().UID,
+			TradeNO:      tradeNo,
+			CodeURL:      codeURL,
+			CreatedAt:    time.Now().UTC(),
+			Type:         types.PaymentTypeAccountRecharge,
+			ChargeSource: types.ChargeSourceNewCard,
+		},
+		Status: types.PaymentOrderStatusPending,
+	}).Error
+	if err != nil {
+		if expireErr := dao.SandboxPayment.ExpireSession(tradeNo); expireErr != nil {
+			logrus.Errorf("Failed to expire sandbox payment %s: %v", tradeNo, expireErr)
+		}
+		SetErrorResp(
+			c,
+			http.StatusConflict,
+			gin.H{"error": fmt.Sprint("failed to create payment order: ", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"redirectUrl": codeURL,
+		"success":     true,
+	})
+}
+
 type requestInfoStruct struct {
 	Path         string
 	Method       string
@@ -268,6 +326,10 @@ func NewPayNotifyHandler(c *gin.Context) {
 		sendError(c, http.StatusBadRequest, "failed to get raw data", err)
 		return
 	}
+	if requestInfo.ClientID == pay.SandboxClientID {
+		newSandboxPayNotifyHandler(c, requestInfo.Body)
+		return
+	}
 
 	if ok, err := dao.PaymentService.CheckRspSign(
 		requestInfo.Path,
@@ -295,30 +357,14 @@ func NewPayNotifyHandler(c *gin.Context) {
 		return
 	}
 
-	var notification types.CaptureNotification
-
-	if err := json.Unmarshal(requestInfo.Body, &notification); err != nil {
-		logrus.Errorf("Failed to unmarshal notification: %v", err)
-		sendError(c, http.StatusBadRequest, "failed to unmarshal notification", err)
+	notification, ok := parsePaymentNotification(c, requestInfo.Body)
+	if !ok {
 		return
 	}
 	notifyType := notification.NotifyType
 	notifyResult := notification.Result
 	paymentRequestID := notification.CaptureRequestID
 	paymentID := notification.PaymentID
-	if notification.NotifyType == types.NotifyTypePaymentResult {
-		var paymentNotification types.PaymentNotification
-		if err := json.Unmarshal(requestInfo.Body, &paymentNotification); err != nil {
-			logrus.Errorf("Failed to unmarshal payment notification: %v", err)
-			sendError(c, http.StatusBadRequest, "failed to unmarshal payment notification", err)
-			return
-		}
-		notifyResult = paymentNotification.Result
-		paymentRequestID = paymentNotification.PaymentRequestID
-		paymentID = paymentNotification.PaymentID
-	}
-
-	logNotification(notification)
 
 	if err := processPaymentResult(
 		c,
@@ -334,6 +380,72 @@ func NewPayNotifyHandler(c *gin.Context) {
 	sendSuccessResponse(c)
 }
 
+// parsePaymentNotification reads a capture or payment notification, the request id and the result
+// of a payment notification are set to the capture fields.
+func parsePaymentNotification(c *gin.Context, body []byte) (types.CaptureNotification, bool) {
+	var notification types.CaptureNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		logrus.Errorf("Failed to unmarshal notification: %v", err)
+		sendError(c, http.StatusBadRequest, "failed to unmarshal notification", err)
+		return notification, false
+	}
+	if notification.NotifyType == types.NotifyTypePaymentResult {
+		var paymentNotification types.PaymentNotification
+		if err := json.Unmarshal(body, &paymentNotification); err != nil {
+			logrus.Errorf("Failed to unmarshal payment notification: %v", err)
+			sendError(c, http.StatusBadRequest, "failed to unmarshal payment notification", err)
+			return notification, false
+		}
+		notification.Result = paymentNotification.Result
+		notification.CaptureRequestID = paymentNotification.PaymentRequestID
+		notification.PaymentID = paymentNotification.PaymentID
+	}
+	logNotification(notification)
+	return notification, true
+}
+
+// newSandboxPayNotifyHandler handles a notification of the sandbox payment, it carries no
+// signature, the result is trusted only if the registered sandbox has the payment in that state.
+func newSandboxPayNotifyHandler(c *gin.Context, body []byte) {
+	if dao.SandboxPayment == nil {
+		sendError(c, http.StatusUnauthorized, "sandbox payment is not enabled", nil)
+		return
+	}
+	notification, ok := parsePaymentNotification(c, body)
+	if !ok {
+		return
+	}
+	tradeNo := notification.CaptureRequestID
+	status, _, err := dao.SandboxPayment.GetPaymentDetails(tradeNo)
+	if err != nil {
+		sendError(c, http.StatusBadRequest, "failed to get sandbox payment", err)
+		return
+	}
+	switch {
+	case notification.NotifyType == types.NotifyTypeCaptureResult &&
+		notification.Result.ResultStatus == "S" && status == pay.PaymentSuccess:
+		err = newCardPaymentHandler(tradeNo, types.CardInfo{})
+	case notification.NotifyType == types.NotifyTypePaymentResult &&
+		notification.Result.ResultCode == types.OrderClosedResultCode &&
+		(status == pay.PaymentFailed || status == pay.PaymentExpired):
+		err = newCardPaymentFailureHandler(tradeNo)
+	default:
+		sendError(
+			c,
+			http.StatusBadRequest,
+			fmt.Sprintf("notification does not match the sandbox payment %s in status %s", tradeNo, status),
+			nil,
+		)
+		return
+	}
+	if err != nil && !errors.Is(err, dao.ErrPaymentOrderAlreadyHandle) {
+		logrus.Errorf("Failed to process sandbox payment result: %v", err)
+		sendError(c, http.StatusInternalServerError, "failed to process payment result", err)
+		return
+	}
+	sendSuccessResponse(c)
+}
+
 func sendError(c *gin.Context, status int, message string, err error) {
 	if err != nil {
 		message = fmt.Sprintf("%s: %v", message, err)
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labring/sealos/controllers/pkg/pay"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/service/account/dao"
+)
+
+func TestSandboxPayNotifyRejectsUnmatchedNotification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	original := dao.SandboxPayment
+	t.Cleanup(func() { dao.SandboxPayment = original })
+
+	sandbox := pay.NewSandboxPayment()
+	unpaid, _, err := sandbox.CreatePayment(1_000_000, "user-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := sandbox.CreatePayment(1_000_000, "user-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sandbox.ExpireSession(expired); err != nil {
+		t.Fatal(err)
+	}
+	captured := func(tradeNo string) any {
+		return types.CaptureNotification{
+			Result:           types.Result{ResultCode: SuccessStatus, ResultStatus: "S"},
+			NotifyType:       types.NotifyTypeCaptureResult,
+			CaptureRequestID: tradeNo,
+			PaymentID:        tradeNo,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		sandbox      *pay.SandboxPayment
+		notification any
+		wantStatus   int
+	}{
+		{
+			name:         "sandbox is not enabled",
+			notification: captured(unpaid),
+			wantStatus:   http.StatusUnauthorized,
+		},
+		{
+			name:         "unknown payment",
+			sandbox:      sandbox,
+			notification: captured("sandbox_unknown"),
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "captured notification of an unpaid payment",
+			sandbox:      sandbox,
+			notification: captured(unpaid),
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "captured notification of an expired payment",
+			sandbox:      sandbox,
+			notification: captured(expired),
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:    "closed notification of an unpaid payment",
+			sandbox: sandbox,
+			notification: types.PaymentNotification{
+				NotifyType:       types.NotifyTypePaymentResult,
+				Result:           types.Result{ResultCode: types.OrderClosedResultCode, ResultStatus: "F"},
+				PaymentRequestID: unpaid,
+				PaymentID:        unpaid,
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dao.SandboxPayment = tt.sandbox
+			body, err := json.Marshal(tt.notification)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequestWithContext(
+				context.Background(), http.MethodPost, "/payment/v1alpha1/notify", bytes.NewReader(body),
+			)
+			req.Header.Set("client-id", pay.SandboxClientID)
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = req
+
+			NewPayNotifyHandler(c)
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+		})
+	}
+}
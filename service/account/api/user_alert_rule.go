@@ -0,0 +1,315 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+	"github.com/labring/sealos/service/pkg/api"
+	"github.com/labring/sealos/service/pkg/request"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// alertDatabaseTemplates are the database templates a rule can use by database type, the minio
+// templates are left out as they select the buckets of the object storage but not a namespace.
+var alertDatabaseTemplates = map[string]map[string]string{
+	"apecloud-mysql": api.Mysql,
+	"postgresql":     api.Pgsql,
+	"mongodb":        api.Mongo,
+	"redis":          api.Redis,
+	"kafka":          api.Kafka,
+	"milvus":         api.Milvus,
+}
+
+// alertLaunchpadTemplates are the launchpad templates a rule can use, the others need a pvc or a
+// service port besides the app.
+var alertLaunchpadTemplates = []string{
+	"cpu", "memory", "average_cpu", "average_memory", "gpu", "gpu_memory",
+}
+
+// userAlertRuleQuery returns the PromQL of the rule with the namespace and the resource filled in.
+func userAlertRuleQuery(rule *types.UserAlertRule) (string, error) {
+	// the names are put into label matchers, only plain names keep the query in the namespace
+	if errs := validation.IsDNS1123Label(rule.Namespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid namespace %q: %s", rule.Namespace, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Label(rule.Resource); len(errs) > 0 {
+		return "", fmt.Errorf("invalid resource %q: %s", rule.Resource, strings.Join(errs, ", "))
+	}
+	switch rule.Source {
+	case types.UserAlertRuleSourceDatabase:
+		templates, ok := alertDatabaseTemplates[rule.DatabaseType]
+		if !ok {
+			return "", fmt.Errorf("unsupported database type: %s", rule.DatabaseType)
+		}
+		if _, ok := templates[rule.Template]; !ok {
+			return "", fmt.Errorf(
+				"unsupported template %s of database type %s",
+				rule.Template,
+				rule.DatabaseType,
+			)
+		}
+		return request.GetQuery(&api.PromRequest{
+			Type:    rule.DatabaseType,
+			Query:   rule.Template,
+			NS:      rule.Namespace,
+			Cluster: rule.Resource,
+		})
+	case types.UserAlertRuleSourceLaunchpad:
+		if !slices.Contains(alertLaunchpadTemplates, rule.Template) {
+			return "", fmt.Errorf("unsupported launchpad template: %s", rule.Template)
+		}
+		// the pods of an app are named after the app with a suffix
+		return strings.NewReplacer(
+			"$namespace", rule.Namespace,
+			"$pod", rule.Resource+"-",
+		).Replace(api.Launchpad[rule.Template]), nil
+	}
+	return "", fmt.Errorf("unsupported source: %s", rule.Source)
+}
+
+// GetUserAlertRuleTemplates
+// @Summary Get user alert rule templates
+// @Description Get the metric templates an alert rule can use
+// @Tags UserAlertRule
+// @Produce json
+// @Success 200 {object} helper.UserAlertRuleTemplatesResp "successfully get templates"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Router /account/v1alpha1/user-alert-rule/templates [post]
+func GetUserAlertRuleTemplates(c *gin.Context) {
+	if _, err := ParseAuthTokenUser(c); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	resp := helper.UserAlertRuleTemplatesResp{
+		Database:  make(map[string][]string, len(alertDatabaseTemplates)),
+		Launchpad: alertLaunchpadTemplates,
+	}
+	for dbType, templates := range alertDatabaseTemplates {
+		keys := make([]string, 0, len(templates))
+		for key := range templates {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		resp.Database[dbType] = keys
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateUserAlertRule
+// @Summary Create user alert rule
+// @Description Create an alert rule on a metric of a database or launchpad app in the workspace, the notifications go to the alert notification accounts of the user
+// @Tags UserAlertRule
+// @Accept json
+// @Produce json
+// @Param request body helper.CreateUserAlertRuleReq true "Create user alert rule request"
+// @Success 200 {object} map[string]interface{} "successfully create user alert rule"
+// @Failure 400 {object} helper.ErrorMessage "failed to parse request"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to create user alert rule"
+// @Router /account/v1alpha1/user-alert-rule/create [post]
+func CreateUserAlertRule(c *gin.Context) {
+	req, err := helper.ParseCreateUserAlertRuleReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateWorkspaceRequest(c, &req.WorkspaceInfoReq); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	rule := &types.UserAlertRule{
+		UserUID:      req.UserUID,
+		RegionDomain: dao.DBClient.GetLocalRegion().Domain,
+		Namespace:    req.Workspace,
+		Name:         req.Name,
+		Source:       req.Source,
+		DatabaseType: req.DatabaseType,
+		Resource:     req.Resource,
+		Template:     req.Template,
+		Comparator:   req.Comparator,
+		Threshold:    *req.Threshold,
+		Duration:     req.Duration,
+		Severity:     req.Severity,
+		IsEnabled:    true,
+	}
+	if err := rule.Validate(); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("invalid user alert rule: %v", err)},
+		)
+		return
+	}
+	if _, err := userAlertRuleQuery(rule); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("invalid user alert rule: %v", err)},
+		)
+		return
+	}
+	if err := dao.DBClient.CreateUserAlertRule(rule); err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to create user alert rule: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"rule": rule,
+	})
+}
+
+// ListUserAlertRules
+// @Summary List user alert rules
+// @Description List the alert rules of the user in the current region
+// @Tags UserAlertRule
+// @Accept json
+// @Produce json
+// @Param request body helper.ListUserAlertRulesReq true "List user alert rules request"
+// @Success 200 {object} map[string]interface{} "successfully list user alert rules"
+// @Failure 400 {object} helper.ErrorMessage "failed to parse request"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to list user alert rules"
+// @Router /account/v1alpha1/user-alert-rule/list [post]
+func ListUserAlertRules(c *gin.Context) {
+	req, err := helper.ParseListUserAlertRulesReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateRequest(c, req); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	rules, err := dao.DBClient.ListUserAlertRules(
+		req.UserUID,
+		dao.DBClient.GetLocalRegion().Domain,
+		req.Workspace,
+	)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to list user alert rules: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"rules": rules,
+	})
+}
+
+// DeleteUserAlertRules
+// @Summary Delete user alert rules
+// @Description Delete alert rules of the user, the rules of other users are ignored
+// @Tags UserAlertRule
+// @Accept json
+// @Produce json
+// @Param request body helper.DeleteUserAlertRulesReq true "Delete user alert rules request"
+// @Success 200 {object} map[string]interface{} "successfully delete user alert rules"
+// @Failure 400 {object} helper.ErrorMessage "failed to parse request"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to delete user alert rules"
+// @Router /account/v1alpha1/user-alert-rule/delete [post]
+func DeleteUserAlertRules(c *gin.Context) {
+	req, err := helper.ParseDeleteUserAlertRulesReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateRequest(c, req); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	if len(req.IDs) == 0 {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: "ids cannot be empty"},
+		)
+		return
+	}
+	deleted, err := dao.DBClient.DeleteUserAlertRules(req.IDs, req.UserUID)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to delete user alert rules: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"deletedCount": deleted,
+	})
+}
+
+// ToggleUserAlertRules
+// @Summary Toggle user alert rules
+// @Description Enable or disable alert rules of the user, the state of the rules is reset
+// @Tags UserAlertRule
+// @Accept json
+// @Produce json
+// @Param request body helper.ToggleUserAlertRulesReq true "Toggle user alert rules request"
+// @Success 200 {object} map[string]interface{} "successfully toggle user alert rules"
+// @Failure 400 {object} helper.ErrorMessage "failed to parse request"
+// @Failure 401 {object} helper.ErrorMessage "authenticate error"
+// @Failure 500 {object} helper.ErrorMessage "failed to toggle user alert rules"
+// @Router /account/v1alpha1/user-alert-rule/toggle [post]
+func ToggleUserAlertRules(c *gin.Context) {
+	req, err := helper.ParseToggleUserAlertRulesReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateRequest(c, req); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	if len(req.IDs) == 0 {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: "ids cannot be empty"},
+		)
+		return
+	}
+	updated, err := dao.DBClient.ToggleUserAlertRules(req.IDs, req.UserUID, *req.IsEnabled)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to toggle user alert rules: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"updatedCount": updated,
+	})
+}
@@ -0,0 +1,417 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+	usernotify "github.com/labring/sealos/controllers/pkg/user_notify"
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+	"github.com/labring/sealos/service/pkg/api"
+	"github.com/labring/sealos/service/pkg/request"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	userAlertStatusFiring   = "firing"
+	userAlertStatusResolved = "resolved"
+)
+
+var userAlertRuleComparatorSymbols = map[types.UserAlertRuleComparator]string{
+	types.UserAlertRuleComparatorGT:  ">",
+	types.UserAlertRuleComparatorGTE: ">=",
+	types.UserAlertRuleComparatorLT:  "<",
+	types.UserAlertRuleComparatorLTE: "<=",
+	types.UserAlertRuleComparatorEQ:  "==",
+	types.UserAlertRuleComparatorNE:  "!=",
+}
+
+// userAlertEvent is a series of a rule starting or stopping to fire.
+type userAlertEvent struct {
+	Series string
+	Status string
+	Value  float64
+}
+
+// userAlertRuleTracker keeps the series of a rule matching its condition between evaluations.
+type userAlertRuleTracker struct {
+	activeSince map[string]time.Time
+	firing      map[string]bool
+	lastValue   map[string]float64
+}
+
+// newUserAlertRuleTracker returns a tracker with the series already notified as firing.
+func newUserAlertRuleTracker(firingSeries []string, now time.Time) *userAlertRuleTracker {
+	t := &userAlertRuleTracker{
+		activeSince: make(map[string]time.Time),
+		firing:      make(map[string]bool),
+		lastValue:   make(map[string]float64),
+	}
+	for _, series := range firingSeries {
+		t.activeSince[series] = now
+		t.firing[series] = true
+	}
+	return t
+}
+
+// evaluate updates the series with the samples of the rule at now. A series fires once it has
+// matched the condition for the duration of the rule, and resolves once it stops matching or
+// disappears.
+func (t *userAlertRuleTracker) evaluate(
+	rule *types.UserAlertRule,
+	samples map[string]float64,
+	now time.Time,
+) []userAlertEvent {
+	var events []userAlertEvent
+	duration := time.Duration(rule.Duration) * time.Second
+	for series, value := range samples {
+		t.lastValue[series] = value
+		if !rule.Matches(value) {
+			continue
+		}
+		since, ok := t.activeSince[series]
+		if !ok {
+			since = now
+			t.activeSince[series] = now
+		}
+		if !t.firing[series] && now.Sub(since) >= duration {
+			t.firing[series] = true
+			events = append(events, userAlertEvent{
+				Series: series,
+				Status: userAlertStatusFiring,
+				Value:  value,
+			})
+		}
+	}
+	for series := range t.activeSince {
+		if value, ok := samples[series]; ok && rule.Matches(value) {
+			continue
+		}
+		if t.firing[series] {
+			events = append(events, userAlertEvent{
+				Series: series,
+				Status: userAlertStatusResolved,
+				Value:  t.lastValue[series],
+			})
+		}
+		delete(t.activeSince, series)
+		delete(t.firing, series)
+	}
+	for series := range t.lastValue {
+		if _, ok := samples[series]; !ok {
+			delete(t.lastValue, series)
+		}
+	}
+	slices.SortFunc(events, func(a, b userAlertEvent) int {
+		return strings.Compare(a.Series, b.Series)
+	})
+	return events
+}
+
+// state returns the state of the rule and its firing series in order.
+func (t *userAlertRuleTracker) state() (types.UserAlertRuleState, []string) {
+	firing := make([]string, 0, len(t.firing))
+	for series := range t.firing {
+		firing = append(firing, series)
+	}
+	slices.Sort(firing)
+	switch {
+	case len(firing) > 0:
+		return types.UserAlertRuleStateFiring, firing
+	case len(t.activeSince) > 0:
+		return types.UserAlertRuleStatePending, firing
+	default:
+		return types.UserAlertRuleStateInactive, firing
+	}
+}
+
+// userAlertSeriesKey identifies a series by its labels, like {namespace="ns-a",pod="db-0"}.
+func userAlertSeriesKey(metric map[string]string) string {
+	names := make([]string, 0, len(metric))
+	for name := range metric {
+		if name != "__name__" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+strconv.Quote(metric[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// userAlertRuleCondition returns the readable condition of the rule, like cpu > 80 for 5m0s.
+func userAlertRuleCondition(rule *types.UserAlertRule) string {
+	condition := fmt.Sprintf(
+		"%s %s %s",
+		rule.Template,
+		userAlertRuleComparatorSymbols[rule.Comparator],
+		strconv.FormatFloat(rule.Threshold, 'f', -1, 64),
+	)
+	if rule.Duration > 0 {
+		condition += " for " + (time.Duration(rule.Duration) * time.Second).String()
+	}
+	return condition
+}
+
+// parseUserAlertSamples returns the values of an instant query result by series.
+func parseUserAlertSamples(body []byte) (map[string]float64, error) {
+	var result api.QueryResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse query result: %w", err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query status %q", result.Status)
+	}
+	samples := make(map[string]float64, len(result.Data.Result))
+	for _, r := range result.Data.Result {
+		if len(r.Value) != 2 {
+			continue
+		}
+		raw, ok := r.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+		samples[userAlertSeriesKey(r.Metric)] = value
+	}
+	return samples, nil
+}
+
+// queryUserAlertRule runs the instant query of the rule against the metric server of its source.
+func queryUserAlertRule(rule *types.UserAlertRule) (map[string]float64, error) {
+	promQL, err := userAlertRuleQuery(rule)
+	if err != nil {
+		return nil, err
+	}
+	host := os.Getenv(helper.EnvPromServiceHost)
+	if rule.Source == types.UserAlertRuleSourceLaunchpad {
+		if vmHost := os.Getenv(helper.EnvVMServiceHost); vmHost != "" {
+			host = vmHost
+		}
+	}
+	if host == "" {
+		return nil, api.ErrNoPromHost
+	}
+	form := url.Values{}
+	form.Set("query", promQL)
+	body, err := request.Request(host+"/api/v1/query", bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+	return parseUserAlertSamples(body)
+}
+
+// notifyUserAlertRule sends the event to every email and phone of the user, the alert
+// notification accounts of the user included.
+func notifyUserAlertRule(
+	ctx context.Context,
+	rule *types.UserAlertRule,
+	event userAlertEvent,
+) error {
+	if dao.UserContactProvider == nil || dao.UserNotificationService == nil {
+		return nil
+	}
+	providers, err := dao.DBClient.GetUserOauthProvider(&types.UserQueryOpts{UID: rule.UserUID})
+	if err != nil {
+		return fmt.Errorf("failed to get notification accounts: %w", err)
+	}
+	var userName string
+	if nr, err := dao.DBClient.GetNotificationRecipient(rule.UserUID); err == nil && nr != nil {
+		userName = nr.UserName
+	}
+	eventData := &usernotify.UserAlertRuleEventData{
+		Type:         usernotify.EventTypeUserAlertRule,
+		RegionDomain: rule.RegionDomain,
+		Namespace:    rule.Namespace,
+		RuleName:     rule.Name,
+		Resource:     rule.Resource,
+		Template:     rule.Template,
+		Severity:     string(rule.Severity),
+		Status:       event.Status,
+		Condition:    userAlertRuleCondition(rule),
+		Value:        event.Value,
+		Labels:       event.Series,
+	}
+	priority := usernotify.NotificationPriorityNormal
+	switch {
+	case event.Status == userAlertStatusResolved:
+	case rule.Severity == types.UserAlertRuleSeverityCritical:
+		priority = usernotify.NotificationPriorityCritical
+	case rule.Severity == types.UserAlertRuleSeverityWarning:
+		priority = usernotify.NotificationPriorityHigh
+	}
+
+	defer dao.UserContactProvider.RemoveUserContact(rule.UserUID)
+	var errs []error
+	for _, provider := range providers {
+		recipient := &types.NotificationRecipient{UserName: userName, UserUID: rule.UserUID}
+		var method usernotify.NotificationMethod
+		switch provider.ProviderType {
+		case types.OauthProviderTypeEmail:
+			recipient.Email = provider.ProviderID
+			method = usernotify.NotificationMethodEmail
+		case types.OauthProviderTypePhone:
+			recipient.PhoneNumber = provider.ProviderID
+			method = usernotify.NotificationMethodSMS
+		default:
+			continue
+		}
+		dao.UserContactProvider.SetUserContact(rule.UserUID, recipient)
+		if _, err := dao.UserNotificationService.SendEventNotification(
+			ctx,
+			&usernotify.NotificationEvent{
+				UserUID:   rule.UserUID,
+				EventType: eventData.GetType(),
+				EventData: eventData.ToMap(),
+				Methods:   []usernotify.NotificationMethod{method},
+				Priority:  priority,
+			},
+		); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// userAlertRuleOwnerIsMember reports whether the user of the rule is still a member of its workspace.
+func userAlertRuleOwnerIsMember(rule *types.UserAlertRule) (bool, error) {
+	role, err := dao.DBClient.GetUserWorkspaceRole(rule.UserUID, rule.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user role: %w", err)
+	}
+	return role != "", nil
+}
+
+func removeUserAlertRule(rule *types.UserAlertRule) error {
+	_, err := dao.DBClient.DeleteUserAlertRules([]uuid.UUID{rule.ID}, rule.UserUID)
+	return err
+}
+
+// UserAlertRuleEvaluator evaluates the enabled alert rules of the local region on a schedule and
+// notifies the users of the series that start or stop firing.
+type UserAlertRuleEvaluator struct {
+	interval time.Duration
+	isMember func(rule *types.UserAlertRule) (bool, error)
+	remove   func(rule *types.UserAlertRule) error
+	query    func(rule *types.UserAlertRule) (map[string]float64, error)
+	notify   func(ctx context.Context, rule *types.UserAlertRule, event userAlertEvent) error
+	trackers map[uuid.UUID]*userAlertRuleTracker
+}
+
+func NewUserAlertRuleEvaluator(interval time.Duration) *UserAlertRuleEvaluator {
+	return &UserAlertRuleEvaluator{
+		interval: interval,
+		isMember: userAlertRuleOwnerIsMember,
+		remove:   removeUserAlertRule,
+		query:    queryUserAlertRule,
+		notify:   notifyUserAlertRule,
+		trackers: make(map[uuid.UUID]*userAlertRuleTracker),
+	}
+}
+
+// Start evaluates the rules every interval until ctx is done.
+func (e *UserAlertRuleEvaluator) Start(ctx context.Context) {
+	logrus.Infof("Starting user alert rule evaluator, interval: %s", e.interval)
+	go func() {
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logrus.Info("Stopping user alert rule evaluator")
+				return
+			case now := <-ticker.C:
+				rules, err := dao.DBClient.ListEnabledUserAlertRules(
+					dao.DBClient.GetLocalRegion().Domain,
+				)
+				if err != nil {
+					logrus.Errorf("Failed to list user alert rules: %v", err)
+					continue
+				}
+				e.evaluate(ctx, rules, now)
+			}
+		}
+	}()
+}
+
+// evaluate evaluates the rules at now and drops the trackers of the rules deleted or disabled.
+// The rules of the users who are no longer members of the workspace are removed instead.
+func (e *UserAlertRuleEvaluator) evaluate(
+	ctx context.Context,
+	rules []types.UserAlertRule,
+	now time.Time,
+) {
+	seen := make(map[uuid.UUID]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+		member, err := e.isMember(rule)
+		if err != nil {
+			// not notified until the membership can be checked again
+			seen[rule.ID] = true
+			logrus.Errorf("Failed to check the workspace of user alert rule %s: %v", rule.ID, err)
+			continue
+		}
+		if !member {
+			logrus.Infof(
+				"Removing user alert rule %s, user %s is not a member of %s",
+				rule.ID, rule.UserUID, rule.Namespace,
+			)
+			if err := e.remove(rule); err != nil {
+				logrus.Errorf("Failed to remove user alert rule %s: %v", rule.ID, err)
+			}
+			continue
+		}
+		seen[rule.ID] = true
+		samples, err := e.query(rule)
+		if err != nil {
+			// the rule keeps its state until the metrics can be queried again
+			logrus.Errorf("Failed to evaluate user alert rule %s: %v", rule.ID, err)
+			continue
+		}
+		tracker, ok := e.trackers[rule.ID]
+		if !ok {
+			tracker = newUserAlertRuleTracker(rule.FiringSeries, now)
+			e.trackers[rule.ID] = tracker
+		}
+		var firedAt time.Time
+		for _, event := range tracker.evaluate(rule, samples, now) {
+			if event.Status == userAlertStatusFiring {
+				firedAt = now
+			}
+			if err := e.notify(ctx, rule, event); err != nil {
+				logrus.Errorf(
+					"Failed to notify user alert rule %s of series %s: %v",
+					rule.ID, event.Series, err,
+				)
+			}
+		}
+		state, firing := tracker.state()
+		if err := dao.DBClient.UpdateUserAlertRuleState(
+			rule.ID, state, firing, now, firedAt,
+		); err != nil {
+			logrus.Errorf("Failed to update user alert rule %s state: %v", rule.ID, err)
+		}
+	}
+	for id := range e.trackers {
+		if !seen[id] {
+			delete(e.trackers, id)
+		}
+	}
+}
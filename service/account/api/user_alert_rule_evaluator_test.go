@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+func newTestUserAlertRule(duration int64) *types.UserAlertRule {
+	return &types.UserAlertRule{
+		Namespace:    "ns-test",
+		RegionDomain: "test.sealos.io",
+		Name:         "cpu high",
+		Source:       types.UserAlertRuleSourceDatabase,
+		DatabaseType: "apecloud-mysql",
+		Resource:     "my-mysql",
+		Template:     "cpu",
+		Comparator:   types.UserAlertRuleComparatorGT,
+		Threshold:    80,
+		Duration:     duration,
+		Severity:     types.UserAlertRuleSeverityWarning,
+	}
+}
+
+func TestUserAlertRuleTracker_FiresAfterDurationAndResolves(t *testing.T) {
+	rule := newTestUserAlertRule(120)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newUserAlertRuleTracker(nil, start)
+	series := `{pod="my-mysql-0"}`
+
+	if events := tracker.evaluate(rule, map[string]float64{series: 90}, start); len(events) != 0 {
+		t.Fatalf("expected no event before the duration, got %v", events)
+	}
+	if state, _ := tracker.state(); state != types.UserAlertRuleStatePending {
+		t.Fatalf("expected pending state, got %s", state)
+	}
+
+	events := tracker.evaluate(rule, map[string]float64{series: 95}, start.Add(2*time.Minute))
+	if len(events) != 1 || events[0].Status != userAlertStatusFiring || events[0].Value != 95 {
+		t.Fatalf("expected one firing event, got %v", events)
+	}
+	state, firing := tracker.state()
+	if state != types.UserAlertRuleStateFiring || len(firing) != 1 || firing[0] != series {
+		t.Fatalf("expected firing state with %s, got %s %v", series, state, firing)
+	}
+
+	// a firing series is notified once
+	events = tracker.evaluate(rule, map[string]float64{series: 99}, start.Add(3*time.Minute))
+	if len(events) != 0 {
+		t.Fatalf("expected no event while firing, got %v", events)
+	}
+
+	events = tracker.evaluate(rule, map[string]float64{series: 10}, start.Add(4*time.Minute))
+	if len(events) != 1 || events[0].Status != userAlertStatusResolved || events[0].Value != 10 {
+		t.Fatalf("expected one resolved event, got %v", events)
+	}
+	if state, _ := tracker.state(); state != types.UserAlertRuleStateInactive {
+		t.Fatalf("expected inactive state, got %s", state)
+	}
+}
+
+func TestUserAlertRuleTracker_PendingResetsWhenConditionBreaks(t *testing.T) {
+	rule := newTestUserAlertRule(120)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newUserAlertRuleTracker(nil, start)
+	series := `{pod="my-mysql-0"}`
+
+	tracker.evaluate(rule, map[string]float64{series: 90}, start)
+	tracker.evaluate(rule, map[string]float64{series: 50}, start.Add(time.Minute))
+	tracker.evaluate(rule, map[string]float64{series: 90}, start.Add(2*time.Minute))
+	events := tracker.evaluate(rule, map[string]float64{series: 90}, start.Add(3*time.Minute))
+	if len(events) != 0 {
+		t.Fatalf("expected the duration to restart, got %v", events)
+	}
+	events = tracker.evaluate(rule, map[string]float64{series: 90}, start.Add(4*time.Minute))
+	if len(events) != 1 || events[0].Status != userAlertStatusFiring {
+		t.Fatalf("expected one firing event, got %v", events)
+	}
+}
+
+func TestUserAlertRuleTracker_RestoredSeriesResolveWhenGone(t *testing.T) {
+	rule := newTestUserAlertRule(0)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	kept, gone := `{pod="my-mysql-0"}`, `{pod="my-mysql-1"}`
+	tracker := newUserAlertRuleTracker([]string{kept, gone}, now)
+
+	events := tracker.evaluate(rule, map[string]float64{kept: 90}, now)
+	if len(events) != 1 || events[0].Series != gone || events[0].Status != userAlertStatusResolved {
+		t.Fatalf("expected only %s to resolve, got %v", gone, events)
+	}
+	state, firing := tracker.state()
+	if state != types.UserAlertRuleStateFiring || len(firing) != 1 || firing[0] != kept {
+		t.Fatalf("expected %s to keep firing, got %s %v", kept, state, firing)
+	}
+}
+
+func TestParseUserAlertSamples(t *testing.T) {
+	body := []byte(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"pod":"db-0","namespace":"ns-test"},"value":[1700000000,"12.5"]},
+		{"metric":{"pod":"db-1","namespace":"ns-test"},"value":[1700000000,"NaN"]}
+	]}}`)
+	samples, err := parseUserAlertSamples(body)
+	if err != nil {
+		t.Fatalf("parse samples: %v", err)
+	}
+	if len(samples) != 1 || samples[`{namespace="ns-test",pod="db-0"}`] != 12.5 {
+		t.Fatalf("unexpected samples: %v", samples)
+	}
+	if _, err := parseUserAlertSamples([]byte(`{"status":"error"}`)); err == nil {
+		t.Fatal("expected error for a failed query")
+	}
+}
+
+func TestUserAlertRuleQuery(t *testing.T) {
+	rule := newTestUserAlertRule(0)
+	query, err := userAlertRuleQuery(rule)
+	if err != nil {
+		t.Fatalf("database query: %v", err)
+	}
+	if !strings.Contains(query, `namespace=~"ns-test"`) ||
+		!strings.Contains(query, `pod=~"my-mysql-mysql-\\d"`) {
+		t.Fatalf("unexpected database query: %s", query)
+	}
+
+	rule.Source = types.UserAlertRuleSourceLaunchpad
+	rule.Resource = "my-app"
+	query, err = userAlertRuleQuery(rule)
+	if err != nil {
+		t.Fatalf("launchpad query: %v", err)
+	}
+	if !strings.Contains(query, `pod=~"my-app-.*"`) || strings.Contains(query, "$") {
+		t.Fatalf("unexpected launchpad query: %s", query)
+	}
+
+	for name, mutate := range map[string]func(r *types.UserAlertRule){
+		"resource escapes the matcher": func(r *types.UserAlertRule) { r.Resource = `a".*` },
+		"storage needs a pvc":          func(r *types.UserAlertRule) { r.Template = "storage" },
+		"minio is not namespaced": func(r *types.UserAlertRule) {
+			r.Source = types.UserAlertRuleSourceDatabase
+			r.DatabaseType = "minio"
+		},
+	} {
+		r := newTestUserAlertRule(0)
+		r.Source = types.UserAlertRuleSourceLaunchpad
+		mutate(r)
+		if _, err := userAlertRuleQuery(r); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestUserAlertRuleCondition(t *testing.T) {
+	rule := newTestUserAlertRule(300)
+	if got := userAlertRuleCondition(rule); got != "cpu > 80 for 5m0s" {
+		t.Fatalf("unexpected condition: %s", got)
+	}
+}
+
+func TestUserAlertRuleEvaluator_RemovesRulesOfFormerMembers(t *testing.T) {
+	rule := newTestUserAlertRule(0)
+	rule.ID = uuid.New()
+	var removed []uuid.UUID
+	e := &UserAlertRuleEvaluator{
+		isMember: func(*types.UserAlertRule) (bool, error) { return false, nil },
+		remove: func(r *types.UserAlertRule) error {
+			removed = append(removed, r.ID)
+			return nil
+		},
+		query: func(*types.UserAlertRule) (map[string]float64, error) {
+			t.Fatal("unexpected query of a removed rule")
+			return nil, nil
+		},
+		notify: func(context.Context, *types.UserAlertRule, userAlertEvent) error {
+			t.Fatal("unexpected notification of a former member")
+			return nil
+		},
+		trackers: map[uuid.UUID]*userAlertRuleTracker{
+			rule.ID: newUserAlertRuleTracker([]string{`{pod="my-mysql-0"}`}, time.Now()),
+		},
+	}
+
+	e.evaluate(context.Background(), []types.UserAlertRule{*rule}, time.Now())
+	if len(removed) != 1 || removed[0] != rule.ID {
+		t.Fatalf("expected rule %s to be removed, got %v", rule.ID, removed)
+	}
+	if _, ok := e.trackers[rule.ID]; ok {
+		t.Fatal("expected the tracker of the removed rule to be dropped")
+	}
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/service/account/dao"
+	"github.com/labring/sealos/service/account/helper"
+	"gorm.io/gorm"
+)
+
+func authenticateWorkspaceBudgetRequest(
+	c *gin.Context,
+	req *helper.WorkspaceInfoReq,
+	isOwner bool,
+) error {
+	if err := authenticateRequest(c, req); err != nil {
+		return fmt.Errorf("authenticate error : %w", err)
+	}
+	role, err := dao.DBClient.GetUserWorkspaceRole(req.UserUID, req.Workspace)
+	if err != nil {
+		return fmt.Errorf("failed to get user role: %w", err)
+	}
+	if role == "" || (isOwner && role != types.RoleOwner) {
+		return errors.New("there is no permission find this workspace")
+	}
+	return nil
+}
+
+// GetWorkspaceBudget
+// @Summary Get workspace budget
+// @Description Get the monthly budget of the workspace in the current region
+// @Tags WorkspaceBudget
+// @Accept json
+// @Produce json
+// @Param request body helper.WorkspaceInfoReq true "Workspace info request"
+// @Success 200 {object} map[string]interface{} "successfully get workspace budget"
+// @Failure 400 {object} map[string]interface{} "failed to parse request"
+// @Failure 401 {object} map[string]interface{} "authenticate error"
+// @Failure 500 {object} map[string]interface{} "failed to get workspace budget"
+// @Router /account/v1alpha1/workspace-budget/get [post]
+func GetWorkspaceBudget(c *gin.Context) {
+	req, err := helper.ParseWorkspaceInfoReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateWorkspaceBudgetRequest(c, req, false); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	budget, err := dao.DBClient.GetWorkspaceBudget(
+		req.Workspace,
+		dao.DBClient.GetLocalRegion().Domain,
+	)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to get workspace budget: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"budget": budget,
+	})
+}
+
+// SetWorkspaceBudget
+// @Summary Set workspace budget
+// @Description Create or update the monthly budget of the workspace in the current region, only the owner of the workspace can set it
+// @Tags WorkspaceBudget
+// @Accept json
+// @Produce json
+// @Param request body helper.SetWorkspaceBudgetReq true "Set workspace budget request"
+// @Success 200 {object} map[string]interface{} "successfully set workspace budget"
+// @Failure 400 {object} map[string]interface{} "failed to parse request"
+// @Failure 401 {object} map[string]interface{} "authenticate error"
+// @Failure 500 {object} map[string]interface{} "failed to set workspace budget"
+// @Router /account/v1alpha1/workspace-budget/set [post]
+func SetWorkspaceBudget(c *gin.Context) {
+	req, err := helper.ParseSetWorkspaceBudgetReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateWorkspaceBudgetRequest(c, &req.WorkspaceInfoReq, true); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	// the alerts go to the owner of the workspace
+	ownerUID, err := dao.DBClient.GetWorkspaceUserUID(req.Workspace)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to get workspace owner: %v", err)},
+		)
+		return
+	}
+	budget := &types.WorkspaceBudget{
+		Workspace:       req.Workspace,
+		RegionDomain:    dao.DBClient.GetLocalRegion().Domain,
+		UserUID:         ownerUID,
+		MonthlyLimit:    req.MonthlyLimit,
+		AlertThresholds: req.AlertThresholds,
+		SoftStop:        req.SoftStop,
+	}
+	if err := budget.Validate(); err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("invalid workspace budget: %v", err)},
+		)
+		return
+	}
+	if err := dao.DBClient.SetWorkspaceBudget(budget); err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to set workspace budget: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// DeleteWorkspaceBudget
+// @Summary Delete workspace budget
+// @Description Delete the monthly budget of the workspace in the current region, a workspace soft-stopped by its budget must be resumed first
+// @Tags WorkspaceBudget
+// @Accept json
+// @Produce json
+// @Param request body helper.WorkspaceInfoReq true "Workspace info request"
+// @Success 200 {object} map[string]interface{} "successfully delete workspace budget"
+// @Failure 400 {object} map[string]interface{} "failed to parse request"
+// @Failure 401 {object} map[string]interface{} "authenticate error"
+// @Failure 409 {object} map[string]interface{} "workspace is soft-stopped by its budget"
+// @Failure 500 {object} map[string]interface{} "failed to delete workspace budget"
+// @Router /account/v1alpha1/workspace-budget/delete [post]
+func DeleteWorkspaceBudget(c *gin.Context) {
+	req, err := helper.ParseWorkspaceInfoReq(c)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to parse request: %v", err)},
+		)
+		return
+	}
+	if err := authenticateWorkspaceBudgetRequest(c, req, true); err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			helper.ErrorMessage{Error: fmt.Sprintf("authenticate error : %v", err)},
+		)
+		return
+	}
+	regionDomain := dao.DBClient.GetLocalRegion().Domain
+	budget, err := dao.DBClient.GetWorkspaceBudget(req.Workspace, regionDomain)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusOK, gin.H{})
+		return
+	}
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to get workspace budget: %v", err)},
+		)
+		return
+	}
+	// the controller resumes the workspace only while the budget exists
+	if budget.Status == types.WorkspaceBudgetStatusSoftStopped {
+		c.JSON(
+			http.StatusConflict,
+			helper.ErrorMessage{
+				Error: "workspace is soft-stopped by its budget, raise the limit or disable soft stop first",
+			},
+		)
+		return
+	}
+	if err := dao.DBClient.DeleteWorkspaceBudget(req.Workspace, regionDomain); err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			helper.ErrorMessage{Error: fmt.Sprintf("failed to delete workspace budget: %v", err)},
+		)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
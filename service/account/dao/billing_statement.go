@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/resources"
+	"github.com/labring/sealos/controllers/pkg/types"
+	"github.com/labring/sealos/service/account/helper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// BillingStatement methods implementation
+
+func (g *Cockroach) GetUsedCredits(
+	userUID, regionUID uuid.UUID,
+	startTime, endTime time.Time,
+) (int64, error) {
+	return g.ck.GetUsedCredits(userUID, regionUID, startTime, endTime)
+}
+
+func (g *Cockroach) GetBillingStatementSubscription(
+	userUID uuid.UUID,
+	regionDomain string,
+) (*types.BillingStatementSubscription, error) {
+	return g.ck.GetBillingStatementSubscription(userUID, regionDomain)
+}
+
+func (g *Cockroach) SetBillingStatementSubscription(sub *types.BillingStatementSubscription) error {
+	return g.ck.SetBillingStatementSubscription(sub)
+}
+
+func (g *Cockroach) ListDueBillingStatementSubscriptions(
+	regionDomain, period string,
+) ([]types.BillingStatementSubscription, error) {
+	return g.ck.ListDueBillingStatementSubscriptions(regionDomain, period)
+}
+
+func (g *Cockroach) SetBillingStatementSubscriptionPeriod(id uuid.UUID, period string) error {
+	return g.ck.SetBillingStatementSubscriptionPeriod(id, period)
+}
+
+// GetBillingStatementItems sums the settled costs of the owner within [startTime, endTime) by
+// namespace, app and property, only the ones of namespace if it is not empty. The apps of an app
+// store app are summed into the app store app.
+func (m *MongoDB) GetBillingStatementItems(
+	owner, namespace string,
+	startTime, endTime time.Time,
+) ([]helper.BillingStatementItem, error) {
+	match := bson.M{
+		"owner":  owner,
+		"status": resources.Settled,
+		"time":   bson.M{"$gte": startTime, "$lt": endTime},
+	}
+	if namespace != "" {
+		match["namespace"] = namespace
+	}
+	props := resources.DefaultPropertyTypeLS
+	group := bson.D{{Key: "_id", Value: bson.D{
+		{Key: "namespace", Value: "$namespace"},
+		{Key: "app_type", Value: "$app_type"},
+		{Key: "app_name", Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$eq", Value: bson.A{"$app_type", resources.AppType[resources.AppStore]}}},
+			"$app_name",
+			"$app_costs.name",
+		}}}},
+	}}}
+	for i := range props.EnumMap {
+		for _, field := range []string{"used", "used_amount"} {
+			group = append(group, bson.E{
+				Key: fmt.Sprintf("%s_%d", field, i),
+				Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$ifNull", Value: bson.A{
+					bson.D{{Key: "$toLong", Value: fmt.Sprintf("$app_costs.%s.%d", field, i)}},
+					0,
+				}}}}},
+			})
+		}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$app_costs"}},
+		{{Key: "$group", Value: group}},
+	}
+
+	ctx := context.Background()
+	cursor, err := m.getBillingCollection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate billing statement: %w", err)
+	}
+	defer cursor.Close(ctx)
+	var results []struct {
+		ID struct {
+			Namespace string `bson:"namespace"`
+			AppType   uint8  `bson:"app_type"`
+			AppName   string `bson:"app_name"`
+		} `bson:"_id"`
+		Sums map[string]int64 `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode billing statement: %w", err)
+	}
+
+	enums := make([]uint8, 0, len(props.EnumMap))
+	for i := range props.EnumMap {
+		enums = append(enums, i)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i] < enums[j] })
+	var items []helper.BillingStatementItem
+	for _, result := range results {
+		for _, i := range enums {
+			used, amount := result.Sums[fmt.Sprintf("used_%d", i)],
+				result.Sums[fmt.Sprintf("used_amount_%d", i)]
+			if used == 0 && amount == 0 {
+				continue
+			}
+			prop := props.EnumMap[i]
+			name := prop.Name
+			if name == "" {
+				name = strconv.Itoa(int(i))
+			}
+			items = append(items, helper.BillingStatementItem{
+				Namespace: result.ID.Namespace,
+				AppType:   strings.ToLower(resources.AppTypeReverse[result.ID.AppType]),
+				AppName:   result.ID.AppName,
+				Property:  name,
+				Used:      used,
+				Unit:      prop.UnitString,
+				Amount:    amount,
+			})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.AppType != b.AppType {
+			return a.AppType < b.AppType
+		}
+		return a.AppName < b.AppName
+	})
+	return items, nil
+}
@@ -0,0 +1,32 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	mongodb "github.com/labring/sealos/controllers/pkg/database/mongo"
+	"github.com/labring/sealos/controllers/pkg/resources"
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+func (g *Cockroach) GetAccountWithCredits(
+	userUID uuid.UUID,
+) (*types.UsableBalanceWithCredits, error) {
+	return g.ck.GetAccountWithCredits(userUID)
+}
+
+// GetLatestMonitors returns the latest sample of every resource of the namespaces
+// monitored within resources.ForecastSampleWindow before at.
+func (m *MongoDB) GetLatestMonitors(
+	namespaces []string,
+	at time.Time,
+) ([]resources.Monitor, error) {
+	return mongodb.FindLatestMonitors(
+		context.Background(),
+		m.Client.Database(m.AccountDBName),
+		mongodb.DefaultMonitorConn,
+		namespaces,
+		at,
+	)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/labring/sealos/controllers/pkg/database"
 	"github.com/labring/sealos/controllers/pkg/gpu"
 	v1 "github.com/labring/sealos/controllers/pkg/notification/api/v1"
+	"github.com/labring/sealos/controllers/pkg/pay"
 	"github.com/labring/sealos/controllers/pkg/resources"
 	"github.com/labring/sealos/controllers/pkg/types"
 	usernotify "github.com/labring/sealos/controllers/pkg/user_notify"
@@ -51,6 +52,7 @@ var (
 	ClientIP              string
 	DeviceTokenID         string
 	PaymentService        *services.AtomPaymentService
+	SandboxPayment        *pay.SandboxPayment
 	PaymentCurrency       string
 	SubPlanResourceQuota  map[string]corev1.ResourceList
 	WorkspacePlanResQuota map[string]corev1.ResourceList
@@ -199,6 +201,16 @@ func Init(ctx context.Context) error {
 			return fmt.Errorf("empty client ip, please check env: %s", helper.EnvClientIP)
 		}
 	}
+	if SandboxPayment, err = pay.EnableSandboxFromEnv(); err != nil {
+		return fmt.Errorf("enable sandbox payment error: %w", err)
+	}
+	if SandboxPayment != nil {
+		logrus.Warnf(
+			"sandbox payment method is enabled, do not use it in production, payer endpoint: %s, callback: %s",
+			SandboxPayment.BaseURL,
+			SandboxPayment.CallbackURL,
+		)
+	}
 	if PaymentCurrency = os.Getenv(helper.EnvPaymentCurrency); PaymentCurrency == "" {
 		PaymentCurrency = "USD"
 	}
@@ -37,6 +37,10 @@ type Interface interface {
 	GetAppCostTimeRange(req helper.GetCostAppListReq) (helper.TimeRange, error)
 	GetCostOverview(req helper.GetCostAppListReq) (helper.CostOverviewResp, error)
 	GetBasicCostDistribution(req helper.GetCostAppListReq) (map[string]int64, error)
+	GetBillingStatementItems(
+		owner, namespace string,
+		startTime, endTime time.Time,
+	) ([]helper.BillingStatementItem, error)
 	GetCostAppList(req helper.GetCostAppListReq) (helper.CostAppListResp, error)
 	Disconnect(ctx context.Context) error
 	GetConsumptionAmount(req helper.ConsumptionRecordReq) (int64, error)
@@ -52,6 +56,9 @@ type Interface interface {
 		startTime, endTime time.Time,
 		namespaces []string,
 	) ([]common.Monitor, error)
+	GetLatestMonitors(namespaces []string, at time.Time) ([]resources.Monitor, error)
+	GetLatestNodeUsage(at time.Time, node string) ([]resources.NodeUsage, error)
+	GetAccountWithCredits(userUID uuid.UUID) (*types.UsableBalanceWithCredits, error)
 	ApplyInvoice(
 		req *helper.ApplyInvoiceReq,
 	) (invoice types.Invoice, payments []types.Payment, err error)
@@ -117,6 +124,39 @@ type Interface interface {
 	GetWorkspaceSubscription(workspace, regionDomain string) (*types.WorkspaceSubscription, error)
 	GetWorkspaceSubscriptionTraffic(workspace, regionDomain string) (total, used int64, err error)
 	GetAIQuota(workspace, regionDomain string) (total, used int64, err error)
+	// WorkspaceBudget methods
+	GetWorkspaceBudget(workspace, regionDomain string) (*types.WorkspaceBudget, error)
+	SetWorkspaceBudget(budget *types.WorkspaceBudget) error
+	DeleteWorkspaceBudget(workspace, regionDomain string) error
+	// UserAlertRule methods
+	CreateUserAlertRule(rule *types.UserAlertRule) error
+	ListUserAlertRules(
+		userUID uuid.UUID,
+		regionDomain, namespace string,
+	) ([]types.UserAlertRule, error)
+	ListEnabledUserAlertRules(regionDomain string) ([]types.UserAlertRule, error)
+	DeleteUserAlertRules(ids []uuid.UUID, userUID uuid.UUID) (int64, error)
+	ToggleUserAlertRules(ids []uuid.UUID, userUID uuid.UUID, isEnabled bool) (int64, error)
+	UpdateUserAlertRuleState(
+		id uuid.UUID,
+		state types.UserAlertRuleState,
+		firingSeries []string,
+		evaluatedAt, firedAt time.Time,
+	) error
+	// GetUserOauthProvider returns the email and phone of the user merged with the enabled
+	// alert notification accounts
+	GetUserOauthProvider(ops *types.UserQueryOpts) ([]types.OauthProvider, error)
+	// BillingStatement methods
+	GetUsedCredits(userUID, regionUID uuid.UUID, startTime, endTime time.Time) (int64, error)
+	GetBillingStatementSubscription(
+		userUID uuid.UUID,
+		regionDomain string,
+	) (*types.BillingStatementSubscription, error)
+	SetBillingStatementSubscription(sub *types.BillingStatementSubscription) error
+	ListDueBillingStatementSubscriptions(
+		regionDomain, period string,
+	) ([]types.BillingStatementSubscription, error)
+	SetBillingStatementSubscriptionPeriod(id uuid.UUID, period string) error
 	ListWorkspaceSubscription(userUID uuid.UUID) ([]types.WorkspaceSubscription, error)
 	ListWorkspaceSubscriptionWorkspace(userUID uuid.UUID) ([]string, error)
 	GetWorkspaceSubscriptionPlanList() ([]types.WorkspaceSubscriptionPlan, error)
@@ -0,0 +1,43 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/labring/sealos/controllers/pkg/resources"
+	"github.com/labring/sealos/service/account/helper"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetLatestNodeUsage returns the latest usage report of every node reported within
+// helper.AdminNodeUsageWindow before at, node filters a single node if it is not empty.
+func (m *MongoDB) GetLatestNodeUsage(at time.Time, node string) ([]resources.NodeUsage, error) {
+	endTime := at.UTC()
+	match := bson.M{
+		"time": bson.M{"$gt": endTime.Add(-helper.AdminNodeUsageWindow), "$lte": endTime},
+	}
+	if node != "" {
+		match["node"] = node
+	}
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$sort": bson.M{"time": -1}},
+		{"$group": bson.M{"_id": "$node", "usage": bson.M{"$first": "$$ROOT"}}},
+		{"$replaceRoot": bson.M{"newRoot": "$usage"}},
+		{"$sort": bson.M{"node": 1}},
+	}
+	ctx := context.Background()
+	cursor, err := m.Client.Database(m.AccountDBName).
+		Collection(resources.NodeUsageConn).
+		Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate node usage: %w", err)
+	}
+	defer cursor.Close(ctx)
+	usages := make([]resources.NodeUsage, 0)
+	if err := cursor.All(ctx, &usages); err != nil {
+		return nil, fmt.Errorf("failed to decode node usage: %w", err)
+	}
+	return usages, nil
+}
@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+// UserAlertRule methods implementation
+
+func (g *Cockroach) CreateUserAlertRule(rule *types.UserAlertRule) error {
+	return g.ck.CreateUserAlertRule(rule)
+}
+
+func (g *Cockroach) ListUserAlertRules(
+	userUID uuid.UUID,
+	regionDomain, namespace string,
+) ([]types.UserAlertRule, error) {
+	return g.ck.ListUserAlertRules(userUID, regionDomain, namespace)
+}
+
+func (g *Cockroach) ListEnabledUserAlertRules(regionDomain string) ([]types.UserAlertRule, error) {
+	return g.ck.ListEnabledUserAlertRules(regionDomain)
+}
+
+func (g *Cockroach) DeleteUserAlertRules(ids []uuid.UUID, userUID uuid.UUID) (int64, error) {
+	return g.ck.DeleteUserAlertRules(ids, userUID)
+}
+
+func (g *Cockroach) ToggleUserAlertRules(
+	ids []uuid.UUID,
+	userUID uuid.UUID,
+	isEnabled bool,
+) (int64, error) {
+	return g.ck.ToggleUserAlertRules(ids, userUID, isEnabled)
+}
+
+func (g *Cockroach) UpdateUserAlertRuleState(
+	id uuid.UUID,
+	state types.UserAlertRuleState,
+	firingSeries []string,
+	evaluatedAt, firedAt time.Time,
+) error {
+	return g.ck.UpdateUserAlertRuleState(id, state, firingSeries, evaluatedAt, firedAt)
+}
+
+func (g *Cockroach) GetUserOauthProvider(
+	ops *types.UserQueryOpts,
+) ([]types.OauthProvider, error) {
+	return g.ck.GetUserOauthProvider(ops)
+}
@@ -0,0 +1,21 @@
+package dao
+
+import (
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+// WorkspaceBudget methods implementation
+
+func (g *Cockroach) GetWorkspaceBudget(
+	workspace, regionDomain string,
+) (*types.WorkspaceBudget, error) {
+	return g.ck.GetWorkspaceBudget(workspace, regionDomain)
+}
+
+func (g *Cockroach) SetWorkspaceBudget(budget *types.WorkspaceBudget) error {
+	return g.ck.SetWorkspaceBudget(budget)
+}
+
+func (g *Cockroach) DeleteWorkspaceBudget(workspace, regionDomain string) error {
+	return g.ck.DeleteWorkspaceBudget(workspace, regionDomain)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/resources"
 )
 
 type AdminPage struct {
@@ -196,3 +197,13 @@ type AdminRegion struct {
 	Domain      string    `json:"domain"`
 	Description string    `json:"description"`
 }
+
+// AdminNodeUsageWindow is how far back the latest node usage report of a node is looked up
+const AdminNodeUsageWindow = time.Hour
+
+// AdminNodeUsageResp is the latest allocated, billed and idle resources of the nodes
+type AdminNodeUsageResp struct {
+	Time    time.Time                  `json:"time"`
+	Summary resources.NodeUsageSummary `json:"summary"`
+	Nodes   []resources.NodeUsage      `json:"nodes"`
+}
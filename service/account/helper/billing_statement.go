@@ -0,0 +1,118 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+// maxBillingStatementRange bounds the time range of an exported statement.
+const maxBillingStatementRange = 366 * 24 * time.Hour
+
+// ExportBillingStatementReq represents the request to export the billing statement of a time range
+type ExportBillingStatementReq struct {
+	// @Summary Start and end time for the request
+	// @Description Start and end time of the statement, the end time is exclusive
+	// @JSONSchema required
+	TimeRange `json:",inline" bson:",inline"`
+
+	// @Summary Format
+	// @Description File format of the statement, csv or xlsx, csv by default
+	Format types.BillingStatementFormat `json:"format,omitempty" bson:"format,omitempty" example:"xlsx"`
+
+	// @Summary Namespace
+	// @Description Only export the costs of the namespace if it is set
+	Namespace string `json:"namespace,omitempty" bson:"namespace,omitempty" example:"ns-admin"`
+
+	// @Summary Authentication information
+	// @Description Authentication information
+	// @JSONSchema required
+	AuthBase `json:",inline" bson:",inline"`
+}
+
+func ParseExportBillingStatementReq(c *gin.Context) (*ExportBillingStatementReq, error) {
+	req := &ExportBillingStatementReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	if req.Format == "" {
+		req.Format = types.BillingStatementFormatCSV
+	}
+	if !req.Format.IsValid() {
+		return nil, fmt.Errorf("unsupported format: %s", req.Format)
+	}
+	if req.StartTime.IsZero() || !req.EndTime.After(req.StartTime) {
+		return nil, errors.New("end time must be after start time")
+	}
+	if req.EndTime.Sub(req.StartTime) > maxBillingStatementRange {
+		return nil, fmt.Errorf("time range cannot exceed %s", maxBillingStatementRange)
+	}
+	return req, nil
+}
+
+// SetBillingStatementSubscriptionReq represents the request to opt in to or out of the monthly
+// billing statement of the current region
+type SetBillingStatementSubscriptionReq struct {
+	// @Summary Enable flag
+	// @Description Set to true to receive the monthly statement, false to stop it
+	// @JSONSchema required
+	IsEnabled *bool `json:"isEnabled" bson:"isEnabled" binding:"required" example:"true"`
+
+	// @Summary Email
+	// @Description Email address the statements are sent to, it must be the email bound to the account, which is used by default
+	Email string `json:"email,omitempty" bson:"email,omitempty" binding:"omitempty,email" example:"finance@example.com"`
+
+	// @Summary Format
+	// @Description File format of the statements, csv or xlsx, csv by default
+	Format types.BillingStatementFormat `json:"format,omitempty" bson:"format,omitempty" example:"xlsx"`
+
+	AuthBase `json:",inline" bson:",inline"`
+}
+
+func ParseSetBillingStatementSubscriptionReq(
+	c *gin.Context,
+) (*SetBillingStatementSubscriptionReq, error) {
+	req := &SetBillingStatementSubscriptionReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	if req.Format == "" {
+		req.Format = types.BillingStatementFormatCSV
+	}
+	if !req.Format.IsValid() {
+		return nil, fmt.Errorf("unsupported format: %s", req.Format)
+	}
+	return req, nil
+}
+
+// BillingStatementItem is the cost of a property of an app in a workspace
+type BillingStatementItem struct {
+	Namespace string
+	// Workspace is the display name of the namespace
+	Workspace string
+	AppType   string
+	AppName   string
+	Property  string
+	// Used is the usage in Unit
+	Used int64
+	Unit string
+	// Amount has the same unit as the billing amount
+	Amount int64
+}
+
+// BillingStatement is the cost of a user in a region within [StartTime, EndTime)
+type BillingStatement struct {
+	RegionDomain string
+	StartTime    time.Time
+	EndTime      time.Time
+	Items        []BillingStatementItem
+	// TotalAmount is the sum of the item amounts
+	TotalAmount int64
+	// CreditsAmount is the part of the cost paid with credits
+	CreditsAmount int64
+	// NetAmount is the part of the cost paid with the balance
+	NetAmount int64
+}
@@ -26,6 +26,7 @@ const (
 	GetAppTypeList                = "/cost-app-type-list"
 	GetBasicCostDistribution      = "/cost-basic-distribution"
 	GetAppCostTimeRange           = "/cost-app-time-range"
+	GetCostForecast               = "/cost-forecast"
 	CheckPermission               = "/check-permission"
 	GetInvoice                    = "/invoice/get"
 	ApplyInvoice                  = "/invoice/apply"
@@ -41,6 +42,18 @@ const (
 	UserAlertNotificationAccountList   = "/user-alert-notification-account/list"
 	UserAlertNotificationAccountDelete = "/user-alert-notification-account/delete"
 	UserAlertNotificationAccountToggle = "/user-alert-notification-account/toggle"
+
+	// UserAlertRule routes
+	UserAlertRuleTemplates = "/user-alert-rule/templates"
+	UserAlertRuleCreate    = "/user-alert-rule/create"
+	UserAlertRuleList      = "/user-alert-rule/list"
+	UserAlertRuleDelete    = "/user-alert-rule/delete"
+	UserAlertRuleToggle    = "/user-alert-rule/toggle"
+
+	// BillingStatement routes
+	BillingStatementExport          = "/billing-statement/export"
+	BillingStatementSubscriptionGet = "/billing-statement/subscription/get"
+	BillingStatementSubscriptionSet = "/billing-statement/subscription/set"
 )
 
 const (
@@ -76,6 +89,7 @@ const (
 	AdminRefundStatus             = "/refund-status"
 	AdminRechargeGiftPolicyPath   = "/recharge-gift-policy"
 	AdminRegionList               = "/regions"
+	AdminNodeUsage                = "/node-usage"
 )
 
 const (
@@ -112,6 +126,11 @@ const (
 	WorkspaceSubscriptionCardManage      = "/workspace-subscription/card-manage"
 	WorkspaceSubscriptionCardInfo        = "/workspace-subscription/card-info"
 	WorkspaceSubscriptionInvoiceCancel   = "/workspace-subscription/invoice-cancel"
+
+	// WorkspaceBudget routes
+	WorkspaceBudgetGet    = "/workspace-budget/get"
+	WorkspaceBudgetSet    = "/workspace-budget/set"
+	WorkspaceBudgetDelete = "/workspace-budget/delete"
 )
 
 const PayNotificationPath = PaymentGroup + Notify
@@ -130,6 +149,19 @@ const (
 
 	EnvSubscriptionEnabled = "SUBSCRIPTION_ENABLED"
 	EnvKycProcessEnabled   = "KYC_PROCESS_ENABLED"
+
+	// EnvUserAlertRuleEnabled starts the evaluator of the user alert rules, enable it in one
+	// replica only as every evaluator sends the notifications.
+	EnvUserAlertRuleEnabled  = "USER_ALERT_RULE_ENABLED"
+	EnvUserAlertRuleInterval = "USER_ALERT_RULE_INTERVAL"
+	// EnvPromServiceHost and EnvVMServiceHost are the metric servers the database and launchpad
+	// rules are evaluated against, the same ones the monitor services query.
+	EnvPromServiceHost = "PROMETHEUS_SERVICE_HOST"
+	EnvVMServiceHost   = "VM_SERVICE_HOST"
+
+	// EnvBillingStatementEnabled starts the sender of the monthly billing statements, enable it in
+	// one replica only as every sender emails the statements.
+	EnvBillingStatementEnabled = "BILLING_STATEMENT_ENABLED"
 )
 
 const (
@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"time"
+
+	"github.com/labring/sealos/controllers/pkg/resources"
+)
+
+// CostForecastResp is the projected cost of the workspaces owned by the user
+type CostForecastResp struct {
+	// @Summary Cost forecast
+	// @Description Hourly, daily and monthly cost of the resources running now, and the daily cost of each workspace
+	resources.CostForecast `json:",inline" bson:",inline"`
+
+	// @Summary Usable balance
+	// @Description Balance left after deductions, including the active credits
+	UsableBalance int64 `json:"usableBalance" bson:"usableBalance" example:"100000000"`
+
+	// @Summary Runs out at
+	// @Description Estimated time the usable balance reaches zero, empty if nothing is running
+	RunsOutAt *time.Time `json:"runsOutAt,omitempty" bson:"runsOutAt,omitempty"`
+
+	// @Summary Runs out in days
+	// @Description Estimated whole days until the usable balance reaches zero, empty if nothing is running
+	RunsOutInDays *int64 `json:"runsOutInDays,omitempty" bson:"runsOutInDays,omitempty" example:"12"`
+}
+
+// NewCostForecastResp estimates when the usable balance runs out at the forecast cost.
+func NewCostForecastResp(
+	forecast *resources.CostForecast,
+	usableBalance int64,
+	now time.Time,
+) CostForecastResp {
+	resp := CostForecastResp{CostForecast: *forecast, UsableBalance: usableBalance}
+	if d, ok := forecast.RunsOutIn(usableBalance); ok {
+		runsOutAt := now.Add(d).UTC()
+		days, _ := forecast.RunsOutInDays(usableBalance)
+		resp.RunsOutAt, resp.RunsOutInDays = &runsOutAt, &days
+	}
+	return resp
+}
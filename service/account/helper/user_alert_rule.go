@@ -0,0 +1,137 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/labring/sealos/controllers/pkg/types"
+)
+
+// CreateUserAlertRuleReq represents the request to create an alert rule on a metric of a
+// database or launchpad app in the workspace
+type CreateUserAlertRuleReq struct {
+	WorkspaceInfoReq `json:",inline" bson:",inline"`
+
+	// @Summary Rule name
+	// @Description Name of the rule shown in the notifications
+	// @JSONSchema required
+	Name string `json:"name" bson:"name" binding:"required" example:"mysql cpu high"`
+
+	// @Summary Source
+	// @Description Service the metric template belongs to, database or launchpad
+	// @JSONSchema required
+	Source types.UserAlertRuleSource `json:"source" bson:"source" binding:"required" example:"database"`
+
+	// @Summary Database type
+	// @Description Type of the database, required for database rules
+	DatabaseType string `json:"databaseType,omitempty" bson:"databaseType,omitempty" example:"apecloud-mysql"`
+
+	// @Summary Resource
+	// @Description Name of the database cluster or the launchpad app
+	// @JSONSchema required
+	Resource string `json:"resource" bson:"resource" binding:"required" example:"my-mysql"`
+
+	// @Summary Template
+	// @Description Key of the metric template, see the templates endpoint
+	// @JSONSchema required
+	Template string `json:"template" bson:"template" binding:"required" example:"cpu"`
+
+	// @Summary Comparator
+	// @Description Comparator of the metric value and the threshold, one of gt, gte, lt, lte, eq and ne
+	// @JSONSchema required
+	Comparator types.UserAlertRuleComparator `json:"comparator" bson:"comparator" binding:"required" example:"gt"`
+
+	// @Summary Threshold
+	// @Description Threshold the metric value is compared with
+	// @JSONSchema required
+	Threshold *float64 `json:"threshold" bson:"threshold" binding:"required" example:"80"`
+
+	// @Summary Duration
+	// @Description Seconds the condition must hold before the rule fires, 0 by default
+	Duration int64 `json:"duration,omitempty" bson:"duration,omitempty" example:"300"`
+
+	// @Summary Severity
+	// @Description Severity of the alert, one of info, warning and critical
+	// @JSONSchema required
+	Severity types.UserAlertRuleSeverity `json:"severity" bson:"severity" binding:"required" example:"warning"`
+}
+
+func ParseCreateUserAlertRuleReq(c *gin.Context) (*CreateUserAlertRuleReq, error) {
+	req := &CreateUserAlertRuleReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	if req.Workspace == "" {
+		return nil, errors.New("workspace cannot be empty")
+	}
+	return req, nil
+}
+
+// ListUserAlertRulesReq represents the request to list the alert rules of the user in the current
+// region
+type ListUserAlertRulesReq struct {
+	AuthBase `json:",inline" bson:",inline"`
+
+	// @Summary Workspace name
+	// @Description Only list the rules of the workspace if it is set
+	Workspace string `json:"workspace,omitempty" bson:"workspace,omitempty" example:"my-workspace"`
+}
+
+func ParseListUserAlertRulesReq(c *gin.Context) (*ListUserAlertRulesReq, error) {
+	req := &ListUserAlertRulesReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	return req, nil
+}
+
+// DeleteUserAlertRulesReq represents the request to delete alert rules of the user
+type DeleteUserAlertRulesReq struct {
+	// @Summary Rule IDs
+	// @Description List of rule IDs to delete
+	// @JSONSchema required
+	IDs []uuid.UUID `json:"ids" bson:"ids" binding:"required" example:"[550e8400-e29b-41d4-a716-446655440000]"`
+
+	AuthBase `json:",inline" bson:",inline"`
+}
+
+func ParseDeleteUserAlertRulesReq(c *gin.Context) (*DeleteUserAlertRulesReq, error) {
+	req := &DeleteUserAlertRulesReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	return req, nil
+}
+
+// ToggleUserAlertRulesReq represents the request to enable or disable alert rules of the user
+type ToggleUserAlertRulesReq struct {
+	// @Summary Rule IDs
+	// @Description List of rule IDs to toggle
+	// @JSONSchema required
+	IDs []uuid.UUID `json:"ids" bson:"ids" binding:"required" example:"[550e8400-e29b-41d4-a716-446655440000]"`
+
+	// @Summary Enable flag
+	// @Description Set to true to enable, false to disable
+	// @JSONSchema required
+	IsEnabled *bool `json:"isEnabled" bson:"isEnabled" binding:"required" example:"true"`
+
+	AuthBase `json:",inline" bson:",inline"`
+}
+
+func ParseToggleUserAlertRulesReq(c *gin.Context) (*ToggleUserAlertRulesReq, error) {
+	req := &ToggleUserAlertRulesReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	return req, nil
+}
+
+// UserAlertRuleTemplatesResp lists the metric templates a rule can use
+type UserAlertRuleTemplatesResp struct {
+	// Database maps the database types to their template keys
+	Database map[string][]string `json:"database"`
+	// Launchpad are the template keys of the launchpad apps
+	Launchpad []string `json:"launchpad"`
+}
@@ -0,0 +1,40 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetWorkspaceBudgetReq represents the request to set the monthly budget of a workspace
+type SetWorkspaceBudgetReq struct {
+	WorkspaceInfoReq `json:",inline" bson:",inline"`
+
+	// @Summary Monthly limit
+	// @Description Monthly spending limit of the workspace, in the same unit as the billing amount
+	// @JSONSchema required
+	MonthlyLimit int64 `json:"monthlyLimit" bson:"monthlyLimit" binding:"required" example:"100000000"`
+
+	// @Summary Alert thresholds
+	// @Description Used percentages of the monthly limit to alert at, 50, 80 and 100 by default
+	AlertThresholds []int64 `json:"alertThresholds,omitempty" bson:"alertThresholds,omitempty" example:"50,80,100"`
+
+	// @Summary Soft stop
+	// @Description Suspend the workspace once the monthly limit is used up
+	SoftStop bool `json:"softStop" bson:"softStop" example:"true"`
+}
+
+func ParseSetWorkspaceBudgetReq(c *gin.Context) (*SetWorkspaceBudgetReq, error) {
+	req := &SetWorkspaceBudgetReq{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		return nil, fmt.Errorf("bind json error: %w", err)
+	}
+	if req.Workspace == "" {
+		return nil, errors.New("workspace cannot be empty")
+	}
+	if req.MonthlyLimit <= 0 {
+		return nil, errors.New("monthly limit must be greater than zero")
+	}
+	return req, nil
+}
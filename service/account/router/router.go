@@ -69,6 +69,7 @@ func RegisterPayRouter() {
 		POST(helper.GetAppTypeList, api.GetAppTypeList).
 		POST(helper.GetBasicCostDistribution, api.GetBasicCostDistribution).
 		POST(helper.GetAppCostTimeRange, api.GetAppCostTimeRange).
+		POST(helper.GetCostForecast, api.GetCostForecast).
 		POST(helper.GetInvoice, api.GetInvoice).
 		POST(helper.ApplyInvoice, api.ApplyInvoice).
 		POST(helper.SetStatusInvoice, api.SetStatusInvoice).
@@ -83,6 +84,16 @@ func RegisterPayRouter() {
 		POST(helper.UserAlertNotificationAccountList, api.ListUserAlertNotificationAccounts).
 		POST(helper.UserAlertNotificationAccountDelete, api.DeleteUserAlertNotificationAccount).
 		POST(helper.UserAlertNotificationAccountToggle, api.ToggleUserAlertNotificationAccounts).
+		// UserAlertRule routes
+		POST(helper.UserAlertRuleTemplates, api.GetUserAlertRuleTemplates).
+		POST(helper.UserAlertRuleCreate, api.CreateUserAlertRule).
+		POST(helper.UserAlertRuleList, api.ListUserAlertRules).
+		POST(helper.UserAlertRuleDelete, api.DeleteUserAlertRules).
+		POST(helper.UserAlertRuleToggle, api.ToggleUserAlertRules).
+		// BillingStatement routes
+		POST(helper.BillingStatementExport, api.ExportBillingStatement).
+		POST(helper.BillingStatementSubscriptionGet, api.GetBillingStatementSubscription).
+		POST(helper.BillingStatementSubscriptionSet, api.SetBillingStatementSubscription).
 		// WorkspaceSubscription routes
 		POST(helper.WorkspaceSubscriptionInfo, api.GetWorkspaceSubscriptionInfo).
 		POST(helper.WorkspaceSubscriptionList, api.GetWorkspaceSubscriptionList).
@@ -97,7 +108,11 @@ func RegisterPayRouter() {
 		POST(helper.WorkspaceSubscriptionPlans, api.GetWorkspaceSubscriptionPlans).
 		POST(helper.WorkspaceSubscriptionCardManage, api.CreateWorkspaceSubscriptionSetupIntent).
 		POST(helper.WorkspaceSubscriptionCardInfo, api.GetWorkspaceSubscriptionCardInfo).
-		POST(helper.WorkspaceSubscriptionInvoiceCancel, api.CancelWorkspaceSubscriptionInvoice)
+		POST(helper.WorkspaceSubscriptionInvoiceCancel, api.CancelWorkspaceSubscriptionInvoice).
+		// WorkspaceBudget routes
+		POST(helper.WorkspaceBudgetGet, api.GetWorkspaceBudget).
+		POST(helper.WorkspaceBudgetSet, api.SetWorkspaceBudget).
+		POST(helper.WorkspaceBudgetDelete, api.DeleteWorkspaceBudget)
 	adminGroup := router.Group(helper.AdminGroup).
 		GET(helper.AdminGetAccountWithWorkspace, api.AdminGetAccountWithWorkspaceID).
 		GET(helper.AdminGetUserRealNameInfo, api.AdminGetUserRealNameInfo).
@@ -112,6 +127,7 @@ func RegisterPayRouter() {
 		GET(helper.AdminRefundStatus, api.AdminGetRefundStatus).
 		GET(helper.AdminRechargeGiftPolicyPath, api.AdminGetRechargeGiftPolicy).
 		GET(helper.AdminRegionList, api.AdminListRegions).
+		GET(helper.AdminNodeUsage, api.AdminGetNodeUsage).
 		GET(helper.AdminWorkspaceSubscriptionList, api.AdminWorkspaceSubscriptionListGET).
 		GET(helper.AdminSubscriptionPlans, api.AdminSubscriptionPlansGET).
 		POST(helper.AdminCreateCorporate, api.AdminCreateCorporate).
@@ -199,6 +215,27 @@ func RegisterPayRouter() {
 	workspaceSub := api.NewWorkspaceSubscriptionProcessor()
 	workspaceSub.Start(ctx)
 
+	// evaluate user alert rules
+	if os.Getenv(helper.EnvUserAlertRuleEnabled) == _true {
+		interval, err := time.ParseDuration(
+			env.GetEnvWithDefault(helper.EnvUserAlertRuleInterval, "1m"),
+		)
+		if err != nil || interval <= 0 {
+			logrus.Errorf(
+				"Failed to parse %s: %v, using default 1m",
+				helper.EnvUserAlertRuleInterval,
+				err,
+			)
+			interval = time.Minute
+		}
+		api.NewUserAlertRuleEvaluator(interval).Start(rootCtx)
+	}
+
+	// email monthly billing statements
+	if os.Getenv(helper.EnvBillingStatementEnabled) == _true {
+		api.NewBillingStatementSender(time.Hour).Start(rootCtx)
+	}
+
 	// Wait for interrupt signal.
 	<-rootCtx.Done()
 
@@ -14,6 +14,7 @@ replace (
 require (
 	github.com/alipay/global-open-sdk-go v1.2.11
 	github.com/google/uuid v1.6.0
+	golang.org/x/sync v0.7.0
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.28.4
 	k8s.io/apimachinery v0.28.4
@@ -51,27 +51,8 @@ func Request(addr string, params *bytes.Buffer) ([]byte, error) {
 }
 
 func GetQuery(query *api.VMRequest) (string, error) {
-	var result string
-	switch query.Type {
-	case "cpu":
-		result = "round(sum(node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) / sum(cluster:namespace:pod_cpu:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) * 100,0.01)"
-	case "memory":
-		result = "round(sum(node_namespace_pod_container:container_memory_working_set_bytes{namespace=~\"$namespace\",pod=~\"$pod.*\",container!=\"\",image!=\"\"}) by(pod) / sum(cluster:namespace:pod_memory:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod)* 100, 0.01)"
-	case "average_cpu":
-		result = "avg(round(sum(node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) / sum(cluster:namespace:pod_cpu:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) * 100,0.01))"
-	case "average_memory":
-		result = "avg(round(sum(node_namespace_pod_container:container_memory_working_set_bytes{namespace=~\"$namespace\",pod=~\"$pod.*\",container!=\"\",image!=\"\"}) by(pod) / sum(cluster:namespace:pod_memory:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) * 100, 0.01))"
-	case "storage":
-		result = "round((max by (persistentvolumeclaim,namespace) (kubelet_volume_stats_used_bytes {namespace=~\"$namespace\", persistentvolumeclaim=~\"@persistentvolumeclaim\"})) / (max by (persistentvolumeclaim,namespace) (kubelet_volume_stats_capacity_bytes {namespace=~\"$namespace\", persistentvolumeclaim=~\"@persistentvolumeclaim\"})) * 100, 0.01)"
-	case "gpu":
-		result = "Device_utilization_desc_of_container{podnamespace=~\"$namespace\",podname=~\"$pod.*\"}"
-	case "gpu_memory":
-		result = "sum without(data) (Device_memory_desc_of_container{podnamespace=~\"$namespace\",podname=~\"$pod.*\"})"
-	case "network_service_request_count":
-		result = "envoy_cluster_upstream_rq{cluster_name=\"$cluster\"}"
-	case "network_service_request_percent":
-		result = "envoy_upstream_request_percentage{cluster_name=\"$cluster\"}"
-	default:
+	result, ok := api.Launchpad[query.Type]
+	if !ok {
 		log.Println(query.Type)
 	}
 	if isNetworkServiceRequest(query.Type) {
@@ -10,21 +10,37 @@ import (
 	"github.com/labring/sealos/service/launchpad/request"
 	"github.com/labring/sealos/service/pkg/api"
 	"github.com/labring/sealos/service/pkg/auth"
+	"github.com/labring/sealos/service/pkg/cache"
 )
 
 type VMServer struct {
 	Config *Config
+	caches *cache.Caches[*api.LaunchpadQueryResult]
 }
 
 func NewVMServer(c *Config) (*VMServer, error) {
 	vs := &VMServer{
 		Config: c,
+		caches: cache.NewCaches[*api.LaunchpadQueryResult](c.Cache),
 	}
 	return vs, nil
 }
 
 func (vs *VMServer) Authenticate(vr *api.VMRequest) error {
-	return auth.Authenticate(vr.NS, vr.Pwd)
+	return vs.caches.Auth.Authenticate(vr.NS, vr.Pwd, auth.Authenticate)
+}
+
+// CachedDBReq shares the result of DBReq between identical queries of the namespace, the range
+// is rounded to the step so the refreshes of a dashboard hit the cache.
+func (vs *VMServer) CachedDBReq(vr *api.VMRequest) (*api.LaunchpadQueryResult, error) {
+	vr.Range.Start, vr.Range.End = cache.RoundRange(vr.Range.Start, vr.Range.End, vr.Range.Step)
+	key := cache.Key(
+		vr.NS, vr.Type, vr.LaunchPadName, vr.Service, vr.Port, vr.PvcName,
+		vr.Range.Start, vr.Range.End, vr.Range.Step, vr.Range.Time,
+	)
+	return vs.caches.Query.Get(key, func() (*api.LaunchpadQueryResult, error) {
+		return vs.DBReq(vr)
+	})
 }
 
 func (vs *VMServer) DBReq(vr *api.VMRequest) (*api.LaunchpadQueryResult, error) {
@@ -104,6 +120,11 @@ func (vs *VMServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	switch req.URL.Path {
 	case pathPrefix + "/query":
 		vs.doReqNew(rw, req)
+	case pathPrefix + "/metrics":
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := vs.caches.WriteMetrics(rw); err != nil {
+			log.Printf("Write metrics failed (%s)\n", err)
+		}
 	default:
 		http.Error(rw, "Not found", http.StatusNotFound)
 		return
@@ -128,7 +149,7 @@ func (vs *VMServer) doReqNew(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	res, err := vs.DBReq(vr)
+	res, err := vs.CachedDBReq(vr)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("Query failed (%s)", err), http.StatusInternalServerError)
 		log.Printf("Query failed (%s)\n", err)
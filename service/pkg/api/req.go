@@ -112,6 +112,53 @@ type VlogsDatabaseRequest struct {
 	EndTime     string   `json:"endTime,omitempty"`
 }
 
+// Aggregations of VlogsAggregateRequest.
+const (
+	// VlogsAggregateVolume counts the logs per time bucket
+	VlogsAggregateVolume = "volume"
+	// VlogsAggregateErrorRate counts all and error logs per time bucket, and their ratio
+	VlogsAggregateErrorRate = "errorRate"
+	// VlogsAggregateLevel counts the logs per time bucket and level of JSON logs
+	VlogsAggregateLevel = "level"
+	// VlogsAggregatePod counts the logs per time bucket and pod
+	VlogsAggregatePod = "pod"
+	// VlogsAggregatePattern returns the most frequent messages with numbers and UUIDs replaced
+	VlogsAggregatePattern = "pattern"
+)
+
+// VlogsAggregateRequest aggregates the logs matching the launchpad filters, limit and
+// number mode are ignored.
+type VlogsAggregateRequest struct {
+	VlogsLaunchpadRequest
+	Aggregate string `json:"aggregate"`
+	// Step is the time bucket like 1m, defaults to 1m
+	Step string `json:"step,omitempty"`
+	// TopN is the number of patterns, defaults to 10
+	TopN string `json:"topN,omitempty"`
+}
+
+type VlogsAggregateResponse struct {
+	Aggregate string         `json:"aggregate"`
+	Step      string         `json:"step,omitempty"`
+	Series    []VlogsSeries  `json:"series,omitempty"`
+	Patterns  []VlogsPattern `json:"patterns,omitempty"`
+}
+
+type VlogsSeries struct {
+	Name   string       `json:"name"`
+	Points []VlogsPoint `json:"points"`
+}
+
+type VlogsPoint struct {
+	Time  string  `json:"time"`
+	Value float64 `json:"value"`
+}
+
+type VlogsPattern struct {
+	Pattern string `json:"pattern"`
+	Count   int64  `json:"count"`
+}
+
 type VlogsLaunchpadResponse struct {
 	Time      string `json:"_time"`
 	Message   string `json:"_msg"`
@@ -209,6 +256,21 @@ var (
 		"cpu":    "round(sum(node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{namespace=~\"#\",pod=~\"@-milvus-.*\"}) by (pod) / sum(cluster:namespace:pod_cpu:active:kube_pod_container_resource_limits{namespace=~\"#\",pod=~\"@-milvus-.*\"}) by (pod)*100,0.01)",
 		"memory": "round(sum(container_memory_working_set_bytes{job=\"kubelet\", metrics_path=\"/metrics/cadvisor\",namespace=~\"#\",container!=\"\", image!=\"\",pod=~\"@-milvus-.*\"}) by(pod) / sum(cluster:namespace:pod_memory:active:kube_pod_container_resource_limits{namespace=~\"#\", pod=~\"@-milvus-.*\"}) by (pod) * 100, 0.01)",
 	}
+
+	// Launchpad are the templates of the launchpad app metrics, $namespace, $pod,
+	// @persistentvolumeclaim and $cluster are replaced by the namespace, the pod name prefix of
+	// the app, the pvc name and the envoy cluster of the service.
+	Launchpad = map[string]string{
+		"cpu":                             "round(sum(node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) / sum(cluster:namespace:pod_cpu:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) * 100,0.01)",
+		"memory":                          "round(sum(node_namespace_pod_container:container_memory_working_set_bytes{namespace=~\"$namespace\",pod=~\"$pod.*\",container!=\"\",image!=\"\"}) by(pod) / sum(cluster:namespace:pod_memory:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod)* 100, 0.01)",
+		"average_cpu":                     "avg(round(sum(node_namespace_pod_container:container_cpu_usage_seconds_total:sum_irate{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) / sum(cluster:namespace:pod_cpu:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) * 100,0.01))",
+		"average_memory":                  "avg(round(sum(node_namespace_pod_container:container_memory_working_set_bytes{namespace=~\"$namespace\",pod=~\"$pod.*\",container!=\"\",image!=\"\"}) by(pod) / sum(cluster:namespace:pod_memory:active:kube_pod_container_resource_limits{namespace=~\"$namespace\",pod=~\"$pod.*\"}) by (pod) * 100, 0.01))",
+		"storage":                         "round((max by (persistentvolumeclaim,namespace) (kubelet_volume_stats_used_bytes {namespace=~\"$namespace\", persistentvolumeclaim=~\"@persistentvolumeclaim\"})) / (max by (persistentvolumeclaim,namespace) (kubelet_volume_stats_capacity_bytes {namespace=~\"$namespace\", persistentvolumeclaim=~\"@persistentvolumeclaim\"})) * 100, 0.01)",
+		"gpu":                             "Device_utilization_desc_of_container{podnamespace=~\"$namespace\",podname=~\"$pod.*\"}",
+		"gpu_memory":                      "sum without(data) (Device_memory_desc_of_container{podnamespace=~\"$namespace\",podname=~\"$pod.*\"})",
+		"network_service_request_count":   "envoy_cluster_upstream_rq{cluster_name=\"$cluster\"}",
+		"network_service_request_percent": "envoy_upstream_request_percentage{cluster_name=\"$cluster\"}",
+	}
 )
 
 var (
@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultQueryTTL = 15 * time.Second
+	DefaultAuthTTL  = time.Minute
+	// maxEntries bounds the memory of a cache, expired entries are evicted first
+	maxEntries = 10000
+)
+
+// Config is the cache section of the server config, zero values use the defaults and a
+// negative TTL disables the cache.
+type Config struct {
+	QueryTTL time.Duration `yaml:"queryTTL"`
+	AuthTTL  time.Duration `yaml:"authTTL"`
+}
+
+func (c Config) queryTTL() time.Duration {
+	if c.QueryTTL == 0 {
+		return DefaultQueryTTL
+	}
+	return c.QueryTTL
+}
+
+func (c Config) authTTL() time.Duration {
+	if c.AuthTTL == 0 {
+		return DefaultAuthTTL
+	}
+	return c.AuthTTL
+}
+
+// Stats counts the lookups of a cache, a coalesced lookup waited for an identical one in
+// flight and is counted as a miss as well.
+type Stats struct {
+	hits      atomic.Int64
+	misses    atomic.Int64
+	coalesced atomic.Int64
+}
+
+type entry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// Cache keeps the results of the loads for the TTL and runs one load for identical keys in
+// flight. Errors are never cached.
+type Cache[T any] struct {
+	Stats
+	name  string
+	ttl   time.Duration
+	now   func() time.Time
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]entry[T]
+}
+
+func New[T any](name string, ttl time.Duration) *Cache[T] {
+	return &Cache[T]{
+		name:    name,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]entry[T]),
+	}
+}
+
+// Get returns the cached value of key, or loads it.
+func (c *Cache[T]) Get(key string, load func() (T, error)) (T, error) {
+	if c.ttl < 0 {
+		c.misses.Add(1)
+		return load()
+	}
+	if value, ok := c.lookup(key); ok {
+		c.hits.Add(1)
+		return value, nil
+	}
+	c.misses.Add(1)
+	value, err, shared := c.group.Do(key, func() (any, error) {
+		value, err := load()
+		if err == nil {
+			c.store(key, value)
+		}
+		return value, err
+	})
+	if shared {
+		c.coalesced.Add(1)
+	}
+	return value.(T), err
+}
+
+func (c *Cache[T]) lookup(key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expiresAt) {
+		var zero T
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *Cache[T]) store(key string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= maxEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry[T]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// AuthCache remembers the kubeconfigs that passed the authentication of a namespace for a
+// while. Failed authentications are never cached, so a revoked user is rejected at the latest
+// after the TTL.
+type AuthCache struct {
+	cache *Cache[struct{}]
+}
+
+func NewAuthCache(ttl time.Duration) *AuthCache {
+	return &AuthCache{cache: New[struct{}]("auth", ttl)}
+}
+
+// Authenticate runs authenticate unless the kubeconfig passed it for the namespace recently.
+func (a *AuthCache) Authenticate(
+	namespace, kubeconfig string,
+	authenticate func(namespace, kubeconfig string) error,
+) error {
+	// the kubeconfig holds credentials, only its hash is kept
+	sum := sha256.Sum256([]byte(kubeconfig))
+	key := namespace + "/" + hex.EncodeToString(sum[:])
+	_, err := a.cache.Get(key, func() (struct{}, error) {
+		return struct{}{}, authenticate(namespace, kubeconfig)
+	})
+	return err
+}
+
+// Caches are the query and auth caches of a server.
+type Caches[T any] struct {
+	Query *Cache[T]
+	Auth  *AuthCache
+}
+
+func NewCaches[T any](c Config) *Caches[T] {
+	return &Caches[T]{
+		Query: New[T]("query", c.queryTTL()),
+		Auth:  NewAuthCache(c.authTTL()),
+	}
+}
+
+// WriteMetrics writes the hit and miss counters in the Prometheus text format.
+func (c *Caches[T]) WriteMetrics(w io.Writer) error {
+	for _, m := range []struct {
+		name, help string
+		value      func(s *Stats) int64
+	}{
+		{"cache_hits_total", "Lookups served from the cache.", func(s *Stats) int64 { return s.hits.Load() }},
+		{"cache_misses_total", "Lookups not found in the cache.", func(s *Stats) int64 { return s.misses.Load() }},
+		{"cache_coalesced_total", "Missed lookups that waited for an identical one in flight.",
+			func(s *Stats) int64 { return s.coalesced.Load() }},
+	} {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name); err != nil {
+			return err
+		}
+		for _, s := range []struct {
+			name  string
+			stats *Stats
+		}{
+			{c.Query.name, &c.Query.Stats},
+			{c.Auth.cache.name, &c.Auth.cache.Stats},
+		} {
+			if _, err := fmt.Fprintf(w, "%s{cache=%q} %d\n", m.name, s.name, m.value(s.stats)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RoundRange rounds the start and end of a range query down to a multiple of step, so the
+// refreshes of a dashboard within one step share the same range. Values that are not unix
+// seconds are returned as is.
+func RoundRange(start, end, step string) (string, string) {
+	stepSeconds, ok := parseStep(step)
+	if !ok {
+		return start, end
+	}
+	return roundTime(start, stepSeconds), roundTime(end, stepSeconds)
+}
+
+func roundTime(value string, step float64) string {
+	t, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatInt(int64(math.Floor(t/step)*step), 10)
+}
+
+func parseStep(step string) (float64, bool) {
+	if step == "" {
+		return 0, false
+	}
+	if s, err := strconv.ParseFloat(step, 64); err == nil {
+		return s, s > 0
+	}
+	d, err := time.ParseDuration(step)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d.Seconds(), true
+}
+
+// Key joins the parts of a cache key.
+func Key(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
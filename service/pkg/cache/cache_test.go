@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheGet(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := New[int]("query", 10*time.Second)
+	c.now = func() time.Time { return now }
+
+	var loads atomic.Int32
+	load := func() (int, error) { return int(loads.Add(1)), nil }
+	if v, _ := c.Get("a", load); v != 1 {
+		t.Fatalf("first get = %d, want 1", v)
+	}
+	if v, _ := c.Get("a", load); v != 1 {
+		t.Fatalf("cached get = %d, want 1", v)
+	}
+	now = now.Add(10 * time.Second)
+	if v, _ := c.Get("a", load); v != 2 {
+		t.Fatalf("expired get = %d, want 2", v)
+	}
+	if _, err := c.Get("b", func() (int, error) { return 0, errors.New("boom") }); err == nil {
+		t.Fatal("expected the load error")
+	}
+	if v, _ := c.Get("b", load); v != 3 {
+		t.Fatalf("errors must not be cached, got %d", v)
+	}
+	if c.hits.Load() != 1 || c.misses.Load() != 4 {
+		t.Fatalf("hits = %d, misses = %d", c.hits.Load(), c.misses.Load())
+	}
+}
+
+func TestCacheCoalesce(t *testing.T) {
+	c := New[int]("query", time.Minute)
+	release := make(chan struct{})
+	var loads atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get("a", func() (int, error) {
+				loads.Add(1)
+				<-release
+				return 42, nil
+			})
+			if err != nil || v != 42 {
+				t.Errorf("get = %d, %v", v, err)
+			}
+		}()
+	}
+	// wait for the first load to start, the others either wait for it or hit the cache
+	for loads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if loads.Load() != 1 {
+		t.Fatalf("loads = %d, want 1", loads.Load())
+	}
+}
+
+func TestAuthCache(t *testing.T) {
+	a := NewAuthCache(time.Minute)
+	var calls int
+	authenticate := func(namespace, kubeconfig string) error {
+		calls++
+		if kubeconfig != "valid" {
+			return errors.New("denied")
+		}
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		if err := a.Authenticate("ns-a", "valid", authenticate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("positive results should be cached, calls = %d", calls)
+	}
+	if err := a.Authenticate("ns-b", "valid", authenticate); err != nil || calls != 2 {
+		t.Fatalf("the namespace is part of the key, calls = %d", calls)
+	}
+	for i := 0; i < 2; i++ {
+		if err := a.Authenticate("ns-a", "invalid", authenticate); err == nil {
+			t.Fatal("expected the authentication to fail")
+		}
+	}
+	if calls != 4 {
+		t.Fatalf("failures must not be cached, calls = %d", calls)
+	}
+}
+
+func TestRoundRange(t *testing.T) {
+	tests := []struct {
+		start, end, step   string
+		wantStart, wantEnd string
+	}{
+		{"1700000017", "1700003617", "60s", "1699999980", "1700003580"},
+		{"1700000017.5", "1700003617", "60", "1699999980", "1700003580"},
+		{"1700000017", "1700003617", "", "1700000017", "1700003617"},
+		{"2023-11-14T22:13:37Z", "1700003617", "1m", "2023-11-14T22:13:37Z", "1700003580"},
+	}
+	for _, tt := range tests {
+		start, end := RoundRange(tt.start, tt.end, tt.step)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("RoundRange(%s, %s, %s) = %s, %s, want %s, %s",
+				tt.start, tt.end, tt.step, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	c := NewCaches[int](Config{})
+	_, _ = c.Query.Get("a", func() (int, error) { return 1, nil })
+	_, _ = c.Query.Get("a", func() (int, error) { return 1, nil })
+	var sb strings.Builder
+	if err := c.WriteMetrics(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`cache_hits_total{cache="query"} 1`,
+		`cache_misses_total{cache="query"} 1`,
+		`cache_misses_total{cache="auth"} 0`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, sb.String())
+		}
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/labring/sealos/service/pkg/cache"
 	"gopkg.in/yaml.v2"
 )
 
 type Config struct {
-	Server ServeConfig `yaml:"server"`
+	Server ServeConfig  `yaml:"server"`
+	Cache  cache.Config `yaml:"cache"`
 }
 
 type ServeConfig struct {
@@ -10,22 +10,38 @@ import (
 
 	"github.com/labring/sealos/service/pkg/api"
 	"github.com/labring/sealos/service/pkg/auth"
+	"github.com/labring/sealos/service/pkg/cache"
 	"github.com/labring/sealos/service/pkg/request"
 )
 
 type PromServer struct {
 	Config *Config
+	caches *cache.Caches[*api.QueryResult]
 }
 
 func NewPromServer(c *Config) (*PromServer, error) {
 	ps := &PromServer{
 		Config: c,
+		caches: cache.NewCaches[*api.QueryResult](c.Cache),
 	}
 	return ps, nil
 }
 
 func (ps *PromServer) Authenticate(pr *api.PromRequest) error {
-	return auth.Authenticate(pr.NS, pr.Pwd)
+	return ps.caches.Auth.Authenticate(pr.NS, pr.Pwd, auth.Authenticate)
+}
+
+// CachedDBReq shares the result of DBReq between identical queries of the namespace, the range
+// is rounded to the step so the refreshes of a dashboard hit the cache.
+func (ps *PromServer) CachedDBReq(pr *api.PromRequest) (*api.QueryResult, error) {
+	pr.Range.Start, pr.Range.End = cache.RoundRange(pr.Range.Start, pr.Range.End, pr.Range.Step)
+	key := cache.Key(
+		pr.NS, pr.Type, pr.Query, pr.Cluster,
+		pr.Range.Start, pr.Range.End, pr.Range.Step, pr.Range.Time,
+	)
+	return ps.caches.Query.Get(key, func() (*api.QueryResult, error) {
+		return ps.DBReq(pr)
+	})
 }
 
 func (ps *PromServer) Request(pr *api.PromRequest) (*api.QueryResult, error) {
@@ -106,6 +122,11 @@ func (ps *PromServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		ps.doReqPre(rw, req)
 	case pathPrefix + "/q":
 		ps.doReqNew(rw, req)
+	case pathPrefix + "/metrics":
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		if err := ps.caches.WriteMetrics(rw); err != nil {
+			log.Printf("Write metrics failed (%s)\n", err)
+		}
 	default:
 		http.Error(rw, "Not found", http.StatusNotFound)
 		return
@@ -130,7 +151,7 @@ func (ps *PromServer) doReqNew(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	res, err := ps.DBReq(pr)
+	res, err := ps.CachedDBReq(pr)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("Query failed (%s)", err), http.StatusInternalServerError)
 		log.Printf("Query failed (%s)\n", err)
@@ -0,0 +1,214 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/labring/sealos/service/pkg/api"
+)
+
+const (
+	defaultAggregateStep = "1m"
+	defaultTopN          = 10
+	maxTopN              = 100
+
+	logsTotalField   = "logs_total"
+	errorsTotalField = "errors_total"
+	errorRateSeries  = "error_rate"
+	unknownLevel     = "unknown"
+)
+
+var aggregateStepRegexp = regexp.MustCompile(`^[1-9][0-9]*[smhd]$`)
+
+// errorFilter matches the logs counted as errors by the error rate
+const errorFilter = `stream:="stderr" OR i(error) OR i(fatal) OR i(panic)`
+
+// patternPipes normalize the messages so the logs of one statement share a pattern
+const patternPipes = `| replace_regexp ("[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}", "<uuid>") at _msg ` +
+	`| replace_regexp ("0x[0-9a-fA-F]+", "<hex>") at _msg ` +
+	`| replace_regexp ("[0-9]+", "<num>") at _msg `
+
+type AggregateQuery struct {
+	query string
+}
+
+// GetAggregateQuery builds the stats query of the aggregation over the launchpad filters.
+func (a *AggregateQuery) GetAggregateQuery(req *api.VlogsAggregateRequest) (string, error) {
+	a.query = ""
+	step, err := AggregateStep(req)
+	if err != nil {
+		return "", err
+	}
+	if err := a.generateFilterQuery(req); err != nil {
+		return "", err
+	}
+	switch req.Aggregate {
+	case api.VlogsAggregateVolume:
+		a.query += fmt.Sprintf("| stats by (_time:%s) count() %s | sort by (_time)",
+			step, logsTotalField)
+	case api.VlogsAggregateErrorRate:
+		a.query += fmt.Sprintf(
+			"| stats by (_time:%s) count() %s, count() if (%s) %s | sort by (_time)",
+			step,
+			logsTotalField,
+			errorFilter,
+			errorsTotalField,
+		)
+	case api.VlogsAggregateLevel:
+		a.query += fmt.Sprintf(
+			"| unpack_json fields (level) | stats by (_time:%s, level) count() %s | sort by (_time)",
+			step,
+			logsTotalField,
+		)
+	case api.VlogsAggregatePod:
+		a.query += fmt.Sprintf("| stats by (_time:%s, pod) count() %s | sort by (_time)",
+			step, logsTotalField)
+	case api.VlogsAggregatePattern:
+		topN, err := aggregateTopN(req.TopN)
+		if err != nil {
+			return "", err
+		}
+		a.query += patternPipes + fmt.Sprintf(
+			"| stats by (_msg) count() %s | sort by (%s desc) | limit %d",
+			logsTotalField, logsTotalField, topN)
+	default:
+		return "", fmt.Errorf("invalid aggregate: %s", req.Aggregate)
+	}
+	return a.query, nil
+}
+
+// generateFilterQuery reuses the filters of the launchpad log list, the aggregation replaces
+// its limit.
+func (a *AggregateQuery) generateFilterQuery(req *api.VlogsAggregateRequest) error {
+	filters := req.VlogsLaunchpadRequest
+	filters.NumberMode = ""
+	var v VLogsQuery
+	v.generateKeywordQuery(&filters)
+	v.generateStreamQuery(&filters)
+	v.generateCommonQuery(&filters)
+	if err := v.generateJSONQuery(&filters); err != nil {
+		return err
+	}
+	a.query = v.query
+	return nil
+}
+
+// AggregateStep returns the time bucket of the request, 1m if it is not set.
+func AggregateStep(req *api.VlogsAggregateRequest) (string, error) {
+	if req.Step == "" {
+		return defaultAggregateStep, nil
+	}
+	if !aggregateStepRegexp.MatchString(req.Step) {
+		return "", fmt.Errorf("invalid step: %s", req.Step)
+	}
+	return req.Step, nil
+}
+
+func aggregateTopN(s string) (int, error) {
+	if s == "" {
+		return defaultTopN, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > maxTopN {
+		return 0, fmt.Errorf("invalid topN: %s, must be between 1 and %d", s, maxTopN)
+	}
+	return n, nil
+}
+
+// ParseAggregateResult converts the stats rows returned by VictoriaLogs to typed series.
+func ParseAggregateResult(
+	req *api.VlogsAggregateRequest,
+	body []byte,
+) (*api.VlogsAggregateResponse, error) {
+	resp := &api.VlogsAggregateResponse{Aggregate: req.Aggregate}
+	if req.Aggregate != api.VlogsAggregatePattern {
+		step, err := AggregateStep(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Step = step
+	}
+	series := make(map[string]*api.VlogsSeries)
+	var names []string
+	addPoint := func(name, time string, value float64) {
+		s, ok := series[name]
+		if !ok {
+			s = &api.VlogsSeries{Name: name, Points: []api.VlogsPoint{}}
+			series[name] = s
+			names = append(names, name)
+		}
+		s.Points = append(s.Points, api.VlogsPoint{Time: time, Value: value})
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		row := map[string]string{}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse stats row: %w", err)
+		}
+		total, err := parseCount(row, logsTotalField)
+		if err != nil {
+			return nil, err
+		}
+		time := row["_time"]
+		switch req.Aggregate {
+		case api.VlogsAggregateVolume:
+			addPoint(logsTotalField, time, float64(total))
+		case api.VlogsAggregateErrorRate:
+			errors, err := parseCount(row, errorsTotalField)
+			if err != nil {
+				return nil, err
+			}
+			rate := 0.0
+			if total > 0 {
+				rate = float64(errors) / float64(total)
+			}
+			addPoint(logsTotalField, time, float64(total))
+			addPoint(errorsTotalField, time, float64(errors))
+			addPoint(errorRateSeries, time, rate)
+		case api.VlogsAggregateLevel:
+			level := row["level"]
+			if level == "" {
+				level = unknownLevel
+			}
+			addPoint(level, time, float64(total))
+		case api.VlogsAggregatePod:
+			addPoint(row["pod"], time, float64(total))
+		case api.VlogsAggregatePattern:
+			resp.Patterns = append(resp.Patterns, api.VlogsPattern{
+				Pattern: row["_msg"],
+				Count:   total,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+	if req.Aggregate != api.VlogsAggregateVolume && req.Aggregate != api.VlogsAggregateErrorRate {
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		resp.Series = append(resp.Series, *series[name])
+	}
+	return resp, nil
+}
+
+func parseCount(row map[string]string, field string) (int64, error) {
+	value, ok := row[field]
+	if !ok {
+		return 0, fmt.Errorf("stats row has no %s", field)
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", field, value, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,167 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos/service/pkg/api"
+)
+
+func TestAggregateQuery_GetAggregateQuery(t *testing.T) {
+	filters := api.VlogsLaunchpadRequest{
+		Namespace:  "ns-a",
+		App:        "hello",
+		Time:       "1h",
+		NumberMode: "false",
+		Limit:      "10",
+	}
+	const prefix = "{namespace='ns-a'} _time:'1h' app:='hello' "
+	tests := []struct {
+		name    string
+		req     *api.VlogsAggregateRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "volume with default step",
+			req: &api.VlogsAggregateRequest{
+				VlogsLaunchpadRequest: filters,
+				Aggregate:             api.VlogsAggregateVolume,
+			},
+			want: prefix + "| stats by (_time:1m) count() logs_total | sort by (_time)",
+		},
+		{
+			name: "error rate",
+			req: &api.VlogsAggregateRequest{
+				VlogsLaunchpadRequest: filters,
+				Aggregate:             api.VlogsAggregateErrorRate,
+				Step:                  "5m",
+			},
+			want: prefix + "| stats by (_time:5m) count() logs_total, " +
+				"count() if (stream:=\"stderr\" OR i(error) OR i(fatal) OR i(panic)) errors_total " +
+				"| sort by (_time)",
+		},
+		{
+			name: "pod",
+			req: &api.VlogsAggregateRequest{
+				VlogsLaunchpadRequest: filters,
+				Aggregate:             api.VlogsAggregatePod,
+				Step:                  "1h",
+			},
+			want: prefix + "| stats by (_time:1h, pod) count() logs_total | sort by (_time)",
+		},
+		{
+			name: "pattern",
+			req: &api.VlogsAggregateRequest{
+				VlogsLaunchpadRequest: filters,
+				Aggregate:             api.VlogsAggregatePattern,
+				TopN:                  "5",
+			},
+			want: prefix + patternPipes +
+				"| stats by (_msg) count() logs_total | sort by (logs_total desc) | limit 5",
+		},
+		{
+			name: "invalid step",
+			req: &api.VlogsAggregateRequest{
+				VlogsLaunchpadRequest: filters,
+				Aggregate:             api.VlogsAggregateVolume,
+				Step:                  "1m) | delete",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid topN",
+			req: &api.VlogsAggregateRequest{
+				VlogsLaunchpadRequest: filters,
+				Aggregate:             api.VlogsAggregatePattern,
+				TopN:                  "1000",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown aggregate",
+			req:     &api.VlogsAggregateRequest{VlogsLaunchpadRequest: filters, Aggregate: "sum"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a AggregateQuery
+			got, err := a.GetAggregateQuery(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetAggregateQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetAggregateQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAggregateResult(t *testing.T) {
+	body := strings.Join([]string{
+		`{"_time":"2024-05-10T10:00:00Z","logs_total":"4","errors_total":"1"}`,
+		`{"_time":"2024-05-10T10:01:00Z","logs_total":"0","errors_total":"0"}`,
+		``,
+	}, "\n")
+	got, err := ParseAggregateResult(
+		&api.VlogsAggregateRequest{Aggregate: api.VlogsAggregateErrorRate},
+		[]byte(body),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &api.VlogsAggregateResponse{
+		Aggregate: api.VlogsAggregateErrorRate,
+		Step:      "1m",
+		Series: []api.VlogsSeries{
+			{Name: "logs_total", Points: []api.VlogsPoint{
+				{Time: "2024-05-10T10:00:00Z", Value: 4}, {Time: "2024-05-10T10:01:00Z", Value: 0},
+			}},
+			{Name: "errors_total", Points: []api.VlogsPoint{
+				{Time: "2024-05-10T10:00:00Z", Value: 1}, {Time: "2024-05-10T10:01:00Z", Value: 0},
+			}},
+			{Name: "error_rate", Points: []api.VlogsPoint{
+				{
+					Time:  "2024-05-10T10:00:00Z",
+					Value: 0.25,
+				}, {Time: "2024-05-10T10:01:00Z", Value: 0},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAggregateResult() = %+v, want %+v", got, want)
+	}
+
+	levels, err := ParseAggregateResult(
+		&api.VlogsAggregateRequest{Aggregate: api.VlogsAggregateLevel},
+		[]byte(`{"_time":"t1","level":"warn","logs_total":"2"}
+{"_time":"t1","level":"","logs_total":"3"}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels.Series) != 2 || levels.Series[0].Name != unknownLevel ||
+		levels.Series[1].Name != "warn" {
+		t.Errorf("unexpected level series %+v", levels.Series)
+	}
+
+	patterns, err := ParseAggregateResult(
+		&api.VlogsAggregateRequest{Aggregate: api.VlogsAggregatePattern},
+		[]byte(`{"_msg":"user <num> logged in","logs_total":"42"}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns.Patterns) != 1 || patterns.Patterns[0].Count != 42 || patterns.Step != "" {
+		t.Errorf("unexpected patterns %+v", patterns)
+	}
+
+	if _, err := ParseAggregateResult(
+		&api.VlogsAggregateRequest{Aggregate: api.VlogsAggregateVolume},
+		[]byte(`{"_time":"t1"}`),
+	); err == nil {
+		t.Error("expected an error for a row without logs_total")
+	}
+}
@@ -35,6 +35,20 @@ func (v *VLogsQuery) GetQuery(req *api.VlogsLaunchpadRequest) (string, error) {
 	return v.query, nil
 }
 
+// GetTailQuery builds the query to tail new logs. It keeps the filters of GetQuery but not the
+// time range, limit and stats, live tailing does not support them.
+func (v *VLogsQuery) GetTailQuery(req *api.VlogsLaunchpadRequest) (string, error) {
+	v.generateKeywordQuery(req)
+	v.generateStreamQuery(req)
+	v.generateTailCommonQuery(req)
+	err := v.generateJSONQuery(req)
+	if err != nil {
+		return "", err
+	}
+	v.generateDropQuery()
+	return v.query, nil
+}
+
 func EscapeSingleQuoted(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	s = strings.ReplaceAll(s, `'`, `\'`)
@@ -174,6 +188,13 @@ func (v *VLogsQuery) generateCommonQuery(req *api.VlogsLaunchpadRequest) {
 	v.query += builder.String()
 }
 
+func (v *VLogsQuery) generateTailCommonQuery(req *api.VlogsLaunchpadRequest) {
+	v.query += fmt.Sprintf(` app:='%s' `, EscapeSingleQuoted(req.App))
+	if req.StderrMode == modeTrue {
+		v.query += `| stream:="stderr" `
+	}
+}
+
 func (v *VLogsQuery) generateDropQuery() {
 	v.query += "| Drop _stream_id,_stream,app,job,namespace,node"
 }
@@ -2,6 +2,8 @@ package query
 
 import (
 	"testing"
+
+	"github.com/labring/sealos/service/pkg/api"
 )
 
 func TestEscapeSingleQuoted(t *testing.T) {
@@ -57,3 +59,60 @@ func TestEscapeSingleQuoted(t *testing.T) {
 		})
 	}
 }
+
+func TestVLogsQuery_GetTailQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *api.VlogsLaunchpadRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "namespace and app only",
+			req: &api.VlogsLaunchpadRequest{
+				Namespace: "ns-a",
+				App:       "hello",
+				Time:      "5m",
+				Limit:     "10",
+			},
+			want: "{namespace='ns-a'} app:='hello' | Drop _stream_id,_stream,app,job,namespace,node",
+		},
+		{
+			name: "pods, stderr, keyword and json filters",
+			req: &api.VlogsLaunchpadRequest{
+				Namespace:  "ns-a",
+				App:        "hello",
+				Pod:        []string{"hello-0", "hello-1"},
+				Keyword:    "error",
+				StderrMode: "true",
+				JSONMode:   "true",
+				JSONQuery:  []api.JSONQuery{{Key: "level", Mode: "=", Value: "warn"}},
+				NumberMode: "true",
+			},
+			want: "'error' {pod='hello-0',namespace='ns-a'} OR {pod='hello-1',namespace='ns-a'} app:='hello' " +
+				"| stream:=\"stderr\"  | unpack_json| 'level':='warn' " +
+				"| Drop _stream_id,_stream,app,job,namespace,node",
+		},
+		{
+			name: "invalid json mode",
+			req: &api.VlogsLaunchpadRequest{
+				Namespace: "ns-a",
+				JSONMode:  "true",
+				JSONQuery: []api.JSONQuery{{Mode: "?"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v VLogsQuery
+			got, err := v.GetTailQuery(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTailQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetTailQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
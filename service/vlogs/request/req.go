@@ -9,6 +9,11 @@ import (
 	"net/url"
 )
 
+const (
+	queryPath = "select/logsql/query"
+	tailPath  = "select/logsql/tail"
+)
+
 type QueryParams struct {
 	Path      string
 	Username  string
@@ -16,15 +21,30 @@ type QueryParams struct {
 	Query     string
 	StartTime string
 	EndTime   string
+	// StartOffset returns the logs of the recent duration like 5m before tailing new logs
+	StartOffset string
 }
 
 func QueryLogsByParams(query *QueryParams) (io.ReadCloser, error) {
-	httpClient := http.DefaultClient
-	req, err := generateReq(query)
+	req, err := generateReq(context.Background(), queryPath, query)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := httpClient.Do(req)
+	return doQuery(req)
+}
+
+// TailLogsByParams streams the new logs matching the query until ctx is done, one JSON line
+// per log.
+func TailLogsByParams(ctx context.Context, query *QueryParams) (io.ReadCloser, error) {
+	req, err := generateReq(ctx, tailPath, query)
+	if err != nil {
+		return nil, err
+	}
+	return doQuery(req)
+}
+
+func doQuery(req *http.Request) (io.ReadCloser, error) {
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP req error: %w", err)
 	}
@@ -49,19 +69,25 @@ func QueryLogsByParams(query *QueryParams) (io.ReadCloser, error) {
 	return resp.Body, nil
 }
 
-func generateReq(query *QueryParams) (*http.Request, error) {
+func generateReq(ctx context.Context, path string, query *QueryParams) (*http.Request, error) {
 	parsedURL, err := url.Parse(query.Path)
 	if err != nil {
 		return nil, fmt.Errorf("can not parser API URL: %w", err)
 	}
-	parsedURL = parsedURL.JoinPath("select/logsql/query")
+	parsedURL = parsedURL.JoinPath(path)
 	params := url.Values{}
 	params.Add("query", query.Query)
-	params.Add("start", query.StartTime)
-	params.Add("end", query.EndTime)
+	if path == tailPath {
+		if query.StartOffset != "" {
+			params.Add("start_offset", query.StartOffset)
+		}
+	} else {
+		params.Add("start", query.StartTime)
+		params.Add("end", query.EndTime)
+	}
 	parsedURL.RawQuery = params.Encode()
 	req, err := http.NewRequestWithContext(
-		context.Background(),
+		ctx,
 		http.MethodGet,
 		parsedURL.String(),
 		nil,
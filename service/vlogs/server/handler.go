@@ -170,3 +170,69 @@ func (vl *VLogsServer) verifyDBParams(
 	}
 	return vlogsReq, kubeConfig, nil
 }
+
+func (vl *VLogsServer) queryLogsAggregate(rw http.ResponseWriter, req *http.Request) error {
+	vlogsReq, kubeConfig, err := vl.verifyAggregateParams(req)
+	if err != nil {
+		return fmt.Errorf("bad request (%w)", err)
+	}
+	err = auth.Authenticate(vlogsReq.Namespace, kubeConfig)
+	if err != nil {
+		return fmt.Errorf("authentication failed (%w)", err)
+	}
+	var vlogs query.AggregateQuery
+	aggregateQuery, err := vlogs.GetAggregateQuery(vlogsReq)
+	if err != nil {
+		return fmt.Errorf("failed to parse request body: %w", err)
+	}
+	resp, err := request.QueryLogsByParams(&request.QueryParams{
+		Path:      vl.path,
+		Query:     aggregateQuery,
+		Username:  vl.username,
+		Password:  vl.password,
+		StartTime: vlogsReq.StartTime,
+		EndTime:   vlogsReq.EndTime,
+	})
+	if err != nil {
+		return fmt.Errorf("query failed (%w)", err)
+	}
+	defer resp.Close()
+	body, err := io.ReadAll(resp)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	result, err := query.ParseAggregateResult(vlogsReq, body)
+	if err != nil {
+		return fmt.Errorf("failed to parse aggregate result: %w", err)
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(result); err != nil {
+		return fmt.Errorf("failed to write response: %w", err)
+	}
+	return nil
+}
+
+func (vl *VLogsServer) verifyAggregateParams(
+	req *http.Request,
+) (*api.VlogsAggregateRequest, string, error) {
+	kubeConfig, err := vl.extractKubeConfig(req)
+	if err != nil {
+		return nil, "", err
+	}
+	vlogsReq := &api.VlogsAggregateRequest{}
+	err = json.NewDecoder(req.Body).Decode(&vlogsReq)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse request body: %w", err)
+	}
+	if vlogsReq.Namespace == "" {
+		return nil, "", errors.New("failed to get namespace")
+	}
+	if err := vl.validateTimeParams(
+		vlogsReq.StartTime,
+		vlogsReq.EndTime,
+		vlogsReq.Time,
+	); err != nil {
+		return nil, "", err
+	}
+	return vlogsReq, kubeConfig, nil
+}
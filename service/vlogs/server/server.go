@@ -56,6 +56,10 @@ func (vl *VLogsServer) queryConvert(
 		return vl.queryPodList, nil
 	case "/queryLogsByPod":
 		return vl.queryDBLogs, nil
+	case "/tailLogsByParams":
+		return vl.tailLogsByParams, nil
+	case "/queryLogsAggregate":
+		return vl.queryLogsAggregate, nil
 	default:
 		return nil, errors.New("unknown url path")
 	}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labring/sealos/service/pkg/auth"
+	"github.com/labring/sealos/service/vlogs/query"
+	"github.com/labring/sealos/service/vlogs/request"
+)
+
+const (
+	contentTypeNDJSON      = "application/x-ndjson"
+	contentTypeEventStream = "text/event-stream"
+	// tailHeartbeatInterval keeps idle streams alive through proxies
+	tailHeartbeatInterval = 15 * time.Second
+	maxTailLineSize       = 1 << 20
+)
+
+// tailLogsByParams streams the new logs of the launchpad filters until the client goes away.
+// The logs are sent as Server-Sent Events if the client accepts text/event-stream, and as
+// chunked NDJSON otherwise. The time param, like 5m, sends the recent logs first.
+func (vl *VLogsServer) tailLogsByParams(rw http.ResponseWriter, req *http.Request) error {
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		return errors.New("streaming is not supported")
+	}
+	resp, err := vl.executeTailQuery(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	sse := strings.Contains(req.Header.Get("Accept"), contentTypeEventStream)
+	if sse {
+		rw.Header().Set("Content-Type", contentTypeEventStream)
+	} else {
+		rw.Header().Set("Content-Type", contentTypeNDJSON)
+	}
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("X-Accel-Buffering", "no")
+	rw.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(resp)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxTailLineSize)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-req.Context().Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if err := writeTailHeartbeat(rw, sse); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		case line, ok := <-lines:
+			if !ok {
+				// the headers are sent, errors can only be logged from now on
+				if err := <-readErr; err != nil && req.Context().Err() == nil {
+					slog.Error("tail logs stream error", "error", err)
+				}
+				return nil
+			}
+			if len(line) == 0 {
+				continue
+			}
+			if err := writeTailLine(rw, line, sse); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (vl *VLogsServer) executeTailQuery(req *http.Request) (io.ReadCloser, error) {
+	vlogsReq, kubeConfig, err := vl.verifyParams(req)
+	if err != nil {
+		return nil, fmt.Errorf("bad request (%w)", err)
+	}
+	if vlogsReq.StartTime != "" || vlogsReq.EndTime != "" {
+		return nil, errors.New("bad request (tailing does not support a time range)")
+	}
+	err = auth.Authenticate(vlogsReq.Namespace, kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed (%w)", err)
+	}
+	var vlogs query.VLogsQuery
+	query, err := vlogs.GetTailQuery(vlogsReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request body: %w", err)
+	}
+	resp, err := request.TailLogsByParams(req.Context(), &request.QueryParams{
+		Path:        vl.path,
+		Query:       query,
+		Username:    vl.username,
+		Password:    vl.password,
+		StartOffset: vlogsReq.Time,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query failed (%w)", err)
+	}
+	return resp, nil
+}
+
+func writeTailLine(w io.Writer, line []byte, sse bool) error {
+	var err error
+	if sse {
+		_, err = fmt.Fprintf(w, "data: %s\n\n", line)
+	} else {
+		_, err = fmt.Fprintf(w, "%s\n", line)
+	}
+	return err
+}
+
+func writeTailHeartbeat(w io.Writer, sse bool) error {
+	var err error
+	if sse {
+		_, err = io.WriteString(w, ": heartbeat\n\n")
+	} else {
+		// an empty line is skipped by NDJSON readers
+		_, err = io.WriteString(w, "\n")
+	}
+	return err
+}